@@ -181,6 +181,7 @@ replace github.com/cosmos/iavl => github.com/cosmos/iavl v1.0.0-beta.1.0.2024081
 // TODO remove post spinning out all modules
 replace (
 	cosmossdk.io/api => ./../../api
+	cosmossdk.io/collections => ./../../collections
 	cosmossdk.io/core => ./../../core
 	cosmossdk.io/core/testing => ../../core/testing
 	cosmossdk.io/store => ./../../store
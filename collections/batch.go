@@ -0,0 +1,286 @@
+package collections
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/tidwall/btree"
+
+	"cosmossdk.io/core/store"
+)
+
+// ErrBatchClosed is returned when a Batch is used after being written or discarded.
+var ErrBatchClosed = errors.New("collections: batch already written or discarded")
+
+// batchContextKey identifies the batch of a specific schema in a context.Context.
+// It is not zero sized so that every schema gets a distinct pointer.
+type batchContextKey struct{ _ byte }
+
+// batchAwareAccessor wraps the provided store accessor so that collections
+// operating on a context returned by Batch.Context read and write through the
+// batch instead of the underlying store.
+func batchAwareAccessor(key *batchContextKey, accessor func(context.Context) store.KVStore) func(context.Context) store.KVStore {
+	return func(ctx context.Context) store.KVStore {
+		if b, ok := ctx.Value(key).(*Batch); ok {
+			return b.store
+		}
+		return accessor(ctx)
+	}
+}
+
+// Batch stages the writes performed on all the collections of a Schema, and
+// applies or discards them as a unit. Collections must be used with the context
+// returned by Context in order for their writes to be staged. Reads performed
+// with such context, including iterations, observe the staged writes.
+// Batches can be nested, in which case the inner batch writes into the outer one.
+// A Batch is not safe for concurrent use.
+type Batch struct {
+	ctx       context.Context
+	parentCtx context.Context
+	accessor  func(context.Context) store.KVStore
+	store     *batchStore
+}
+
+// NewBatch creates a new Batch which stages the writes performed on the
+// collections of the schema on top of the store reachable from ctx.
+func (s Schema) NewBatch(ctx context.Context) *Batch {
+	b := &Batch{
+		parentCtx: ctx,
+		accessor:  s.storeAccessor,
+		store:     &batchStore{parent: s.storeAccessor(ctx), writes: new(btree.Map[string, batchWrite])},
+	}
+	b.ctx = context.WithValue(ctx, s.batchKey, b)
+	return b
+}
+
+// Context returns the context that collections must be used with in order
+// to read and write through the batch.
+func (b *Batch) Context() context.Context {
+	return b.ctx
+}
+
+// Write applies the staged writes, in key order, to the underlying store.
+// The batch cannot be used afterward.
+func (b *Batch) Write() error {
+	if b.store.closed {
+		return ErrBatchClosed
+	}
+	b.store.closed = true
+	kv := b.accessor(b.parentCtx)
+	var err error
+	b.store.writes.Scan(func(key string, w batchWrite) bool {
+		if w.deleted {
+			err = kv.Delete([]byte(key))
+		} else {
+			err = kv.Set([]byte(key), w.value)
+		}
+		return err == nil
+	})
+	b.store.writes.Clear()
+	return err
+}
+
+// Discard drops the staged writes. The batch cannot be used afterward.
+func (b *Batch) Discard() {
+	b.store.closed = true
+	b.store.writes.Clear()
+}
+
+// Atomic runs f with a context which stages all the writes performed on the
+// collections of the schema. The writes are applied only if f returns no error,
+// otherwise they are discarded and the error of f is returned.
+func (s Schema) Atomic(ctx context.Context, f func(ctx context.Context) error) error {
+	b := s.NewBatch(ctx)
+	if err := f(b.Context()); err != nil {
+		b.Discard()
+		return err
+	}
+	return b.Write()
+}
+
+// batchWrite is a staged write, deleted is true if the key was removed.
+type batchWrite struct {
+	value   []byte
+	deleted bool
+}
+
+var _ store.KVStore = (*batchStore)(nil)
+
+// batchStore is a store.KVStore which keeps the writes in memory on top of
+// a parent store.
+type batchStore struct {
+	parent store.KVStore
+	writes *btree.Map[string, batchWrite]
+	closed bool
+}
+
+func (s *batchStore) Get(key []byte) ([]byte, error) {
+	if w, ok := s.writes.Get(string(key)); ok {
+		if w.deleted {
+			return nil, nil
+		}
+		return w.value, nil
+	}
+	return s.parent.Get(key)
+}
+
+func (s *batchStore) Has(key []byte) (bool, error) {
+	if w, ok := s.writes.Get(string(key)); ok {
+		return !w.deleted, nil
+	}
+	return s.parent.Has(key)
+}
+
+func (s *batchStore) Set(key, value []byte) error {
+	if s.closed {
+		return ErrBatchClosed
+	}
+	// the value is copied so that the caller can reuse its slice
+	s.writes.Set(string(key), batchWrite{value: bytes.Clone(value)})
+	return nil
+}
+
+func (s *batchStore) Delete(key []byte) error {
+	if s.closed {
+		return ErrBatchClosed
+	}
+	s.writes.Set(string(key), batchWrite{deleted: true})
+	return nil
+}
+
+func (s *batchStore) Iterator(start, end []byte) (store.Iterator, error) {
+	return s.iterator(start, end, true)
+}
+
+func (s *batchStore) ReverseIterator(start, end []byte) (store.Iterator, error) {
+	return s.iterator(start, end, false)
+}
+
+func (s *batchStore) iterator(start, end []byte, ascending bool) (store.Iterator, error) {
+	var (
+		parent store.Iterator
+		err    error
+	)
+	if ascending {
+		parent, err = s.parent.Iterator(start, end)
+	} else {
+		parent, err = s.parent.ReverseIterator(start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the staged writes in the domain are snapshotted, so that writing
+	// while iterating does not invalidate the iterator.
+	var writes []batchEntry
+	collect := func(key string, w batchWrite) bool {
+		switch {
+		case end != nil && key >= string(end):
+			// past the domain when ascending, not yet in it when descending
+			return !ascending
+		case start != nil && key < string(start):
+			return ascending
+		}
+		writes = append(writes, batchEntry{key: []byte(key), batchWrite: w})
+		return true
+	}
+	switch {
+	case ascending && start != nil:
+		s.writes.Ascend(string(start), collect)
+	case ascending:
+		s.writes.Scan(collect)
+	case end != nil:
+		s.writes.Descend(string(end), collect)
+	default:
+		s.writes.Reverse(collect)
+	}
+
+	it := &batchIterator{
+		parent:    parent,
+		writes:    writes,
+		ascending: ascending,
+		start:     start,
+		end:       end,
+	}
+	it.advance()
+	return it, nil
+}
+
+type batchEntry struct {
+	key []byte
+	batchWrite
+}
+
+var _ store.Iterator = (*batchIterator)(nil)
+
+// batchIterator merges the iterator of the parent store with the staged
+// writes, the staged writes take precedence over the parent values.
+type batchIterator struct {
+	parent     store.Iterator
+	writes     []batchEntry
+	ascending  bool
+	start, end []byte
+
+	valid      bool
+	key, value []byte
+}
+
+// advance moves the iterator to the next visible key, skipping
+// the keys that were deleted in the batch.
+func (i *batchIterator) advance() {
+	for {
+		parentValid := i.parent.Valid()
+		writesValid := len(i.writes) > 0
+		if !parentValid && !writesValid {
+			i.valid = false
+			return
+		}
+
+		if parentValid {
+			cmp := -1
+			if writesValid {
+				cmp = bytes.Compare(i.parent.Key(), i.writes[0].key)
+				if !i.ascending {
+					cmp = -cmp
+				}
+			}
+			if cmp < 0 {
+				i.key, i.value, i.valid = i.parent.Key(), i.parent.Value(), true
+				i.parent.Next()
+				return
+			}
+			// the parent key is shadowed by the staged write
+			if cmp == 0 {
+				i.parent.Next()
+			}
+		}
+
+		w := i.writes[0]
+		i.writes = i.writes[1:]
+		if w.deleted {
+			continue
+		}
+		i.key, i.value, i.valid = w.key, w.value, true
+		return
+	}
+}
+
+func (i *batchIterator) Domain() (start, end []byte) { return i.start, i.end }
+
+func (i *batchIterator) Valid() bool { return i.valid }
+
+func (i *batchIterator) Next() {
+	if !i.valid {
+		panic("collections: Next called on invalid batch iterator")
+	}
+	i.advance()
+}
+
+func (i *batchIterator) Key() []byte { return i.key }
+
+func (i *batchIterator) Value() []byte { return i.value }
+
+func (i *batchIterator) Error() error { return i.parent.Error() }
+
+func (i *batchIterator) Close() error { return i.parent.Close() }
@@ -0,0 +1,202 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/btree"
+
+	"cosmossdk.io/core/store"
+)
+
+func TestBatch(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	m := NewMap(schemaBuilder, NewPrefix(0), "m", StringKey, Uint64Value)
+	item := NewItem(schemaBuilder, NewPrefix(1), "item", StringValue)
+	schema, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	require.NoError(t, m.Set(ctx, "a", 1))
+	require.NoError(t, m.Set(ctx, "c", 3))
+	require.NoError(t, m.Set(ctx, "e", 5))
+
+	batch := schema.NewBatch(ctx)
+	bctx := batch.Context()
+	require.NoError(t, m.Set(bctx, "b", 2))
+	require.NoError(t, m.Set(bctx, "c", 30))
+	require.NoError(t, m.Remove(bctx, "e"))
+	require.NoError(t, item.Set(bctx, "staged"))
+
+	// read your writes
+	v, err := m.Get(bctx, "c")
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), v)
+	has, err := m.Has(bctx, "e")
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// iteration merges the staged writes with the underlying state
+	iter, err := m.Iterate(bctx, nil)
+	require.NoError(t, err)
+	kvs, err := iter.KeyValues()
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue[string, uint64]{{"a", 1}, {"b", 2}, {"c", 30}}, kvs)
+
+	iter, err = m.Iterate(bctx, new(Range[string]).StartExclusive("a").EndInclusive("e").Descending())
+	require.NoError(t, err)
+	keys, err := iter.Keys()
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b"}, keys)
+
+	// nothing touched the underlying state
+	v, err = m.Get(ctx, "c")
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), v)
+	_, err = item.Get(ctx)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, batch.Write())
+	iter, err = m.Iterate(ctx, nil)
+	require.NoError(t, err)
+	kvs, err = iter.KeyValues()
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue[string, uint64]{{"a", 1}, {"b", 2}, {"c", 30}}, kvs)
+	itemValue, err := item.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "staged", itemValue)
+
+	// the batch cannot be reused
+	require.ErrorIs(t, batch.Write(), ErrBatchClosed)
+	require.ErrorIs(t, m.Set(bctx, "d", 4), ErrBatchClosed)
+}
+
+func TestBatchDiscard(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	m := NewMap(schemaBuilder, NewPrefix(0), "m", StringKey, Uint64Value)
+	schema, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	batch := schema.NewBatch(ctx)
+	require.NoError(t, m.Set(batch.Context(), "a", 1))
+	batch.Discard()
+
+	has, err := m.Has(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestBatchNested(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	m := NewMap(schemaBuilder, NewPrefix(0), "m", StringKey, Uint64Value)
+	schema, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	outer := schema.NewBatch(ctx)
+	require.NoError(t, m.Set(outer.Context(), "a", 1))
+
+	inner := schema.NewBatch(outer.Context())
+	require.NoError(t, m.Set(inner.Context(), "b", 2))
+	require.NoError(t, inner.Write())
+
+	// the inner batch wrote into the outer one
+	has, err := m.Has(outer.Context(), "b")
+	require.NoError(t, err)
+	require.True(t, has)
+	has, err = m.Has(ctx, "b")
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.NoError(t, outer.Write())
+	has, err = m.Has(ctx, "b")
+	require.NoError(t, err)
+	require.True(t, has)
+}
+
+func TestSchemaAtomic(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	m := NewMap(schemaBuilder, NewPrefix(0), "m", StringKey, Uint64Value)
+	seq := NewSequence(schemaBuilder, NewPrefix(1), "seq")
+	schema, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	errInvalid := errors.New("invalid")
+	err = schema.Atomic(ctx, func(ctx context.Context) error {
+		id, err := seq.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if err := m.Set(ctx, "a", id); err != nil {
+			return err
+		}
+		return errInvalid
+	})
+	require.ErrorIs(t, err, errInvalid)
+
+	// nothing was written
+	has, err := m.Has(ctx, "a")
+	require.NoError(t, err)
+	require.False(t, has)
+	id, err := seq.Peek(ctx)
+	require.NoError(t, err)
+	require.Equal(t, DefaultSequenceStart, id)
+
+	err = schema.Atomic(ctx, func(ctx context.Context) error {
+		return m.Set(ctx, "a", 1)
+	})
+	require.NoError(t, err)
+	v, err := m.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), v)
+}
+
+func TestBatchCopiesValues(t *testing.T) {
+	sk, ctx := deps()
+	bs := &batchStore{parent: sk.OpenKVStore(ctx), writes: new(btree.Map[string, batchWrite])}
+
+	value := []byte("value")
+	require.NoError(t, bs.Set([]byte("a"), value))
+	// reusing the slice of the value does not change the staged write
+	copy(value, "other")
+
+	v, err := bs.Get([]byte("a"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value"), v)
+}
+
+// errIterator is an empty store.Iterator failing with err.
+type errIterator struct {
+	store.Iterator
+	err error
+}
+
+func (i errIterator) Valid() bool  { return false }
+func (i errIterator) Error() error { return i.err }
+func (i errIterator) Close() error { return nil }
+
+// errIteratorStore is a store.KVStore whose iterators fail with err.
+type errIteratorStore struct {
+	store.KVStore
+	err error
+}
+
+func (s errIteratorStore) Iterator(_, _ []byte) (store.Iterator, error) {
+	return errIterator{err: s.err}, nil
+}
+
+func TestBatchIteratorError(t *testing.T) {
+	expectedErr := errors.New("iterator failure")
+	bs := &batchStore{parent: errIteratorStore{err: expectedErr}, writes: new(btree.Map[string, batchWrite])}
+	require.NoError(t, bs.Set([]byte("a"), []byte("1")))
+
+	iter, err := bs.Iterator(nil, nil)
+	require.NoError(t, err)
+	require.True(t, iter.Valid())
+	require.ErrorIs(t, iter.Error(), expectedErr)
+	require.NoError(t, iter.Close())
+}
@@ -0,0 +1,67 @@
+package codec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// timeSize is the size of an encoded time: 8 bytes for the seconds and 4 bytes
+// for the nanoseconds.
+const timeSize = 12
+
+func NewTimeKey() KeyCodec[time.Time] { return timeKey{} }
+
+type timeKey struct{}
+
+// Encode encodes the time as its seconds since the unix epoch, with the MSB toggled
+// to retain ordering of times before the epoch, followed by its nanoseconds. Both
+// are big endian. The location of the time is not encoded, decoded times are UTC.
+func (t timeKey) Encode(buffer []byte, key time.Time) (int, error) {
+	binary.BigEndian.PutUint64(buffer, uint64(key.Unix()))
+	buffer[0] ^= 0x80
+	binary.BigEndian.PutUint32(buffer[8:], uint32(key.Nanosecond()))
+	return timeSize, nil
+}
+
+func (t timeKey) Decode(buffer []byte) (int, time.Time, error) {
+	if len(buffer) < timeSize {
+		return 0, time.Time{}, fmt.Errorf("%w: invalid buffer size, wanted: %d", ErrEncoding, timeSize)
+	}
+	secs := binary.BigEndian.Uint64(buffer) ^ (0x80 << 56)
+	nanos := binary.BigEndian.Uint32(buffer[8:])
+	if nanos >= uint32(time.Second) {
+		return 0, time.Time{}, fmt.Errorf("%w: invalid nanoseconds: %d", ErrEncoding, nanos)
+	}
+	return timeSize, time.Unix(int64(secs), int64(nanos)).UTC(), nil
+}
+
+func (t timeKey) Size(_ time.Time) int { return timeSize }
+
+func (t timeKey) EncodeJSON(value time.Time) ([]byte, error) {
+	return value.MarshalJSON()
+}
+
+func (t timeKey) DecodeJSON(b []byte) (time.Time, error) {
+	var value time.Time
+	err := value.UnmarshalJSON(b)
+	return value, err
+}
+
+func (t timeKey) Stringify(key time.Time) string { return key.UTC().Format(time.RFC3339Nano) }
+
+func (t timeKey) KeyType() string {
+	return "time"
+}
+
+func (t timeKey) EncodeNonTerminal(buffer []byte, key time.Time) (int, error) {
+	return t.Encode(buffer, key)
+}
+
+func (t timeKey) DecodeNonTerminal(buffer []byte) (int, time.Time, error) {
+	return t.Decode(buffer)
+}
+
+func (t timeKey) SizeNonTerminal(_ time.Time) int {
+	return timeSize
+}
@@ -0,0 +1,42 @@
+package codec
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"pgregory.net/rapid"
+)
+
+// TestTimeKeys applies the same logic as TestInt64Keys to times, including
+// times before the unix epoch and times sharing the same second.
+func TestTimeKeys(t *testing.T) {
+	kc := NewTimeKey()
+	rapid.Check(t, func(t *rapid.T) {
+		secs := rapid.SliceOfN(rapid.Int64Range(-1<<40, 1<<40), 1_000, 5_000).Draw(t, "random seconds")
+		nanos := rapid.SliceOfN(rapid.Int64Range(0, int64(time.Second)-1), len(secs), len(secs)).Draw(t, "random nanos")
+		slice := make([]time.Time, len(secs))
+		for i := range secs {
+			slice[i] = time.Unix(secs[i], nanos[i]).UTC()
+		}
+		sort.Slice(slice, func(i, j int) bool {
+			return slice[i].Before(slice[j])
+		})
+
+		var current []byte
+		for _, i := range slice {
+			next := make([]byte, kc.Size(i))
+			_, err := kc.Encode(next, i)
+			require.NoError(t, err)
+			cmp := bytes.Compare(current, next)
+			require.True(t, cmp == 0 || cmp == -1)
+			current = next
+
+			_, decoded, err := kc.Decode(next)
+			require.NoError(t, err)
+			require.Equal(t, i, decoded)
+		}
+	})
+}
@@ -52,6 +52,10 @@ var (
 	// BoolKey can be used to encode booleans. It uses a single byte to represent the boolean.
 	// 0x0 is used to represent false, and 0x1 is used to represent true.
 	BoolKey = codec.NewBoolKey[bool]()
+	// TimeKey can be used to encode time.Time keys. It encodes the seconds since the
+	// unix epoch like Int64Key, followed by the nanoseconds in big endian, to retain ordering.
+	// Times are decoded in UTC.
+	TimeKey = codec.NewTimeKey()
 )
 
 // VALUES
@@ -73,6 +77,8 @@ var (
 	StringValue = codec.KeyToValueCodec(StringKey)
 	// BytesValue implements a ValueCodec for bytes.
 	BytesValue = codec.KeyToValueCodec(BytesKey)
+	// TimeValue implements a ValueCodec for time.Time.
+	TimeValue = codec.KeyToValueCodec(TimeKey)
 )
 
 // Collection is the interface that all collections implement. It will eventually
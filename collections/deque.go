@@ -0,0 +1,222 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/collections/codec"
+)
+
+// ErrEmptyDeque is returned when trying to pop or peek an element from an empty Deque.
+var ErrEmptyDeque = errors.New("deque is empty")
+
+const (
+	DequeElementsNameSuffix   = "_elements"
+	DequeHeadNameSuffix       = "_head"
+	DequeTailNameSuffix       = "_tail"
+	DequeElementsPrefixSuffix = 0x0
+	DequeHeadPrefixSuffix     = 0x1
+	DequeTailPrefixSuffix     = 0x2
+)
+
+// dequeStart is the position of the first element pushed in an empty Deque.
+// Positions start in the middle of the uint64 range so that the Deque can
+// grow in both directions.
+const dequeStart uint64 = 1 << 63
+
+// NewDeque creates a new Deque instance. Since Deque relies on three collections, one for the
+// elements and two for the positions of its ends, it will register three state objects on the
+// schema builder. The elements are a map, whose prefix is the provided prefix with a suffix which
+// equals to DequeElementsPrefixSuffix, the name is also suffixed with DequeElementsNameSuffix.
+// The head and the tail are items, whose prefixes are suffixed with DequeHeadPrefixSuffix and
+// DequeTailPrefixSuffix, and whose names are suffixed with DequeHeadNameSuffix and DequeTailNameSuffix.
+func NewDeque[T any](sb *SchemaBuilder, prefix Prefix, name string, vc codec.ValueCodec[T]) Deque[T] {
+	return Deque[T]{
+		head:     NewItem(sb, append(prefix, DequeHeadPrefixSuffix), name+DequeHeadNameSuffix, Uint64Value),
+		tail:     NewItem(sb, append(prefix, DequeTailPrefixSuffix), name+DequeTailNameSuffix, Uint64Value),
+		elements: NewMap(sb, append(prefix, DequeElementsPrefixSuffix), name+DequeElementsNameSuffix, Uint64Key, vc),
+	}
+}
+
+// Deque is a double-ended queue sitting on top of a KVStore. Elements can be
+// pushed and popped at both ends in constant time, which makes it suitable for
+// FIFO queues. It relies on a Map[uint64, T] for the elements, which are stored
+// at consecutive positions from the head (inclusive) to the tail (exclusive),
+// and on two Item[uint64] for the positions of the head and of the tail.
+// Iteration goes from the front to the back of the Deque.
+type Deque[T any] struct {
+	head     Item[uint64]
+	tail     Item[uint64]
+	elements Map[uint64, T]
+}
+
+// bounds returns the position of the head and of the tail of the Deque.
+func (d Deque[T]) bounds(ctx context.Context) (head, tail uint64, err error) {
+	head, err = d.head.Get(ctx)
+	if errors.Is(err, ErrNotFound) {
+		head = dequeStart
+	} else if err != nil {
+		return 0, 0, err
+	}
+	tail, err = d.tail.Get(ctx)
+	if errors.Is(err, ErrNotFound) {
+		tail = dequeStart
+	} else if err != nil {
+		return 0, 0, err
+	}
+	return head, tail, nil
+}
+
+// PushFront adds an element to the front of the Deque.
+func (d Deque[T]) PushFront(ctx context.Context, elem T) error {
+	head, _, err := d.bounds(ctx)
+	if err != nil {
+		return err
+	}
+	head--
+	err = d.elements.Set(ctx, head, elem)
+	if err != nil {
+		return err
+	}
+	return d.head.Set(ctx, head)
+}
+
+// PushBack adds an element to the back of the Deque.
+func (d Deque[T]) PushBack(ctx context.Context, elem T) error {
+	_, tail, err := d.bounds(ctx)
+	if err != nil {
+		return err
+	}
+	err = d.elements.Set(ctx, tail, elem)
+	if err != nil {
+		return err
+	}
+	return d.tail.Set(ctx, tail+1)
+}
+
+// PopFront removes the element at the front of the Deque and returns it.
+// Fails with ErrEmptyDeque if the Deque is empty.
+func (d Deque[T]) PopFront(ctx context.Context) (elem T, err error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if head == tail {
+		return elem, ErrEmptyDeque
+	}
+	elem, err = d.elements.Get(ctx, head)
+	if err != nil {
+		return elem, err
+	}
+	err = d.elements.Remove(ctx, head)
+	if err != nil {
+		return elem, err
+	}
+	return elem, d.setBounds(ctx, head+1, tail)
+}
+
+// PopBack removes the element at the back of the Deque and returns it.
+// Fails with ErrEmptyDeque if the Deque is empty.
+func (d Deque[T]) PopBack(ctx context.Context) (elem T, err error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if head == tail {
+		return elem, ErrEmptyDeque
+	}
+	tail--
+	elem, err = d.elements.Get(ctx, tail)
+	if err != nil {
+		return elem, err
+	}
+	err = d.elements.Remove(ctx, tail)
+	if err != nil {
+		return elem, err
+	}
+	return elem, d.setBounds(ctx, head, tail)
+}
+
+// setBounds sets the positions of the head and of the tail after a pop.
+// When the Deque becomes empty, both are removed so that the Deque does not
+// drift towards one end of the uint64 range when used as a FIFO queue.
+func (d Deque[T]) setBounds(ctx context.Context, head, tail uint64) error {
+	if head == tail {
+		err := d.head.Remove(ctx)
+		if err != nil {
+			return err
+		}
+		return d.tail.Remove(ctx)
+	}
+	err := d.head.Set(ctx, head)
+	if err != nil {
+		return err
+	}
+	return d.tail.Set(ctx, tail)
+}
+
+// Front returns the element at the front of the Deque without removing it.
+// Fails with ErrEmptyDeque if the Deque is empty.
+func (d Deque[T]) Front(ctx context.Context) (elem T, err error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if head == tail {
+		return elem, ErrEmptyDeque
+	}
+	return d.elements.Get(ctx, head)
+}
+
+// Back returns the element at the back of the Deque without removing it.
+// Fails with ErrEmptyDeque if the Deque is empty.
+func (d Deque[T]) Back(ctx context.Context) (elem T, err error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if head == tail {
+		return elem, ErrEmptyDeque
+	}
+	return d.elements.Get(ctx, tail-1)
+}
+
+// Get returns the element at a given index, where index 0 is the front of the
+// Deque. Returns ErrOutOfBounds if the index is out of bounds.
+func (d Deque[T]) Get(ctx context.Context, index uint64) (elem T, err error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if index >= tail-head {
+		return elem, fmt.Errorf("%w: index %d", ErrOutOfBounds, index)
+	}
+	return d.elements.Get(ctx, head+index)
+}
+
+// Len returns the number of elements in the Deque.
+func (d Deque[T]) Len(ctx context.Context) (uint64, error) {
+	head, tail, err := d.bounds(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return tail - head, nil
+}
+
+// Walk walks over the Deque from the front to the back, or from the back to
+// the front if reverse is true. It calls the walkFn for each element, where the
+// index is the index of the element from the front of the Deque.
+func (d Deque[T]) Walk(ctx context.Context, reverse bool, walkFn func(index uint64, elem T) (stop bool, err error)) error {
+	head, _, err := d.bounds(ctx)
+	if err != nil {
+		return err
+	}
+	rng := new(Range[uint64])
+	if reverse {
+		rng = rng.Descending()
+	}
+	return d.elements.Walk(ctx, rng, func(position uint64, elem T) (bool, error) {
+		return walkFn(position-head, elem)
+	})
+}
@@ -0,0 +1,168 @@
+package collections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/schema"
+)
+
+func TestDeque(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	deque := NewDeque(schemaBuilder, NewPrefix(0), "deque", StringValue)
+	_, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	// empty deque
+	length, err := deque.Len(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), length)
+	_, err = deque.PopFront(ctx)
+	require.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.PopBack(ctx)
+	require.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.Front(ctx)
+	require.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.Back(ctx)
+	require.ErrorIs(t, err, ErrEmptyDeque)
+	_, err = deque.Get(ctx, 0)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+
+	// push on both ends: b, a, c, d
+	require.NoError(t, deque.PushBack(ctx, "c"))
+	require.NoError(t, deque.PushFront(ctx, "a"))
+	require.NoError(t, deque.PushBack(ctx, "d"))
+	require.NoError(t, deque.PushFront(ctx, "b"))
+
+	length, err = deque.Len(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), length)
+
+	front, err := deque.Front(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "b", front)
+	back, err := deque.Back(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "d", back)
+
+	elem, err := deque.Get(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, "c", elem)
+	_, err = deque.Get(ctx, 4)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+
+	walk := func(reverse bool) ([]uint64, []string) {
+		var indexes []uint64
+		var elems []string
+		require.NoError(t, deque.Walk(ctx, reverse, func(index uint64, elem string) (bool, error) {
+			indexes = append(indexes, index)
+			elems = append(elems, elem)
+			return false, nil
+		}))
+		return indexes, elems
+	}
+	indexes, elems := walk(false)
+	require.Equal(t, []uint64{0, 1, 2, 3}, indexes)
+	require.Equal(t, []string{"b", "a", "c", "d"}, elems)
+	indexes, elems = walk(true)
+	require.Equal(t, []uint64{3, 2, 1, 0}, indexes)
+	require.Equal(t, []string{"d", "c", "a", "b"}, elems)
+
+	// pop on both ends
+	elem, err = deque.PopFront(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "b", elem)
+	elem, err = deque.PopBack(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "d", elem)
+	elem, err = deque.PopBack(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "c", elem)
+	elem, err = deque.PopBack(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "a", elem)
+
+	length, err = deque.Len(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), length)
+	_, err = deque.PopFront(ctx)
+	require.ErrorIs(t, err, ErrEmptyDeque)
+
+	// once empty, the positions are reset
+	has, err := deque.head.Has(ctx)
+	require.NoError(t, err)
+	require.False(t, has)
+	has, err = deque.tail.Has(ctx)
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestDequeFIFO(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	deque := NewDeque(schemaBuilder, NewPrefix(0), "deque", Uint64Value)
+	_, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	var next uint64
+	for i := uint64(0); i < 100; i++ {
+		require.NoError(t, deque.PushBack(ctx, i))
+		if i%3 == 2 {
+			elem, err := deque.PopFront(ctx)
+			require.NoError(t, err)
+			require.Equal(t, next, elem)
+			next++
+		}
+	}
+
+	length, err := deque.Len(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 100-next, length)
+
+	for ; next < 100; next++ {
+		elem, err := deque.PopFront(ctx)
+		require.NoError(t, err)
+		require.Equal(t, next, elem)
+	}
+}
+
+func TestDequeModuleCodec(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	deque := NewDeque(schemaBuilder, NewPrefix(0), "deque", StringValue)
+	sch, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	cdc, err := sch.ModuleCodec(IndexingOptions{})
+	require.NoError(t, err)
+	for _, name := range []string{"deque_elements", "deque_head", "deque_tail"} {
+		_, ok := cdc.Schema.LookupType(name)
+		require.True(t, ok, name)
+	}
+
+	// elements are decoded like any other object
+	require.NoError(t, deque.PushBack(ctx, "a"))
+	updates, err := cdc.KVDecoder(schema.KVPairUpdate{
+		Key:   append([]byte{0, DequeElementsPrefixSuffix}, 0x80, 0, 0, 0, 0, 0, 0, 0),
+		Value: []byte("a"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.ObjectUpdate{{
+		TypeName: "deque_elements",
+		Key:      dequeStart,
+		Value:    "a",
+	}}, updates)
+
+	// the ends are decoded as singletons
+	updates, err = cdc.KVDecoder(schema.KVPairUpdate{
+		Key:   []byte{0, DequeTailPrefixSuffix},
+		Value: []byte{0x80, 0, 0, 0, 0, 0, 0, 1},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.ObjectUpdate{{
+		TypeName: "deque_tail",
+		Value:    dequeStart + 1,
+	}}, updates)
+}
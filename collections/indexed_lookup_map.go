@@ -0,0 +1,110 @@
+package collections
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/collections/codec"
+)
+
+// IndexedLookupMap works like an IndexedMap but its primary storage is a
+// LookupMap, which means that the objects cannot be iterated over using the
+// primary key. Objects can still be found through the Indexes, which maintain
+// iterable references from fields of Value to the PrimaryKey, the Value can then
+// be fetched using Get.
+type IndexedLookupMap[PrimaryKey, Value, Idx any] struct {
+	Indexes         Idx
+	computedIndexes []Index[PrimaryKey, Value]
+	m               LookupMap[PrimaryKey, Value]
+}
+
+// NewIndexedLookupMapSafe behaves like NewIndexedLookupMap but returns errors.
+func NewIndexedLookupMapSafe[K, V, I any](
+	schema *SchemaBuilder,
+	prefix Prefix,
+	name string,
+	pkCodec codec.KeyCodec[K],
+	valueCodec codec.ValueCodec[V],
+	indexes I,
+) (im *IndexedLookupMap[K, V, I], err error) {
+	var indexesList []Index[K, V]
+	indexesImpl, ok := any(indexes).(Indexes[K, V])
+	if ok {
+		indexesList = indexesImpl.IndexesList()
+	} else {
+		// if does not implement Indexes, then we try to infer using reflection
+		indexesList, err = tryInferIndexes[I, K, V](indexes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to infer indexes using reflection, consider implementing Indexes interface: %w", err)
+		}
+	}
+
+	return &IndexedLookupMap[K, V, I]{
+		computedIndexes: indexesList,
+		Indexes:         indexes,
+		m:               NewLookupMap(schema, prefix, name, pkCodec, valueCodec),
+	}, nil
+}
+
+// NewIndexedLookupMap instantiates a new IndexedLookupMap. It accepts the same
+// arguments as NewIndexedMap, panicking on failure to create indexes. If you
+// want an erroring API use NewIndexedLookupMapSafe.
+func NewIndexedLookupMap[PrimaryKey, Value, Idx any](
+	schema *SchemaBuilder,
+	prefix Prefix,
+	name string,
+	pkCodec codec.KeyCodec[PrimaryKey],
+	valueCodec codec.ValueCodec[Value],
+	indexes Idx,
+) *IndexedLookupMap[PrimaryKey, Value, Idx] {
+	im, err := NewIndexedLookupMapSafe(schema, prefix, name, pkCodec, valueCodec, indexes)
+	if err != nil {
+		panic(err)
+	}
+	return im
+}
+
+// Get gets the object given its primary key.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) Get(ctx context.Context, pk PrimaryKey) (Value, error) {
+	return m.m.Get(ctx, pk)
+}
+
+// Has reports if exists a value with the provided primary key.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) Has(ctx context.Context, pk PrimaryKey) (bool, error) {
+	return m.m.Has(ctx, pk)
+}
+
+// Set maps the value using the primary key. It will also iterate every index and instruct them to
+// add or update the indexes.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) Set(ctx context.Context, pk PrimaryKey, value Value) error {
+	for _, index := range m.computedIndexes {
+		err := index.Reference(ctx, pk, value, cachedGet[PrimaryKey, Value](ctx, m, pk))
+		if err != nil {
+			return err
+		}
+	}
+	return m.m.Set(ctx, pk, value)
+}
+
+// Remove removes the value associated with the primary key from the map. Then
+// it iterates over all the indexes and instructs them to remove all the references
+// associated with the removed value.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) Remove(ctx context.Context, pk PrimaryKey) error {
+	for _, index := range m.computedIndexes {
+		err := index.Unreference(ctx, pk, cachedGet[PrimaryKey, Value](ctx, m, pk))
+		if err != nil {
+			return err
+		}
+	}
+	return m.m.Remove(ctx, pk)
+}
+
+// KeyCodec returns the primary key codec.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) KeyCodec() codec.KeyCodec[PrimaryKey] {
+	return m.m.KeyCodec()
+}
+
+// ValueCodec returns the value codec.
+func (m *IndexedLookupMap[PrimaryKey, Value, Idx]) ValueCodec() codec.ValueCodec[Value] {
+	return m.m.ValueCodec()
+}
@@ -0,0 +1,123 @@
+package collections_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/collections/indexes"
+	"cosmossdk.io/core/testing"
+	"cosmossdk.io/schema"
+)
+
+type balanceIndexes struct {
+	// Denom indexes the accounts holding a balance of a given denom.
+	Denom *indexes.Multi[string, collections.Pair[string, string], uint64]
+}
+
+func (b balanceIndexes) IndexesList() []collections.Index[collections.Pair[string, string], uint64] {
+	return []collections.Index[collections.Pair[string, string], uint64]{b.Denom}
+}
+
+func newTestIndexedLookupMap(sb *collections.SchemaBuilder) *collections.IndexedLookupMap[collections.Pair[string, string], uint64, balanceIndexes] {
+	return collections.NewIndexedLookupMap(
+		sb, collections.NewPrefix(0), "balances",
+		collections.PairKeyCodec(collections.StringKey, collections.StringKey),
+		collections.Uint64Value,
+		balanceIndexes{
+			Denom: indexes.NewMulti(
+				sb, collections.NewPrefix(1), "balances_by_denom",
+				collections.StringKey, collections.PairKeyCodec(collections.StringKey, collections.StringKey),
+				func(pk collections.Pair[string, string], _ uint64) (string, error) {
+					return pk.K2(), nil
+				},
+			),
+		},
+	)
+}
+
+func TestIndexedLookupMap(t *testing.T) {
+	ctx := coretesting.Context()
+	sk := coretesting.KVStoreService(ctx, "test")
+	sb := collections.NewSchemaBuilder(sk)
+
+	im := newTestIndexedLookupMap(sb)
+	_, err := sb.Build()
+	require.NoError(t, err)
+
+	// test insertion
+	require.NoError(t, im.Set(ctx, collections.Join("alice", "atom"), 10))
+	require.NoError(t, im.Set(ctx, collections.Join("bob", "atom"), 20))
+	require.NoError(t, im.Set(ctx, collections.Join("bob", "osmo"), 30))
+
+	// test reverse lookup through the index
+	iter, err := im.Indexes.Denom.MatchExact(ctx, "atom")
+	require.NoError(t, err)
+	kvs, err := indexes.CollectKeyValues(ctx, im, iter)
+	require.NoError(t, err)
+	require.Equal(t, []collections.KeyValue[collections.Pair[string, string], uint64]{
+		{Key: collections.Join("alice", "atom"), Value: 10},
+		{Key: collections.Join("bob", "atom"), Value: 20},
+	}, kvs)
+
+	// test removal
+	require.NoError(t, im.Remove(ctx, collections.Join("alice", "atom")))
+	has, err := im.Has(ctx, collections.Join("alice", "atom"))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	iter, err = im.Indexes.Denom.MatchExact(ctx, "atom")
+	require.NoError(t, err)
+	values, err := indexes.CollectValues(ctx, im, iter)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{20}, values)
+
+	// test get
+	v, err := im.Get(ctx, collections.Join("bob", "osmo"))
+	require.NoError(t, err)
+	require.Equal(t, uint64(30), v)
+
+	// removing a non-existing object fails
+	err = im.Remove(ctx, collections.Join("alice", "atom"))
+	require.ErrorIs(t, err, collections.ErrNotFound)
+}
+
+func TestIndexedLookupMapModuleCodec(t *testing.T) {
+	ctx := coretesting.Context()
+	sk := coretesting.KVStoreService(ctx, "test")
+	sb := collections.NewSchemaBuilder(sk)
+
+	newTestIndexedLookupMap(sb)
+	sch, err := sb.Build()
+	require.NoError(t, err)
+
+	cdc, err := sch.ModuleCodec(collections.IndexingOptions{})
+	require.NoError(t, err)
+
+	typ, ok := cdc.Schema.LookupType("balances")
+	require.True(t, ok)
+	balances := typ.(schema.ObjectType)
+	require.Equal(t, []schema.Field{
+		{Name: "key1", Kind: schema.StringKind},
+		{Name: "key2", Kind: schema.StringKind},
+	}, balances.KeyFields)
+
+	typ, ok = cdc.Schema.LookupType("balances_by_denom")
+	require.True(t, ok)
+	byDenom := typ.(schema.ObjectType)
+	require.Len(t, byDenom.KeyFields, 3)
+	require.Empty(t, byDenom.ValueFields)
+
+	// index entries are decoded like any other object
+	updates, err := cdc.KVDecoder(schema.KVPairUpdate{
+		Key:   append([]byte{1}, []byte("atom\x00alice\x00atom")...),
+		Value: []byte{},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []schema.ObjectUpdate{{
+		TypeName: "balances_by_denom",
+		Key:      []any{"atom", "alice", "atom"},
+		Value:    nil,
+	}}, updates)
+}
@@ -19,11 +19,18 @@ type iterator[K any] interface {
 	Close() error
 }
 
+// getter defines the minimum set of methods of an indexed collection
+// required to resolve primary keys into values, it is implemented by
+// both collections.IndexedMap and collections.IndexedLookupMap.
+type getter[K, V any] interface {
+	Get(ctx context.Context, key K) (V, error)
+}
+
 // CollectKeyValues collects all the keys and the values of an indexed map index iterator.
 // The Iterator is fully consumed and closed.
-func CollectKeyValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
+func CollectKeyValues[K, V any, I iterator[K]](
 	ctx context.Context,
-	indexedMap *collections.IndexedMap[K, V, Idx],
+	indexedMap getter[K, V],
 	iter I,
 ) (kvs []collections.KeyValue[K, V], err error) {
 	err = ScanKeyValues(ctx, indexedMap, iter, func(kv collections.KeyValue[K, V]) bool {
@@ -36,9 +43,9 @@ func CollectKeyValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
 // ScanKeyValues calls the do function on every record found, in the indexed map
 // from the index iterator. Returning true stops the iteration.
 // The Iterator is closed when this function exits.
-func ScanKeyValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
+func ScanKeyValues[K, V any, I iterator[K]](
 	ctx context.Context,
-	indexedMap *collections.IndexedMap[K, V, Idx],
+	indexedMap getter[K, V],
 	iter I,
 	do func(kv collections.KeyValue[K, V]) (stop bool),
 ) (err error) {
@@ -70,9 +77,9 @@ func ScanKeyValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
 
 // CollectValues collects all the values from an Index iterator and the IndexedMap.
 // Closes the Iterator.
-func CollectValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
+func CollectValues[K, V any, I iterator[K]](
 	ctx context.Context,
-	indexedMap *collections.IndexedMap[K, V, Idx],
+	indexedMap getter[K, V],
 	iter I,
 ) (values []V, err error) {
 	err = ScanValues(ctx, indexedMap, iter, func(value V) (stop bool) {
@@ -84,9 +91,9 @@ func CollectValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
 
 // ScanValues collects all the values from an Index iterator and the IndexedMap in a lazy way.
 // The iterator is closed when this function exits.
-func ScanValues[K, V any, I iterator[K], Idx collections.Indexes[K, V]](
+func ScanValues[K, V any, I iterator[K]](
 	ctx context.Context,
-	indexedMap *collections.IndexedMap[K, V, Idx],
+	indexedMap getter[K, V],
 	iter I,
 	f func(value V) (stop bool),
 ) error {
@@ -121,6 +121,13 @@ func (m *Multi[ReferenceKey, PrimaryKey, Value]) MatchExact(ctx context.Context,
 	return m.Iterate(ctx, collections.NewPrefixedPairRange[ReferenceKey, PrimaryKey](refKey))
 }
 
+// IterateRaw iterates over the index entries between the start and end raw byte keys in the provided order.
+// It is meant to be used by pagination APIs, which work on raw key cursors.
+func (m *Multi[ReferenceKey, PrimaryKey, Value]) IterateRaw(ctx context.Context, start, end []byte, order collections.Order) (MultiIterator[ReferenceKey, PrimaryKey], error) {
+	iter, err := m.refKeys.IterateRaw(ctx, start, end, order)
+	return (MultiIterator[ReferenceKey, PrimaryKey])(iter), err
+}
+
 func (m *Multi[K1, K2, Value]) KeyCodec() codec.KeyCodec[collections.Pair[K1, K2]] {
 	return m.refKeys.KeyCodec()
 }
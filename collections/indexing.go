@@ -133,6 +133,13 @@ func (c collectionImpl[K, V]) schemaCodec() (*collectionSchemaCodec, error) {
 		if err != nil {
 			return nil, err
 		}
+		// a codec without fields, such as an item key, represents no key
+		if len(keyDecoder.Fields) == 0 {
+			return nil, nil
+		}
+		if keyDecoder.ToSchemaType == nil {
+			return x, nil
+		}
 		return keyDecoder.ToSchemaType(x)
 	}
 	ensureFieldNames(c.m.kc, "key", res.objectType.KeyFields)
@@ -147,6 +154,13 @@ func (c collectionImpl[K, V]) schemaCodec() (*collectionSchemaCodec, error) {
 		if err != nil {
 			return nil, err
 		}
+		// a codec without fields, such as a key set value, represents no value
+		if len(valueDecoder.Fields) == 0 {
+			return nil, nil
+		}
+		if valueDecoder.ToSchemaType == nil {
+			return x, nil
+		}
 		return valueDecoder.ToSchemaType(x)
 	}
 	ensureFieldNames(c.m.vc, "value", res.objectType.ValueFields)
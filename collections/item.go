@@ -65,3 +65,9 @@ func (noKey) DecodeJSON(b []byte) (noKey, error) {
 func (k noKey) EncodeNonTerminal(_ []byte, _ noKey) (int, error) { panic("must not be called") }
 func (k noKey) DecodeNonTerminal(_ []byte) (int, noKey, error)   { panic("must not be called") }
 func (k noKey) SizeNonTerminal(_ noKey) int                      { panic("must not be called") }
+
+// SchemaCodec implements codec.HasSchemaCodec. noKey has no schema fields,
+// which makes items singleton object types.
+func (noKey) SchemaCodec() (codec.SchemaCodec[noKey], error) {
+	return codec.SchemaCodec[noKey]{}, nil
+}
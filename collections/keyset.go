@@ -140,3 +140,8 @@ func (NoValue) Stringify(_ NoValue) string {
 func (n NoValue) ValueType() string {
 	return noValueValueType
 }
+
+// SchemaCodec implements codec.HasSchemaCodec. NoValue has no schema fields.
+func (NoValue) SchemaCodec() (codec.SchemaCodec[NoValue], error) {
+	return codec.SchemaCodec[NoValue]{}, nil
+}
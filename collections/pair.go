@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"cosmossdk.io/collections/codec"
+	"cosmossdk.io/schema"
 )
 
 // Pair defines a key composed of two keys.
@@ -181,6 +182,92 @@ func (p pairKeyCodec[K1, K2]) SizeNonTerminal(key Pair[K1, K2]) int {
 	return size
 }
 
+// SchemaCodec implements codec.HasSchemaCodec. The schema fields of the
+// two parts of the key are flattened into a single list of key fields, which
+// allows pairs (and therefore indexes.Multi) to be used as object keys.
+func (p pairKeyCodec[K1, K2]) SchemaCodec() (codec.SchemaCodec[Pair[K1, K2]], error) {
+	cdc1, err := codec.KeySchemaCodec(p.keyCodec1)
+	if err != nil {
+		return codec.SchemaCodec[Pair[K1, K2]]{}, err
+	}
+	cdc2, err := codec.KeySchemaCodec(p.keyCodec2)
+	if err != nil {
+		return codec.SchemaCodec[Pair[K1, K2]]{}, err
+	}
+
+	fields := make([]schema.Field, 0, len(cdc1.Fields)+len(cdc2.Fields))
+	fields = append(fields, cdc1.Fields...)
+	fields = append(fields, cdc2.Fields...)
+	split := len(cdc1.Fields)
+
+	return codec.SchemaCodec[Pair[K1, K2]]{
+		Fields: fields,
+		ToSchemaType: func(pair Pair[K1, K2]) (any, error) {
+			values1, err := toSchemaFieldValues(cdc1, pair.K1())
+			if err != nil {
+				return nil, err
+			}
+			values2, err := toSchemaFieldValues(cdc2, pair.K2())
+			if err != nil {
+				return nil, err
+			}
+			return append(values1, values2...), nil
+		},
+		FromSchemaType: func(a any) (Pair[K1, K2], error) {
+			values, ok := a.([]any)
+			if !ok || len(values) != len(fields) {
+				return Pair[K1, K2]{}, fmt.Errorf("expected %d pair key values, got %v", len(fields), a)
+			}
+			k1, err := fromSchemaFieldValues(cdc1, values[:split])
+			if err != nil {
+				return Pair[K1, K2]{}, err
+			}
+			k2, err := fromSchemaFieldValues(cdc2, values[split:])
+			if err != nil {
+				return Pair[K1, K2]{}, err
+			}
+			return Join(k1, k2), nil
+		},
+	}, nil
+}
+
+// toSchemaFieldValues converts t to its schema representation and always
+// returns it as a list of field values, regardless of the number of fields.
+func toSchemaFieldValues[T any](cdc codec.SchemaCodec[T], t T) ([]any, error) {
+	var v any = t
+	if cdc.ToSchemaType != nil {
+		var err error
+		v, err = cdc.ToSchemaType(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cdc.Fields) == 1 {
+		return []any{v}, nil
+	}
+	values, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected %d schema values, got %T", len(cdc.Fields), v)
+	}
+	return values, nil
+}
+
+// fromSchemaFieldValues is the inverse of toSchemaFieldValues.
+func fromSchemaFieldValues[T any](cdc codec.SchemaCodec[T], values []any) (t T, err error) {
+	var v any = values
+	if len(cdc.Fields) == 1 {
+		v = values[0]
+	}
+	if cdc.FromSchemaType != nil {
+		return cdc.FromSchemaType(v)
+	}
+	t, ok := v.(T)
+	if !ok {
+		return t, fmt.Errorf("expected schema value of type %T, got %T", t, v)
+	}
+	return t, nil
+}
+
 // GENESIS
 
 type jsonPairKey [2]json.RawMessage
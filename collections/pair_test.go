@@ -1,9 +1,12 @@
 package collections
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/schema"
 )
 
 func TestPair(t *testing.T) {
@@ -22,6 +25,34 @@ func TestPair(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, []byte(`["k1","k2"]`), b)
 	})
+
+	t.Run("schema codec", func(t *testing.T) {
+		nested := PairKeyCodec(PairKeyCodec(StringKey, Uint64Key), BoolKey)
+		cdc, err := nested.(pairKeyCodec[Pair[string, uint64], bool]).SchemaCodec()
+		require.NoError(t, err)
+		require.Len(t, cdc.Fields, 3)
+		require.Equal(t, schema.StringKind, cdc.Fields[0].Kind)
+		require.Equal(t, schema.Uint64Kind, cdc.Fields[1].Kind)
+		require.Equal(t, schema.BoolKind, cdc.Fields[2].Kind)
+
+		key := Join(Join("a", uint64(1)), true)
+		v, err := cdc.ToSchemaType(key)
+		require.NoError(t, err)
+		require.Equal(t, []any{"a", uint64(1), true}, v)
+
+		fields := cdc.Fields
+		for i := range fields {
+			fields[i].Name = fmt.Sprintf("key%d", i+1)
+		}
+		require.NoError(t, schema.ValidateObjectKey(fields, v, nil))
+
+		decoded, err := cdc.FromSchemaType(v)
+		require.NoError(t, err)
+		require.Equal(t, key, decoded)
+
+		_, err = cdc.FromSchemaType([]any{"a"})
+		require.Error(t, err)
+	})
 }
 
 func TestPairRange(t *testing.T) {
@@ -21,13 +21,8 @@ type SchemaBuilder struct {
 
 // NewSchemaBuilderFromAccessor creates a new schema builder from the provided store accessor function.
 func NewSchemaBuilderFromAccessor(accessorFunc func(ctx context.Context) store.KVStore) *SchemaBuilder {
-	return &SchemaBuilder{
-		schema: &Schema{
-			storeAccessor:       accessorFunc,
-			collectionsByName:   map[string]Collection{},
-			collectionsByPrefix: map[string]Collection{},
-		},
-	}
+	schema := NewSchemaFromAccessor(accessorFunc)
+	return &SchemaBuilder{schema: &schema}
 }
 
 // NewSchemaBuilder creates a new schema builder from the provided store key.
@@ -126,6 +121,8 @@ var nameRegex = regexp.MustCompile("^" + NameRegex + "$")
 // clients.
 type Schema struct {
 	storeAccessor       func(context.Context) store.KVStore
+	batchKey            *batchContextKey
+	versionKey          *versionContextKey
 	collectionsOrdered  []string
 	collectionsByPrefix map[string]Collection
 	collectionsByName   map[string]Collection
@@ -154,8 +151,12 @@ func NewMemoryStoreSchema(service store.MemoryStoreService) Schema {
 //			return sdk.UnwrapSDKContext(ctx).KVStore(kvStoreKey)
 //	}
 func NewSchemaFromAccessor(accessor func(context.Context) store.KVStore) Schema {
+	batchKey := new(batchContextKey)
+	versionKey := new(versionContextKey)
 	return Schema{
-		storeAccessor:       accessor,
+		storeAccessor:       batchAwareAccessor(batchKey, versionAwareAccessor(versionKey, accessor)),
+		batchKey:            batchKey,
+		versionKey:          versionKey,
 		collectionsByName:   map[string]Collection{},
 		collectionsByPrefix: map[string]Collection{},
 	}
@@ -0,0 +1,171 @@
+package collections
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cosmossdk.io/collections/codec"
+)
+
+const (
+	TimeQueueQueueNameSuffix   = "_queue"
+	TimeQueueIndexNameSuffix   = "_index"
+	TimeQueueQueuePrefixSuffix = 0x0
+	TimeQueueIndexPrefixSuffix = 0x1
+)
+
+// WithTimeQueueTimeKey sets the KeyCodec used to encode the expiration times
+// of the TimeQueue, which defaults to TimeKey. It allows modules to adopt a
+// TimeQueue while retaining the encoding of an existing time based queue.
+func WithTimeQueueTimeKey(timeKeyCodec codec.KeyCodec[time.Time]) func(opt *timeQueueOptions) {
+	return func(opt *timeQueueOptions) {
+		opt.timeKeyCodec = timeKeyCodec
+	}
+}
+
+type timeQueueOptions struct {
+	timeKeyCodec codec.KeyCodec[time.Time]
+}
+
+// NewTimeQueue creates a new TimeQueue instance. Since TimeQueue relies on two collections, one
+// for the queue and the other for the reverse index, it will register two state objects on the
+// schema builder. The first is the queue which is a map, whose prefix is the provided prefix with
+// a suffix which equals to TimeQueueQueuePrefixSuffix, the name is also suffixed with
+// TimeQueueQueueNameSuffix. The second is the reverse index which is a map, whose prefix is the
+// provided prefix with a suffix which equals to TimeQueueIndexPrefixSuffix, the name is also
+// suffixed with TimeQueueIndexNameSuffix.
+func NewTimeQueue[K, V any](
+	sb *SchemaBuilder,
+	prefix Prefix,
+	name string,
+	kc codec.KeyCodec[K],
+	vc codec.ValueCodec[V],
+	options ...func(opt *timeQueueOptions),
+) TimeQueue[K, V] {
+	o := &timeQueueOptions{timeKeyCodec: TimeKey}
+	for _, opt := range options {
+		opt(o)
+	}
+	return TimeQueue[K, V]{
+		queue: NewMap(
+			sb, append(prefix, TimeQueueQueuePrefixSuffix), name+TimeQueueQueueNameSuffix,
+			PairKeyCodec(o.timeKeyCodec, kc), vc,
+		),
+		index: NewMap(
+			sb, append(prefix, TimeQueueIndexPrefixSuffix), name+TimeQueueIndexNameSuffix,
+			kc, codec.KeyToValueCodec(o.timeKeyCodec),
+		),
+	}
+}
+
+// TimeQueue is a queue of values which expire at a given time, such as
+// proposals whose voting period ends or grants which expire. Values are
+// identified by a unique key and ordered by expiration time, then by key.
+// It relies on a Map[Pair[time.Time, K], V] for the queue and on a
+// Map[K, time.Time] as a reverse index, which allows to find and remove
+// a value from its key only.
+type TimeQueue[K, V any] struct {
+	queue Map[Pair[time.Time, K], V]
+	index Map[K, time.Time]
+}
+
+// TimeQueueEntry is a value of a TimeQueue along with its key and expiration time.
+type TimeQueueEntry[K, V any] struct {
+	Expiration time.Time
+	Key        K
+	Value      V
+}
+
+// Enqueue adds a value identified by key to the queue, which expires at the provided
+// time. If the key is already in the queue, its value and expiration are replaced.
+func (q TimeQueue[K, V]) Enqueue(ctx context.Context, expiration time.Time, key K, value V) error {
+	err := q.remove(ctx, key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	err = q.queue.Set(ctx, Join(expiration, key), value)
+	if err != nil {
+		return err
+	}
+	return q.index.Set(ctx, key, expiration)
+}
+
+// Get returns the value identified by key along with its expiration time.
+// Errors with ErrNotFound if the key is not in the queue.
+func (q TimeQueue[K, V]) Get(ctx context.Context, key K) (expiration time.Time, value V, err error) {
+	expiration, err = q.index.Get(ctx, key)
+	if err != nil {
+		return expiration, value, err
+	}
+	value, err = q.queue.Get(ctx, Join(expiration, key))
+	return expiration, value, err
+}
+
+// Has reports whether the key is in the queue.
+func (q TimeQueue[K, V]) Has(ctx context.Context, key K) (bool, error) {
+	return q.index.Has(ctx, key)
+}
+
+// Remove removes the value identified by key from the queue, without waiting
+// for its expiration. Errors with ErrNotFound if the key is not in the queue.
+func (q TimeQueue[K, V]) Remove(ctx context.Context, key K) error {
+	return q.remove(ctx, key)
+}
+
+func (q TimeQueue[K, V]) remove(ctx context.Context, key K) error {
+	expiration, err := q.index.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	err = q.queue.Remove(ctx, Join(expiration, key))
+	if err != nil {
+		return err
+	}
+	return q.index.Remove(ctx, key)
+}
+
+// DequeueUntil removes from the queue the values which expire at or before now,
+// in order of expiration, and returns them. At most limit values are removed, the
+// others are left in the queue to be dequeued by later calls, which bounds the work
+// done by a single call, ex. in an EndBlocker. A limit of zero removes all the
+// expired values.
+func (q TimeQueue[K, V]) DequeueUntil(ctx context.Context, now time.Time, limit uint64) ([]TimeQueueEntry[K, V], error) {
+	var entries []TimeQueueEntry[K, V]
+	err := q.WalkUntil(ctx, now, func(expiration time.Time, key K, value V) (bool, error) {
+		entries = append(entries, TimeQueueEntry[K, V]{Expiration: expiration, Key: key, Value: value})
+		return limit != 0 && uint64(len(entries)) >= limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// values are removed once iteration is over, as writes during
+	// iteration are not supported by every store.
+	for _, entry := range entries {
+		err = q.queue.Remove(ctx, Join(entry.Expiration, entry.Key))
+		if err != nil {
+			return nil, err
+		}
+		err = q.index.Remove(ctx, entry.Key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// WalkUntil walks over the values which expire at or before now, in order of
+// expiration, without removing them. It calls the walkFn for each value until
+// walkFn returns stop true or an error.
+func (q TimeQueue[K, V]) WalkUntil(ctx context.Context, now time.Time, walkFn func(expiration time.Time, key K, value V) (stop bool, err error)) error {
+	return q.Walk(ctx, NewPrefixUntilPairRange[time.Time, K](now), walkFn)
+}
+
+// Walk walks over the values of the queue in the provided range, in order of
+// expiration. It calls the walkFn for each value until walkFn returns stop true
+// or an error.
+func (q TimeQueue[K, V]) Walk(ctx context.Context, rng Ranger[Pair[time.Time, K]], walkFn func(expiration time.Time, key K, value V) (stop bool, err error)) error {
+	return q.queue.Walk(ctx, rng, func(key Pair[time.Time, K], value V) (bool, error) {
+		return walkFn(key.K1(), key.K2(), value)
+	})
+}
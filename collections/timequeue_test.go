@@ -0,0 +1,125 @@
+package collections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeQueue(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	queue := NewTimeQueue(schemaBuilder, NewPrefix(0), "queue", Uint64Key, StringValue)
+	_, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, queue.Enqueue(ctx, t0.Add(2*time.Hour), 1, "a"))
+	require.NoError(t, queue.Enqueue(ctx, t0.Add(time.Hour), 2, "b"))
+	require.NoError(t, queue.Enqueue(ctx, t0.Add(time.Hour), 3, "c"))
+	require.NoError(t, queue.Enqueue(ctx, t0.Add(3*time.Hour), 4, "d"))
+
+	// get
+	expiration, value, err := queue.Get(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(2*time.Hour), expiration)
+	require.Equal(t, "a", value)
+	_, _, err = queue.Get(ctx, 5)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// enqueuing an existing key reschedules it
+	require.NoError(t, queue.Enqueue(ctx, t0.Add(4*time.Hour), 4, "e"))
+	expiration, value, err = queue.Get(ctx, 4)
+	require.NoError(t, err)
+	require.Equal(t, t0.Add(4*time.Hour), expiration)
+	require.Equal(t, "e", value)
+
+	// remove by key
+	require.NoError(t, queue.Remove(ctx, 3))
+	has, err := queue.Has(ctx, 3)
+	require.NoError(t, err)
+	require.False(t, has)
+	require.ErrorIs(t, queue.Remove(ctx, 3), ErrNotFound)
+
+	// nothing expired yet
+	entries, err := queue.DequeueUntil(ctx, t0, 0)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// dequeue is inclusive of now
+	entries, err = queue.DequeueUntil(ctx, t0.Add(2*time.Hour), 0)
+	require.NoError(t, err)
+	require.Equal(t, []TimeQueueEntry[uint64, string]{
+		{Expiration: t0.Add(time.Hour), Key: 2, Value: "b"},
+		{Expiration: t0.Add(2 * time.Hour), Key: 1, Value: "a"},
+	}, entries)
+	has, err = queue.Has(ctx, 1)
+	require.NoError(t, err)
+	require.False(t, has)
+
+	// only the rescheduled key remains
+	var keys []uint64
+	require.NoError(t, queue.Walk(ctx, nil, func(_ time.Time, key uint64, _ string) (bool, error) {
+		keys = append(keys, key)
+		return false, nil
+	}))
+	require.Equal(t, []uint64{4}, keys)
+}
+
+func TestTimeQueueDequeueLimit(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	queue := NewTimeQueue(schemaBuilder, NewPrefix(0), "queue", Uint64Key, Uint64Value)
+	_, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	// times are compared regardless of their location
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("UTC+1", 3600))
+	for i := uint64(0); i < 10; i++ {
+		require.NoError(t, queue.Enqueue(ctx, t0.Add(time.Duration(i)*time.Second), i, i*10))
+	}
+
+	now := t0.Add(6 * time.Second)
+	var dequeued []uint64
+	for {
+		entries, err := queue.DequeueUntil(ctx, now, 3)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(entries), 3)
+		if len(entries) == 0 {
+			break
+		}
+		for _, entry := range entries {
+			require.True(t, entry.Expiration.Equal(t0.Add(time.Duration(entry.Key)*time.Second)))
+			require.Equal(t, entry.Key*10, entry.Value)
+			dequeued = append(dequeued, entry.Key)
+		}
+	}
+	require.Equal(t, []uint64{0, 1, 2, 3, 4, 5, 6}, dequeued)
+
+	// walking until a time does not remove the values
+	var keys []uint64
+	require.NoError(t, queue.WalkUntil(ctx, t0.Add(time.Hour), func(_ time.Time, key uint64, _ uint64) (bool, error) {
+		keys = append(keys, key)
+		return false, nil
+	}))
+	require.Equal(t, []uint64{7, 8, 9}, keys)
+	has, err := queue.Has(ctx, 7)
+	require.NoError(t, err)
+	require.True(t, has)
+}
+
+func TestTimeQueueModuleCodec(t *testing.T) {
+	sk, _ := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	NewTimeQueue(schemaBuilder, NewPrefix(0), "queue", Uint64Key, StringValue)
+	sch, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	cdc, err := sch.ModuleCodec(IndexingOptions{})
+	require.NoError(t, err)
+	for _, name := range []string{"queue_queue", "queue_index"} {
+		_, ok := cdc.Schema.LookupType(name)
+		require.True(t, ok, name)
+	}
+}
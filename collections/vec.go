@@ -98,6 +98,101 @@ func (v Vec[T]) Replace(ctx context.Context, index uint64, elem T) error {
 	return v.elements.Set(ctx, index, elem)
 }
 
+// Insert inserts an element at a given index, shifting the elements at and after
+// the index one position towards the end. Inserting at the index equal to the
+// length of the Vec is equivalent to Push. Fails if the index is greater than the
+// length. Insert performs a number of writes proportional to the number of shifted elements.
+func (v Vec[T]) Insert(ctx context.Context, index uint64, elem T) error {
+	length, err := v.Len(ctx)
+	if err != nil {
+		return err
+	}
+	if index > length {
+		return fmt.Errorf("%w: length %d", ErrOutOfBounds, length)
+	}
+	for i := length; i > index; i-- {
+		prev, err := v.elements.Get(ctx, i-1)
+		if err != nil {
+			return err
+		}
+		err = v.elements.Set(ctx, i, prev)
+		if err != nil {
+			return err
+		}
+	}
+	err = v.elements.Set(ctx, index, elem)
+	if err != nil {
+		return err
+	}
+	return v.length.Set(ctx, length+1)
+}
+
+// Remove removes the element at a given index and returns it, shifting the elements
+// after the index one position towards the start, so that the order of the elements
+// is preserved. Fails if the index is out of bounds. Remove performs a number of writes
+// proportional to the number of shifted elements, SwapRemove should be preferred when
+// the order of the elements does not matter.
+func (v Vec[T]) Remove(ctx context.Context, index uint64) (elem T, err error) {
+	length, err := v.Len(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if index >= length {
+		return elem, fmt.Errorf("%w: length %d", ErrOutOfBounds, length)
+	}
+	elem, err = v.elements.Get(ctx, index)
+	if err != nil {
+		return elem, err
+	}
+	for i := index + 1; i < length; i++ {
+		next, err := v.elements.Get(ctx, i)
+		if err != nil {
+			return elem, err
+		}
+		err = v.elements.Set(ctx, i-1, next)
+		if err != nil {
+			return elem, err
+		}
+	}
+	err = v.elements.Remove(ctx, length-1)
+	if err != nil {
+		return elem, err
+	}
+	return elem, v.length.Set(ctx, length-1)
+}
+
+// SwapRemove removes the element at a given index and returns it, replacing it with
+// the last element of the Vec. It does not preserve the order of the elements but
+// only performs a constant number of writes. Fails if the index is out of bounds.
+func (v Vec[T]) SwapRemove(ctx context.Context, index uint64) (elem T, err error) {
+	length, err := v.Len(ctx)
+	if err != nil {
+		return elem, err
+	}
+	if index >= length {
+		return elem, fmt.Errorf("%w: length %d", ErrOutOfBounds, length)
+	}
+	elem, err = v.elements.Get(ctx, index)
+	if err != nil {
+		return elem, err
+	}
+	if index != length-1 {
+		last, err := v.elements.Get(ctx, length-1)
+		if err != nil {
+			return elem, err
+		}
+		err = v.elements.Set(ctx, index, last)
+		if err != nil {
+			return elem, err
+		}
+	}
+	err = v.elements.Remove(ctx, length-1)
+	if err != nil {
+		return elem, err
+	}
+	return elem, v.length.Set(ctx, length-1)
+}
+
 // Get returns an element at a given index. Returns ErrOutOfBounds
 // if the index is out of bounds.
 func (v Vec[T]) Get(ctx context.Context, index uint64) (elem T, err error) {
@@ -61,3 +61,59 @@ func TestVec(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "bar", v)
 }
+
+func TestVecInsertRemove(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	vec := NewVec(schemaBuilder, NewPrefix(0), "vec", StringValue)
+	_, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	elems := func() []string {
+		var res []string
+		require.NoError(t, vec.Walk(ctx, nil, func(_ uint64, elem string) (bool, error) {
+			res = append(res, elem)
+			return false, nil
+		}))
+		return res
+	}
+
+	// insert out of bounds
+	require.ErrorIs(t, vec.Insert(ctx, 1, "a"), ErrOutOfBounds)
+
+	// insert at the end, at the start and in the middle
+	require.NoError(t, vec.Insert(ctx, 0, "c"))
+	require.NoError(t, vec.Insert(ctx, 1, "e"))
+	require.NoError(t, vec.Insert(ctx, 0, "a"))
+	require.NoError(t, vec.Insert(ctx, 1, "b"))
+	require.NoError(t, vec.Insert(ctx, 3, "d"))
+	require.Equal(t, []string{"a", "b", "c", "d", "e"}, elems())
+
+	// remove preserves the order
+	_, err = vec.Remove(ctx, 5)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+	elem, err := vec.Remove(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "b", elem)
+	require.Equal(t, []string{"a", "c", "d", "e"}, elems())
+	elem, err = vec.Remove(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, "e", elem)
+	require.Equal(t, []string{"a", "c", "d"}, elems())
+
+	// swap remove replaces the element with the last one
+	_, err = vec.SwapRemove(ctx, 3)
+	require.ErrorIs(t, err, ErrOutOfBounds)
+	elem, err = vec.SwapRemove(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, "a", elem)
+	require.Equal(t, []string{"d", "c"}, elems())
+	elem, err = vec.SwapRemove(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "c", elem)
+	require.Equal(t, []string{"d"}, elems())
+
+	length, err := vec.Len(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), length)
+}
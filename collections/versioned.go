@@ -0,0 +1,97 @@
+package collections
+
+import (
+	"context"
+	"errors"
+
+	"cosmossdk.io/core/store"
+)
+
+// ErrReadOnly is returned when writing to collections through a context
+// returned by Schema.WithReader or Schema.AtVersion.
+var ErrReadOnly = errors.New("collections: historical state is read-only")
+
+// VersionedReader reads the state of a store at any of its versions.
+// It is implemented by the cosmossdk.io/store/v2 VersionedDatabase.
+type VersionedReader interface {
+	Has(storeKey []byte, version uint64, key []byte) (bool, error)
+	Get(storeKey []byte, version uint64, key []byte) ([]byte, error)
+	Iterator(storeKey []byte, version uint64, start, end []byte) (store.Iterator, error)
+	ReverseIterator(storeKey []byte, version uint64, start, end []byte) (store.Iterator, error)
+}
+
+// versionContextKey identifies the historical store of a specific schema in a
+// context.Context. It is not zero sized so that every schema gets a distinct pointer.
+type versionContextKey struct{ _ byte }
+
+// versionAwareAccessor wraps the provided store accessor so that collections
+// operating on a context returned by Schema.WithReader or Schema.AtVersion read
+// from the historical store instead of the underlying store.
+func versionAwareAccessor(key *versionContextKey, accessor func(context.Context) store.KVStore) func(context.Context) store.KVStore {
+	return func(ctx context.Context) store.KVStore {
+		if s, ok := ctx.Value(key).(readOnlyStore); ok {
+			return s
+		}
+		return accessor(ctx)
+	}
+}
+
+// WithReader returns a context with which the collections of the schema read
+// their state from reader instead of the store reachable from ctx. The reader
+// is usually the state of the schema's store at a past version, ex. obtained
+// with StateAt(version).GetReader(storeKey) on a cosmossdk.io/store/v2 root
+// store. Writes through the returned context fail with ErrReadOnly.
+func (s Schema) WithReader(ctx context.Context, reader store.Reader) context.Context {
+	return context.WithValue(ctx, s.versionKey, readOnlyStore{reader})
+}
+
+// AtVersion returns a context with which the collections of the schema read
+// their state at the provided version of the store identified by storeKey in
+// reader, instead of the state of the store reachable from ctx. This allows
+// keepers to serve historical queries, ex. the balance of an account at a given
+// height, from any reader of versioned state. Writes through the returned
+// context fail with ErrReadOnly.
+func (s Schema) AtVersion(ctx context.Context, reader VersionedReader, storeKey []byte, version uint64) context.Context {
+	return s.WithReader(ctx, versionedReader{
+		reader:   reader,
+		storeKey: storeKey,
+		version:  version,
+	})
+}
+
+// readOnlyStore adapts a store.Reader to a store.KVStore whose writes fail.
+type readOnlyStore struct {
+	store.Reader
+}
+
+func (r readOnlyStore) Set(_, _ []byte) error {
+	return ErrReadOnly
+}
+
+func (r readOnlyStore) Delete(_ []byte) error {
+	return ErrReadOnly
+}
+
+// versionedReader is a store.Reader which reads the state of a store at a
+// specific version.
+type versionedReader struct {
+	reader   VersionedReader
+	storeKey []byte
+	version  uint64
+}
+
+func (v versionedReader) Get(key []byte) ([]byte, error) {
+	return v.reader.Get(v.storeKey, v.version, key)
+}
+
+func (v versionedReader) Has(key []byte) (bool, error) {
+	return v.reader.Has(v.storeKey, v.version, key)
+}
+
+func (v versionedReader) Iterator(start, end []byte) (store.Iterator, error) {
+	return v.reader.Iterator(v.storeKey, v.version, start, end)
+}
+
+func (v versionedReader) ReverseIterator(start, end []byte) (store.Iterator, error) {
+	return v.reader.ReverseIterator(v.storeKey, v.version, start, end)
+}
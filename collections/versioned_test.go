@@ -0,0 +1,152 @@
+package collections
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/core/store"
+	coretesting "cosmossdk.io/core/testing"
+)
+
+// mockVersionedReader keeps a copy of the state of a store at every version.
+type mockVersionedReader struct {
+	storeKey []byte
+	versions map[uint64]store.KVStore
+}
+
+// snapshot copies the current state of the store at the version.
+func (m *mockVersionedReader) snapshot(t *testing.T, kv store.KVStore, version uint64) {
+	t.Helper()
+	ctx := coretesting.Context()
+	snapshot := coretesting.KVStoreService(ctx, fmt.Sprintf("v%d", version)).OpenKVStore(ctx)
+	iter, err := kv.Iterator(nil, nil)
+	require.NoError(t, err)
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		require.NoError(t, snapshot.Set(iter.Key(), iter.Value()))
+	}
+	m.versions[version] = snapshot
+}
+
+func (m *mockVersionedReader) store(storeKey []byte, version uint64) (store.KVStore, error) {
+	if string(storeKey) != string(m.storeKey) {
+		return nil, fmt.Errorf("unknown store key %s", storeKey)
+	}
+	kv, ok := m.versions[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %d", version)
+	}
+	return kv, nil
+}
+
+func (m *mockVersionedReader) Has(storeKey []byte, version uint64, key []byte) (bool, error) {
+	kv, err := m.store(storeKey, version)
+	if err != nil {
+		return false, err
+	}
+	return kv.Has(key)
+}
+
+func (m *mockVersionedReader) Get(storeKey []byte, version uint64, key []byte) ([]byte, error) {
+	kv, err := m.store(storeKey, version)
+	if err != nil {
+		return nil, err
+	}
+	return kv.Get(key)
+}
+
+func (m *mockVersionedReader) Iterator(storeKey []byte, version uint64, start, end []byte) (store.Iterator, error) {
+	kv, err := m.store(storeKey, version)
+	if err != nil {
+		return nil, err
+	}
+	return kv.Iterator(start, end)
+}
+
+func (m *mockVersionedReader) ReverseIterator(storeKey []byte, version uint64, start, end []byte) (store.Iterator, error) {
+	kv, err := m.store(storeKey, version)
+	if err != nil {
+		return nil, err
+	}
+	return kv.ReverseIterator(start, end)
+}
+
+func TestSchemaAtVersion(t *testing.T) {
+	sk, ctx := deps()
+	schemaBuilder := NewSchemaBuilder(sk)
+	m := NewMap(schemaBuilder, NewPrefix(0), "m", StringKey, Uint64Value)
+	item := NewItem(schemaBuilder, NewPrefix(1), "item", StringValue)
+	schema, err := schemaBuilder.Build()
+	require.NoError(t, err)
+
+	reader := &mockVersionedReader{storeKey: []byte("test"), versions: map[uint64]store.KVStore{}}
+	kv := sk.OpenKVStore(ctx)
+
+	require.NoError(t, m.Set(ctx, "a", 1))
+	require.NoError(t, m.Set(ctx, "b", 2))
+	require.NoError(t, item.Set(ctx, "one"))
+	reader.snapshot(t, kv, 1)
+
+	require.NoError(t, m.Set(ctx, "a", 10))
+	require.NoError(t, m.Remove(ctx, "b"))
+	require.NoError(t, m.Set(ctx, "c", 3))
+	require.NoError(t, item.Set(ctx, "two"))
+	reader.snapshot(t, kv, 2)
+
+	require.NoError(t, m.Set(ctx, "a", 100))
+
+	v1ctx := schema.AtVersion(ctx, reader, []byte("test"), 1)
+	v2ctx := schema.AtVersion(ctx, reader, []byte("test"), 2)
+
+	v, err := m.Get(v1ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), v)
+	v, err = m.Get(v2ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), v)
+	v, err = m.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), v)
+
+	has, err := m.Has(v2ctx, "b")
+	require.NoError(t, err)
+	require.False(t, has)
+
+	itemValue, err := item.Get(v1ctx)
+	require.NoError(t, err)
+	require.Equal(t, "one", itemValue)
+
+	iter, err := m.Iterate(v1ctx, nil)
+	require.NoError(t, err)
+	kvs, err := iter.KeyValues()
+	require.NoError(t, err)
+	require.Equal(t, []KeyValue[string, uint64]{{"a", 1}, {"b", 2}}, kvs)
+
+	iter, err = m.Iterate(v2ctx, new(Range[string]).Descending())
+	require.NoError(t, err)
+	keys, err := iter.Keys()
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "a"}, keys)
+
+	// historical state is read-only
+	require.ErrorIs(t, m.Set(v1ctx, "a", 2), ErrReadOnly)
+	require.ErrorIs(t, m.Remove(v1ctx, "a"), ErrReadOnly)
+
+	// contexts of other schemas are not affected
+	otherCtx := NewSchemaFromAccessor(sk.OpenKVStore).AtVersion(ctx, reader, []byte("test"), 1)
+	v, err = m.Get(otherCtx, "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), v)
+
+	// any store.Reader can be used
+	v, err = m.Get(schema.WithReader(ctx, reader.versions[2]), "a")
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), v)
+	require.ErrorIs(t, m.Set(schema.WithReader(ctx, reader.versions[2]), "a", 2), ErrReadOnly)
+
+	// errors of the reader are returned
+	_, err = m.Get(schema.AtVersion(ctx, reader, []byte("test"), 3), "a")
+	require.ErrorContains(t, err, "unknown version 3")
+}
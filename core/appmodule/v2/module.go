@@ -47,6 +47,30 @@ type HasEndBlocker interface {
 	EndBlock(context.Context) error
 }
 
+// HasExtendVote is the extension interface that modules should implement to add
+// data to the precommit votes of their validator, when vote extensions are
+// enabled. The vote extensions of the modules are available in PreBlock of the
+// next block.
+type HasExtendVote interface {
+	AppModule
+
+	// ExtendVote returns the vote extension of the module for the block with the
+	// given height and hash.
+	ExtendVote(ctx context.Context, height int64, hash []byte) ([]byte, error)
+}
+
+// HasVerifyVoteExtension is the extension interface that modules should implement
+// to verify the vote extensions added by their HasExtendVote implementation in the
+// precommit votes of the other validators.
+type HasVerifyVoteExtension interface {
+	AppModule
+
+	// VerifyVoteExtension verifies the vote extension of the module in the
+	// precommit vote of the validator with the given address. If an error is
+	// returned, the vote is rejected.
+	VerifyVoteExtension(ctx context.Context, height int64, validatorAddress, extension []byte) error
+}
+
 // HasTxValidator is the extension interface that modules should implement to run
 // custom logic for validating transactions.
 // It was previously known as AnteHandler/Decorator.
@@ -50,6 +50,21 @@ type VoteInfo struct {
 	BlockIDFlag BlockIDFlag
 }
 
+// ExtendedCommitInfo is the extended commit information of ABCI, with the vote
+// extensions of the validators
+type ExtendedCommitInfo struct {
+	Round int32
+	Votes []ExtendedVoteInfo
+}
+
+// ExtendedVoteInfo is the extended vote information of ABCI
+type ExtendedVoteInfo struct {
+	Validator          Validator
+	VoteExtension      []byte
+	ExtensionSignature []byte
+	BlockIDFlag        BlockIDFlag
+}
+
 // BlockIDFlag indicates which BlockID the signature is for
 type BlockIDFlag int32
 
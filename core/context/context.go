@@ -1,10 +1,11 @@
 package context
 
 type (
-	execModeKey    struct{}
-	cometInfoKey   struct{}
-	initInfoKey    struct{}
-	environmentKey struct{}
+	execModeKey           struct{}
+	cometInfoKey          struct{}
+	extendedCommitInfoKey struct{}
+	initInfoKey           struct{}
+	environmentKey        struct{}
 )
 
 var (
@@ -12,6 +13,9 @@ var (
 	ExecModeKey = execModeKey{}
 	// CometInfoKey is the context key for allowing modules to get CometInfo.
 	CometInfoKey = cometInfoKey{}
+	// ExtendedCommitInfoKey is the context key for allowing modules to get the
+	// vote extensions of the previous block in PreBlock.
+	ExtendedCommitInfoKey = extendedCommitInfoKey{}
 	// CometParamsInitInfoKey is the context key for setting consensus params from genesis in the consensus module.
 	CometParamsInitInfoKey = initInfoKey{}
 
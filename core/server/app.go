@@ -5,6 +5,7 @@ import (
 	"time"
 
 	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/comet"
 	"cosmossdk.io/core/event"
 	"cosmossdk.io/core/transaction"
 )
@@ -20,6 +21,10 @@ type BlockRequest[T transaction.Tx] struct {
 
 	// IsGenesis indicates if this block is the first block of the chain.
 	IsGenesis bool
+
+	// ExtendedCommitInfo is the commit of the previous block with the vote
+	// extensions of the validators. It is nil when vote extensions are disabled.
+	ExtendedCommitInfo *comet.ExtendedCommitInfo
 }
 
 // BlockResponse defines the response structure for a block coming from the state transition function to consensus server.
@@ -49,6 +54,31 @@ type TxResult struct {
 	GasUsed uint64
 }
 
+// BlockTrace defines the state changes of each step of the execution of a block.
+// It is produced when replaying a block, for debugging purposes.
+type BlockTrace struct {
+	// BeginBlock are the state changes of the header info, PreBlock and BeginBlock.
+	BeginBlock []StateDiff
+	// Txs are the state changes of each transaction of the block.
+	Txs [][]StateDiff
+	// EndBlock are the state changes of EndBlock and of the validator updates.
+	EndBlock []StateDiff
+}
+
+// StateDiff defines the change of the value of a key in the state of an actor.
+type StateDiff struct {
+	// Actor is the space in storage where the key is stored.
+	Actor []byte
+	// Key is the changed key.
+	Key []byte
+	// Previous is the value of the key before the change, nil if it did not exist.
+	Previous []byte
+	// Value is the value of the key after the change.
+	Value []byte
+	// Remove is true when the key was removed.
+	Remove bool
+}
+
 // VersionModifier defines the interface fulfilled by BaseApp
 // which allows getting and setting it's appVersion field. This
 // in turn updates the consensus params that are sent to the
@@ -0,0 +1,132 @@
+package parquet
+
+import (
+	"encoding/json"
+
+	"github.com/parquet-go/parquet-go"
+
+	"cosmossdk.io/schema/appdata"
+)
+
+// blockTables are the tables of the block headers, transactions and events.
+type blockTables struct {
+	block, tx, event *table
+}
+
+func newBlockTables(opts options) *blockTables {
+	return &blockTables{
+		block: newTable("block", "block", parquet.Group{
+			"number": parquet.Uint(64),
+			"header": parquet.Optional(parquet.JSON()),
+		}, opts),
+		tx: newTable("tx", "tx", parquet.Group{
+			"block_number":   parquet.Uint(64),
+			"index_in_block": parquet.Int(32),
+			"bytes":          parquet.Optional(parquet.Leaf(parquet.ByteArrayType)),
+			"data":           parquet.Optional(parquet.JSON()),
+		}, opts),
+		event: newTable("event", "event", parquet.Group{
+			"block_number": parquet.Uint(64),
+			"tx_index":     parquet.Int(32),
+			"msg_index":    parquet.Int(32),
+			"event_index":  parquet.Int(32),
+			"type":         parquet.String(),
+			"data":         parquet.Optional(parquet.JSON()),
+			"attributes":   parquet.Optional(parquet.JSON()),
+		}, opts),
+	}
+}
+
+// writeBlock writes the block header.
+func (b *blockTables) writeBlock(data appdata.StartBlockData) error {
+	r := b.block.newRow()
+	r.set("number", parquet.Int64Value(int64(data.Height)))
+	err := setJSON(r, "header", data.HeaderJSON)
+	if err != nil {
+		return err
+	}
+	return b.block.write(data.Height, r)
+}
+
+// writeTx writes the transaction in the block.
+func (b *blockTables) writeTx(blockNum uint64, data appdata.TxData) error {
+	r := b.tx.newRow()
+	r.set("block_number", parquet.Int64Value(int64(blockNum)))
+	r.set("index_in_block", parquet.Int32Value(data.TxIndex))
+	if data.Bytes != nil {
+		bz, err := data.Bytes()
+		if err != nil {
+			return err
+		}
+		r.set("bytes", parquet.ByteArrayValue(bz))
+	}
+	err := setJSON(r, "data", data.JSON)
+	if err != nil {
+		return err
+	}
+	return b.tx.write(blockNum, r)
+}
+
+// writeEvents writes the events in the block.
+func (b *blockTables) writeEvents(blockNum uint64, data appdata.EventData) error {
+	for _, event := range data.Events {
+		r := b.event.newRow()
+		r.set("block_number", parquet.Int64Value(int64(blockNum)))
+		r.set("tx_index", parquet.Int32Value(event.TxIndex))
+		r.set("msg_index", parquet.Int32Value(event.MsgIndex))
+		r.set("event_index", parquet.Int32Value(event.EventIndex))
+		r.set("type", parquet.ByteArrayValue([]byte(event.Type)))
+		err := setJSON(r, "data", event.Data)
+		if err != nil {
+			return err
+		}
+
+		if event.Attributes != nil {
+			attrs, err := event.Attributes()
+			if err != nil {
+				return err
+			}
+			// attributes are stored as a JSON array of {"key": ..., "value": ...} objects
+			bz, err := json.Marshal(attributesJSON(attrs))
+			if err != nil {
+				return err
+			}
+			r.set("attributes", parquet.ByteArrayValue(bz))
+		}
+
+		err = b.event.write(blockNum, r)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setJSON sets the column to the JSON returned by f, if f is not nil.
+func setJSON(r row, column string, f appdata.ToJSON) error {
+	if f == nil {
+		return nil
+	}
+
+	bz, err := f()
+	if err != nil {
+		return err
+	}
+	if bz != nil {
+		r.set(column, parquet.ByteArrayValue(bz))
+	}
+	return nil
+}
+
+type eventAttributeJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func attributesJSON(attrs []appdata.EventAttribute) []eventAttributeJSON {
+	res := make([]eventAttributeJSON, len(attrs))
+	for i, attr := range attrs {
+		res[i] = eventAttributeJSON{Key: attr.Key, Value: attr.Value}
+	}
+	return res
+}
@@ -0,0 +1,109 @@
+package parquet
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"cosmossdk.io/schema"
+)
+
+// columnNode returns the parquet node of the column of the field.
+func columnNode(field schema.Field, optional bool) (parquet.Node, error) {
+	var node parquet.Node
+	switch field.Kind {
+	case schema.StringKind, schema.IntegerStringKind, schema.DecimalStringKind, schema.AddressKind:
+		node = parquet.String()
+	case schema.BytesKind:
+		node = parquet.Leaf(parquet.ByteArrayType)
+	case schema.Int8Kind:
+		node = parquet.Int(8)
+	case schema.Int16Kind:
+		node = parquet.Int(16)
+	case schema.Int32Kind:
+		node = parquet.Int(32)
+	case schema.Int64Kind, schema.DurationKind:
+		node = parquet.Int(64)
+	case schema.Uint8Kind:
+		node = parquet.Uint(8)
+	case schema.Uint16Kind:
+		node = parquet.Uint(16)
+	case schema.Uint32Kind:
+		node = parquet.Uint(32)
+	case schema.Uint64Kind:
+		node = parquet.Uint(64)
+	case schema.BoolKind:
+		node = parquet.Leaf(parquet.BooleanType)
+	case schema.Float32Kind:
+		node = parquet.Leaf(parquet.FloatType)
+	case schema.Float64Kind:
+		node = parquet.Leaf(parquet.DoubleType)
+	case schema.TimeKind:
+		node = parquet.Timestamp(parquet.Nanosecond)
+	case schema.EnumKind:
+		node = parquet.Enum()
+	case schema.JSONKind:
+		node = parquet.JSON()
+	default:
+		return nil, fmt.Errorf("unsupported kind %v for field %q", field.Kind, field.Name)
+	}
+
+	if optional || field.Nullable {
+		node = parquet.Optional(node)
+	}
+	return node, nil
+}
+
+// encodeValue converts the value of the field to a parquet value of the column type of the field.
+func (o options) encodeValue(field schema.Field, value interface{}) (parquet.Value, error) {
+	if err := field.Kind.ValidateValueType(value); err != nil {
+		return parquet.Value{}, fmt.Errorf("invalid value for field %q: %w", field.Name, err)
+	}
+
+	switch field.Kind {
+	case schema.StringKind, schema.IntegerStringKind, schema.DecimalStringKind, schema.EnumKind:
+		return parquet.ByteArrayValue([]byte(value.(string))), nil
+	case schema.BytesKind:
+		return parquet.ByteArrayValue(value.([]byte)), nil
+	case schema.AddressKind:
+		addr, err := o.addressCodec.BytesToString(value.([]byte))
+		if err != nil {
+			return parquet.Value{}, fmt.Errorf("invalid address for field %q: %w", field.Name, err)
+		}
+		return parquet.ByteArrayValue([]byte(addr)), nil
+	case schema.Int8Kind:
+		return parquet.Int32Value(int32(value.(int8))), nil
+	case schema.Int16Kind:
+		return parquet.Int32Value(int32(value.(int16))), nil
+	case schema.Int32Kind:
+		return parquet.Int32Value(value.(int32)), nil
+	case schema.Int64Kind:
+		return parquet.Int64Value(value.(int64)), nil
+	case schema.Uint8Kind:
+		return parquet.Int32Value(int32(value.(uint8))), nil
+	case schema.Uint16Kind:
+		return parquet.Int32Value(int32(value.(uint16))), nil
+	case schema.Uint32Kind:
+		// unsigned 32-bit integers are stored in the bits of signed 32-bit integers
+		return parquet.Int32Value(int32(value.(uint32))), nil
+	case schema.Uint64Kind:
+		// unsigned 64-bit integers are stored in the bits of signed 64-bit integers
+		return parquet.Int64Value(int64(value.(uint64))), nil
+	case schema.BoolKind:
+		return parquet.BooleanValue(value.(bool)), nil
+	case schema.Float32Kind:
+		return parquet.FloatValue(value.(float32)), nil
+	case schema.Float64Kind:
+		return parquet.DoubleValue(value.(float64)), nil
+	case schema.TimeKind:
+		return parquet.Int64Value(value.(time.Time).UnixNano()), nil
+	case schema.DurationKind:
+		return parquet.Int64Value(int64(value.(time.Duration))), nil
+	case schema.JSONKind:
+		return parquet.ByteArrayValue(value.(json.RawMessage)), nil
+	default:
+		return parquet.Value{}, fmt.Errorf("unsupported kind %v for field %q", field.Kind, field.Name)
+	}
+}
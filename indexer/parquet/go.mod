@@ -0,0 +1,22 @@
+module cosmossdk.io/indexer/parquet
+
+go 1.23
+
+require (
+	cosmossdk.io/schema v0.1.1
+	github.com/parquet-go/parquet-go v0.25.1
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace cosmossdk.io/schema => ../../schema
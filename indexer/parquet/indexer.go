@@ -0,0 +1,190 @@
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/indexer"
+)
+
+// IndexerType is the type under which the Parquet indexer is registered in the
+// cosmossdk.io/schema/indexer registry.
+const IndexerType = "parquet"
+
+// DefaultBlocksPerFile is the default number of blocks in each partition.
+const DefaultBlocksPerFile = 10000
+
+func init() {
+	indexer.Register(IndexerType, StartIndexer)
+}
+
+type Config struct {
+	// OutputDir is the directory in which the Parquet files are written.
+	OutputDir string `json:"output_dir"`
+
+	// BlocksPerFile is the number of blocks in each partition. This defaults to DefaultBlocksPerFile.
+	BlocksPerFile uint64 `json:"blocks_per_file"`
+
+	// Compression is the compression codec of the Parquet files, one of "snappy" (the default),
+	// "gzip", "zstd", "lz4", "brotli" or "none".
+	Compression string `json:"compression"`
+}
+
+type indexerImpl struct {
+	// mtx guards all the fields below because the files are closed in a separate go routine on shutdown.
+	mtx      sync.Mutex
+	ctx      context.Context
+	opts     options
+	modules  map[string]*moduleIndexer
+	blocks   *blockTables
+	blockNum uint64
+	closed   bool
+
+	// finishOnCommit is true if the files are finished at every commit, because the context
+	// cannot be canceled to finish them on shutdown.
+	finishOnCommit bool
+}
+
+func StartIndexer(params indexer.InitParams) (indexer.InitResult, error) {
+	config, err := decodeConfig(params.Config.Config)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	ctx := params.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if config.OutputDir == "" {
+		return indexer.InitResult{}, errors.New("missing output directory")
+	}
+
+	err = os.MkdirAll(config.OutputDir, 0o755)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	blocksPerFile := config.BlocksPerFile
+	if blocksPerFile == 0 {
+		blocksPerFile = DefaultBlocksPerFile
+	}
+
+	codec, err := compressionCodec(config.Compression)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	addressCodec := params.AddressCodec
+	if addressCodec == nil {
+		addressCodec = addressutil.HexAddressCodec{}
+	}
+
+	opts := options{
+		outputDir:     config.OutputDir,
+		blocksPerFile: blocksPerFile,
+		compression:   codec,
+		logger:        params.Logger,
+		addressCodec:  addressCodec,
+	}
+
+	idx := &indexerImpl{
+		ctx:     ctx,
+		opts:    opts,
+		modules: map[string]*moduleIndexer{},
+		blocks:  newBlockTables(opts),
+	}
+	if ctx.Done() == nil {
+		// nothing would finish the files of the current partition on shutdown
+		idx.finishOnCommit = true
+		if opts.logger != nil {
+			opts.logger.Warn("The parquet indexer context cannot be canceled, the files will be finished at every commit")
+		}
+	} else {
+		go func() {
+			<-ctx.Done()
+			if err := idx.close(); err != nil && opts.logger != nil {
+				opts.logger.Error("failed to close parquet files", "error", err)
+			}
+		}()
+	}
+
+	return indexer.InitResult{
+		Listener: idx.listener(),
+	}, nil
+}
+
+// close finishes the files of the current partition. Rows which are written after close
+// are an error.
+func (i *indexerImpl) close() error {
+	i.mtx.Lock()
+	defer i.mtx.Unlock()
+
+	if i.closed {
+		return nil
+	}
+	i.closed = true
+	return i.closeFiles()
+}
+
+// closeFiles finishes all the open files.
+func (i *indexerImpl) closeFiles() error {
+	var errs []error
+	for _, tbl := range i.tables() {
+		errs = append(errs, tbl.close())
+	}
+	return errors.Join(errs...)
+}
+
+// tables returns all the tables which are written by the indexer.
+func (i *indexerImpl) tables() []*table {
+	tables := []*table{i.blocks.block, i.blocks.tx, i.blocks.event}
+	for _, mm := range i.modules {
+		for _, tm := range mm.tables {
+			tables = append(tables, tm.table)
+		}
+	}
+	return tables
+}
+
+func decodeConfig(rawConfig map[string]interface{}) (*Config, error) {
+	bz, err := json.Marshal(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = json.Unmarshal(bz, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+func compressionCodec(name string) (compress.Codec, error) {
+	switch name {
+	case "", "snappy":
+		return &parquet.Snappy, nil
+	case "gzip":
+		return &parquet.Gzip, nil
+	case "zstd":
+		return &parquet.Zstd, nil
+	case "lz4":
+		return &parquet.Lz4Raw, nil
+	case "brotli":
+		return &parquet.Brotli, nil
+	case "none":
+		return &parquet.Uncompressed, nil
+	default:
+		return nil, fmt.Errorf("unknown compression codec %q", name)
+	}
+}
@@ -0,0 +1,207 @@
+package parquet
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/appdata"
+	"cosmossdk.io/schema/indexer"
+)
+
+var testObjectType = schema.ObjectType{
+	Name: "balance",
+	KeyFields: []schema.Field{
+		{Name: "address", Kind: schema.AddressKind},
+		{Name: "denom", Kind: schema.StringKind},
+	},
+	ValueFields: []schema.Field{
+		{Name: "amount", Kind: schema.Uint64Kind},
+		{Name: "updated", Kind: schema.TimeKind},
+		{Name: "memo", Kind: schema.JSONKind, Nullable: true},
+	},
+}
+
+func TestIndexer(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	res, err := StartIndexer(indexer.InitParams{
+		Config: indexer.Config{
+			Type: IndexerType,
+			Config: map[string]interface{}{
+				"output_dir":      dir,
+				"blocks_per_file": 2,
+				"compression":     "zstd",
+			},
+		},
+		Context: ctx,
+	})
+	require.NoError(t, err)
+	listener := res.Listener
+
+	require.NoError(t, listener.InitializeModuleData(appdata.ModuleInitializationData{
+		ModuleName: "bank",
+		Schema:     schema.MustCompileModuleSchema(testObjectType),
+	}))
+
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	blocks := map[uint64][]schema.ObjectUpdate{
+		1: {
+			{TypeName: "balance", Key: []interface{}{[]byte{0xab}, "atom"}, Value: []interface{}{uint64(10), ts, nil}},
+			{TypeName: "balance", Key: []interface{}{[]byte{0xcd}, "atom"}, Value: []interface{}{uint64(1) << 63, ts, json.RawMessage(`{"a":1}`)}},
+		},
+		2: {
+			{TypeName: "balance", Key: []interface{}{[]byte{0xab}, "atom"}, Value: schema.MapValueUpdates{"amount": uint64(5)}},
+		},
+		3: {
+			{TypeName: "balance", Key: []interface{}{[]byte{0xcd}, "atom"}, Delete: true},
+		},
+	}
+
+	for blockNum := uint64(1); blockNum <= 3; blockNum++ {
+		require.NoError(t, listener.StartBlock(appdata.StartBlockData{Height: blockNum}))
+		require.NoError(t, listener.OnObjectUpdate(appdata.ObjectUpdateData{ModuleName: "bank", Updates: blocks[blockNum]}))
+		_, err = listener.Commit(appdata.CommitData{})
+		require.NoError(t, err)
+	}
+
+	tableDir := filepath.Join(dir, "state", "bank", "balance")
+
+	// blocks 0-1 and 2-3 are two partitions and the second one is finished when block 3 is committed
+	require.Equal(t, []map[string]interface{}{
+		{"_block_number": int64(1), "_delete": false, "_updated_fields": nil, "address": "0xab", "denom": "atom", "amount": int64(10), "updated": ts.UnixNano(), "memo": nil},
+		{"_block_number": int64(1), "_delete": false, "_updated_fields": nil, "address": "0xcd", "denom": "atom", "amount": int64(-1 << 63), "updated": ts.UnixNano(), "memo": `{"a":1}`},
+	}, readRows(t, filepath.Join(tableDir, "00000000000000000000-00000000000000000001.parquet")))
+
+	require.Equal(t, []map[string]interface{}{
+		{"_block_number": int64(2), "_delete": false, "_updated_fields": []interface{}{"amount"}, "address": "0xab", "denom": "atom", "amount": int64(5), "updated": nil, "memo": nil},
+		{"_block_number": int64(3), "_delete": true, "_updated_fields": nil, "address": "0xcd", "denom": "atom", "amount": nil, "updated": nil, "memo": nil},
+	}, readRows(t, filepath.Join(tableDir, "00000000000000000002-00000000000000000003.parquet")))
+
+	// the block table was written too
+	require.Len(t, readRows(t, filepath.Join(dir, "block", "00000000000000000002-00000000000000000003.parquet")), 2)
+
+	// writing after the indexer was closed is an error
+	cancel()
+	require.Eventually(t, func() bool {
+		return listener.StartBlock(appdata.StartBlockData{Height: 4}) != nil
+	}, time.Second, time.Millisecond)
+	_, err = os.Stat(filepath.Join(tableDir, "00000000000000000004-00000000000000000005.parquet"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestIndexerRestart(t *testing.T) {
+	dir := t.TempDir()
+	tableDir := filepath.Join(dir, "state", "bank", "balance")
+	partition := filepath.Join(tableDir, "00000000000000000000-00000000000000000009")
+
+	// start starts the indexer and writes the blocks, the files are finished at every commit
+	// with a context which cannot be canceled
+	start := func(ctx context.Context, blocks ...uint64) {
+		res, err := StartIndexer(indexer.InitParams{
+			Config:  indexer.Config{Type: IndexerType, Config: map[string]interface{}{"output_dir": dir, "blocks_per_file": 10}},
+			Context: ctx,
+		})
+		require.NoError(t, err)
+		require.NoError(t, res.Listener.InitializeModuleData(appdata.ModuleInitializationData{
+			ModuleName: "bank",
+			Schema:     schema.MustCompileModuleSchema(testObjectType),
+		}))
+		for _, blockNum := range blocks {
+			require.NoError(t, res.Listener.StartBlock(appdata.StartBlockData{Height: blockNum}))
+			require.NoError(t, res.Listener.OnObjectUpdate(appdata.ObjectUpdateData{ModuleName: "bank", Updates: []schema.ObjectUpdate{
+				{TypeName: "balance", Key: []interface{}{[]byte{0xab}, "atom"}, Value: []interface{}{blockNum, time.Unix(0, 0), nil}},
+			}}))
+			_, err = res.Listener.Commit(appdata.CommitData{})
+			require.NoError(t, err)
+		}
+	}
+
+	start(context.Background(), 1, 2)
+	require.Len(t, readRows(t, partition+".parquet"), 1)
+	require.Len(t, readRows(t, partition+".1.parquet"), 1)
+
+	// an incomplete file left by an unclean shutdown is kept
+	require.NoError(t, os.WriteFile(partition+".2.parquet.tmp", []byte("incomplete"), 0o600))
+
+	// the rows of the same partition written after a restart go to a new part
+	ctx, cancel := context.WithCancel(context.Background())
+	start(ctx, 3, 4)
+	cancel()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(partition + ".3.parquet")
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	rows := readRows(t, partition+".3.parquet")
+	require.Len(t, rows, 2)
+	require.Equal(t, int64(3), rows[0]["_block_number"])
+	require.Len(t, readRows(t, partition+".parquet"), 1)
+	bz, err := os.ReadFile(partition + ".2.parquet.tmp")
+	require.NoError(t, err)
+	require.Equal(t, "incomplete", string(bz))
+}
+
+// readRows reads the rows of the file as maps of column names to values.
+func readRows(t *testing.T, fileName string) []map[string]interface{} {
+	t.Helper()
+
+	f, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer f.Close()
+
+	reader := parquet.NewReader(f)
+	columns := reader.Schema().Columns()
+
+	rows := make([]parquet.Row, reader.NumRows())
+	n, err := reader.ReadRows(rows)
+	if n < len(rows) {
+		require.NoError(t, err)
+	}
+
+	res := make([]map[string]interface{}, 0, n)
+	for _, r := range rows[:n] {
+		m := map[string]interface{}{}
+		r.Range(func(columnIndex int, values []parquet.Value) bool {
+			name := columns[columnIndex][0]
+			if name == updatedFieldsColumn {
+				var list []interface{}
+				for _, v := range values {
+					if !v.IsNull() {
+						list = append(list, v.String())
+					}
+				}
+				if list == nil {
+					m[name] = nil
+				} else {
+					m[name] = list
+				}
+				return true
+			}
+
+			v := values[0]
+			switch {
+			case v.IsNull():
+				m[name] = nil
+			case v.Kind() == parquet.ByteArray:
+				m[name] = string(v.ByteArray())
+			case v.Kind() == parquet.Boolean:
+				m[name] = v.Boolean()
+			default:
+				m[name] = v.Int64()
+			}
+			return true
+		})
+		res = append(res, m)
+	}
+	return res
+}
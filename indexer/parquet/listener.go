@@ -0,0 +1,111 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+
+	"cosmossdk.io/schema/appdata"
+)
+
+func (i *indexerImpl) listener() appdata.Listener {
+	return appdata.Listener{
+		InitializeModuleData: func(data appdata.ModuleInitializationData) error {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			moduleName := data.ModuleName
+			_, ok := i.modules[moduleName]
+			if ok {
+				return fmt.Errorf("module %s already initialized", moduleName)
+			}
+
+			mm, err := newModuleIndexer(moduleName, data.Schema, i.opts)
+			if err != nil {
+				return err
+			}
+
+			i.modules[moduleName] = mm
+			return nil
+		},
+		StartBlock: func(data appdata.StartBlockData) error {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			if err := i.checkOpen(); err != nil {
+				return err
+			}
+
+			i.blockNum = data.Height
+			return i.blocks.writeBlock(data)
+		},
+		OnTx: func(data appdata.TxData) error {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			if err := i.checkOpen(); err != nil {
+				return err
+			}
+
+			return i.blocks.writeTx(i.blockNum, data)
+		},
+		OnEvent: func(data appdata.EventData) error {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			if err := i.checkOpen(); err != nil {
+				return err
+			}
+
+			return i.blocks.writeEvents(i.blockNum, data)
+		},
+		OnObjectUpdate: func(data appdata.ObjectUpdateData) error {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			if err := i.checkOpen(); err != nil {
+				return err
+			}
+
+			mod, ok := i.modules[data.ModuleName]
+			if !ok {
+				return fmt.Errorf("module %s not initialized", data.ModuleName)
+			}
+
+			for _, update := range data.Updates {
+				tm, ok := mod.tables[update.TypeName]
+				if !ok {
+					return fmt.Errorf("object type %s not found in schema for module %s", update.TypeName, data.ModuleName)
+				}
+
+				err := tm.writeUpdate(i.blockNum, update)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Commit: func(data appdata.CommitData) (func() error, error) {
+			i.mtx.Lock()
+			defer i.mtx.Unlock()
+
+			if err := i.checkOpen(); err != nil {
+				return nil, err
+			}
+
+			// the files are finished as soon as the last block of their partition is committed
+			// so that they can be read without waiting for the next partition to start
+			if i.finishOnCommit || i.opts.partitionOf(i.blockNum).last == i.blockNum {
+				return nil, i.closeFiles()
+			}
+			return nil, nil
+		},
+	}
+}
+
+// checkOpen returns an error if the indexer was closed.
+func (i *indexerImpl) checkOpen() error {
+	if i.closed {
+		return errors.New("parquet indexer is closed")
+	}
+	return nil
+}
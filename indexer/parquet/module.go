@@ -0,0 +1,33 @@
+package parquet
+
+import (
+	"cosmossdk.io/schema"
+)
+
+// moduleIndexer manages the tables for a module.
+type moduleIndexer struct {
+	moduleName string
+	schema     schema.ModuleSchema
+	tables     map[string]*objectTable
+}
+
+// newModuleIndexer creates a new moduleIndexer for the given module schema.
+func newModuleIndexer(moduleName string, modSchema schema.ModuleSchema, opts options) (*moduleIndexer, error) {
+	tables := map[string]*objectTable{}
+	var err error
+	modSchema.ObjectTypes(func(typ schema.ObjectType) bool {
+		var tm *objectTable
+		tm, err = newObjectTable(moduleName, typ, opts)
+		tables[typ.Name] = tm
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &moduleIndexer{
+		moduleName: moduleName,
+		schema:     modSchema,
+		tables:     tables,
+	}, nil
+}
@@ -0,0 +1,176 @@
+package parquet
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+
+	"cosmossdk.io/schema"
+)
+
+const (
+	// blockNumberColumn is the column of the block number at which an object was updated.
+	blockNumberColumn = "_block_number"
+
+	// deleteColumn is the column which is true for rows which record the deletion of an object.
+	deleteColumn = "_delete"
+
+	// updatedFieldsColumn is the column which lists the value fields which were updated by a partial update.
+	updatedFieldsColumn = "_updated_fields"
+)
+
+// objectTable writes the updates of an object type.
+type objectTable struct {
+	*table
+	typ         schema.ObjectType
+	valueFields map[string]schema.Field
+	opts        options
+}
+
+// newObjectTable creates an objectTable for the object type of the module.
+func newObjectTable(moduleName string, typ schema.ObjectType, opts options) (*objectTable, error) {
+	root := parquet.Group{
+		blockNumberColumn:   parquet.Uint(64),
+		deleteColumn:        parquet.Leaf(parquet.BooleanType),
+		updatedFieldsColumn: parquet.Repeated(parquet.String()),
+	}
+
+	addColumn := func(field schema.Field, optional bool) error {
+		if _, ok := root[field.Name]; ok {
+			return fmt.Errorf("field %q of object type %q clashes with a reserved column name", field.Name, typ.Name)
+		}
+
+		node, err := columnNode(field, optional)
+		if err != nil {
+			return err
+		}
+		root[field.Name] = node
+		return nil
+	}
+
+	for _, field := range typ.KeyFields {
+		if err := addColumn(field, false); err != nil {
+			return nil, err
+		}
+	}
+
+	valueFields := map[string]schema.Field{}
+	for _, field := range typ.ValueFields {
+		// value columns are always optional because deletions and partial updates don't have values for them
+		if err := addColumn(field, true); err != nil {
+			return nil, err
+		}
+		valueFields[field.Name] = field
+	}
+
+	dir := filepath.Join("state", moduleName, typ.Name)
+	return &objectTable{
+		table:       newTable(dir, fmt.Sprintf("%s_%s", moduleName, typ.Name), root, opts),
+		typ:         typ,
+		valueFields: valueFields,
+		opts:        opts,
+	}, nil
+}
+
+// writeUpdate writes a row recording the update at the block.
+func (tm *objectTable) writeUpdate(blockNum uint64, update schema.ObjectUpdate) error {
+	r := tm.newRow()
+	r.set(blockNumberColumn, parquet.Int64Value(int64(blockNum)))
+	r.set(deleteColumn, parquet.BooleanValue(update.Delete))
+
+	err := tm.setKey(r, update.Key)
+	if err != nil {
+		return err
+	}
+
+	if !update.Delete {
+		err = tm.setValue(r, update.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tm.write(blockNum, r)
+}
+
+// setKey sets the key columns of the row.
+func (tm *objectTable) setKey(r row, key interface{}) error {
+	n := len(tm.typ.KeyFields)
+	if n == 0 {
+		// singletons have no key columns
+		return nil
+	} else if n == 1 {
+		return tm.setFields(r, tm.typ.KeyFields, []interface{}{key})
+	}
+
+	values, ok := key.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected key to be a slice")
+	}
+	return tm.setFields(r, tm.typ.KeyFields, values)
+}
+
+// setValue sets the value columns of the row. If the value is a schema.ValueUpdates, only the
+// updated columns are set and their names are listed in the updated fields column.
+func (tm *objectTable) setValue(r row, value interface{}) error {
+	n := len(tm.typ.ValueFields)
+	if n == 0 {
+		return nil
+	} else if valueUpdates, ok := value.(schema.ValueUpdates); ok {
+		var e error
+		var fields []schema.Field
+		var values []interface{}
+		var names []parquet.Value
+		if err := valueUpdates.Iterate(func(name string, value interface{}) bool {
+			field, ok := tm.valueFields[name]
+			if !ok {
+				e = fmt.Errorf("unknown field %q", name)
+				return false
+			}
+			fields = append(fields, field)
+			values = append(values, value)
+			names = append(names, parquet.ByteArrayValue([]byte(name)))
+			return true
+		}); err != nil {
+			return err
+		}
+		if e != nil {
+			return e
+		}
+
+		r.setList(updatedFieldsColumn, names)
+		return tm.setFields(r, fields, values)
+	} else if n == 1 {
+		return tm.setFields(r, tm.typ.ValueFields, []interface{}{value})
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected values to be a slice")
+	}
+	return tm.setFields(r, tm.typ.ValueFields, values)
+}
+
+// setFields sets the columns of the fields to the values.
+func (tm *objectTable) setFields(r row, fields []schema.Field, values []interface{}) error {
+	if len(values) != len(fields) {
+		return fmt.Errorf("expected %d values, got %d", len(fields), len(values))
+	}
+
+	for i, field := range fields {
+		if values[i] == nil {
+			if !field.Nullable {
+				return fmt.Errorf("expected non-null value for field %q", field.Name)
+			}
+			continue
+		}
+
+		value, err := tm.opts.encodeValue(field, values[i])
+		if err != nil {
+			return err
+		}
+		r.set(field.Name, value)
+	}
+	return nil
+}
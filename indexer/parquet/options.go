@@ -0,0 +1,26 @@
+package parquet
+
+import (
+	"github.com/parquet-go/parquet-go/compress"
+
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/logutil"
+)
+
+// options are the options for the tables written by the indexer.
+type options struct {
+	// outputDir is the directory in which the files are written.
+	outputDir string
+
+	// blocksPerFile is the number of blocks in each partition.
+	blocksPerFile uint64
+
+	// compression is the compression codec of the files.
+	compression compress.Codec
+
+	// logger is the logger for the indexer to use. It may be nil.
+	logger logutil.Logger
+
+	// addressCodec is the codec used to convert addresses to their string representation.
+	addressCodec addressutil.AddressCodec
+}
@@ -0,0 +1,21 @@
+package parquet
+
+import "fmt"
+
+// partition is a range of blocks which are written to the same files.
+type partition struct {
+	// first and last are the first and the last blocks of the partition, inclusive.
+	first, last uint64
+}
+
+// partitionOf returns the partition of the block.
+func (o options) partitionOf(blockNum uint64) partition {
+	first := blockNum - blockNum%o.blocksPerFile
+	return partition{first: first, last: first + o.blocksPerFile - 1}
+}
+
+// String returns the name of the files of the partition. The block numbers are zero padded
+// so that the files are sorted by block number.
+func (p partition) String() string {
+	return fmt.Sprintf("%020d-%020d", p.first, p.last)
+}
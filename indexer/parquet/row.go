@@ -0,0 +1,42 @@
+package parquet
+
+import "github.com/parquet-go/parquet-go"
+
+// row builds a row of a table by column name.
+type row struct {
+	columns map[string]parquet.LeafColumn
+	values  [][]parquet.Value
+}
+
+// set sets the value of the column.
+func (r row) set(column string, value parquet.Value) {
+	leaf := r.columns[column]
+	r.values[leaf.ColumnIndex] = []parquet.Value{value.Level(0, leaf.MaxDefinitionLevel, leaf.ColumnIndex)}
+}
+
+// setList sets the values of a repeated column.
+func (r row) setList(column string, values []parquet.Value) {
+	leaf := r.columns[column]
+	levels := make([]parquet.Value, len(values))
+	for i, value := range values {
+		repetitionLevel := 0
+		if i > 0 {
+			repetitionLevel = leaf.MaxRepetitionLevel
+		}
+		levels[i] = value.Level(repetitionLevel, leaf.MaxDefinitionLevel, leaf.ColumnIndex)
+	}
+	r.values[leaf.ColumnIndex] = levels
+}
+
+// parquetRow returns the values of the row in column order, with null values for the columns which were not set.
+func (r row) parquetRow() parquet.Row {
+	res := make(parquet.Row, 0, len(r.values))
+	for i, values := range r.values {
+		if len(values) == 0 {
+			res = append(res, parquet.Value{}.Level(0, 0, i))
+			continue
+		}
+		res = append(res, values...)
+	}
+	return res
+}
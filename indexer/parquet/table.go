@@ -0,0 +1,151 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// table writes rows with the same schema to files in a directory, one file per partition of blocks.
+type table struct {
+	dir     string
+	schema  *parquet.Schema
+	columns map[string]parquet.LeafColumn
+	opts    options
+
+	// the file of the current partition, which is nil until a row is written in the partition
+	file      *os.File
+	writer    *parquet.Writer
+	partition partition
+	part      int
+}
+
+// newTable creates a table which writes its files to the directory dir relative to the output directory.
+func newTable(dir, name string, root parquet.Group, opts options) *table {
+	s := parquet.NewSchema(name, root)
+	columns := map[string]parquet.LeafColumn{}
+	for _, path := range s.Columns() {
+		leaf, _ := s.Lookup(path...)
+		columns[path[0]] = leaf
+	}
+
+	return &table{
+		dir:     filepath.Join(opts.outputDir, dir),
+		schema:  s,
+		columns: columns,
+		opts:    opts,
+	}
+}
+
+// newRow creates an empty row for the table in which all columns are null.
+func (t *table) newRow() row {
+	return row{columns: t.columns, values: make([][]parquet.Value, len(t.columns))}
+}
+
+// write writes the row to the file of the partition of the block.
+func (t *table) write(blockNum uint64, r row) error {
+	p := t.opts.partitionOf(blockNum)
+	if t.writer != nil && p != t.partition {
+		err := t.close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.writer == nil {
+		err := t.open(p)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := t.writer.WriteRows([]parquet.Row{r.parquetRow()})
+	return err
+}
+
+// open creates the temporary file of the next part of the partition. The files of the partition which
+// already exist, either finished or left incomplete by an unclean shutdown, are never overwritten: the
+// rows are written to a new part instead.
+func (t *table) open(p partition) error {
+	err := os.MkdirAll(t.dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	part := 0
+	for ; ; part++ {
+		finished, err := fileExists(t.fileName(p, part))
+		if err != nil {
+			return err
+		}
+		incomplete, err := fileExists(t.tmpFileName(p, part))
+		if err != nil {
+			return err
+		}
+		if !finished && !incomplete {
+			break
+		}
+		if incomplete && t.opts.logger != nil {
+			t.opts.logger.Warn("Keeping incomplete parquet file of a previous run", "file", t.tmpFileName(p, part))
+		}
+	}
+
+	file, err := os.OpenFile(t.tmpFileName(p, part), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+
+	t.file = file
+	t.partition = p
+	t.part = part
+	t.writer = parquet.NewWriter(file, t.schema, parquet.Compression(t.opts.compression))
+	return nil
+}
+
+// close writes the footer of the file of the current partition and renames it from its temporary
+// name to its final name. It does nothing if no file is open.
+func (t *table) close() error {
+	if t.writer == nil {
+		return nil
+	}
+
+	writer, file := t.writer, t.file
+	t.writer, t.file = nil, nil
+
+	err := errors.Join(writer.Close(), file.Close())
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", t.tmpFileName(t.partition, t.part), err)
+	}
+
+	if t.opts.logger != nil {
+		t.opts.logger.Debug("Finished parquet file", "file", t.fileName(t.partition, t.part))
+	}
+	return os.Rename(t.tmpFileName(t.partition, t.part), t.fileName(t.partition, t.part))
+}
+
+// fileName returns the name of the file of the part of the partition. The first part is named after
+// the partition only, the following ones are suffixed with their part number.
+func (t *table) fileName(p partition, part int) string {
+	name := p.String()
+	if part > 0 {
+		name = fmt.Sprintf("%s.%d", name, part)
+	}
+	return filepath.Join(t.dir, name+".parquet")
+}
+
+// tmpFileName returns the name of the file of the part of the partition while it is being written.
+func (t *table) tmpFileName(p partition, part int) string {
+	return t.fileName(p, part) + ".tmp"
+}
+
+// fileExists returns true if the file exists.
+func fileExists(name string) (bool, error) {
+	_, err := os.Stat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/require"
+
+	indexerparquet "cosmossdk.io/indexer/parquet"
+	"cosmossdk.io/schema/appdata"
+	"cosmossdk.io/schema/indexer"
+	schematesting "cosmossdk.io/schema/testing"
+	"cosmossdk.io/schema/testing/appdatasim"
+)
+
+func TestAppSimulator(t *testing.T) {
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	const blocksPerFile = 3
+	res, err := indexerparquet.StartIndexer(indexer.InitParams{
+		Config: indexer.Config{
+			Type: indexerparquet.IndexerType,
+			Config: map[string]interface{}{
+				"output_dir":      dir,
+				"blocks_per_file": blocksPerFile,
+			},
+		},
+		Context: ctx,
+	})
+	require.NoError(t, err)
+
+	// count the updates of each object type to check that every update was written
+	expectedRows := map[string]int{}
+	listener := res.Listener
+	onObjectUpdate := listener.OnObjectUpdate
+	listener.OnObjectUpdate = func(data appdata.ObjectUpdateData) error {
+		for _, update := range data.Updates {
+			expectedRows[filepath.Join(data.ModuleName, update.TypeName)]++
+		}
+		return onObjectUpdate(data)
+	}
+
+	sim, err := appdatasim.NewSimulator(appdatasim.Options{
+		AppSchema: schematesting.ExampleAppSchema,
+		Listener:  listener,
+	})
+	require.NoError(t, err)
+
+	blockDataGen := sim.BlockDataGenN(50, 100)
+	const numBlocks = 10
+	for i := 0; i < numBlocks; i++ {
+		data := blockDataGen.Example(i + 1)
+		require.NoError(t, sim.ProcessBlockData(data))
+	}
+
+	// closing the indexer finishes the files of the last partition
+	cancel()
+	require.Eventually(t, func() bool {
+		return res.Listener.StartBlock(appdata.StartBlockData{Height: numBlocks + 1}) != nil
+	}, time.Second, time.Millisecond)
+
+	actualRows := map[string]int{}
+	stateDir := filepath.Join(dir, "state")
+	err = filepath.Walk(stateDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		require.True(t, strings.HasSuffix(path, ".parquet"), "unexpected file %s", path)
+
+		var first, last uint64
+		_, err = fmt.Sscanf(filepath.Base(path), "%d-%d.parquet", &first, &last)
+		require.NoError(t, err)
+		require.Equal(t, uint64(blocksPerFile-1), last-first)
+
+		for _, blockNum := range readBlockNumbers(t, path) {
+			require.GreaterOrEqual(t, blockNum, first)
+			require.LessOrEqual(t, blockNum, last)
+			rel, err := filepath.Rel(stateDir, filepath.Dir(path))
+			require.NoError(t, err)
+			actualRows[rel]++
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, expectedRows, actualRows)
+}
+
+// readBlockNumbers reads the block number of every row of the file.
+func readBlockNumbers(t *testing.T, fileName string) []uint64 {
+	t.Helper()
+
+	f, err := os.Open(fileName)
+	require.NoError(t, err)
+	defer f.Close()
+
+	reader := parquet.NewReader(f)
+	leaf, ok := reader.Schema().Lookup("_block_number")
+	require.True(t, ok)
+
+	rows := make([]parquet.Row, reader.NumRows())
+	n, err := reader.ReadRows(rows)
+	if n < len(rows) {
+		require.NoError(t, err)
+	}
+
+	var res []uint64
+	for _, r := range rows[:n] {
+		r.Range(func(columnIndex int, values []parquet.Value) bool {
+			if columnIndex == leaf.ColumnIndex {
+				res = append(res, values[0].Uint64())
+			}
+			return true
+		})
+	}
+	return res
+}
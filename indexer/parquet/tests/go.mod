@@ -0,0 +1,31 @@
+module cosmossdk.io/indexer/parquet/testing
+
+go 1.23
+
+require (
+	cosmossdk.io/indexer/parquet v0.0.0-00010101000000-000000000000
+	cosmossdk.io/schema v0.1.1
+	cosmossdk.io/schema/testing v0.0.0
+	github.com/parquet-go/parquet-go v0.25.1
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/cockroachdb/apd/v3 v3.2.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/tidwall/btree v1.7.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	pgregory.net/rapid v1.1.0 // indirect
+)
+
+replace cosmossdk.io/indexer/parquet => ../.
+
+replace cosmossdk.io/schema => ../../../schema
+
+replace cosmossdk.io/schema/testing => ../../../schema/testing
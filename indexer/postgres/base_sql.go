@@ -30,4 +30,10 @@ CREATE TABLE IF NOT EXISTS event
     type         TEXT   NOT NULL,
     data         JSONB  NOT NULL
 );
+
+CREATE TABLE IF NOT EXISTS module_schema
+(
+    module_name TEXT  NOT NULL PRIMARY KEY,
+    schema      JSONB NOT NULL
+);
 `
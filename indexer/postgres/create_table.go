@@ -20,7 +20,15 @@ func (tm *objectIndexer) createTable(ctx context.Context, conn dbConn) error {
 		tm.options.logger.Debug("Creating table %s", "table", tm.tableName(), "sql", sqlStr)
 	}
 	_, err = conn.ExecContext(ctx, sqlStr)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if tm.retainDeletions() {
+		return tm.createHistoryTable(ctx, conn)
+	}
+
+	return nil
 }
 
 // createTableSql generates a CREATE TABLE statement for the object type.
@@ -29,49 +37,23 @@ func (tm *objectIndexer) createTableSql(writer io.Writer) error {
 	if err != nil {
 		return err
 	}
-	isSingleton := false
-	if len(tm.typ.KeyFields) == 0 {
-		isSingleton = true
-		_, err = fmt.Fprintf(writer, "_id INTEGER NOT NULL CHECK (_id = 1),\n\t")
-		if err != nil {
-			return err
-		}
-	} else {
-		for _, field := range tm.typ.KeyFields {
-			err = tm.createColumnDefinition(writer, field)
-			if err != nil {
-				return err
-			}
-		}
-	}
 
-	for _, field := range tm.typ.ValueFields {
-		err = tm.createColumnDefinition(writer, field)
-		if err != nil {
-			return err
-		}
+	err = tm.createColumnDefinitions(writer)
+	if err != nil {
+		return err
 	}
 
 	// add _deleted column when we have RetainDeletions set and enabled
-	if !tm.options.disableRetainDeletions && tm.typ.RetainDeletions {
+	if tm.retainDeletions() {
 		_, err = fmt.Fprintf(writer, "_deleted BOOLEAN NOT NULL DEFAULT FALSE,\n\t")
 		if err != nil {
 			return err
 		}
 	}
 
-	var pKeys []string
-	if !isSingleton {
-		for _, field := range tm.typ.KeyFields {
-			name, err := tm.updatableColumnName(field)
-			if err != nil {
-				return err
-			}
-
-			pKeys = append(pKeys, name)
-		}
-	} else {
-		pKeys = []string{"_id"}
+	pKeys, err := tm.keyColumnNames()
+	if err != nil {
+		return err
 	}
 
 	_, err = fmt.Fprintf(writer, "PRIMARY KEY (%s)", strings.Join(pKeys, ", "))
@@ -94,3 +76,29 @@ func (tm *objectIndexer) createTableSql(writer io.Writer) error {
 
 	return nil
 }
+
+// createColumnDefinitions writes the column definitions of the key and value fields.
+func (tm *objectIndexer) createColumnDefinitions(writer io.Writer) error {
+	if len(tm.typ.KeyFields) == 0 {
+		_, err := fmt.Fprintf(writer, "_id INTEGER NOT NULL CHECK (_id = 1),\n\t")
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, field := range tm.typ.KeyFields {
+			err := tm.createColumnDefinition(writer, field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, field := range tm.typ.ValueFields {
+		err := tm.createColumnDefinition(writer, field)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// delete deletes the row with the provided key from the table, or marks it as
+// deleted if the object type retains deletions.
+func (tm *objectIndexer) delete(ctx context.Context, conn dbConn, key interface{}) error {
+	buf := new(strings.Builder)
+	params, err := tm.deleteSql(buf, key)
+	if err != nil {
+		return err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Delete", "table", tm.tableName(), "sql", sqlStr, "params", params)
+	}
+	_, err = conn.ExecContext(ctx, sqlStr, params...)
+	return err
+}
+
+// deleteSql generates a DELETE or UPDATE statement for the provided key.
+func (tm *objectIndexer) deleteSql(w io.Writer, key interface{}) ([]interface{}, error) {
+	var err error
+	if tm.retainDeletions() {
+		_, err = fmt.Fprintf(w, "UPDATE %q SET _deleted = TRUE", tm.tableName())
+	} else {
+		_, err = fmt.Fprintf(w, "DELETE FROM %q", tm.tableName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, params, err := tm.whereSqlAndParams(w, key, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(w, ";")
+	return params, err
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// historyTableName returns the name of the table which records the state of the objects
+// at every block in which they were updated. History is only recorded for object types
+// which retain deletions.
+func (tm *objectIndexer) historyTableName() string {
+	return fmt.Sprintf("%s_history", tm.tableName())
+}
+
+// createHistoryTable creates the history table for the object type.
+func (tm *objectIndexer) createHistoryTable(ctx context.Context, conn dbConn) error {
+	buf := new(strings.Builder)
+	err := tm.createHistoryTableSql(buf)
+	if err != nil {
+		return err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Creating table %s", "table", tm.historyTableName(), "sql", sqlStr)
+	}
+	_, err = conn.ExecContext(ctx, sqlStr)
+	return err
+}
+
+// createHistoryTableSql generates a CREATE TABLE statement for the history table of the object type.
+// The history table has the same columns as the object table plus the block number at which the
+// object had that state.
+func (tm *objectIndexer) createHistoryTableSql(writer io.Writer) error {
+	_, err := fmt.Fprintf(writer, "CREATE TABLE IF NOT EXISTS %q (\n\t", tm.historyTableName())
+	if err != nil {
+		return err
+	}
+
+	err = tm.createColumnDefinitions(writer)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "_deleted BOOLEAN NOT NULL DEFAULT FALSE,\n\t_block_number BIGINT NOT NULL,\n\t")
+	if err != nil {
+		return err
+	}
+
+	pKeys, err := tm.keyColumnNames()
+	if err != nil {
+		return err
+	}
+	pKeys = append(pKeys, "_block_number")
+
+	_, err = fmt.Fprintf(writer, "PRIMARY KEY (%s)\n);\n", strings.Join(pKeys, ", "))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "GRANT SELECT ON TABLE %q TO PUBLIC;", tm.historyTableName())
+	return err
+}
+
+// recordHistory copies the current state of the object with the provided key to the history table
+// at the provided block number.
+func (tm *objectIndexer) recordHistory(ctx context.Context, conn dbConn, key interface{}, blockNumber uint64) error {
+	buf := new(strings.Builder)
+	params, err := tm.recordHistorySql(buf, key, blockNumber)
+	if err != nil {
+		return err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Record history", "table", tm.historyTableName(), "sql", sqlStr, "params", params)
+	}
+	_, err = conn.ExecContext(ctx, sqlStr, params...)
+	return err
+}
+
+// recordHistorySql generates an INSERT ... SELECT statement copying the object with the provided key
+// into the history table.
+func (tm *objectIndexer) recordHistorySql(w io.Writer, key interface{}, blockNumber uint64) ([]interface{}, error) {
+	cols, err := tm.columnNames()
+	if err != nil {
+		return nil, err
+	}
+	colsStr := strings.Join(cols, ", ")
+
+	_, err = fmt.Fprintf(w, "INSERT INTO %q (%s, _deleted, _block_number) SELECT %s, _deleted, $1 FROM %q",
+		tm.historyTableName(), colsStr, colsStr, tm.tableName())
+	if err != nil {
+		return nil, err
+	}
+
+	_, keyParams, err := tm.whereSqlAndParams(w, key, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	pKeys, err := tm.keyColumnNames()
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []string{"_deleted = EXCLUDED._deleted"}
+	for _, col := range cols[len(pKeys):] {
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+	}
+
+	_, err = fmt.Fprintf(w, " ON CONFLICT (%s, _block_number) DO UPDATE SET %s;", strings.Join(pKeys, ", "), strings.Join(sets, ", "))
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]interface{}{int64(blockNumber)}, keyParams...), nil
+}
@@ -0,0 +1,426 @@
+package postgres
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/schema"
+)
+
+// NewHTTPHandler returns an http.Handler serving the objects indexed by the querier as JSON.
+//
+// The following routes are served:
+//   - GET /{module}/{object_type} lists the objects of the type. Value and key fields can be
+//     filtered with field=value or field[op]=value where op is one of eq, ne, lt, lte, gt, gte.
+//     field[null]=true or field[null]=false filters nullable fields on whether they are NULL.
+//     The limit, after, as_of and include_deleted parameters map to the fields of ListOptions,
+//     where after is the next cursor returned by the previous page.
+//   - GET /{module}/{object_type}/get?{key_field}={value}&as_of={block} returns a single object.
+//
+// Field values are formatted as follows: bytes as standard base64, times as RFC 3339 with
+// nanoseconds, durations as Go durations, addresses with the indexer address codec and JSON
+// as raw JSON. All other values are formatted as in Go.
+func NewHTTPHandler(q *Querier) http.Handler {
+	return httpHandler{q}
+}
+
+type httpHandler struct {
+	q *Querier
+}
+
+// httpError is an error with the HTTP status code it should be reported with.
+type httpError struct {
+	code int
+	msg  string
+}
+
+func (e httpError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return httpError{code: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func (h httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSONError(w, httpError{code: http.StatusMethodNotAllowed, msg: "only GET is supported"})
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || (len(parts) == 3 && parts[2] != "get") {
+		writeJSONError(w, httpError{code: http.StatusNotFound, msg: fmt.Sprintf("unknown route %q", r.URL.Path)})
+		return
+	}
+
+	tm, err := h.q.objectIndexer(parts[0], parts[1])
+	if err != nil {
+		writeJSONError(w, httpError{code: http.StatusNotFound, msg: err.Error()})
+		return
+	}
+
+	var res interface{}
+	if len(parts) == 3 {
+		res, err = h.get(r, tm)
+	} else {
+		res, err = h.list(r, tm)
+	}
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(res)
+	if err != nil && tm.options.logger != nil {
+		tm.options.logger.Error("failed to write response", "error", err)
+	}
+}
+
+func (h httpHandler) get(r *http.Request, tm *objectIndexer) (interface{}, error) {
+	params := r.URL.Query()
+	asOf, err := parseBlockParam("as_of", params["as_of"])
+	if err != nil {
+		return nil, err
+	}
+
+	keyValues := make([]interface{}, len(tm.typ.KeyFields))
+	for i, field := range tm.typ.KeyFields {
+		str := params.Get(field.Name)
+		if str == "" {
+			return nil, badRequest("missing key field %q", field.Name)
+		}
+		keyValues[i], err = tm.parseFieldValue(field, str)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	update, found, err := tm.get(r.Context(), h.q.db, fieldsValue(keyValues), asOf)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, httpError{code: http.StatusNotFound, msg: "object not found"}
+	}
+	return tm.objectJSON(update)
+}
+
+type listResponse struct {
+	Objects []map[string]interface{} `json:"objects"`
+	Next    string                   `json:"next,omitempty"`
+}
+
+func (h httpHandler) list(r *http.Request, tm *objectIndexer) (interface{}, error) {
+	opts, err := tm.parseListOptions(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := tm.list(r.Context(), h.q.db, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := listResponse{Objects: make([]map[string]interface{}, 0, len(res.Objects))}
+	for _, update := range res.Objects {
+		obj, err := tm.objectJSON(update)
+		if err != nil {
+			return nil, err
+		}
+		resp.Objects = append(resp.Objects, obj)
+	}
+
+	if res.NextKey != nil {
+		resp.Next, err = tm.encodeCursor(res.NextKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// parseListOptions parses the query parameters of a list request.
+func (tm *objectIndexer) parseListOptions(r *http.Request) (ListOptions, error) {
+	var opts ListOptions
+	var err error
+	for name, values := range r.URL.Query() {
+		switch name {
+		case "limit":
+			opts.Limit, err = strconv.Atoi(values[0])
+			if err != nil {
+				return opts, badRequest("invalid limit %q", values[0])
+			}
+		case "after":
+			opts.After, err = tm.decodeCursor(values[0])
+			if err != nil {
+				return opts, err
+			}
+		case "as_of":
+			opts.AsOfBlock, err = parseBlockParam(name, values)
+			if err != nil {
+				return opts, err
+			}
+		case "include_deleted":
+			opts.IncludeDeleted, err = strconv.ParseBool(values[0])
+			if err != nil {
+				return opts, badRequest("invalid include_deleted %q", values[0])
+			}
+		default:
+			for _, value := range values {
+				filter, err := tm.parseFilter(name, value)
+				if err != nil {
+					return opts, err
+				}
+				opts.Filters = append(opts.Filters, filter)
+			}
+		}
+	}
+	return opts, nil
+}
+
+var httpFilterOps = map[string]FilterOp{
+	"eq":  OpEq,
+	"ne":  OpNe,
+	"lt":  OpLt,
+	"lte": OpLte,
+	"gt":  OpGt,
+	"gte": OpGte,
+}
+
+// parseFilter parses a field=value or field[op]=value query parameter.
+func (tm *objectIndexer) parseFilter(name, value string) (Filter, error) {
+	fieldName, op := name, "eq"
+	if i := strings.IndexByte(name, '['); i > 0 && strings.HasSuffix(name, "]") {
+		fieldName, op = name[:i], name[i+1:len(name)-1]
+	}
+
+	field, ok := tm.allFields[fieldName]
+	if !ok {
+		return Filter{}, badRequest("unknown field %q", fieldName)
+	}
+
+	if op == "null" {
+		isNull, err := strconv.ParseBool(value)
+		if err != nil {
+			return Filter{}, badRequest("invalid null filter value %q", value)
+		}
+		if isNull {
+			return Filter{Field: fieldName, Op: OpEq}, nil
+		}
+		return Filter{Field: fieldName, Op: OpNe}, nil
+	}
+
+	filterOp, ok := httpFilterOps[op]
+	if !ok {
+		return Filter{}, badRequest("unknown filter operator %q", op)
+	}
+
+	v, err := tm.parseFieldValue(field, value)
+	if err != nil {
+		return Filter{}, err
+	}
+	return Filter{Field: fieldName, Op: filterOp, Value: v}, nil
+}
+
+// encodeCursor encodes an object key as an opaque pagination cursor.
+func (tm *objectIndexer) encodeCursor(key interface{}) (string, error) {
+	var keyValues []interface{}
+	if len(tm.typ.KeyFields) == 1 {
+		keyValues = []interface{}{key}
+	} else {
+		keyValues = key.([]interface{})
+	}
+
+	strs := make([]string, len(keyValues))
+	for i, field := range tm.typ.KeyFields {
+		var err error
+		strs[i], err = tm.formatFieldValue(field, keyValues[i])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	bz, err := json.Marshal(strs)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bz), nil
+}
+
+// decodeCursor decodes a pagination cursor returned by encodeCursor.
+func (tm *objectIndexer) decodeCursor(cursor string) (interface{}, error) {
+	bz, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, badRequest("invalid cursor")
+	}
+
+	var strs []string
+	if err := json.Unmarshal(bz, &strs); err != nil || len(strs) != len(tm.typ.KeyFields) {
+		return nil, badRequest("invalid cursor")
+	}
+
+	keyValues := make([]interface{}, len(strs))
+	for i, field := range tm.typ.KeyFields {
+		keyValues[i], err = tm.parseFieldValue(field, strs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fieldsValue(keyValues), nil
+}
+
+// objectJSON converts an object update to a JSON object with one property per field.
+// Objects whose deletion was retained have a _deleted property set to true.
+func (tm *objectIndexer) objectJSON(update schema.ObjectUpdate) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	err := tm.addFieldsJSON(obj, tm.typ.KeyFields, update.Key)
+	if err != nil {
+		return nil, err
+	}
+	err = tm.addFieldsJSON(obj, tm.typ.ValueFields, update.Value)
+	if err != nil {
+		return nil, err
+	}
+	if update.Delete {
+		obj["_deleted"] = true
+	}
+	return obj, nil
+}
+
+func (tm *objectIndexer) addFieldsJSON(obj map[string]interface{}, fields []schema.Field, value interface{}) error {
+	var values []interface{}
+	switch len(fields) {
+	case 0:
+		return nil
+	case 1:
+		values = []interface{}{value}
+	default:
+		values = value.([]interface{})
+	}
+
+	for i, field := range fields {
+		if values[i] == nil {
+			obj[field.Name] = nil
+			continue
+		}
+
+		switch field.Kind {
+		case schema.BoolKind, schema.Int8Kind, schema.Int16Kind, schema.Int32Kind, schema.Int64Kind,
+			schema.Uint8Kind, schema.Uint16Kind, schema.Uint32Kind, schema.Uint64Kind,
+			schema.Float32Kind, schema.Float64Kind, schema.JSONKind:
+			obj[field.Name] = values[i]
+		default:
+			str, err := tm.formatFieldValue(field, values[i])
+			if err != nil {
+				return err
+			}
+			obj[field.Name] = str
+		}
+	}
+	return nil
+}
+
+// formatFieldValue formats a field value as a string which can be parsed by parseFieldValue.
+func (tm *objectIndexer) formatFieldValue(field schema.Field, value interface{}) (string, error) {
+	switch field.Kind {
+	case schema.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.([]byte)), nil
+	case schema.AddressKind:
+		return tm.options.addressCodec.BytesToString(value.([]byte))
+	case schema.TimeKind:
+		return value.(time.Time).UTC().Format(time.RFC3339Nano), nil
+	case schema.DurationKind:
+		return value.(time.Duration).String(), nil
+	case schema.JSONKind:
+		return string(value.(json.RawMessage)), nil
+	default:
+		return fmt.Sprintf("%v", value), nil
+	}
+}
+
+// parseFieldValue parses a string formatted with formatFieldValue into a value of the field kind.
+func (tm *objectIndexer) parseFieldValue(field schema.Field, str string) (value interface{}, err error) {
+	switch field.Kind {
+	case schema.BoolKind:
+		value, err = strconv.ParseBool(str)
+	case schema.BytesKind:
+		value, err = base64.StdEncoding.DecodeString(str)
+	case schema.Int8Kind, schema.Int16Kind, schema.Int32Kind, schema.Int64Kind:
+		var x int64
+		x, err = strconv.ParseInt(str, 10, intBitSize(field.Kind))
+		value = intValue(field.Kind, x)
+	case schema.Uint8Kind, schema.Uint16Kind, schema.Uint32Kind:
+		var x uint64
+		x, err = strconv.ParseUint(str, 10, intBitSize(field.Kind))
+		value = intValue(field.Kind, int64(x))
+	case schema.Uint64Kind:
+		value, err = strconv.ParseUint(str, 10, 64)
+	case schema.Float32Kind:
+		var x float64
+		x, err = strconv.ParseFloat(str, 32)
+		value = float32(x)
+	case schema.Float64Kind:
+		value, err = strconv.ParseFloat(str, 64)
+	case schema.TimeKind:
+		value, err = time.Parse(time.RFC3339Nano, str)
+	case schema.DurationKind:
+		value, err = time.ParseDuration(str)
+	case schema.AddressKind:
+		value, err = tm.options.addressCodec.StringToBytes(str)
+	case schema.JSONKind:
+		value = json.RawMessage(str)
+	default:
+		value = str
+	}
+	if err != nil {
+		return nil, badRequest("invalid value %q for field %q: %v", str, field.Name, err) //nolint:errorlint // using %v for go 1.12 compat
+	}
+
+	if err := field.Kind.ValidateValue(value); err != nil {
+		return nil, badRequest("invalid value %q for field %q: %v", str, field.Name, err) //nolint:errorlint // using %v for go 1.12 compat
+	}
+	return value, nil
+}
+
+func intBitSize(kind schema.Kind) int {
+	switch kind {
+	case schema.Int8Kind, schema.Uint8Kind:
+		return 8
+	case schema.Int16Kind, schema.Uint16Kind:
+		return 16
+	case schema.Int32Kind, schema.Uint32Kind:
+		return 32
+	default:
+		return 64
+	}
+}
+
+// parseBlockParam parses the block number of the values of the named query parameter, it returns
+// nil if the parameter is not set.
+func parseBlockParam(name string, values []string) (*uint64, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	x, err := strconv.ParseUint(values[0], 10, 64)
+	if err != nil {
+		return nil, badRequest("invalid %s %q", name, values[0])
+	}
+	return &x, nil
+}
+
+func writeJSONError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	if httpErr, ok := err.(httpError); ok {
+		code = httpErr.code
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
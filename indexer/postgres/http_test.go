@@ -0,0 +1,30 @@
+package postgres
+
+import (
+	"fmt"
+	"net/http/httptest"
+
+	"cosmossdk.io/indexer/postgres/internal/testdata"
+)
+
+func Example_objectIndexer_parseListOptions() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	cursor, err := tm.encodeCursor([]interface{}{int64(1), []byte{0xab}})
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest("GET", "/test/vote?proposal[gte]=2&vote=no&limit=10&as_of=5&after="+cursor, nil)
+	opts, err := tm.parseListOptions(req)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(opts.Limit, *opts.AsOfBlock, opts.After)
+	for _, filter := range opts.Filters {
+		fmt.Println(filter.Field, filter.Op, filter.Value)
+	}
+	// Unordered output:
+	// 10 5 [1 [171]]
+	// proposal >= 2
+	// vote = no
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 
+	"cosmossdk.io/schema/addressutil"
 	"cosmossdk.io/schema/indexer"
 	"cosmossdk.io/schema/logutil"
 )
@@ -24,12 +25,14 @@ type Config struct {
 type SqlLogger = func(msg, sql string, params ...interface{})
 
 type indexerImpl struct {
-	ctx     context.Context
-	db      *sql.DB
-	tx      *sql.Tx
-	opts    options
-	modules map[string]*moduleIndexer
-	logger  logutil.Logger
+	ctx      context.Context
+	db       *sql.DB
+	tx       *sql.Tx
+	opts     options
+	modules  map[string]*moduleIndexer
+	querier  *Querier
+	blockNum uint64
+	logger   logutil.Logger
 }
 
 func StartIndexer(params indexer.InitParams) (indexer.InitResult, error) {
@@ -69,9 +72,15 @@ func StartIndexer(params indexer.InitParams) (indexer.InitResult, error) {
 	}
 
 	moduleIndexers := map[string]*moduleIndexer{}
+	addressCodec := params.AddressCodec
+	if addressCodec == nil {
+		addressCodec = addressutil.HexAddressCodec{}
+	}
+
 	opts := options{
 		disableRetainDeletions: config.DisableRetainDeletions,
 		logger:                 params.Logger,
+		addressCodec:           addressCodec,
 	}
 
 	idx := &indexerImpl{
@@ -80,11 +89,13 @@ func StartIndexer(params indexer.InitParams) (indexer.InitResult, error) {
 		tx:      tx,
 		opts:    opts,
 		modules: moduleIndexers,
+		querier: newQuerier(ctx, db, opts),
 		logger:  params.Logger,
 	}
 
 	return indexer.InitResult{
 		Listener: idx.listener(),
+		View:     idx.querier,
 	}, nil
 }
 
@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"cosmossdk.io/schema"
+)
+
+// insertUpdate inserts or updates the row with the provided key and value.
+// If value is a schema.ValueUpdates which does not set all the value fields, only the updated
+// columns of the existing row are written, or the row is inserted with NULL in the other
+// columns if it does not exist.
+func (tm *objectIndexer) insertUpdate(ctx context.Context, conn dbConn, key, value interface{}) error {
+	buf := new(strings.Builder)
+	var params []interface{}
+	var err error
+	isPartial := tm.isPartialValueUpdates(value)
+	if isPartial {
+		params, err = tm.updateSql(buf, key, value)
+	} else {
+		params, err = tm.insertUpdateSql(buf, key, value)
+	}
+	if err != nil {
+		return err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Insert or update", "table", tm.tableName(), "sql", sqlStr, "params", params)
+	}
+	res, err := conn.ExecContext(ctx, sqlStr, params...)
+	if err != nil || !isPartial {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// the object doesn't exist, so we insert it with the fields that are set, the
+	// other fields are NULL and this fails if they are not nullable
+	buf.Reset()
+	params, err = tm.insertUpdateSql(buf, key, value)
+	if err != nil {
+		return err
+	}
+
+	sqlStr = buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Insert or update", "table", tm.tableName(), "sql", sqlStr, "params", params)
+	}
+	_, err = conn.ExecContext(ctx, sqlStr, params...)
+	return err
+}
+
+// isPartialValueUpdates returns true if value is a schema.ValueUpdates which sets some, but not all, of the value fields.
+func (tm *objectIndexer) isPartialValueUpdates(value interface{}) bool {
+	valueUpdates, ok := value.(schema.ValueUpdates)
+	if !ok {
+		return false
+	}
+
+	n := 0
+	_ = valueUpdates.Iterate(func(string, interface{}) bool {
+		n++
+		return true
+	})
+	return n > 0 && n < len(tm.typ.ValueFields)
+}
+
+// updateSql generates an UPDATE statement setting the columns of the provided schema.ValueUpdates.
+// A partial set of columns cannot be upserted with an INSERT because the NOT NULL constraints are
+// checked before the conflict is detected.
+func (tm *objectIndexer) updateSql(w io.Writer, key, value interface{}) ([]interface{}, error) {
+	valueParams, valueCols, err := tm.bindValueParams(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var sets []string
+	for i, col := range valueCols {
+		sets = append(sets, fmt.Sprintf("%s = $%d", col, i+1))
+	}
+	if tm.retainDeletions() {
+		sets = append(sets, "_deleted = FALSE")
+	}
+
+	_, err = fmt.Fprintf(w, "UPDATE %q SET %s", tm.tableName(), strings.Join(sets, ", "))
+	if err != nil {
+		return nil, err
+	}
+
+	_, keyParams, err := tm.whereSqlAndParams(w, key, len(valueParams)+1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(w, ";")
+	return append(valueParams, keyParams...), err
+}
+
+// insertUpdateSql generates an INSERT ... ON CONFLICT DO UPDATE statement for the provided key and value.
+func (tm *objectIndexer) insertUpdateSql(w io.Writer, key, value interface{}) ([]interface{}, error) {
+	keyParams, keyCols, err := tm.bindKeyParams(key)
+	if err != nil {
+		return nil, err
+	}
+
+	valueParams, valueCols, err := tm.bindValueParams(value)
+	if err != nil {
+		return nil, err
+	}
+
+	allCols := make([]string, 0, len(keyCols)+len(valueCols))
+	allCols = append(allCols, keyCols...)
+	allCols = append(allCols, valueCols...)
+	allParams := make([]interface{}, 0, len(keyParams)+len(valueParams))
+	allParams = append(allParams, keyParams...)
+	allParams = append(allParams, valueParams...)
+
+	_, err = fmt.Fprintf(w, "INSERT INTO %q (%s) VALUES (", tm.tableName(), strings.Join(allCols, ", "))
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range allParams {
+		if i > 0 {
+			_, err = fmt.Fprintf(w, ", ")
+			if err != nil {
+				return nil, err
+			}
+		}
+		_, err = fmt.Fprintf(w, "$%d", i+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var sets []string
+	for _, col := range valueCols {
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", col, col))
+	}
+	if tm.retainDeletions() {
+		sets = append(sets, "_deleted = FALSE")
+	}
+
+	if len(sets) == 0 {
+		_, err = fmt.Fprintf(w, ") ON CONFLICT (%s) DO NOTHING;", strings.Join(keyCols, ", "))
+	} else {
+		_, err = fmt.Fprintf(w, ") ON CONFLICT (%s) DO UPDATE SET %s;", strings.Join(keyCols, ", "), strings.Join(sets, ", "))
+	}
+	return allParams, err
+}
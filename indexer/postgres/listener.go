@@ -19,12 +19,64 @@ func (i *indexerImpl) listener() appdata.Listener {
 			mm := newModuleIndexer(moduleName, modSchema, i.opts)
 			i.modules[moduleName] = mm
 
-			return mm.initializeSchema(i.ctx, i.tx)
+			oldSchema, found, err := loadModuleSchema(i.ctx, i.tx, moduleName)
+			if err != nil {
+				return err
+			}
+
+			if found {
+				err = mm.migrateSchema(i.ctx, i.tx, i.db, oldSchema)
+			} else {
+				err = mm.initializeSchema(i.ctx, i.tx)
+			}
+			if err != nil {
+				return err
+			}
+
+			err = mm.saveModuleSchema(i.ctx, i.tx)
+			if err != nil {
+				return err
+			}
+
+			i.querier.addModule(mm)
+			return nil
 		},
 		StartBlock: func(data appdata.StartBlockData) error {
+			i.blockNum = data.Height
 			_, err := i.tx.Exec("INSERT INTO block (number) VALUES ($1)", data.Height)
 			return err
 		},
+		OnObjectUpdate: func(data appdata.ObjectUpdateData) error {
+			mod, ok := i.modules[data.ModuleName]
+			if !ok {
+				return fmt.Errorf("module %s not initialized", data.ModuleName)
+			}
+
+			for _, update := range data.Updates {
+				tm, ok := mod.tables[update.TypeName]
+				if !ok {
+					return fmt.Errorf("object type %s not found in schema for module %s", update.TypeName, data.ModuleName)
+				}
+
+				var err error
+				if update.Delete {
+					err = tm.delete(i.ctx, i.tx, update.Key)
+				} else {
+					err = tm.insertUpdate(i.ctx, i.tx, update.Key, update.Value)
+				}
+				if err != nil {
+					return err
+				}
+
+				if tm.retainDeletions() {
+					err = tm.recordHistory(i.ctx, i.tx, update.Key, i.blockNum)
+					if err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
 		Commit: func(data appdata.CommitData) (func() error, error) {
 			err := i.tx.Commit()
 			if err != nil {
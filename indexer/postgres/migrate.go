@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/diff"
+)
+
+// loadModuleSchema loads the schema of the module as stored by the last initialization of the module.
+// found is false if the module was never initialized.
+func loadModuleSchema(ctx context.Context, conn dbConn, moduleName string) (modSchema schema.ModuleSchema, found bool, err error) {
+	row := conn.QueryRowContext(ctx, "SELECT schema FROM module_schema WHERE module_name = $1", moduleName)
+	var bz []byte
+	err = row.Scan(&bz)
+	if err == sql.ErrNoRows {
+		return schema.ModuleSchema{}, false, nil
+	} else if err != nil {
+		return schema.ModuleSchema{}, false, fmt.Errorf("failed to load schema of module %s: %v", moduleName, err) //nolint:errorlint // using %v for go 1.12 compat
+	}
+
+	err = json.Unmarshal(bz, &modSchema)
+	if err != nil {
+		return schema.ModuleSchema{}, false, fmt.Errorf("failed to decode schema of module %s: %v", moduleName, err) //nolint:errorlint // using %v for go 1.12 compat
+	}
+	return modSchema, true, nil
+}
+
+// saveModuleSchema stores the schema of the module so that it can be migrated when it changes.
+func (m *moduleIndexer) saveModuleSchema(ctx context.Context, conn dbConn) error {
+	bz, err := json.Marshal(m.schema)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.ExecContext(ctx,
+		"INSERT INTO module_schema (module_name, schema) VALUES ($1, $2) ON CONFLICT (module_name) DO UPDATE SET schema = EXCLUDED.schema",
+		m.moduleName, string(bz))
+	return err
+}
+
+// migrateSchema migrates the tables and enum types of the module from the old schema to the module schema.
+// Only the compatible changes defined by diff.CompareModuleSchemas are supported, which are adding
+// object types, enum types, enum values and nullable value fields. An error describing the incompatible
+// changes is returned otherwise and nothing is migrated.
+//
+// New enum values are added with enumConn rather than conn because postgres does not allow a value added
+// to an enum type to be used before the transaction which added it is committed, so enumConn should not
+// be the transaction in which the module data is written. As they cannot be rolled back with conn, they
+// are added before any other change and with ADD VALUE IF NOT EXISTS: if the migration then fails, the
+// stored schema is unchanged, the added values are unused by it, and retrying the migration is a no-op
+// for them.
+func (m *moduleIndexer) migrateSchema(ctx context.Context, conn, enumConn dbConn, oldSchema schema.ModuleSchema) error {
+	schemaDiff := diff.CompareModuleSchemas(oldSchema, m.schema)
+	if schemaDiff.Empty() {
+		return m.checkRetainDeletions(oldSchema)
+	}
+
+	if changes := incompatibleChanges(schemaDiff); len(changes) != 0 {
+		return fmt.Errorf("cannot migrate schema of module %s, found incompatible changes:\n  - %s",
+			m.moduleName, strings.Join(changes, "\n  - "))
+	}
+
+	err := m.checkRetainDeletions(oldSchema)
+	if err != nil {
+		return err
+	}
+
+	// enum types must be created and extended before the columns which use them, the enum values are
+	// added first as they are not part of the migration transaction
+	for _, enumDiff := range schemaDiff.ChangedEnumTypes {
+		for _, value := range enumDiff.AddedValues {
+			err = m.exec(ctx, enumConn, "Adding enum value", func(w io.Writer) error {
+				return addEnumValueSql(w, m.moduleName, enumDiff.Name, value)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, enumType := range schemaDiff.AddedEnumTypes {
+		err = m.createEnumType(ctx, conn, enumType)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, objectType := range schemaDiff.AddedObjectTypes {
+		err = m.tables[objectType.Name].createTable(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("failed to create table for %s in module %s: %v", objectType.Name, m.moduleName, err) //nolint:errorlint // using %v for go 1.12 compat
+		}
+	}
+
+	for _, objectDiff := range schemaDiff.ChangedObjectTypes {
+		tm := m.tables[objectDiff.Name]
+		tables := []string{tm.tableName()}
+		if tm.retainDeletions() {
+			tables = append(tables, tm.historyTableName())
+		}
+
+		for _, table := range tables {
+			for _, field := range objectDiff.ValueFieldsDiff.Added {
+				err = m.exec(ctx, conn, "Adding column", func(w io.Writer) error {
+					return tm.addColumnSql(w, table, field)
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRetainDeletions returns an error if the retain deletions setting of an object type changed,
+// because the existing rows cannot be migrated.
+func (m *moduleIndexer) checkRetainDeletions(oldSchema schema.ModuleSchema) error {
+	var err error
+	oldSchema.ObjectTypes(func(oldType schema.ObjectType) bool {
+		tm, ok := m.tables[oldType.Name]
+		if !ok {
+			return true
+		}
+		if oldType.RetainDeletions != tm.typ.RetainDeletions && !m.options.disableRetainDeletions {
+			err = fmt.Errorf("cannot migrate schema of module %s, retain deletions changed for object type %s", m.moduleName, oldType.Name)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// exec generates a statement with f, logs it with msg and executes it.
+func (m *moduleIndexer) exec(ctx context.Context, conn dbConn, msg string, f func(w io.Writer) error) error {
+	buf := new(strings.Builder)
+	err := f(buf)
+	if err != nil {
+		return err
+	}
+
+	sqlStr := buf.String()
+	if m.options.logger != nil {
+		m.options.logger.Debug(msg, "module", m.moduleName, "sql", sqlStr)
+	}
+	_, err = conn.ExecContext(ctx, sqlStr)
+	return err
+}
+
+// incompatibleChanges describes the changes of the diff which cannot be migrated.
+func incompatibleChanges(d diff.ModuleSchemaDiff) []string {
+	var changes []string
+	for _, objectType := range d.RemovedObjectTypes {
+		changes = append(changes, fmt.Sprintf("object type %s was removed", objectType.Name))
+	}
+
+	for _, enumType := range d.RemovedEnumTypes {
+		changes = append(changes, fmt.Sprintf("enum type %s was removed", enumType.Name))
+	}
+
+	for _, objectDiff := range d.ChangedObjectTypes {
+		if !objectDiff.KeyFieldsDiff.Empty() {
+			changes = append(changes, fmt.Sprintf("key fields of object type %s were changed", objectDiff.Name))
+		}
+
+		valueDiff := objectDiff.ValueFieldsDiff
+		for _, field := range valueDiff.Added {
+			if !field.Nullable {
+				changes = append(changes, fmt.Sprintf("non-nullable value field %s was added to object type %s", field.Name, objectDiff.Name))
+			}
+		}
+		for _, field := range valueDiff.Changed {
+			changes = append(changes, fmt.Sprintf("value field %s of object type %s was changed", field.Name, objectDiff.Name))
+		}
+		for _, field := range valueDiff.Removed {
+			changes = append(changes, fmt.Sprintf("value field %s was removed from object type %s", field.Name, objectDiff.Name))
+		}
+		if valueDiff.OrderChanged() {
+			changes = append(changes, fmt.Sprintf("value fields of object type %s were reordered", objectDiff.Name))
+		}
+	}
+
+	for _, enumDiff := range d.ChangedEnumTypes {
+		for _, value := range enumDiff.RemovedValues {
+			changes = append(changes, fmt.Sprintf("value %s was removed from enum type %s", value.Name, enumDiff.Name))
+		}
+		for _, value := range enumDiff.ChangedValues {
+			changes = append(changes, fmt.Sprintf("numeric value of %s in enum type %s was changed", value.Name, enumDiff.Name))
+		}
+		if enumDiff.KindChanged() {
+			changes = append(changes, fmt.Sprintf("numeric kind of enum type %s was changed", enumDiff.Name))
+		}
+	}
+
+	return changes
+}
+
+// addEnumValueSql generates an ALTER TYPE statement adding the value to the enum type.
+func addEnumValueSql(w io.Writer, moduleName, enumName string, value schema.EnumValueDefinition) error {
+	_, err := fmt.Fprintf(w, "ALTER TYPE %q ADD VALUE IF NOT EXISTS '%s';", enumTypeName(moduleName, enumName), value.Name)
+	return err
+}
+
+// addColumnSql generates an ALTER TABLE statement adding the columns of the nullable field to the table.
+func (tm *objectIndexer) addColumnSql(w io.Writer, table string, field schema.Field) error {
+	if !field.Nullable {
+		return fmt.Errorf("cannot add non-nullable field %s to table %s", field.Name, table)
+	}
+
+	var err error
+	if field.Kind == schema.TimeKind {
+		// the nanos column must exist before the generated column which reads it
+		nanosColName := fmt.Sprintf("%s_nanos", field.Name)
+		_, err = fmt.Fprintf(w, "ALTER TABLE %q ADD COLUMN IF NOT EXISTS %q BIGINT NULL, ADD COLUMN IF NOT EXISTS %q TIMESTAMPTZ GENERATED ALWAYS AS (nanos_to_timestamptz(%q)) STORED;",
+			table, nanosColName, field.Name, nanosColName)
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "ALTER TABLE %q ADD COLUMN IF NOT EXISTS ", table)
+	if err != nil {
+		return err
+	}
+
+	buf := new(strings.Builder)
+	err = tm.createColumnDefinition(buf, field)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s;", strings.TrimSuffix(buf.String(), ",\n\t"))
+	return err
+}
@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"cosmossdk.io/indexer/postgres/internal/testdata"
+	"cosmossdk.io/schema"
+)
+
+func Example_objectIndexer_addColumnSql() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	for _, field := range []schema.Field{
+		{Name: "weight", Kind: schema.StringKind, Nullable: true},
+		{Name: "voted_at", Kind: schema.TimeKind, Nullable: true},
+		{Name: "option", Kind: schema.EnumKind, ReferencedType: testdata.VoteType.Name, Nullable: true},
+	} {
+		err := tm.addColumnSql(os.Stdout, tm.historyTableName(), field)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println()
+	}
+	// Output:
+	// ALTER TABLE "test_vote_history" ADD COLUMN IF NOT EXISTS "weight" TEXT NULL;
+	// ALTER TABLE "test_vote_history" ADD COLUMN IF NOT EXISTS "voted_at_nanos" BIGINT NULL, ADD COLUMN IF NOT EXISTS "voted_at" TIMESTAMPTZ GENERATED ALWAYS AS (nanos_to_timestamptz("voted_at_nanos")) STORED;
+	// ALTER TABLE "test_vote_history" ADD COLUMN IF NOT EXISTS "option" "test_vote_type" NULL;
+}
+
+func Example_addEnumValueSql() {
+	err := addEnumValueSql(os.Stdout, "test", testdata.VoteType.Name, schema.EnumValueDefinition{Name: "veto", Value: 4})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// ALTER TYPE "test_vote_type" ADD VALUE IF NOT EXISTS 'veto';
+}
+
+func Example_moduleIndexer_migrateSchema_incompatible() {
+	voteType := testdata.VoteType
+	voteType.Values = voteType.Values[:2]
+
+	voteObject := testdata.VoteObject
+	voteObject.ValueFields = []schema.Field{
+		{Name: "vote", Kind: schema.EnumKind, ReferencedType: voteType.Name},
+		{Name: "weight", Kind: schema.StringKind},
+	}
+
+	newSchema, err := schema.CompileModuleSchema(voteObject, voteType)
+	if err != nil {
+		panic(err)
+	}
+
+	m := newModuleIndexer("test", newSchema, exampleObjectIndexer(voteObject, false).options)
+	// the schemas are compared before anything is executed so no connection is needed
+	err = m.migrateSchema(context.Background(), nil, nil, testdata.ExampleSchema)
+	fmt.Println(err)
+	// Output:
+	// cannot migrate schema of module test, found incompatible changes:
+	//   - object type all_kinds was removed
+	//   - object type singleton was removed
+	//   - enum type my_enum was removed
+	//   - non-nullable value field weight was added to object type vote
+	//   - value abstain was removed from enum type vote_type
+}
+
+// execLogConn is a dbConn printing the statements it executes.
+type execLogConn struct {
+	dbConn
+	name string
+}
+
+func (c execLogConn) ExecContext(_ context.Context, query string, _ ...interface{}) (sql.Result, error) {
+	fmt.Printf("%s: %s\n", c.name, query)
+	return nil, nil
+}
+
+func Example_moduleIndexer_migrateSchema_enumValuesFirst() {
+	voteType := testdata.VoteType
+	voteType.Values = append(voteType.Values, schema.EnumValueDefinition{Name: "veto", Value: 4})
+
+	voteObject := testdata.VoteObject
+	voteObject.ValueFields = append(voteObject.ValueFields, schema.Field{Name: "weight", Kind: schema.StringKind, Nullable: true})
+
+	newSchema, err := schema.CompileModuleSchema(testdata.AllKindsObject, testdata.SingletonObject, voteObject, voteType, testdata.MyEnum)
+	if err != nil {
+		panic(err)
+	}
+
+	m := newModuleIndexer("test", newSchema, exampleObjectIndexer(voteObject, false).options)
+	// the enum values are added outside of the migration transaction before any other change
+	err = m.migrateSchema(context.Background(), execLogConn{name: "tx"}, execLogConn{name: "db"}, testdata.ExampleSchema)
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// db: ALTER TYPE "test_vote_type" ADD VALUE IF NOT EXISTS 'veto';
+	// tx: ALTER TABLE "test_vote" ADD COLUMN IF NOT EXISTS "weight" TEXT NULL;
+	// tx: ALTER TABLE "test_vote_history" ADD COLUMN IF NOT EXISTS "weight" TEXT NULL;
+}
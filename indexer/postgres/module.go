@@ -18,10 +18,16 @@ type moduleIndexer struct {
 
 // newModuleIndexer creates a new moduleIndexer for the given module schema.
 func newModuleIndexer(moduleName string, modSchema schema.ModuleSchema, options options) *moduleIndexer {
+	tables := map[string]*objectIndexer{}
+	modSchema.ObjectTypes(func(typ schema.ObjectType) bool {
+		tables[typ.Name] = newObjectIndexer(moduleName, typ, options)
+		return true
+	})
+
 	return &moduleIndexer{
 		moduleName:   moduleName,
 		schema:       modSchema,
-		tables:       map[string]*objectIndexer{},
+		tables:       tables,
 		definedEnums: map[string]schema.EnumType{},
 		options:      options,
 	}
@@ -41,8 +47,7 @@ func (m *moduleIndexer) initializeSchema(ctx context.Context, conn dbConn) error
 
 	// create tables for all object types
 	m.schema.ObjectTypes(func(typ schema.ObjectType) bool {
-		tm := newObjectIndexer(m.moduleName, typ, m.options)
-		m.tables[typ.Name] = tm
+		tm := m.tables[typ.Name]
 		err = tm.createTable(ctx, conn)
 		if err != nil {
 			err = fmt.Errorf("failed to create table for %s in module %s: %v", typ.Name, m.moduleName, err) //nolint:errorlint // using %v for go 1.12 compat
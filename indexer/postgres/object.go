@@ -42,3 +42,43 @@ func newObjectIndexer(moduleName string, typ schema.ObjectType, options options)
 func (tm *objectIndexer) tableName() string {
 	return fmt.Sprintf("%s_%s", tm.moduleName, tm.typ.Name)
 }
+
+// retainDeletions returns true if the object type retains deletions and this is not disabled.
+func (tm *objectIndexer) retainDeletions() bool {
+	return !tm.options.disableRetainDeletions && tm.typ.RetainDeletions
+}
+
+// keyColumnNames returns the names of the primary key columns of the table.
+func (tm *objectIndexer) keyColumnNames() ([]string, error) {
+	if len(tm.typ.KeyFields) == 0 {
+		return []string{"_id"}, nil
+	}
+
+	names := make([]string, 0, len(tm.typ.KeyFields))
+	for _, field := range tm.typ.KeyFields {
+		name, err := tm.updatableColumnName(field)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// columnNames returns the names of the primary key columns followed by the
+// updatable columns of the value fields.
+func (tm *objectIndexer) columnNames() ([]string, error) {
+	names, err := tm.keyColumnNames()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range tm.typ.ValueFields {
+		name, err := tm.updatableColumnName(field)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
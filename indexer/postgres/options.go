@@ -1,6 +1,9 @@
 package postgres
 
-import "cosmossdk.io/schema/logutil"
+import (
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/logutil"
+)
 
 // options are the options for module and object indexers.
 type options struct {
@@ -9,4 +12,7 @@ type options struct {
 
 	// logger is the logger for the indexer to use. It may be nil.
 	logger logutil.Logger
+
+	// addressCodec is the codec used to convert addresses to and from their string representation.
+	addressCodec addressutil.AddressCodec
 }
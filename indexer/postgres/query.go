@@ -0,0 +1,323 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/addressutil"
+)
+
+// DefaultListLimit is the number of objects returned by Querier.List when no limit is specified.
+const DefaultListLimit = 100
+
+// FilterOp is a comparison operator used in a Filter.
+type FilterOp string
+
+const (
+	// OpEq matches fields equal to the filter value. A nil value matches NULL fields.
+	OpEq FilterOp = "="
+	// OpNe matches fields not equal to the filter value. A nil value matches non-NULL fields.
+	OpNe FilterOp = "!="
+	// OpLt matches fields less than the filter value.
+	OpLt FilterOp = "<"
+	// OpLte matches fields less than or equal to the filter value.
+	OpLte FilterOp = "<="
+	// OpGt matches fields greater than the filter value.
+	OpGt FilterOp = ">"
+	// OpGte matches fields greater than or equal to the filter value.
+	OpGte FilterOp = ">="
+)
+
+// Filter restricts the objects listed by Querier.List to those whose field compares to Value with Op.
+// Value must conform to the kind of the field, as in an ObjectUpdate.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value interface{}
+}
+
+// ListOptions are the options of Querier.List.
+type ListOptions struct {
+	// Filters are the filters that listed objects must all match. They can refer to both key and value fields.
+	Filters []Filter
+
+	// Limit is the maximum number of objects returned. DefaultListLimit is used if it is zero.
+	Limit int
+
+	// After is the key of the last object of the previous page. When set, only objects whose
+	// key sorts after it are returned.
+	After interface{}
+
+	// AsOfBlock lists the objects as they were at the end of this block. The latest state is listed
+	// if it is nil. Historical queries are only supported for object types which retain deletions.
+	AsOfBlock *uint64
+
+	// IncludeDeleted includes objects whose deletion was retained, with Delete set to true.
+	IncludeDeleted bool
+}
+
+// ListResult is the result of Querier.List.
+type ListResult struct {
+	// Objects are the listed objects in key order.
+	Objects []schema.ObjectUpdate
+
+	// NextKey is the key to pass as ListOptions.After to get the next page. It is nil on the last page.
+	NextKey interface{}
+}
+
+// Querier provides read access to the module state indexed in a PostgreSQL database.
+// It reuses the same table and column mapping as the indexer. Querier also implements
+// view.AppData and is returned as the indexer's view.
+type Querier struct {
+	ctx     context.Context
+	db      *sql.DB
+	opts    options
+	mu      sync.RWMutex
+	modules map[string]*moduleIndexer
+}
+
+// NewQuerier creates a Querier for the modules with the provided schemas, which must have been
+// indexed in db by an indexer using the provided config and address codec.
+func NewQuerier(db *sql.DB, config Config, addressCodec addressutil.AddressCodec, modules map[string]schema.ModuleSchema) *Querier {
+	if addressCodec == nil {
+		addressCodec = addressutil.HexAddressCodec{}
+	}
+
+	q := newQuerier(context.Background(), db, options{
+		disableRetainDeletions: config.DisableRetainDeletions,
+		addressCodec:           addressCodec,
+	})
+	for name, modSchema := range modules {
+		q.addModule(newModuleIndexer(name, modSchema, q.opts))
+	}
+	return q
+}
+
+func newQuerier(ctx context.Context, db *sql.DB, opts options) *Querier {
+	return &Querier{
+		ctx:     ctx,
+		db:      db,
+		opts:    opts,
+		modules: map[string]*moduleIndexer{},
+	}
+}
+
+func (q *Querier) addModule(m *moduleIndexer) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.modules[m.moduleName] = m
+}
+
+// moduleNames returns the names of the queryable modules in sorted order.
+func (q *Querier) moduleNames() []string {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	names := make([]string, 0, len(q.modules))
+	for name := range q.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (q *Querier) module(moduleName string) (*moduleIndexer, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	m, ok := q.modules[moduleName]
+	return m, ok
+}
+
+func (q *Querier) objectIndexer(moduleName, objectType string) (*objectIndexer, error) {
+	m, ok := q.module(moduleName)
+	if !ok {
+		return nil, fmt.Errorf("unknown module %q", moduleName)
+	}
+	tm, ok := m.tables[objectType]
+	if !ok {
+		return nil, fmt.Errorf("unknown object type %q in module %q", objectType, moduleName)
+	}
+	return tm, nil
+}
+
+// Get returns the object of the provided type with the provided key, either at the latest block or
+// as of asOfBlock if it is not nil. Objects whose deletion was retained are returned with Delete set
+// to true. found is false if the object does not exist.
+func (q *Querier) Get(ctx context.Context, moduleName, objectType string, key interface{}, asOfBlock *uint64) (update schema.ObjectUpdate, found bool, err error) {
+	tm, err := q.objectIndexer(moduleName, objectType)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+	return tm.get(ctx, q.db, key, asOfBlock)
+}
+
+// List lists the objects of the provided type matching the provided options in key order.
+func (q *Querier) List(ctx context.Context, moduleName, objectType string, opts ListOptions) (ListResult, error) {
+	tm, err := q.objectIndexer(moduleName, objectType)
+	if err != nil {
+		return ListResult{}, err
+	}
+	return tm.list(ctx, q.db, opts)
+}
+
+// list lists the objects matching the provided options.
+func (tm *objectIndexer) list(ctx context.Context, conn dbConn, opts ListOptions) (ListResult, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	buf := new(strings.Builder)
+	// we fetch one more object than requested to know whether there is a next page
+	params, err := tm.listSql(buf, opts, limit+1)
+	if err != nil {
+		return ListResult{}, err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Select", "table", tm.tableName(), "sql", sqlStr, "params", params)
+	}
+
+	rows, err := conn.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return ListResult{}, err
+	}
+	defer rows.Close()
+
+	var res ListResult
+	for rows.Next() {
+		update, err := tm.readRow(rows)
+		if err != nil {
+			return ListResult{}, err
+		}
+		if len(res.Objects) == limit {
+			res.NextKey = res.Objects[limit-1].Key
+			break
+		}
+		res.Objects = append(res.Objects, update)
+	}
+
+	if err := rows.Err(); err != nil {
+		return ListResult{}, err
+	}
+	return res, rows.Close()
+}
+
+// listSql generates the SELECT statement listing the objects matching the provided options.
+func (tm *objectIndexer) listSql(w io.Writer, opts ListOptions, limit int) ([]interface{}, error) {
+	cols, err := tm.selectColumns()
+	if err != nil {
+		return nil, err
+	}
+	colsStr := strings.Join(cols, ", ")
+
+	pKeys, err := tm.keyColumnNames()
+	if err != nil {
+		return nil, err
+	}
+	pKeysStr := strings.Join(pKeys, ", ")
+
+	var params []interface{}
+	if opts.AsOfBlock == nil {
+		_, err = fmt.Fprintf(w, "SELECT %s FROM %q", colsStr, tm.tableName())
+	} else {
+		if !tm.retainDeletions() {
+			return nil, fmt.Errorf("historical queries are only supported for object types which retain deletions, %s does not", tm.typ.Name)
+		}
+		// the state as of a block is the latest history entry of each object at or before that block
+		params = append(params, int64(*opts.AsOfBlock))
+		_, err = fmt.Fprintf(w, "SELECT %s FROM (SELECT DISTINCT ON (%s) %s FROM %q WHERE _block_number <= $1 ORDER BY %s, _block_number DESC) AS t",
+			colsStr, pKeysStr, colsStr, tm.historyTableName(), pKeysStr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var conds []string
+	if tm.retainDeletions() && !opts.IncludeDeleted {
+		conds = append(conds, "NOT _deleted")
+	}
+
+	for _, filter := range opts.Filters {
+		cond, param, err := tm.filterSql(filter, len(params)+1)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+		if param != nil {
+			params = append(params, param)
+		}
+	}
+
+	if opts.After != nil {
+		if len(tm.typ.KeyFields) == 0 {
+			return nil, fmt.Errorf("cannot paginate singleton object type %s", tm.typ.Name)
+		}
+		keyParams, keyCols, err := tm.bindKeyParams(opts.After)
+		if err != nil {
+			return nil, err
+		}
+		placeholders := make([]string, len(keyParams))
+		for i := range keyParams {
+			placeholders[i] = fmt.Sprintf("$%d", len(params)+i+1)
+		}
+		conds = append(conds, fmt.Sprintf("(%s) > (%s)", strings.Join(keyCols, ", "), strings.Join(placeholders, ", ")))
+		params = append(params, keyParams...)
+	}
+
+	if len(conds) > 0 {
+		_, err = fmt.Fprintf(w, " WHERE %s", strings.Join(conds, " AND "))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	params = append(params, limit)
+	_, err = fmt.Fprintf(w, " ORDER BY %s LIMIT $%d;", pKeysStr, len(params))
+	return params, err
+}
+
+// filterSql generates the condition for the filter. param is nil if the condition has no parameter.
+func (tm *objectIndexer) filterSql(filter Filter, paramIdx int) (cond string, param interface{}, err error) {
+	field, ok := tm.allFields[filter.Field]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown field %q in object type %s", filter.Field, tm.typ.Name)
+	}
+
+	col, err := tm.updatableColumnName(field)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch filter.Op {
+	case OpEq, OpNe, OpLt, OpLte, OpGt, OpGte:
+	default:
+		return "", nil, fmt.Errorf("unknown filter operator %q", filter.Op)
+	}
+
+	if filter.Value == nil {
+		switch filter.Op {
+		case OpEq:
+			return fmt.Sprintf("%s IS NULL", col), nil, nil
+		case OpNe:
+			return fmt.Sprintf("%s IS NOT NULL", col), nil, nil
+		default:
+			return "", nil, fmt.Errorf("operator %q cannot be used with a nil value", filter.Op)
+		}
+	}
+
+	// filters may be applied on non-nullable fields with any value
+	field.Nullable = false
+	param, err = tm.bindParam(field, filter.Value)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("%s %s $%d", col, filter.Op, paramIdx), param, nil
+}
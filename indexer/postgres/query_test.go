@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"cosmossdk.io/indexer/postgres/internal/testdata"
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/logutil"
+)
+
+func Example_objectIndexer_insertUpdateSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.insertUpdateSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}, "yes"))
+	// Output:
+	// INSERT INTO "test_vote" ("proposal", "address", "vote") VALUES ($1, $2, $3) ON CONFLICT ("proposal", "address") DO UPDATE SET "vote" = EXCLUDED."vote", _deleted = FALSE;
+	// [1 0xab yes]
+}
+
+func Example_objectIndexer_updateSql_singleton() {
+	tm := exampleObjectIndexer(testdata.SingletonObject, false)
+	exampleSql(tm.updateSql(os.Stdout, nil, schema.MapValueUpdates{"bar": nil}))
+	// Output:
+	// UPDATE "test_singleton" SET "bar" = $1 WHERE _id = $2;
+	// [<nil> 1]
+}
+
+func Example_objectIndexer_deleteSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.deleteSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}))
+	// Output:
+	// UPDATE "test_vote" SET _deleted = TRUE WHERE "proposal" = $1 AND "address" = $2;
+	// [1 0xab]
+}
+
+func Example_objectIndexer_deleteSql_vote_no_retain_delete() {
+	tm := exampleObjectIndexer(testdata.VoteObject, true)
+	exampleSql(tm.deleteSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}))
+	// Output:
+	// DELETE FROM "test_vote" WHERE "proposal" = $1 AND "address" = $2;
+	// [1 0xab]
+}
+
+func Example_objectIndexer_createHistoryTableSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	err := tm.createHistoryTableSql(os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// CREATE TABLE IF NOT EXISTS "test_vote_history" (
+	// 	"proposal" BIGINT NOT NULL,
+	// 	"address" TEXT NOT NULL,
+	// 	"vote" "test_vote_type" NOT NULL,
+	// 	_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	// 	_block_number BIGINT NOT NULL,
+	// 	PRIMARY KEY ("proposal", "address", _block_number)
+	// );
+	// GRANT SELECT ON TABLE "test_vote_history" TO PUBLIC;
+}
+
+func Example_objectIndexer_recordHistorySql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.recordHistorySql(os.Stdout, []interface{}{int64(1), []byte{0xab}}, 10))
+	// Output:
+	// INSERT INTO "test_vote_history" ("proposal", "address", "vote", _deleted, _block_number) SELECT "proposal", "address", "vote", _deleted, $1 FROM "test_vote" WHERE "proposal" = $2 AND "address" = $3 ON CONFLICT ("proposal", "address", _block_number) DO UPDATE SET _deleted = EXCLUDED._deleted, "vote" = EXCLUDED."vote";
+	// [10 1 0xab]
+}
+
+func Example_objectIndexer_getSql_allKinds() {
+	tm := exampleObjectIndexer(testdata.AllKindsObject, false)
+	exampleSql(tm.getSql(os.Stdout, []interface{}{int64(1), time.Unix(0, 2)}, nil))
+	// Output:
+	// SELECT "id", "ts_nanos", "string", "bytes", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64", "integer", "decimal", "bool", "time_nanos", "duration", "float32", "float64", "address", "enum", "json" FROM "test_all_kinds" WHERE "id" = $1 AND "ts_nanos" = $2;
+	// [1 2]
+}
+
+func Example_objectIndexer_getSql_vote_asOf() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	// the genesis state is queried as of block 0
+	var genesis uint64
+	exampleSql(tm.getSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}, &genesis))
+	// Output:
+	// SELECT "proposal", "address", "vote", _deleted FROM "test_vote_history" WHERE "proposal" = $1 AND "address" = $2 AND _block_number <= $3 ORDER BY _block_number DESC LIMIT 1;
+	// [1 0xab 0]
+}
+
+func Example_objectIndexer_listSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.listSql(os.Stdout, ListOptions{
+		Filters: []Filter{{Field: "vote", Op: OpEq, Value: "no"}},
+		After:   []interface{}{int64(1), []byte{0xab}},
+	}, 11))
+	// Output:
+	// SELECT "proposal", "address", "vote", _deleted FROM "test_vote" WHERE NOT _deleted AND "vote" = $1 AND ("proposal", "address") > ($2, $3) ORDER BY "proposal", "address" LIMIT $4;
+	// [no 1 0xab 11]
+}
+
+func Example_objectIndexer_listSql_vote_asOf() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	asOf := uint64(10)
+	exampleSql(tm.listSql(os.Stdout, ListOptions{
+		Filters:        []Filter{{Field: "proposal", Op: OpGte, Value: int64(2)}},
+		AsOfBlock:      &asOf,
+		IncludeDeleted: true,
+	}, 101))
+	// Output:
+	// SELECT "proposal", "address", "vote", _deleted FROM (SELECT DISTINCT ON ("proposal", "address") "proposal", "address", "vote", _deleted FROM "test_vote_history" WHERE _block_number <= $1 ORDER BY "proposal", "address", _block_number DESC) AS t WHERE "proposal" >= $2 ORDER BY "proposal", "address" LIMIT $3;
+	// [10 2 101]
+}
+
+func Example_objectIndexer_listSql_singleton_nullFilter() {
+	tm := exampleObjectIndexer(testdata.SingletonObject, false)
+	exampleSql(tm.listSql(os.Stdout, ListOptions{
+		Filters: []Filter{{Field: "bar", Op: OpNe}},
+	}, 101))
+	// Output:
+	// SELECT "foo", "bar", "an_enum" FROM "test_singleton" WHERE "bar" IS NOT NULL ORDER BY _id LIMIT $1;
+	// [101]
+}
+
+func exampleObjectIndexer(objectType schema.ObjectType, noRetainDelete bool) *objectIndexer {
+	return newObjectIndexer("test", objectType, options{
+		logger:                 logutil.NoopLogger{},
+		disableRetainDeletions: noRetainDelete,
+		addressCodec:           addressutil.HexAddressCodec{},
+	})
+}
+
+func exampleSql(params []interface{}, err error) {
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\n%v\n", params)
+}
@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/schema"
+)
+
+// selectColumns returns the columns that are read for the object type. These are the
+// columns of the key and value fields, followed by _deleted if deletions are retained.
+func (tm *objectIndexer) selectColumns() ([]string, error) {
+	var cols []string
+	if len(tm.typ.KeyFields) == 0 {
+		// skip the _id column of singletons
+		for _, field := range tm.typ.ValueFields {
+			name, err := tm.updatableColumnName(field)
+			if err != nil {
+				return nil, err
+			}
+			cols = append(cols, name)
+		}
+	} else {
+		var err error
+		cols, err = tm.columnNames()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tm.retainDeletions() {
+		cols = append(cols, "_deleted")
+	}
+	return cols, nil
+}
+
+// get reads the object with the provided key, either at the latest block or as of asOfBlock if
+// it is not nil.
+func (tm *objectIndexer) get(ctx context.Context, conn dbConn, key interface{}, asOfBlock *uint64) (schema.ObjectUpdate, bool, error) {
+	buf := new(strings.Builder)
+	params, err := tm.getSql(buf, key, asOfBlock)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+
+	sqlStr := buf.String()
+	if tm.options.logger != nil {
+		tm.options.logger.Debug("Select", "table", tm.tableName(), "sql", sqlStr, "params", params)
+	}
+
+	rows, err := conn.QueryContext(ctx, sqlStr, params...)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return schema.ObjectUpdate{}, false, rows.Err()
+	}
+
+	update, err := tm.readRow(rows)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+	return update, true, rows.Close()
+}
+
+// getSql generates the SELECT statement reading the object with the provided key.
+func (tm *objectIndexer) getSql(w io.Writer, key interface{}, asOfBlock *uint64) ([]interface{}, error) {
+	cols, err := tm.selectColumns()
+	if err != nil {
+		return nil, err
+	}
+
+	if asOfBlock == nil {
+		_, err = fmt.Fprintf(w, "SELECT %s FROM %q", strings.Join(cols, ", "), tm.tableName())
+		if err != nil {
+			return nil, err
+		}
+
+		_, params, err := tm.whereSqlAndParams(w, key, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = fmt.Fprintf(w, ";")
+		return params, err
+	}
+
+	if !tm.retainDeletions() {
+		return nil, fmt.Errorf("historical queries are only supported for object types which retain deletions, %s does not", tm.typ.Name)
+	}
+
+	_, err = fmt.Fprintf(w, "SELECT %s FROM %q", strings.Join(cols, ", "), tm.historyTableName())
+	if err != nil {
+		return nil, err
+	}
+
+	paramIdx, params, err := tm.whereSqlAndParams(w, key, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(w, " AND _block_number <= $%d ORDER BY _block_number DESC LIMIT 1;", paramIdx)
+	return append(params, int64(*asOfBlock)), err
+}
+
+// count returns the number of rows in the table, including the rows whose deletion is retained.
+func (tm *objectIndexer) count(ctx context.Context, conn dbConn) (int, error) {
+	row := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q;", tm.tableName()))
+	var n int64
+	err := row.Scan(&n)
+	return int(n), err
+}
+
+// rowScanner is implemented by *sql.Rows and *sql.Row.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// readRow reads a row selected with the columns returned by selectColumns into an object update.
+func (tm *objectIndexer) readRow(row rowScanner) (schema.ObjectUpdate, error) {
+	var dests []interface{}
+	for _, field := range tm.typ.KeyFields {
+		dests = append(dests, scanDest(field))
+	}
+	for _, field := range tm.typ.ValueFields {
+		dests = append(dests, scanDest(field))
+	}
+	var deleted bool
+	if tm.retainDeletions() {
+		dests = append(dests, &deleted)
+	}
+
+	if err := row.Scan(dests...); err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+
+	keys, err := tm.readValues(tm.typ.KeyFields, dests[:len(tm.typ.KeyFields)])
+	if err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+	values, err := tm.readValues(tm.typ.ValueFields, dests[len(tm.typ.KeyFields):len(tm.typ.KeyFields)+len(tm.typ.ValueFields)])
+	if err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+
+	return schema.ObjectUpdate{
+		TypeName: tm.typ.Name,
+		Key:      fieldsValue(keys),
+		Value:    fieldsValue(values),
+		Delete:   deleted,
+	}, nil
+}
+
+// fieldsValue converts a list of field values to the object key or value format,
+// which is a single value when there is only one field.
+func fieldsValue(values []interface{}) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		return values
+	}
+}
+
+// scanDest returns a destination for scanning the column of the field.
+func scanDest(field schema.Field) interface{} {
+	switch field.Kind {
+	case schema.BoolKind:
+		return new(sql.NullBool)
+	case schema.Float32Kind, schema.Float64Kind:
+		return new(sql.NullFloat64)
+	case schema.BytesKind, schema.JSONKind:
+		return new([]byte)
+	case schema.Int8Kind, schema.Int16Kind, schema.Int32Kind, schema.Int64Kind,
+		schema.Uint8Kind, schema.Uint16Kind, schema.Uint32Kind,
+		schema.TimeKind, schema.DurationKind:
+		return new(sql.NullInt64)
+	default:
+		return new(sql.NullString)
+	}
+}
+
+// readValues converts the scanned destinations into schema values.
+func (tm *objectIndexer) readValues(fields []schema.Field, dests []interface{}) ([]interface{}, error) {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value, err := tm.readValue(field, dests[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read field %q: %v", field.Name, err) //nolint:errorlint // using %v for go 1.12 compat
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// readValue converts a scanned destination into a schema value of the field kind.
+func (tm *objectIndexer) readValue(field schema.Field, dest interface{}) (interface{}, error) {
+	switch d := dest.(type) {
+	case *sql.NullBool:
+		if !d.Valid {
+			return nil, nil
+		}
+		return d.Bool, nil
+	case *sql.NullFloat64:
+		if !d.Valid {
+			return nil, nil
+		}
+		if field.Kind == schema.Float32Kind {
+			return float32(d.Float64), nil
+		}
+		return d.Float64, nil
+	case *[]byte:
+		if *d == nil {
+			return nil, nil
+		}
+		if field.Kind == schema.JSONKind {
+			return json.RawMessage(*d), nil
+		}
+		return *d, nil
+	case *sql.NullInt64:
+		if !d.Valid {
+			return nil, nil
+		}
+		return intValue(field.Kind, d.Int64), nil
+	case *sql.NullString:
+		if !d.Valid {
+			return nil, nil
+		}
+		switch field.Kind {
+		case schema.Uint64Kind:
+			return strconv.ParseUint(d.String, 10, 64)
+		case schema.AddressKind:
+			return tm.options.addressCodec.StringToBytes(d.String)
+		default:
+			return d.String, nil
+		}
+	default:
+		return nil, fmt.Errorf("unexpected scan destination %T", dest)
+	}
+}
+
+// intValue converts an integer column value to the go type of the kind.
+func intValue(kind schema.Kind, x int64) interface{} {
+	switch kind {
+	case schema.Int8Kind:
+		return int8(x)
+	case schema.Int16Kind:
+		return int16(x)
+	case schema.Int32Kind:
+		return int32(x)
+	case schema.Uint8Kind:
+		return uint8(x)
+	case schema.Uint16Kind:
+		return uint16(x)
+	case schema.Uint32Kind:
+		return uint32(x)
+	case schema.TimeKind:
+		return time.Unix(0, x)
+	case schema.DurationKind:
+		return time.Duration(x)
+	default:
+		return x
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/indexer/postgres"
+	"cosmossdk.io/indexer/postgres/internal/testdata"
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/appdata"
+	"cosmossdk.io/schema/indexer"
+)
+
+func TestMigrateSchema(t *testing.T) {
+	connectionUrl := createTestDB(t)
+
+	voteType := testdata.VoteType
+	voteType.Values = append(voteType.Values, schema.EnumValueDefinition{Name: "veto", Value: 4})
+
+	voteObject := testdata.VoteObject
+	voteObject.ValueFields = append(voteObject.ValueFields,
+		schema.Field{Name: "weight", Kind: schema.StringKind, Nullable: true},
+		schema.Field{Name: "voted_at", Kind: schema.TimeKind, Nullable: true},
+	)
+
+	newObject := schema.ObjectType{
+		Name:        "new_object",
+		KeyFields:   []schema.Field{{Name: "id", Kind: schema.Uint32Kind}},
+		ValueFields: []schema.Field{{Name: "value", Kind: schema.StringKind}},
+	}
+
+	compatibleSchema := schema.MustCompileModuleSchema(
+		testdata.AllKindsObject,
+		testdata.SingletonObject,
+		voteObject,
+		newObject,
+		testdata.MyEnum,
+		voteType,
+	)
+
+	incompatibleSchema := schema.MustCompileModuleSchema(
+		testdata.AllKindsObject,
+		voteObject,
+		newObject,
+		testdata.MyEnum,
+		voteType,
+	)
+
+	start := func(modSchema schema.ModuleSchema) error {
+		cfg, err := postgresConfigToIndexerConfig(postgres.Config{
+			DatabaseURL: connectionUrl,
+		})
+		require.NoError(t, err)
+
+		res, err := postgres.StartIndexer(indexer.InitParams{
+			Config:  cfg,
+			Context: context.Background(),
+		})
+		require.NoError(t, err)
+
+		err = res.Listener.InitializeModuleData(appdata.ModuleInitializationData{
+			ModuleName: "test",
+			Schema:     modSchema,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = res.Listener.Commit(appdata.CommitData{})
+		return err
+	}
+
+	require.NoError(t, start(testdata.ExampleSchema))
+	require.NoError(t, start(compatibleSchema))
+	// starting again with the same schema is a no-op
+	require.NoError(t, start(compatibleSchema))
+	require.ErrorContains(t, start(incompatibleSchema), "object type singleton was removed")
+}
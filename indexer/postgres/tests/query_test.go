@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/indexer/postgres"
+	"cosmossdk.io/indexer/postgres/internal/testdata"
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/appdata"
+	"cosmossdk.io/schema/indexer"
+	"cosmossdk.io/schema/logutil"
+)
+
+func TestQuerier(t *testing.T) {
+	connectionUrl := createTestDB(t)
+
+	cfg, err := postgresConfigToIndexerConfig(postgres.Config{
+		DatabaseURL: connectionUrl,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	res, err := postgres.StartIndexer(indexer.InitParams{
+		Config:  cfg,
+		Context: ctx,
+		Logger:  logutil.NoopLogger{},
+	})
+	require.NoError(t, err)
+	listener := res.Listener
+
+	require.NoError(t, listener.InitializeModuleData(appdata.ModuleInitializationData{
+		ModuleName: "test",
+		Schema:     testdata.ExampleSchema,
+	}))
+
+	block := func(height uint64, updates ...schema.ObjectUpdate) {
+		require.NoError(t, listener.StartBlock(appdata.StartBlockData{Height: height}))
+		require.NoError(t, listener.OnObjectUpdate(appdata.ObjectUpdateData{
+			ModuleName: "test",
+			Updates:    updates,
+		}))
+		_, err := listener.Commit(appdata.CommitData{})
+		require.NoError(t, err)
+	}
+
+	asOf := func(block uint64) *uint64 { return &block }
+
+	vote := func(proposal int64, addr byte, v string) schema.ObjectUpdate {
+		return schema.ObjectUpdate{TypeName: "vote", Key: []interface{}{proposal, []byte{addr}}, Value: v}
+	}
+
+	// the genesis state is indexed at block 0
+	block(0, vote(3, 0xc, "yes"))
+	block(1, vote(1, 0xa, "yes"), vote(1, 0xb, "no"), vote(2, 0xa, "abstain"))
+	block(2, vote(1, 0xa, "no"), schema.ObjectUpdate{TypeName: "vote", Key: []interface{}{int64(2), []byte{0xa}}, Delete: true})
+
+	q, ok := res.View.(*postgres.Querier)
+	require.True(t, ok)
+
+	blockNum, err := q.BlockNum()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), blockNum)
+
+	// latest state
+	update, found, err := q.Get(ctx, "test", "vote", []interface{}{int64(1), []byte{0xa}}, nil)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "no", update.Value)
+
+	// historical state
+	update, found, err = q.Get(ctx, "test", "vote", []interface{}{int64(1), []byte{0xa}}, asOf(1))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "yes", update.Value)
+
+	// deletions are retained
+	update, found, err = q.Get(ctx, "test", "vote", []interface{}{int64(2), []byte{0xa}}, nil)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.True(t, update.Delete)
+
+	// filtering and pagination
+	list, err := q.List(ctx, "test", "vote", postgres.ListOptions{
+		Filters: []postgres.Filter{{Field: "vote", Op: postgres.OpEq, Value: "no"}},
+		Limit:   1,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Objects, 1)
+	require.Equal(t, []interface{}{int64(1), []byte{0xa}}, list.Objects[0].Key)
+	require.NotNil(t, list.NextKey)
+
+	list, err = q.List(ctx, "test", "vote", postgres.ListOptions{
+		Filters: []postgres.Filter{{Field: "vote", Op: postgres.OpEq, Value: "no"}},
+		After:   list.NextKey,
+	})
+	require.NoError(t, err)
+	require.Len(t, list.Objects, 1)
+	require.Equal(t, []interface{}{int64(1), []byte{0xb}}, list.Objects[0].Key)
+	require.Nil(t, list.NextKey)
+
+	// listing as of a block
+	list, err = q.List(ctx, "test", "vote", postgres.ListOptions{AsOfBlock: asOf(1)})
+	require.NoError(t, err)
+	require.Len(t, list.Objects, 4)
+
+	// the genesis state can be queried
+	list, err = q.List(ctx, "test", "vote", postgres.ListOptions{AsOfBlock: asOf(0)})
+	require.NoError(t, err)
+	require.Len(t, list.Objects, 1)
+	_, found, err = q.Get(ctx, "test", "vote", []interface{}{int64(1), []byte{0xa}}, asOf(0))
+	require.NoError(t, err)
+	require.False(t, found)
+}
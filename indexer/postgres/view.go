@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/view"
+)
+
+var _ view.AppData = (*Querier)(nil)
+
+// BlockNum implements the view.AppData interface. It returns the last indexed block.
+func (q *Querier) BlockNum() (uint64, error) {
+	row := q.db.QueryRowContext(q.ctx, "SELECT COALESCE(MAX(number), 0) FROM block;")
+	var num int64
+	err := row.Scan(&num)
+	return uint64(num), err
+}
+
+// AppState implements the view.AppData interface.
+func (q *Querier) AppState() view.AppState {
+	return appStateView{q}
+}
+
+type appStateView struct {
+	q *Querier
+}
+
+func (a appStateView) GetModule(moduleName string) (view.ModuleState, error) {
+	m, ok := a.q.module(moduleName)
+	if !ok {
+		return nil, nil
+	}
+	return moduleView{a.q, m}, nil
+}
+
+func (a appStateView) Modules(f func(modState view.ModuleState, err error) bool) {
+	for _, name := range a.q.moduleNames() {
+		m, _ := a.q.module(name)
+		if !f(moduleView{a.q, m}, nil) {
+			return
+		}
+	}
+}
+
+func (a appStateView) NumModules() (int, error) {
+	return len(a.q.moduleNames()), nil
+}
+
+type moduleView struct {
+	q *Querier
+	m *moduleIndexer
+}
+
+func (m moduleView) ModuleName() string {
+	return m.m.moduleName
+}
+
+func (m moduleView) ModuleSchema() schema.ModuleSchema {
+	return m.m.schema
+}
+
+func (m moduleView) GetObjectCollection(objectType string) (view.ObjectCollection, error) {
+	tm, ok := m.m.tables[objectType]
+	if !ok {
+		return nil, nil
+	}
+	return objectView{m.q, tm}, nil
+}
+
+func (m moduleView) ObjectCollections(f func(value view.ObjectCollection, err error) bool) {
+	m.m.schema.ObjectTypes(func(typ schema.ObjectType) bool {
+		return f(objectView{m.q, m.m.tables[typ.Name]}, nil)
+	})
+}
+
+func (m moduleView) NumObjectCollections() (int, error) {
+	return len(m.m.tables), nil
+}
+
+type objectView struct {
+	q  *Querier
+	tm *objectIndexer
+}
+
+func (o objectView) ObjectType() schema.ObjectType {
+	return o.tm.typ
+}
+
+func (o objectView) GetObject(key interface{}) (update schema.ObjectUpdate, found bool, err error) {
+	return o.tm.get(o.q.ctx, o.q.db, key, nil)
+}
+
+func (o objectView) AllState(f func(schema.ObjectUpdate, error) bool) {
+	var after interface{}
+	for {
+		res, err := o.tm.list(o.q.ctx, o.q.db, ListOptions{After: after, IncludeDeleted: true})
+		if err != nil {
+			f(schema.ObjectUpdate{}, err)
+			return
+		}
+
+		for _, update := range res.Objects {
+			if !f(update, nil) {
+				return
+			}
+		}
+
+		if res.NextKey == nil {
+			return
+		}
+		after = res.NextKey
+	}
+}
+
+func (o objectView) Len() (int, error) {
+	return o.tm.count(o.q.ctx, o.q.db)
+}
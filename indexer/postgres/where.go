@@ -0,0 +1,45 @@
+package postgres
+
+import (
+	"fmt"
+	"io"
+)
+
+// whereSqlAndParams generates a WHERE clause for the provided key and returns the parameters.
+func (tm *objectIndexer) whereSqlAndParams(w io.Writer, key interface{}, startParamIdx int) (endParamIdx int, keyParams []interface{}, err error) {
+	var keyCols []string
+	keyParams, keyCols, err = tm.bindKeyParams(key)
+	if err != nil {
+		return
+	}
+
+	endParamIdx, err = tm.whereSql(w, keyCols, startParamIdx)
+	return
+}
+
+// whereSql generates a WHERE clause matching the provided columns with parameters starting at startParamIdx.
+func (tm *objectIndexer) whereSql(w io.Writer, cols []string, startParamIdx int) (endParamIdx int, err error) {
+	_, err = fmt.Fprintf(w, " WHERE ")
+	if err != nil {
+		return
+	}
+
+	endParamIdx = startParamIdx
+	for i, col := range cols {
+		if i > 0 {
+			_, err = fmt.Fprintf(w, " AND ")
+			if err != nil {
+				return
+			}
+		}
+
+		_, err = fmt.Fprintf(w, "%s = $%d", col, endParamIdx)
+		if err != nil {
+			return
+		}
+
+		endParamIdx++
+	}
+
+	return
+}
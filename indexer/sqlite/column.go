@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"cosmossdk.io/schema"
+)
+
+// createColumnDefinition writes a column definition within a CREATE TABLE statement for the field.
+func (tm *objectIndexer) createColumnDefinition(writer io.Writer, field schema.Field) error {
+	_, err := fmt.Fprintf(writer, "%s %s", columnName(field), columnType(field.Kind))
+	if err != nil {
+		return err
+	}
+
+	if field.Nullable {
+		_, err = fmt.Fprintf(writer, " NULL")
+	} else {
+		_, err = fmt.Fprintf(writer, " NOT NULL")
+	}
+	if err != nil {
+		return err
+	}
+
+	if field.Kind == schema.EnumKind {
+		// SQLite has no enum types so we restrict the values with a CHECK constraint instead
+		typ, ok := tm.typeSet.LookupType(field.ReferencedType)
+		enumType, isEnum := typ.(schema.EnumType)
+		if !ok || !isEnum {
+			return fmt.Errorf("enum type %q not found", field.ReferencedType)
+		}
+
+		names := make([]string, len(enumType.Values))
+		for i, value := range enumType.Values {
+			names[i] = fmt.Sprintf("'%s'", value.Name)
+		}
+		_, err = fmt.Fprintf(writer, " CHECK (%s IN (%s))", columnName(field), strings.Join(names, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(writer, ",\n\t")
+	return err
+}
+
+// columnType returns the SQLite column type for the kind.
+func columnType(kind schema.Kind) string {
+	//nolint:goconst // adding constants for these sqlite type names would impede readability
+	switch kind {
+	case schema.BoolKind:
+		return "BOOLEAN"
+	case schema.BytesKind:
+		return "BLOB"
+	case schema.Int8Kind, schema.Int16Kind, schema.Int32Kind, schema.Int64Kind,
+		schema.Uint8Kind, schema.Uint16Kind, schema.Uint32Kind,
+		schema.TimeKind, schema.DurationKind:
+		return "INTEGER"
+	case schema.Float32Kind, schema.Float64Kind:
+		return "REAL"
+	default:
+		// uint64, integer and decimal strings are stored as TEXT because SQLite
+		// integers are limited to 64-bit signed values and its numeric type is lossy
+		return "TEXT"
+	}
+}
+
+// columnName returns the quoted name of the column of the field.
+func columnName(field schema.Field) string {
+	return fmt.Sprintf("%q", field.Name)
+}
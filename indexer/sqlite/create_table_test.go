@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"fmt"
+	"os"
+
+	"cosmossdk.io/indexer/sqlite/internal/testdata"
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/logutil"
+)
+
+func Example_objectIndexer_createTableSql_singleton() {
+	exampleCreateTable(testdata.SingletonObject, false)
+	// Output:
+	// CREATE TABLE IF NOT EXISTS "test_singleton" (
+	// 	_id INTEGER NOT NULL CHECK (_id = 1),
+	// 	"foo" TEXT NOT NULL,
+	// 	"bar" INTEGER NULL,
+	// 	PRIMARY KEY (_id)
+	// );
+}
+
+func Example_objectIndexer_createTableSql_vote() {
+	exampleCreateTable(testdata.VoteObject, false)
+	// Output:
+	// CREATE TABLE IF NOT EXISTS "test_vote" (
+	// 	"proposal" INTEGER NOT NULL,
+	// 	"address" TEXT NOT NULL,
+	// 	"vote" TEXT NOT NULL CHECK ("vote" IN ('yes', 'no', 'abstain')),
+	// 	"weight" TEXT NOT NULL,
+	// 	_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+	// 	PRIMARY KEY ("proposal", "address")
+	// );
+}
+
+func Example_objectIndexer_createTableSql_vote_no_retain_delete() {
+	exampleCreateTable(testdata.VoteObject, true)
+	// Output:
+	// CREATE TABLE IF NOT EXISTS "test_vote" (
+	// 	"proposal" INTEGER NOT NULL,
+	// 	"address" TEXT NOT NULL,
+	// 	"vote" TEXT NOT NULL CHECK ("vote" IN ('yes', 'no', 'abstain')),
+	// 	"weight" TEXT NOT NULL,
+	// 	PRIMARY KEY ("proposal", "address")
+	// );
+}
+
+func Example_objectIndexer_insertUpdateSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.insertUpdateSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}, []interface{}{"yes", uint64(10)}))
+	// Output:
+	// INSERT INTO "test_vote" ("proposal", "address", "vote", "weight") VALUES (?, ?, ?, ?) ON CONFLICT ("proposal", "address") DO UPDATE SET "vote" = excluded."vote", "weight" = excluded."weight", _deleted = FALSE;
+	// [1 0xab yes 10]
+}
+
+func Example_objectIndexer_updateSql_singleton() {
+	tm := exampleObjectIndexer(testdata.SingletonObject, false)
+	exampleSql(tm.updateSql(os.Stdout, nil, schema.MapValueUpdates{"bar": nil}))
+	// Output:
+	// UPDATE "test_singleton" SET "bar" = ? WHERE _id = ?;
+	// [<nil> 1]
+}
+
+func Example_objectIndexer_updateSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.updateSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}, schema.MapValueUpdates{"weight": uint64(5)}))
+	// Output:
+	// UPDATE "test_vote" SET "weight" = ?, _deleted = FALSE WHERE "proposal" = ? AND "address" = ?;
+	// [5 1 0xab]
+}
+
+func Example_objectIndexer_deleteSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.deleteSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}))
+	// Output:
+	// UPDATE "test_vote" SET _deleted = TRUE WHERE "proposal" = ? AND "address" = ?;
+	// [1 0xab]
+}
+
+func Example_objectIndexer_deleteSql_vote_no_retain_delete() {
+	tm := exampleObjectIndexer(testdata.VoteObject, true)
+	exampleSql(tm.deleteSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}))
+	// Output:
+	// DELETE FROM "test_vote" WHERE "proposal" = ? AND "address" = ?;
+	// [1 0xab]
+}
+
+func Example_objectIndexer_getSql_vote() {
+	tm := exampleObjectIndexer(testdata.VoteObject, false)
+	exampleSql(tm.getSql(os.Stdout, []interface{}{int64(1), []byte{0xab}}))
+	// Output:
+	// SELECT "proposal", "address", "vote", "weight", _deleted FROM "test_vote" WHERE "proposal" = ? AND "address" = ?;
+	// [1 0xab]
+}
+
+func exampleCreateTable(objectType schema.ObjectType, noRetainDelete bool) {
+	tm := exampleObjectIndexer(objectType, noRetainDelete)
+	err := tm.createTableSql(os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func exampleObjectIndexer(objectType schema.ObjectType, noRetainDelete bool) *objectIndexer {
+	return newObjectIndexer("test", objectType, testdata.ExampleSchema, options{
+		logger:                 logutil.NoopLogger{},
+		disableRetainDeletions: noRetainDelete,
+		addressCodec:           addressutil.HexAddressCodec{},
+	})
+}
+
+func exampleSql(params []interface{}, err error) {
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\n%v\n", params)
+}
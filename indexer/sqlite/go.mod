@@ -0,0 +1,12 @@
+module cosmossdk.io/indexer/sqlite
+
+go 1.23
+
+// NOTE: cosmossdk.io/schema should be the only dependency here
+// so there are no problems building this with any version of the SDK.
+// This module should only use the golang standard library (database/sql)
+// and cosmossdk.io/schema. The SQLite database/sql driver is registered
+// by the application.
+require cosmossdk.io/schema v0.1.1
+
+replace cosmossdk.io/schema => ../../schema
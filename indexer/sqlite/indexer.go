@@ -0,0 +1,119 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"cosmossdk.io/schema/addressutil"
+	"cosmossdk.io/schema/indexer"
+	"cosmossdk.io/schema/logutil"
+)
+
+// IndexerType is the type under which the SQLite indexer is registered in the
+// cosmossdk.io/schema/indexer registry.
+const IndexerType = "sqlite"
+
+func init() {
+	indexer.Register(IndexerType, StartIndexer)
+}
+
+type Config struct {
+	// DatabaseURL is the SQLite data source name to use to open the database, ex. "file:indexer.db".
+	DatabaseURL string `json:"database_url"`
+
+	// DatabaseDriver is the SQLite database/sql driver to use. This defaults to "sqlite3".
+	DatabaseDriver string `json:"database_driver"`
+
+	// DisableRetainDeletions disables the retain deletions functionality even if it is set in an object type schema.
+	DisableRetainDeletions bool `json:"disable_retain_deletions"`
+}
+
+type indexerImpl struct {
+	ctx     context.Context
+	db      *sql.DB
+	tx      *sql.Tx
+	opts    options
+	modules map[string]*moduleIndexer
+	logger  logutil.Logger
+}
+
+func StartIndexer(params indexer.InitParams) (indexer.InitResult, error) {
+	config, err := decodeConfig(params.Config.Config)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	ctx := params.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if config.DatabaseURL == "" {
+		return indexer.InitResult{}, errors.New("missing database URL")
+	}
+
+	driver := config.DatabaseDriver
+	if driver == "" {
+		driver = "sqlite3"
+	}
+
+	db, err := sql.Open(driver, config.DatabaseURL)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	// SQLite only supports a single writer, and the indexer reads through its
+	// current transaction, so a single connection is all we need
+	db.SetMaxOpenConns(1)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	// commit base schema
+	_, err = tx.ExecContext(ctx, baseSQL)
+	if err != nil {
+		return indexer.InitResult{}, err
+	}
+
+	addressCodec := params.AddressCodec
+	if addressCodec == nil {
+		addressCodec = addressutil.HexAddressCodec{}
+	}
+
+	idx := &indexerImpl{
+		ctx: ctx,
+		db:  db,
+		tx:  tx,
+		opts: options{
+			disableRetainDeletions: config.DisableRetainDeletions,
+			logger:                 params.Logger,
+			addressCodec:           addressCodec,
+		},
+		modules: map[string]*moduleIndexer{},
+		logger:  params.Logger,
+	}
+
+	return indexer.InitResult{
+		Listener: idx.listener(),
+		View:     idx,
+	}, nil
+}
+
+func decodeConfig(rawConfig map[string]interface{}) (*Config, error) {
+	bz, err := json.Marshal(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	err = json.Unmarshal(bz, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,59 @@
+package testdata
+
+import "cosmossdk.io/schema"
+
+var ExampleSchema = schema.MustCompileModuleSchema(
+	SingletonObject,
+	VoteObject,
+	VoteType,
+)
+
+var SingletonObject = schema.ObjectType{
+	Name: "singleton",
+	ValueFields: []schema.Field{
+		{
+			Name: "foo",
+			Kind: schema.StringKind,
+		},
+		{
+			Name:     "bar",
+			Kind:     schema.Int32Kind,
+			Nullable: true,
+		},
+	},
+}
+
+var VoteObject = schema.ObjectType{
+	Name: "vote",
+	KeyFields: []schema.Field{
+		{
+			Name: "proposal",
+			Kind: schema.Int64Kind,
+		},
+		{
+			Name: "address",
+			Kind: schema.AddressKind,
+		},
+	},
+	ValueFields: []schema.Field{
+		{
+			Name:           "vote",
+			Kind:           schema.EnumKind,
+			ReferencedType: VoteType.Name,
+		},
+		{
+			Name: "weight",
+			Kind: schema.Uint64Kind,
+		},
+	},
+	RetainDeletions: true,
+}
+
+var VoteType = schema.EnumType{
+	Name: "vote_type",
+	Values: []schema.EnumValueDefinition{
+		{Name: "yes", Value: 1},
+		{Name: "no", Value: 2},
+		{Name: "abstain", Value: 3},
+	},
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"cosmossdk.io/schema/appdata"
+)
+
+func (i *indexerImpl) listener() appdata.Listener {
+	return appdata.Listener{
+		InitializeModuleData: func(data appdata.ModuleInitializationData) error {
+			moduleName := data.ModuleName
+			modSchema := data.Schema
+			_, ok := i.modules[moduleName]
+			if ok {
+				return fmt.Errorf("module %s already initialized", moduleName)
+			}
+
+			mm := newModuleIndexer(moduleName, modSchema, i.opts)
+			i.modules[moduleName] = mm
+
+			return mm.initializeSchema(i.ctx, i.tx)
+		},
+		StartBlock: func(data appdata.StartBlockData) error {
+			_, err := i.tx.ExecContext(i.ctx, "INSERT INTO block (number) VALUES (?)", int64(data.Height))
+			return err
+		},
+		OnObjectUpdate: func(data appdata.ObjectUpdateData) error {
+			mod, ok := i.modules[data.ModuleName]
+			if !ok {
+				return fmt.Errorf("module %s not initialized", data.ModuleName)
+			}
+
+			for _, update := range data.Updates {
+				tm, ok := mod.tables[update.TypeName]
+				if !ok {
+					return fmt.Errorf("object type %s not found in schema for module %s", update.TypeName, data.ModuleName)
+				}
+
+				var err error
+				if update.Delete {
+					err = tm.delete(i.ctx, i.tx, update.Key)
+				} else {
+					err = tm.insertUpdate(i.ctx, i.tx, update.Key, update.Value)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Commit: func(data appdata.CommitData) (func() error, error) {
+			err := i.tx.Commit()
+			if err != nil {
+				return nil, err
+			}
+
+			i.tx, err = i.db.BeginTx(i.ctx, nil)
+			return nil, err
+		},
+	}
+}
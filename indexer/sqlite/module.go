@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"cosmossdk.io/schema"
+)
+
+// moduleIndexer manages the tables for a module.
+type moduleIndexer struct {
+	moduleName string
+	schema     schema.ModuleSchema
+	tables     map[string]*objectIndexer
+	options    options
+}
+
+// newModuleIndexer creates a new moduleIndexer for the given module schema.
+func newModuleIndexer(moduleName string, modSchema schema.ModuleSchema, options options) *moduleIndexer {
+	tables := map[string]*objectIndexer{}
+	modSchema.ObjectTypes(func(typ schema.ObjectType) bool {
+		tables[typ.Name] = newObjectIndexer(moduleName, typ, modSchema, options)
+		return true
+	})
+
+	return &moduleIndexer{
+		moduleName: moduleName,
+		schema:     modSchema,
+		tables:     tables,
+		options:    options,
+	}
+}
+
+// initializeSchema creates tables for all object types in the module schema. Enum types are
+// enforced with CHECK constraints on their columns.
+func (m *moduleIndexer) initializeSchema(ctx context.Context, conn dbConn) error {
+	var err error
+	m.schema.ObjectTypes(func(typ schema.ObjectType) bool {
+		err = m.tables[typ.Name].createTable(ctx, conn)
+		if err != nil {
+			err = fmt.Errorf("failed to create table for %s in module %s: %w", typ.Name, m.moduleName, err)
+		}
+		return err == nil
+	})
+	return err
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"cosmossdk.io/schema"
+)
+
+// objectIndexer is a helper struct that generates SQL for a given object type.
+type objectIndexer struct {
+	moduleName  string
+	typ         schema.ObjectType
+	typeSet     schema.TypeSet
+	valueFields map[string]schema.Field
+	allFields   map[string]schema.Field
+	options     options
+}
+
+// newObjectIndexer creates a new objectIndexer for the given object type. typeSet is used
+// to resolve the enum types referenced by the fields.
+func newObjectIndexer(moduleName string, typ schema.ObjectType, typeSet schema.TypeSet, options options) *objectIndexer {
+	allFields := make(map[string]schema.Field)
+	valueFields := make(map[string]schema.Field)
+
+	for _, field := range typ.KeyFields {
+		allFields[field.Name] = field
+	}
+
+	for _, field := range typ.ValueFields {
+		valueFields[field.Name] = field
+		allFields[field.Name] = field
+	}
+
+	return &objectIndexer{
+		moduleName:  moduleName,
+		typ:         typ,
+		typeSet:     typeSet,
+		allFields:   allFields,
+		valueFields: valueFields,
+		options:     options,
+	}
+}
+
+// tableName returns the name of the table for the object type scoped to its module.
+func (tm *objectIndexer) tableName() string {
+	return fmt.Sprintf("%s_%s", tm.moduleName, tm.typ.Name)
+}
+
+// retainDeletions returns true if the object type retains deletions and this is not disabled.
+func (tm *objectIndexer) retainDeletions() bool {
+	return !tm.options.disableRetainDeletions && tm.typ.RetainDeletions
+}
+
+// keyColumnNames returns the names of the primary key columns of the table.
+func (tm *objectIndexer) keyColumnNames() []string {
+	if len(tm.typ.KeyFields) == 0 {
+		return []string{"_id"}
+	}
+
+	names := make([]string, 0, len(tm.typ.KeyFields))
+	for _, field := range tm.typ.KeyFields {
+		names = append(names, columnName(field))
+	}
+	return names
+}
+
+// selectColumnNames returns the names of the columns of the key and value fields,
+// followed by _deleted if deletions are retained.
+func (tm *objectIndexer) selectColumnNames() []string {
+	var names []string
+	for _, field := range tm.typ.KeyFields {
+		names = append(names, columnName(field))
+	}
+	for _, field := range tm.typ.ValueFields {
+		names = append(names, columnName(field))
+	}
+	if tm.retainDeletions() {
+		names = append(names, "_deleted")
+	}
+	return names
+}
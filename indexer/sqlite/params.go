@@ -0,0 +1,130 @@
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"cosmossdk.io/schema"
+)
+
+// bindKeyParams binds the key to the key columns.
+func (tm *objectIndexer) bindKeyParams(key interface{}) ([]interface{}, []string, error) {
+	n := len(tm.typ.KeyFields)
+	if n == 0 {
+		// singleton, set _id = 1
+		return []interface{}{1}, []string{"_id"}, nil
+	} else if n == 1 {
+		return tm.bindParams(tm.typ.KeyFields, []interface{}{key})
+	} else {
+		key, ok := key.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("expected key to be a slice")
+		}
+
+		return tm.bindParams(tm.typ.KeyFields, key)
+	}
+}
+
+// bindValueParams binds the value to the value columns. If the value is a
+// schema.ValueUpdates, only the updated columns are bound.
+func (tm *objectIndexer) bindValueParams(value interface{}) (params []interface{}, valueCols []string, err error) {
+	n := len(tm.typ.ValueFields)
+	if n == 0 {
+		return nil, nil, nil
+	} else if valueUpdates, ok := value.(schema.ValueUpdates); ok {
+		var e error
+		var fields []schema.Field
+		var values []interface{}
+		if err := valueUpdates.Iterate(func(name string, value interface{}) bool {
+			field, ok := tm.valueFields[name]
+			if !ok {
+				e = fmt.Errorf("unknown column %q", name)
+				return false
+			}
+			fields = append(fields, field)
+			values = append(values, value)
+			return true
+		}); err != nil {
+			return nil, nil, err
+		}
+		if e != nil {
+			return nil, nil, e
+		}
+
+		return tm.bindParams(fields, values)
+	} else if n == 1 {
+		return tm.bindParams(tm.typ.ValueFields, []interface{}{value})
+	} else {
+		values, ok := value.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("expected values to be a slice")
+		}
+
+		return tm.bindParams(tm.typ.ValueFields, values)
+	}
+}
+
+// bindParams binds the values to the columns of the fields.
+func (tm *objectIndexer) bindParams(fields []schema.Field, values []interface{}) ([]interface{}, []string, error) {
+	if len(values) != len(fields) {
+		return nil, nil, fmt.Errorf("expected %d values, got %d", len(fields), len(values))
+	}
+
+	names := make([]string, 0, len(fields))
+	params := make([]interface{}, 0, len(fields))
+	for i, field := range fields {
+		param, err := tm.bindParam(field, values[i])
+		if err != nil {
+			return nil, nil, err
+		}
+
+		names = append(names, columnName(field))
+		params = append(params, param)
+	}
+	return params, names, nil
+}
+
+// bindParam binds a single value to a query parameter of the column type of the field.
+func (tm *objectIndexer) bindParam(field schema.Field, value interface{}) (param interface{}, err error) {
+	if value == nil {
+		if !field.Nullable {
+			return nil, fmt.Errorf("expected non-null value for field %q", field.Name)
+		}
+		return nil, nil
+	}
+
+	if err := field.Kind.ValidateValueType(value); err != nil {
+		return nil, fmt.Errorf("invalid value for field %q: %w", field.Name, err)
+	}
+
+	switch field.Kind {
+	case schema.Int8Kind:
+		return int64(value.(int8)), nil
+	case schema.Int16Kind:
+		return int64(value.(int16)), nil
+	case schema.Int32Kind:
+		return int64(value.(int32)), nil
+	case schema.Uint8Kind:
+		return int64(value.(uint8)), nil
+	case schema.Uint16Kind:
+		return int64(value.(uint16)), nil
+	case schema.Uint32Kind:
+		return int64(value.(uint32)), nil
+	case schema.Uint64Kind:
+		return strconv.FormatUint(value.(uint64), 10), nil
+	case schema.Float32Kind:
+		return float64(value.(float32)), nil
+	case schema.TimeKind:
+		return value.(time.Time).UnixNano(), nil
+	case schema.DurationKind:
+		return int64(value.(time.Duration)), nil
+	case schema.AddressKind:
+		return tm.options.addressCodec.BytesToString(value.([]byte))
+	case schema.JSONKind:
+		return string(value.(json.RawMessage)), nil
+	default:
+		return value, nil
+	}
+}
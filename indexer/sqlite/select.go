@@ -0,0 +1,234 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"cosmossdk.io/schema"
+)
+
+// get reads the object with the provided key. Objects whose deletion was retained are
+// returned with Delete set to true.
+func (tm *objectIndexer) get(ctx context.Context, conn dbConn, key interface{}) (schema.ObjectUpdate, bool, error) {
+	buf := new(strings.Builder)
+	params, err := tm.getSql(buf, key)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+
+	rows, err := conn.QueryContext(ctx, buf.String(), params...)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return schema.ObjectUpdate{}, false, rows.Err()
+	}
+
+	update, err := tm.readRow(rows)
+	if err != nil {
+		return schema.ObjectUpdate{}, false, err
+	}
+	return update, true, rows.Close()
+}
+
+// getSql generates the SELECT statement reading the object with the provided key.
+func (tm *objectIndexer) getSql(w io.Writer, key interface{}) ([]interface{}, error) {
+	_, err := fmt.Fprintf(w, "SELECT %s FROM %q", strings.Join(tm.selectColumnNames(), ", "), tm.tableName())
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := tm.whereSqlAndParams(w, key)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = fmt.Fprintf(w, ";")
+	return params, err
+}
+
+// all calls f with every object in the table, including the objects whose deletion was retained.
+func (tm *objectIndexer) all(ctx context.Context, conn dbConn, f func(schema.ObjectUpdate, error) bool) {
+	sqlStr := fmt.Sprintf("SELECT %s FROM %q ORDER BY %s;",
+		strings.Join(tm.selectColumnNames(), ", "), tm.tableName(), strings.Join(tm.keyColumnNames(), ", "))
+	rows, err := conn.QueryContext(ctx, sqlStr)
+	if err != nil {
+		f(schema.ObjectUpdate{}, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		update, err := tm.readRow(rows)
+		if !f(update, err) || err != nil {
+			return
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		f(schema.ObjectUpdate{}, err)
+	}
+}
+
+// count returns the number of rows in the table, including the rows whose deletion is retained.
+func (tm *objectIndexer) count(ctx context.Context, conn dbConn) (int, error) {
+	row := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %q;", tm.tableName()))
+	var n int64
+	err := row.Scan(&n)
+	return int(n), err
+}
+
+// readRow reads a row selected with the columns returned by selectColumnNames into an object update.
+func (tm *objectIndexer) readRow(rows *sql.Rows) (schema.ObjectUpdate, error) {
+	numKeys, numValues := len(tm.typ.KeyFields), len(tm.typ.ValueFields)
+	cols := make([]interface{}, numKeys+numValues)
+	dests := make([]interface{}, len(cols), len(cols)+1)
+	for i := range cols {
+		dests[i] = &cols[i]
+	}
+	var deleted bool
+	if tm.retainDeletions() {
+		dests = append(dests, &deleted)
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+
+	keys, err := tm.readValues(tm.typ.KeyFields, cols[:numKeys])
+	if err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+	values, err := tm.readValues(tm.typ.ValueFields, cols[numKeys:])
+	if err != nil {
+		return schema.ObjectUpdate{}, err
+	}
+
+	return schema.ObjectUpdate{
+		TypeName: tm.typ.Name,
+		Key:      fieldsValue(keys),
+		Value:    fieldsValue(values),
+		Delete:   deleted,
+	}, nil
+}
+
+// fieldsValue converts a list of field values to the object key or value format,
+// which is a single value when there is only one field.
+func fieldsValue(values []interface{}) interface{} {
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		return values
+	}
+}
+
+// readValues converts the scanned column values into schema values.
+func (tm *objectIndexer) readValues(fields []schema.Field, cols []interface{}) ([]interface{}, error) {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value, err := tm.readValue(field, cols[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read field %q: %w", field.Name, err)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// readValue converts a scanned column value into a schema value of the field kind.
+// SQLite is dynamically typed, so the driver may return any of int64, float64, bool,
+// string or []byte for a column and we convert from those.
+func (tm *objectIndexer) readValue(field schema.Field, col interface{}) (interface{}, error) {
+	if col == nil {
+		return nil, nil
+	}
+
+	switch field.Kind {
+	case schema.BoolKind:
+		switch x := col.(type) {
+		case bool:
+			return x, nil
+		case int64:
+			return x != 0, nil
+		}
+	case schema.BytesKind:
+		switch x := col.(type) {
+		case []byte:
+			return x, nil
+		case string:
+			return []byte(x), nil
+		}
+	case schema.Int8Kind, schema.Int16Kind, schema.Int32Kind, schema.Int64Kind,
+		schema.Uint8Kind, schema.Uint16Kind, schema.Uint32Kind,
+		schema.TimeKind, schema.DurationKind:
+		if x, ok := col.(int64); ok {
+			return intValue(field.Kind, x), nil
+		}
+	case schema.Float32Kind:
+		if x, ok := col.(float64); ok {
+			return float32(x), nil
+		}
+	case schema.Float64Kind:
+		if x, ok := col.(float64); ok {
+			return x, nil
+		}
+	default:
+		var str string
+		switch x := col.(type) {
+		case string:
+			str = x
+		case []byte:
+			str = string(x)
+		default:
+			return nil, fmt.Errorf("unexpected column value %T for kind %s", col, field.Kind)
+		}
+
+		switch field.Kind {
+		case schema.Uint64Kind:
+			return strconv.ParseUint(str, 10, 64)
+		case schema.AddressKind:
+			return tm.options.addressCodec.StringToBytes(str)
+		case schema.JSONKind:
+			return json.RawMessage(str), nil
+		default:
+			return str, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected column value %T for kind %s", col, field.Kind)
+}
+
+// intValue converts an integer column value to the go type of the kind.
+func intValue(kind schema.Kind, x int64) interface{} {
+	switch kind {
+	case schema.Int8Kind:
+		return int8(x)
+	case schema.Int16Kind:
+		return int16(x)
+	case schema.Int32Kind:
+		return int32(x)
+	case schema.Uint8Kind:
+		return uint8(x)
+	case schema.Uint16Kind:
+		return uint16(x)
+	case schema.Uint32Kind:
+		return uint32(x)
+	case schema.TimeKind:
+		return time.Unix(0, x)
+	case schema.DurationKind:
+		return time.Duration(x)
+	default:
+		return x
+	}
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 database/sql driver
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/indexer/sqlite"
+	"cosmossdk.io/schema/indexer"
+	schematesting "cosmossdk.io/schema/testing"
+	"cosmossdk.io/schema/testing/appdatasim"
+	"cosmossdk.io/schema/testing/statesim"
+)
+
+func TestAppSimulator(t *testing.T) {
+	t.Run("retain deletions", func(t *testing.T) {
+		testAppSimulator(t, false)
+	})
+
+	t.Run("retain deletions disabled", func(t *testing.T) {
+		testAppSimulator(t, true)
+	})
+}
+
+func testAppSimulator(t *testing.T, disableRetainDeletions bool) {
+	t.Helper()
+
+	res, err := sqlite.StartIndexer(indexer.InitParams{
+		Config: indexer.Config{
+			Type: sqlite.IndexerType,
+			Config: map[string]interface{}{
+				"database_url":             fmt.Sprintf("file:%s", filepath.Join(t.TempDir(), "indexer.db")),
+				"disable_retain_deletions": disableRetainDeletions,
+			},
+		},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, res.View)
+
+	sim, err := appdatasim.NewSimulator(appdatasim.Options{
+		AppSchema:       schematesting.ExampleAppSchema,
+		Listener:        res.Listener,
+		StateSimOptions: statesim.Options{CanRetainDeletions: !disableRetainDeletions},
+	})
+	require.NoError(t, err)
+
+	blockDataGen := sim.BlockDataGenN(50, 100)
+	for i := 0; i < 20; i++ {
+		data := blockDataGen.Example(i + 1)
+		require.NoError(t, sim.ProcessBlockData(data))
+		require.Empty(t, appdatasim.DiffAppData(sim, res.View))
+	}
+}
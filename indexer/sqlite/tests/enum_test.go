@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 database/sql driver
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/indexer/sqlite"
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/appdata"
+	"cosmossdk.io/schema/indexer"
+)
+
+func TestEnumCheckConstraint(t *testing.T) {
+	voteType := schema.EnumType{
+		Name:   "vote_type",
+		Values: []schema.EnumValueDefinition{{Name: "yes", Value: 1}, {Name: "no", Value: 2}},
+	}
+	modSchema := schema.MustCompileModuleSchema(voteType, schema.ObjectType{
+		Name:        "vote",
+		KeyFields:   []schema.Field{{Name: "proposal", Kind: schema.Uint64Kind}},
+		ValueFields: []schema.Field{{Name: "vote", Kind: schema.EnumKind, ReferencedType: voteType.Name}},
+	})
+
+	res, err := sqlite.StartIndexer(indexer.InitParams{
+		Config: indexer.Config{
+			Type:   sqlite.IndexerType,
+			Config: map[string]interface{}{"database_url": fmt.Sprintf("file:%s", filepath.Join(t.TempDir(), "indexer.db"))},
+		},
+		Context: context.Background(),
+	})
+	require.NoError(t, err)
+	listener := res.Listener
+
+	require.NoError(t, listener.InitializeModuleData(appdata.ModuleInitializationData{ModuleName: "gov", Schema: modSchema}))
+	require.NoError(t, listener.StartBlock(appdata.StartBlockData{Height: 1}))
+	require.NoError(t, listener.OnObjectUpdate(appdata.ObjectUpdateData{
+		ModuleName: "gov",
+		Updates:    []schema.ObjectUpdate{{TypeName: "vote", Key: uint64(1<<64 - 1), Value: "yes"}},
+	}))
+	err = listener.OnObjectUpdate(appdata.ObjectUpdateData{
+		ModuleName: "gov",
+		Updates:    []schema.ObjectUpdate{{TypeName: "vote", Key: uint64(2), Value: "maybe"}},
+	})
+	require.ErrorContains(t, err, "CHECK constraint failed")
+
+	mod, err := res.View.AppState().GetModule("gov")
+	require.NoError(t, err)
+	votes, err := mod.GetObjectCollection("vote")
+	require.NoError(t, err)
+	update, found, err := votes.GetObject(uint64(1<<64 - 1))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "yes", update.Value)
+}
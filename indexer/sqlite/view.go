@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"sort"
+
+	"cosmossdk.io/schema"
+	"cosmossdk.io/schema/view"
+)
+
+var _ view.AppData = (*indexerImpl)(nil)
+
+// BlockNum implements the view.AppData interface. It returns the last indexed block.
+func (i *indexerImpl) BlockNum() (uint64, error) {
+	row := i.tx.QueryRowContext(i.ctx, "SELECT COALESCE(MAX(number), 0) FROM block;")
+	var num int64
+	err := row.Scan(&num)
+	return uint64(num), err
+}
+
+// AppState implements the view.AppData interface. The state is read through the indexer's
+// current transaction, so it includes the updates of the block being indexed.
+func (i *indexerImpl) AppState() view.AppState {
+	return appStateView{i}
+}
+
+type appStateView struct {
+	i *indexerImpl
+}
+
+func (a appStateView) GetModule(moduleName string) (view.ModuleState, error) {
+	m, ok := a.i.modules[moduleName]
+	if !ok {
+		return nil, nil
+	}
+	return moduleView{a.i, m}, nil
+}
+
+func (a appStateView) Modules(f func(modState view.ModuleState, err error) bool) {
+	names := make([]string, 0, len(a.i.modules))
+	for name := range a.i.modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !f(moduleView{a.i, a.i.modules[name]}, nil) {
+			return
+		}
+	}
+}
+
+func (a appStateView) NumModules() (int, error) {
+	return len(a.i.modules), nil
+}
+
+type moduleView struct {
+	i *indexerImpl
+	m *moduleIndexer
+}
+
+func (m moduleView) ModuleName() string {
+	return m.m.moduleName
+}
+
+func (m moduleView) ModuleSchema() schema.ModuleSchema {
+	return m.m.schema
+}
+
+func (m moduleView) GetObjectCollection(objectType string) (view.ObjectCollection, error) {
+	tm, ok := m.m.tables[objectType]
+	if !ok {
+		return nil, nil
+	}
+	return objectView{m.i, tm}, nil
+}
+
+func (m moduleView) ObjectCollections(f func(value view.ObjectCollection, err error) bool) {
+	m.m.schema.ObjectTypes(func(typ schema.ObjectType) bool {
+		return f(objectView{m.i, m.m.tables[typ.Name]}, nil)
+	})
+}
+
+func (m moduleView) NumObjectCollections() (int, error) {
+	return len(m.m.tables), nil
+}
+
+type objectView struct {
+	i  *indexerImpl
+	tm *objectIndexer
+}
+
+func (o objectView) ObjectType() schema.ObjectType {
+	return o.tm.typ
+}
+
+func (o objectView) GetObject(key interface{}) (update schema.ObjectUpdate, found bool, err error) {
+	return o.tm.get(o.i.ctx, o.i.tx, key)
+}
+
+func (o objectView) AllState(f func(schema.ObjectUpdate, error) bool) {
+	o.tm.all(o.i.ctx, o.i.tx, f)
+}
+
+func (o objectView) Len() (int, error) {
+	return o.tm.count(o.i.ctx, o.i.tx)
+}
@@ -0,0 +1,23 @@
+package sqlite
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// whereSqlAndParams generates a WHERE clause for the provided key and returns the parameters.
+func (tm *objectIndexer) whereSqlAndParams(w io.Writer, key interface{}) ([]interface{}, error) {
+	keyParams, keyCols, err := tm.bindKeyParams(key)
+	if err != nil {
+		return nil, err
+	}
+
+	conds := make([]string, len(keyCols))
+	for i, col := range keyCols {
+		conds[i] = fmt.Sprintf("%s = ?", col)
+	}
+
+	_, err = fmt.Fprintf(w, " WHERE %s", strings.Join(conds, " AND "))
+	return keyParams, err
+}
@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"slices"
@@ -10,6 +11,7 @@ import (
 	runtimev2 "cosmossdk.io/api/cosmos/app/runtime/v2"
 	"cosmossdk.io/core/legacy"
 	"cosmossdk.io/core/registry"
+	"cosmossdk.io/core/store"
 	"cosmossdk.io/core/transaction"
 	"cosmossdk.io/log"
 	"cosmossdk.io/server/v2/appmanager"
@@ -117,3 +119,22 @@ func (a *App[T]) GetAppManager() *appmanager.AppManager[T] {
 func (a *App[T]) GetGPRCMethodsToMessageMap() map[string]func() gogoproto.Message {
 	return a.GRPCMethodsToMessageMap
 }
+
+// ExtendVote returns the vote extension of the validator for the block with the
+// given height and hash, produced by the modules on the provided state.
+func (a *App[T]) ExtendVote(ctx context.Context, state store.ReaderMap, height int64, hash []byte) ([]byte, error) {
+	var extension []byte
+	err := a.stf.RunExtendVote(ctx, state, func(ctx context.Context) (err error) {
+		extension, err = a.moduleManager.ExtendVote(ctx, height, hash)
+		return err
+	})
+	return extension, err
+}
+
+// VerifyVoteExtension verifies the vote extension of the validator with the
+// given address with the modules on the provided state.
+func (a *App[T]) VerifyVoteExtension(ctx context.Context, state store.ReaderMap, height int64, validatorAddress, extension []byte) error {
+	return a.stf.RunVerifyVoteExtension(ctx, state, func(ctx context.Context) error {
+		return a.moduleManager.VerifyVoteExtension(ctx, height, validatorAddress, extension)
+	})
+}
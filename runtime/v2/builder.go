@@ -32,6 +32,9 @@ type AppBuilder[T transaction.Tx] struct {
 	branch      func(state store.ReaderMap) store.WriterMap
 	txValidator func(ctx context.Context, tx T) error
 	postTxExec  func(ctx context.Context, tx T, success bool) error
+
+	// parallelWorkers is the number of workers executing the block txs in parallel.
+	parallelWorkers int
 }
 
 // DefaultGenesis returns a default genesis from the registered AppModule's.
@@ -122,6 +125,7 @@ func (a *AppBuilder[T]) Build(opts ...AppBuilderOption[T]) (*App[T], error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create STF: %w", err)
 	}
+	stf.SetParallelExecution(a.parallelWorkers)
 	a.app.stf = stf
 
 	storeOpts := rootstore.DefaultStoreOptions()
@@ -228,3 +232,12 @@ func AppBuilderWithPostTxExec[T transaction.Tx](
 		a.postTxExec = postTxExec
 	}
 }
+
+// AppBuilderWithParallelExecution enables the optimistic parallel execution of the
+// block txs on the given number of workers. The results are identical to the
+// sequential execution, but the modules must support concurrent execution.
+func AppBuilderWithParallelExecution[T transaction.Tx](workers int) AppBuilderOption[T] {
+	return func(a *AppBuilder[T]) {
+		a.parallelWorkers = workers
+	}
+}
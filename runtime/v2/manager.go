@@ -20,6 +20,8 @@ import (
 	cosmosmsg "cosmossdk.io/api/cosmos/msg/v1"
 	"cosmossdk.io/core/appmodule"
 	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/comet"
+	corecontext "cosmossdk.io/core/context"
 	"cosmossdk.io/core/legacy"
 	"cosmossdk.io/core/registry"
 	"cosmossdk.io/core/transaction"
@@ -327,12 +329,34 @@ func (m *MM[T]) EndBlock() (
 	return endBlockFunc, valUpdateFunc
 }
 
-// PreBlocker runs the pre-block logic of all modules
+// PreBlocker runs the pre-block logic of all modules.
+// The modules producing vote extensions only get their own vote extensions in
+// the extended commit info of the context, the other modules do not get it.
 func (m *MM[T]) PreBlocker() func(ctx context.Context, txs []T) error {
 	return func(ctx context.Context, txs []T) error {
+		extCommit, hasExtCommit := ctx.Value(corecontext.ExtendedCommitInfoKey).(comet.ExtendedCommitInfo)
+		var decoded []map[string][]byte
+		if hasExtCommit {
+			decoded = make([]map[string][]byte, len(extCommit.Votes))
+			for i, vote := range extCommit.Votes {
+				// the votes with an invalid vote extension do not extend the block
+				decoded[i], _ = unmarshalVoteExtension(vote.VoteExtension)
+			}
+		}
+
 		for _, moduleName := range m.config.PreBlockers {
 			if module, ok := m.modules[moduleName].(appmodulev2.HasPreBlocker); ok {
-				if err := module.PreBlock(ctx); err != nil {
+				moduleCtx := ctx
+				if hasExtCommit {
+					// each module only sees its own vote extensions, the modules not
+					// producing vote extensions do not see the extended commit info
+					var moduleExtCommit any
+					if _, ok := module.(appmodulev2.HasExtendVote); ok {
+						moduleExtCommit = moduleExtendedCommitInfo(extCommit, decoded, moduleName)
+					}
+					moduleCtx = context.WithValue(ctx, corecontext.ExtendedCommitInfoKey, moduleExtCommit)
+				}
+				if err := module.PreBlock(moduleCtx); err != nil {
 					return fmt.Errorf("failed to run preblock for %s: %w", moduleName, err)
 				}
 			}
@@ -342,6 +366,48 @@ func (m *MM[T]) PreBlocker() func(ctx context.Context, txs []T) error {
 	}
 }
 
+// ExtendVote returns the vote extension of the validator for the block with the
+// given height and hash, combining the vote extensions of all modules.
+func (m *MM[T]) ExtendVote(ctx context.Context, height int64, hash []byte) ([]byte, error) {
+	extensions := make(map[string][]byte)
+	for _, moduleName := range slices.Sorted(maps.Keys(m.modules)) {
+		if module, ok := m.modules[moduleName].(appmodulev2.HasExtendVote); ok {
+			extension, err := module.ExtendVote(ctx, height, hash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to extend vote for %s: %w", moduleName, err)
+			}
+			extensions[moduleName] = extension
+		}
+	}
+
+	return marshalVoteExtension(extensions), nil
+}
+
+// VerifyVoteExtension verifies the vote extension of the validator with the
+// given address, with the vote extension verifiers of all modules. The vote
+// extensions of the modules not producing vote extensions are rejected.
+func (m *MM[T]) VerifyVoteExtension(ctx context.Context, height int64, validatorAddress, extension []byte) error {
+	extensions, err := unmarshalVoteExtension(extension)
+	if err != nil {
+		return err
+	}
+	for moduleName := range extensions {
+		if _, ok := m.modules[moduleName].(appmodulev2.HasExtendVote); !ok {
+			return fmt.Errorf("unexpected vote extension for %s", moduleName)
+		}
+	}
+
+	for _, moduleName := range slices.Sorted(maps.Keys(m.modules)) {
+		if module, ok := m.modules[moduleName].(appmodulev2.HasVerifyVoteExtension); ok {
+			if err := module.VerifyVoteExtension(ctx, height, validatorAddress, extensions[moduleName]); err != nil {
+				return fmt.Errorf("failed to verify vote extension for %s: %w", moduleName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // TxValidators validates incoming transactions
 func (m *MM[T]) TxValidators() func(ctx context.Context, tx T) error {
 	return func(ctx context.Context, tx T) error {
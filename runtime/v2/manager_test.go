@@ -0,0 +1,151 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	runtimev2 "cosmossdk.io/api/cosmos/app/runtime/v2"
+	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/comet"
+	corecontext "cosmossdk.io/core/context"
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/log"
+)
+
+type testModule struct{}
+
+func (testModule) IsAppModule()        {}
+func (testModule) IsOnePerModuleType() {}
+
+// testPreBlockModule records the extended commit info it gets in PreBlock.
+type testPreBlockModule struct {
+	testModule
+	extCommit any
+}
+
+func (m *testPreBlockModule) PreBlock(ctx context.Context) error {
+	m.extCommit = ctx.Value(corecontext.ExtendedCommitInfoKey)
+	return nil
+}
+
+// testVoteExtensionModule extends the votes with a fixed extension and only
+// accepts that extension.
+type testVoteExtensionModule struct {
+	testPreBlockModule
+	extension []byte
+	verified  [][]byte
+}
+
+func (m *testVoteExtensionModule) ExtendVote(context.Context, int64, []byte) ([]byte, error) {
+	return m.extension, nil
+}
+
+func (m *testVoteExtensionModule) VerifyVoteExtension(_ context.Context, _ int64, _, extension []byte) error {
+	m.verified = append(m.verified, extension)
+	if string(extension) != string(m.extension) {
+		return errors.New("invalid vote extension")
+	}
+	return nil
+}
+
+var (
+	_ appmodulev2.HasPreBlocker          = (*testPreBlockModule)(nil)
+	_ appmodulev2.HasExtendVote          = (*testVoteExtensionModule)(nil)
+	_ appmodulev2.HasVerifyVoteExtension = (*testVoteExtensionModule)(nil)
+)
+
+func newTestModuleManager(modules map[string]appmodulev2.AppModule) *MM[transaction.Tx] {
+	return NewModuleManager[transaction.Tx](log.NewNopLogger(), &runtimev2.Module{}, modules)
+}
+
+func TestExtendVerifyVoteExtension(t *testing.T) {
+	oracle := &testVoteExtensionModule{extension: []byte("price")}
+	bank := &testVoteExtensionModule{extension: []byte("balance")}
+	mm := newTestModuleManager(map[string]appmodulev2.AppModule{
+		"oracle": oracle,
+		"bank":   bank,
+		"other":  &testPreBlockModule{},
+	})
+
+	extension, err := mm.ExtendVote(context.Background(), 1, []byte("hash"))
+	require.NoError(t, err)
+	decoded, err := unmarshalVoteExtension(extension)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]byte{"oracle": []byte("price"), "bank": []byte("balance")}, decoded)
+
+	// each module verifies its own vote extension
+	require.NoError(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), extension))
+	require.Equal(t, [][]byte{[]byte("price")}, oracle.verified)
+	require.Equal(t, [][]byte{[]byte("balance")}, bank.verified)
+
+	// a module rejecting its vote extension rejects the vote
+	forged := marshalVoteExtension(map[string][]byte{"oracle": []byte("forged"), "bank": []byte("balance")})
+	require.ErrorContains(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), forged), "failed to verify vote extension for oracle")
+
+	// a missing vote extension is verified as empty by its module
+	missing := marshalVoteExtension(map[string][]byte{"oracle": []byte("price")})
+	require.ErrorContains(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), missing), "failed to verify vote extension for bank")
+	require.Nil(t, bank.verified[len(bank.verified)-1])
+
+	// the vote extensions of the modules not extending the votes are rejected
+	unexpected := marshalVoteExtension(map[string][]byte{"oracle": []byte("price"), "bank": []byte("balance"), "other": []byte("x")})
+	require.ErrorContains(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), unexpected), "unexpected vote extension for other")
+	unknown := marshalVoteExtension(map[string][]byte{"oracle": []byte("price"), "bank": []byte("balance"), "unknown": []byte("x")})
+	require.ErrorContains(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), unknown), "unexpected vote extension for unknown")
+
+	require.Error(t, mm.VerifyVoteExtension(context.Background(), 1, []byte("val"), []byte("garbage")))
+}
+
+func TestPreBlockerVoteExtensions(t *testing.T) {
+	oracle := &testVoteExtensionModule{extension: []byte("price")}
+	bank := &testVoteExtensionModule{extension: []byte("balance")}
+	other := &testPreBlockModule{}
+	mm := newTestModuleManager(map[string]appmodulev2.AppModule{
+		"oracle": oracle,
+		"bank":   bank,
+		"other":  other,
+	})
+	preBlocker := mm.PreBlocker()
+
+	extCommit := comet.ExtendedCommitInfo{
+		Round: 1,
+		Votes: []comet.ExtendedVoteInfo{
+			{
+				Validator:     comet.Validator{Address: []byte("val1"), Power: 2},
+				VoteExtension: marshalVoteExtension(map[string][]byte{"oracle": []byte("price1"), "bank": []byte("balance1")}),
+				BlockIDFlag:   comet.BlockIDFlagCommit,
+			},
+			{
+				Validator:     comet.Validator{Address: []byte("val2"), Power: 1},
+				VoteExtension: []byte("garbage"),
+				BlockIDFlag:   comet.BlockIDFlagCommit,
+			},
+		},
+	}
+	ctx := context.WithValue(context.Background(), corecontext.ExtendedCommitInfoKey, extCommit)
+	require.NoError(t, preBlocker(ctx, nil))
+
+	// the modules extending the votes only get their own vote extensions
+	for module, expected := range map[*testVoteExtensionModule]string{oracle: "price1", bank: "balance1"} {
+		moduleCommit, ok := module.extCommit.(comet.ExtendedCommitInfo)
+		require.True(t, ok)
+		require.Equal(t, extCommit.Round, moduleCommit.Round)
+		require.Len(t, moduleCommit.Votes, 2)
+		require.Equal(t, expected, string(moduleCommit.Votes[0].VoteExtension))
+		require.Equal(t, extCommit.Votes[0].Validator, moduleCommit.Votes[0].Validator)
+		// the invalid vote extensions are dropped
+		require.Nil(t, moduleCommit.Votes[1].VoteExtension)
+	}
+
+	// the other modules do not get the extended commit info
+	require.Nil(t, other.extCommit)
+
+	// without extended commit info the context is passed as is
+	other.extCommit = "unset"
+	require.NoError(t, preBlocker(context.Background(), nil))
+	require.Nil(t, other.extCommit)
+	require.Nil(t, oracle.extCommit)
+}
@@ -0,0 +1,104 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cosmossdk.io/core/comet"
+)
+
+// The vote extension of a validator combines the vote extensions of its modules,
+// encoded as a protobuf message with a repeated field of module extensions:
+//
+//	message VoteExtension {
+//	  message ModuleExtension {
+//	    string module    = 1;
+//	    bytes  extension = 2;
+//	  }
+//	  repeated ModuleExtension extensions = 1;
+//	}
+//
+// The module extensions are sorted by module name and the empty extensions are
+// omitted, so that the encoding is deterministic.
+
+// marshalVoteExtension encodes the vote extensions of the modules.
+func marshalVoteExtension(extensions map[string][]byte) []byte {
+	var bz []byte
+	for _, module := range slices.Sorted(maps.Keys(extensions)) {
+		if len(extensions[module]) == 0 {
+			continue
+		}
+
+		var entry []byte
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, module)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendBytes(entry, extensions[module])
+
+		bz = protowire.AppendTag(bz, 1, protowire.BytesType)
+		bz = protowire.AppendBytes(bz, entry)
+	}
+	return bz
+}
+
+// unmarshalVoteExtension decodes the vote extensions of the modules.
+func unmarshalVoteExtension(bz []byte) (map[string][]byte, error) {
+	extensions := make(map[string][]byte)
+	for len(bz) > 0 {
+		entry, n, err := consumeBytesField(bz, 1)
+		if err != nil {
+			return nil, err
+		}
+		bz = bz[n:]
+
+		module, n, err := consumeBytesField(entry, 1)
+		if err != nil {
+			return nil, err
+		}
+		extension, _, err := consumeBytesField(entry[n:], 2)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := extensions[string(module)]; ok {
+			return nil, fmt.Errorf("duplicate vote extension of module %s", module)
+		}
+		extensions[string(module)] = extension
+	}
+	return extensions, nil
+}
+
+// consumeBytesField consumes the length-delimited field with the given number at
+// the beginning of bz, returning its value and the number of consumed bytes.
+func consumeBytesField(bz []byte, num protowire.Number) ([]byte, int, error) {
+	fieldNum, typ, n := protowire.ConsumeTag(bz)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+	if fieldNum != num || typ != protowire.BytesType {
+		return nil, 0, errors.New("invalid vote extension encoding")
+	}
+	value, m := protowire.ConsumeBytes(bz[n:])
+	if m < 0 {
+		return nil, 0, protowire.ParseError(m)
+	}
+	return value, n + m, nil
+}
+
+// moduleExtendedCommitInfo returns the extended commit info with the vote
+// extensions of the given module only. The votes whose vote extension can not be
+// decoded have no vote extension.
+func moduleExtendedCommitInfo(extCommit comet.ExtendedCommitInfo, decoded []map[string][]byte, module string) comet.ExtendedCommitInfo {
+	moduleCommit := comet.ExtendedCommitInfo{
+		Round: extCommit.Round,
+		Votes: make([]comet.ExtendedVoteInfo, len(extCommit.Votes)),
+	}
+	for i, vote := range extCommit.Votes {
+		vote.VoteExtension = decoded[i][module]
+		moduleCommit.Votes[i] = vote
+	}
+	return moduleCommit
+}
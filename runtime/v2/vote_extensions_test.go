@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cosmossdk.io/core/comet"
+)
+
+func TestVoteExtensionEncoding(t *testing.T) {
+	extensions := map[string][]byte{
+		"oracle": []byte("price"),
+		"bank":   []byte("balance"),
+		"empty":  nil,
+	}
+
+	bz := marshalVoteExtension(extensions)
+	// the encoding does not depend on the map iteration order
+	for i := 0; i < 10; i++ {
+		require.Equal(t, bz, marshalVoteExtension(extensions))
+	}
+
+	decoded, err := unmarshalVoteExtension(bz)
+	require.NoError(t, err)
+	// the empty extensions are omitted
+	require.Equal(t, map[string][]byte{
+		"oracle": []byte("price"),
+		"bank":   []byte("balance"),
+	}, decoded)
+
+	// the module extensions are sorted by module name
+	module, _, err := consumeBytesField(bz[2:], 1)
+	require.NoError(t, err)
+	require.Equal(t, "bank", string(module))
+
+	require.Empty(t, marshalVoteExtension(nil))
+	decoded, err = unmarshalVoteExtension(nil)
+	require.NoError(t, err)
+	require.Empty(t, decoded)
+}
+
+func TestUnmarshalVoteExtensionErrors(t *testing.T) {
+	entry := func(module, extension string) []byte {
+		var bz []byte
+		bz = protowire.AppendTag(bz, 1, protowire.BytesType)
+		bz = protowire.AppendString(bz, module)
+		bz = protowire.AppendTag(bz, 2, protowire.BytesType)
+		bz = protowire.AppendString(bz, extension)
+		return protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), bz)
+	}
+	valid := marshalVoteExtension(map[string][]byte{"oracle": []byte("price")})
+
+	testCases := map[string][]byte{
+		"duplicate module":  append(entry("oracle", "a"), entry("oracle", "b")...),
+		"truncated":         valid[:len(valid)-1],
+		"unknown field":     protowire.AppendBytes(protowire.AppendTag(nil, 2, protowire.BytesType), []byte("x")),
+		"varint field":      protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1),
+		"missing extension": protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "oracle")),
+		"garbage":           []byte("not a vote extension"),
+	}
+
+	for name, bz := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := unmarshalVoteExtension(bz)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestModuleExtendedCommitInfo(t *testing.T) {
+	extCommit := comet.ExtendedCommitInfo{
+		Round: 2,
+		Votes: []comet.ExtendedVoteInfo{
+			{Validator: comet.Validator{Address: []byte("val1"), Power: 2}, VoteExtension: []byte("raw1"), ExtensionSignature: []byte("sig1"), BlockIDFlag: comet.BlockIDFlagCommit},
+			{Validator: comet.Validator{Address: []byte("val2"), Power: 1}, VoteExtension: []byte("raw2"), ExtensionSignature: []byte("sig2"), BlockIDFlag: comet.BlockIDFlagCommit},
+		},
+	}
+	decoded := []map[string][]byte{
+		{"oracle": []byte("price"), "bank": []byte("balance")},
+		nil, // an invalid vote extension
+	}
+
+	moduleCommit := moduleExtendedCommitInfo(extCommit, decoded, "oracle")
+	require.Equal(t, extCommit.Round, moduleCommit.Round)
+	require.Len(t, moduleCommit.Votes, 2)
+	require.Equal(t, []byte("price"), moduleCommit.Votes[0].VoteExtension)
+	require.Equal(t, extCommit.Votes[0].Validator, moduleCommit.Votes[0].Validator)
+	require.Equal(t, extCommit.Votes[0].ExtensionSignature, moduleCommit.Votes[0].ExtensionSignature)
+	require.Equal(t, extCommit.Votes[0].BlockIDFlag, moduleCommit.Votes[0].BlockIDFlag)
+	require.Nil(t, moduleCommit.Votes[1].VoteExtension)
+
+	// the extended commit info is not modified
+	require.Equal(t, []byte("raw1"), extCommit.Votes[0].VoteExtension)
+}
@@ -135,6 +135,30 @@ func (a AppManager[T]) DeliverBlock(
 	return blockResponse, newState, nil
 }
 
+// ReplayBlock re-executes an already committed block against the state of the
+// previous height, without committing anything. It returns the block results,
+// the resulting state and the state changes of each step of the execution, to
+// debug the execution of historical blocks.
+func (a AppManager[T]) ReplayBlock(
+	ctx context.Context,
+	block *server.BlockRequest[T],
+) (*server.BlockResponse, corestore.WriterMap, *server.BlockTrace, error) {
+	if block.Height == 0 {
+		return nil, nil, nil, errors.New("cannot replay block at height 0")
+	}
+	previousState, err := a.db.StateAt(block.Height - 1)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to get state at height %d: %w", block.Height-1, err)
+	}
+
+	blockResponse, newState, trace, err := a.stf.ReplayBlock(ctx, block, previousState)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("block replay failed: %w", err)
+	}
+
+	return blockResponse, newState, trace, nil
+}
+
 // ValidateTx will validate the tx against the latest storage state. This means that
 // only the stateful validation will be run, not the execution portion of the tx.
 // If full execution is needed, Simulate must be used.
@@ -17,6 +17,14 @@ type StateTransitionFunction[T transaction.Tx] interface {
 		state store.ReaderMap,
 	) (blockResult *server.BlockResponse, newState store.WriterMap, err error)
 
+	// ReplayBlock executes a block of transactions like DeliverBlock and returns
+	// the state changes of each step of the execution.
+	ReplayBlock(
+		ctx context.Context,
+		block *server.BlockRequest[T],
+		state store.ReaderMap,
+	) (blockResult *server.BlockResponse, newState store.WriterMap, trace *server.BlockTrace, err error)
+
 	// ValidateTx validates a transaction.
 	ValidateTx(
 		ctx context.Context,
@@ -9,6 +9,7 @@ import (
 
 	abci "github.com/cometbft/cometbft/abci/types"
 	abciproto "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+	cmttypes "github.com/cometbft/cometbft/types"
 	gogoproto "github.com/cosmos/gogoproto/proto"
 
 	"cosmossdk.io/core/comet"
@@ -57,6 +58,7 @@ type Consensus[T transaction.Tx] struct {
 	processProposalHandler handlers.ProcessHandler[T]
 	verifyVoteExt          handlers.VerifyVoteExtensionhandler
 	extendVote             handlers.ExtendVoteHandler
+	valStore               handlers.ValidatorStore // verifies the vote extension signatures, optional
 
 	addrPeerFilter types.PeerFilter // filter peers by address and port
 	idPeerFilter   types.PeerFilter // filter peers by node ID
@@ -138,6 +140,21 @@ func (c *Consensus[T]) CheckTx(ctx context.Context, req *abciproto.CheckTxReques
 	if resp.Error != nil {
 		cometResp.Code = 1
 		cometResp.Log = resp.Error.Error()
+
+		// evict the txs invalidated by the new state
+		if req.Type == abciproto.CHECK_TX_TYPE_RECHECK {
+			if err := c.mempool.Remove([]T{decodedTx}); err != nil && !errors.Is(err, mempool.ErrTxNotFound) {
+				return nil, fmt.Errorf("unable to remove tx: %w", err)
+			}
+		}
+		return cometResp, nil
+	}
+
+	if req.Type != abciproto.CHECK_TX_TYPE_RECHECK {
+		if err := c.mempool.Insert(ctx, decodedTx); err != nil {
+			cometResp.Code = 1
+			cometResp.Log = err.Error()
+		}
 	}
 	return cometResp, nil
 }
@@ -325,17 +342,39 @@ func (c *Consensus[T]) PrepareProposal(
 		return nil, errors.New("PrepareProposal called with invalid height")
 	}
 
-	decodedTxs := make([]T, len(req.Txs))
-	for i, tx := range req.Txs {
+	cp, err := c.GetConsensusParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the extended commit info of the previous block is injected as the first
+	// tx of the proposal when its votes were extended
+	var extCommitBz []byte
+	if voteExtensionsEnabled(cp, req.Height-1) {
+		if c.valStore != nil {
+			// the vote extensions which cannot be verified would cause the
+			// proposal to be rejected
+			req.LocalLastCommit = handlers.PruneVoteExtensions(ctx, c.valStore, c.chainID, req.Height, req.LocalLastCommit)
+		}
+		extCommitBz, err = req.LocalLastCommit.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal the extended commit info: %w", err)
+		}
+		// the space of the injected tx includes its proto encoding overhead in
+		// the block data
+		req.MaxTxBytes -= cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{extCommitBz})
+	}
+
+	decodedTxs := make([]T, 0, len(req.Txs))
+	for _, tx := range req.Txs {
 		decTx, err := c.txCodec.Decode(tx)
 		if err != nil {
-			// TODO: vote extension meta data as a custom type to avoid possibly accepting invalid txs
 			// continue even if tx decoding fails
 			c.logger.Error("failed to decode tx", "err", err)
 			continue
 		}
 
-		decodedTxs[i] = decTx
+		decodedTxs = append(decodedTxs, decTx)
 	}
 
 	ciCtx := contextWithCometInfo(ctx, comet.Info{
@@ -350,9 +389,12 @@ func (c *Consensus[T]) PrepareProposal(
 		return nil, err
 	}
 
-	encodedTxs := make([][]byte, len(txs))
-	for i, tx := range txs {
-		encodedTxs[i] = tx.Bytes()
+	encodedTxs := make([][]byte, 0, len(txs)+1)
+	if extCommitBz != nil {
+		encodedTxs = append(encodedTxs, extCommitBz)
+	}
+	for _, tx := range txs {
+		encodedTxs = append(encodedTxs, tx.Bytes())
 	}
 
 	return &abciproto.PrepareProposalResponse{
@@ -366,11 +408,30 @@ func (c *Consensus[T]) ProcessProposal(
 	ctx context.Context,
 	req *abciproto.ProcessProposalRequest,
 ) (*abciproto.ProcessProposalResponse, error) {
-	decodedTxs := make([]T, len(req.Txs))
-	for _, tx := range req.Txs {
+	cp, err := c.GetConsensusParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawTxs := req.Txs
+	if voteExtensionsEnabled(cp, req.Height-1) {
+		extCommit, err := extendedCommitInfoFromTxs(rawTxs)
+		if err == nil {
+			err = handlers.ValidateVoteExtensions(ctx, c.valStore, c.chainID, req.Height, extCommit, req.ProposedLastCommit)
+		}
+		if err != nil {
+			c.logger.Error("invalid vote extensions in proposal", "height", req.Height, "err", err)
+			return &abciproto.ProcessProposalResponse{
+				Status: abciproto.PROCESS_PROPOSAL_STATUS_REJECT,
+			}, nil
+		}
+		rawTxs = rawTxs[1:]
+	}
+
+	decodedTxs := make([]T, 0, len(rawTxs))
+	for _, tx := range rawTxs {
 		decTx, err := c.txCodec.Decode(tx)
 		if err != nil {
-			// TODO: vote extension meta data as a custom type to avoid possibly accepting invalid txs
 			// continue even if tx decoding fails
 			c.logger.Error("failed to decode tx", "err", err)
 			continue
@@ -385,7 +446,7 @@ func (c *Consensus[T]) ProcessProposal(
 		LastCommit:      toCoreCommitInfo(req.ProposedLastCommit),
 	})
 
-	err := c.processProposalHandler(ciCtx, c.app, decodedTxs, req)
+	err = c.processProposalHandler(ciCtx, c.app, decodedTxs, req)
 	if err != nil {
 		c.logger.Error("failed to process proposal", "height", req.Height, "time", req.Time, "hash", fmt.Sprintf("%X", req.Hash), "err", err)
 		return &abciproto.ProcessProposalResponse{
@@ -424,10 +485,29 @@ func (c *Consensus[T]) FinalizeBlock(
 		}, nil
 	}
 
+	cp, err := c.GetConsensusParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the extended commit info injected as the first tx of the block is not
+	// executed, it is exposed to the pre blockers instead.
+	rawTxs := req.Txs
+	var extCommit *comet.ExtendedCommitInfo
+	if voteExtensionsEnabled(cp, req.Height-1) {
+		abciExtCommit, err := extendedCommitInfoFromTxs(rawTxs)
+		if err != nil {
+			return nil, err
+		}
+		coreExtCommit := toCoreVoteExtensions(abciExtCommit)
+		extCommit = &coreExtCommit
+		rawTxs = rawTxs[1:]
+	}
+
 	// TODO(tip): can we expect some txs to not decode? if so, what we do in this case? this does not seem to be the case,
 	// considering that prepare and process always decode txs, assuming they're the ones providing txs we should never
 	// have a tx that fails decoding.
-	decodedTxs, err := decodeTxs(req.Txs, c.txCodec)
+	decodedTxs, err := decodeTxs(rawTxs, c.txCodec)
 	if err != nil {
 		return nil, err
 	}
@@ -444,6 +524,8 @@ func (c *Consensus[T]) FinalizeBlock(
 		AppHash: cid.Hash,
 		ChainId: c.chainID,
 		Txs:     decodedTxs,
+
+		ExtendedCommitInfo: extCommit,
 	}
 
 	ciCtx := contextWithCometInfo(ctx, comet.Info{
@@ -457,6 +539,11 @@ func (c *Consensus[T]) FinalizeBlock(
 	if err != nil {
 		return nil, err
 	}
+	if extCommit != nil {
+		// the injected extended commit info gets an empty result, as cometbft
+		// expects a result for each tx of the block
+		resp.TxResults = append([]server.TxResult{{}}, resp.TxResults...)
+	}
 
 	// after we get the changeset we can produce the commit hash,
 	// from the store.
@@ -491,7 +578,7 @@ func (c *Consensus[T]) FinalizeBlock(
 
 	c.lastCommittedHeight.Store(req.Height)
 
-	cp, err := c.GetConsensusParams(ctx) // we get the consensus params from the latest state because we committed state above
+	cp, err = c.GetConsensusParams(ctx) // we get the consensus params from the latest state because we committed state above
 	if err != nil {
 		return nil, err
 	}
@@ -532,14 +619,8 @@ func (c *Consensus[T]) VerifyVoteExtension(
 
 	// Note: we verify votes extensions on VoteExtensionsEnableHeight+1. Check
 	// comment in ExtendVote and ValidateVoteExtensions for more details.
-	// Since Abci was deprecated, should check both Feature & Abci
-	extsEnabled := cp.Feature.VoteExtensionsEnableHeight != nil && req.Height >= cp.Feature.VoteExtensionsEnableHeight.Value && cp.Feature.VoteExtensionsEnableHeight.Value != 0
-	if !extsEnabled {
-		// check abci params
-		extsEnabled = cp.Abci != nil && req.Height >= cp.Abci.VoteExtensionsEnableHeight && cp.Abci.VoteExtensionsEnableHeight != 0
-		if !extsEnabled {
-			return nil, fmt.Errorf("vote extensions are not enabled; unexpected call to VerifyVoteExtension at height %d", req.Height)
-		}
+	if !voteExtensionsEnabled(cp, req.Height) {
+		return nil, fmt.Errorf("vote extensions are not enabled; unexpected call to VerifyVoteExtension at height %d", req.Height)
 	}
 
 	if c.verifyVoteExt == nil {
@@ -573,14 +654,8 @@ func (c *Consensus[T]) ExtendVote(ctx context.Context, req *abciproto.ExtendVote
 	// greater than VoteExtensionsEnableHeight. This defers from the check done
 	// in ValidateVoteExtensions and PrepareProposal in which we'll check for
 	// vote extensions on VoteExtensionsEnableHeight+1.
-	// Since Abci was deprecated, should check both Feature & Abci
-	extsEnabled := cp.Feature.VoteExtensionsEnableHeight != nil && req.Height >= cp.Feature.VoteExtensionsEnableHeight.Value && cp.Feature.VoteExtensionsEnableHeight.Value != 0
-	if !extsEnabled {
-		// check abci params
-		extsEnabled = cp.Abci != nil && req.Height >= cp.Abci.VoteExtensionsEnableHeight && cp.Abci.VoteExtensionsEnableHeight != 0
-		if !extsEnabled {
-			return nil, fmt.Errorf("vote extensions are not enabled; unexpected call to ExtendVote at height %d", req.Height)
-		}
+	if !voteExtensionsEnabled(cp, req.Height) {
+		return nil, fmt.Errorf("vote extensions are not enabled; unexpected call to ExtendVote at height %d", req.Height)
 	}
 
 	if c.extendVote == nil {
@@ -612,3 +687,16 @@ func decodeTxs[T transaction.Tx](rawTxs [][]byte, codec transaction.Codec[T]) ([
 	}
 	return txs, nil
 }
+
+// extendedCommitInfoFromTxs decodes the extended commit info injected as the
+// first tx of a block.
+func extendedCommitInfoFromTxs(rawTxs [][]byte) (abciproto.ExtendedCommitInfo, error) {
+	var extCommit abciproto.ExtendedCommitInfo
+	if len(rawTxs) == 0 {
+		return extCommit, errors.New("missing the extended commit info tx")
+	}
+	if err := extCommit.Unmarshal(rawTxs[0]); err != nil {
+		return extCommit, fmt.Errorf("unable to decode the extended commit info tx: %w", err)
+	}
+	return extCommit, nil
+}
@@ -0,0 +1,176 @@
+package cometbft
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	abciproto "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+	"cosmossdk.io/server/v2/appmanager"
+	"cosmossdk.io/server/v2/cometbft/mempool"
+)
+
+type testTx []byte
+
+func (tx testTx) Hash() [32]byte                              { return sha256.Sum256(tx) }
+func (tx testTx) GetMessages() ([]transaction.Msg, error)     { return nil, nil }
+func (tx testTx) GetSenders() ([]transaction.Identity, error) { return nil, nil }
+func (tx testTx) GetGasLimit() (uint64, error)                { return 0, nil }
+func (tx testTx) Bytes() []byte                               { return tx }
+
+type testTxCodec struct{}
+
+func (testTxCodec) Decode(bz []byte) (testTx, error) {
+	if len(bz) == 0 {
+		return nil, errors.New("empty tx")
+	}
+	return testTx(bz), nil
+}
+
+func (c testTxCodec) DecodeJSON(bz []byte) (testTx, error) { return c.Decode(bz) }
+
+// testSTF validates the txs which are not in its invalid txs.
+type testSTF struct {
+	appmanager.StateTransitionFunction[testTx]
+	invalid map[string]bool
+}
+
+func (s testSTF) ValidateTx(_ context.Context, _ store.ReaderMap, gasLimit uint64, tx testTx) server.TxResult {
+	if s.invalid[string(tx)] {
+		return server.TxResult{GasWanted: gasLimit, Error: fmt.Errorf("invalid tx %s", tx)}
+	}
+	return server.TxResult{GasWanted: gasLimit, GasUsed: 10}
+}
+
+type testStore struct{}
+
+func (testStore) StateLatest() (uint64, store.ReaderMap, error) { return 1, nil, nil }
+func (testStore) StateAt(uint64) (store.ReaderMap, error)       { return nil, nil }
+
+// testMempool records the inserted txs, it rejects the txs in its full txs.
+type testMempool struct {
+	txs  map[string]bool
+	full map[string]bool
+}
+
+func (mp *testMempool) Insert(_ context.Context, tx testTx) error {
+	if mp.full[string(tx)] {
+		return mempool.ErrMempoolTxMaxCapacity
+	}
+	mp.txs[string(tx)] = true
+	return nil
+}
+
+func (mp *testMempool) Select(context.Context, []testTx) mempool.Iterator[testTx] { return nil }
+
+func (mp *testMempool) Remove(txs []testTx) error {
+	for _, tx := range txs {
+		if !mp.txs[string(tx)] {
+			return mempool.ErrTxNotFound
+		}
+		delete(mp.txs, string(tx))
+	}
+	return nil
+}
+
+func newTestConsensus(t *testing.T, invalid ...string) (*Consensus[testTx], *testMempool) {
+	t.Helper()
+	stf := testSTF{invalid: make(map[string]bool)}
+	for _, tx := range invalid {
+		stf.invalid[tx] = true
+	}
+	am, err := appmanager.Builder[testTx]{
+		STF:                stf,
+		DB:                 testStore{},
+		ValidateTxGasLimit: 1000,
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mp := &testMempool{txs: make(map[string]bool), full: make(map[string]bool)}
+	return &Consensus[testTx]{app: am, txCodec: testTxCodec{}, mempool: mp}, mp
+}
+
+func checkTx(t *testing.T, c *Consensus[testTx], tx string, typ abciproto.CheckTxType) *abciproto.CheckTxResponse {
+	t.Helper()
+	resp, err := c.CheckTx(context.Background(), &abciproto.CheckTxRequest{Tx: []byte(tx), Type: typ})
+	if err != nil {
+		t.Fatalf("CheckTx error: %v", err)
+	}
+	return resp
+}
+
+func TestCheckTxInsert(t *testing.T) {
+	c, mp := newTestConsensus(t, "invalid")
+	mp.full["rejected"] = true
+
+	// the valid txs are inserted in the mempool
+	resp := checkTx(t, c, "valid", abciproto.CHECK_TX_TYPE_CHECK)
+	if resp.Code != 0 || resp.GasWanted != 1000 || resp.GasUsed != 10 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if !mp.txs["valid"] {
+		t.Fatal("expected the valid tx to be inserted")
+	}
+
+	// the invalid txs are not inserted
+	resp = checkTx(t, c, "invalid", abciproto.CHECK_TX_TYPE_CHECK)
+	if resp.Code == 0 || resp.Log != "invalid tx invalid" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if mp.txs["invalid"] {
+		t.Fatal("expected the invalid tx not to be inserted")
+	}
+
+	// the txs rejected by the mempool are rejected
+	resp = checkTx(t, c, "rejected", abciproto.CHECK_TX_TYPE_CHECK)
+	if resp.Code == 0 || resp.Log != mempool.ErrMempoolTxMaxCapacity.Error() {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	// the valid txs are not inserted again on recheck
+	delete(mp.txs, "valid")
+	if resp = checkTx(t, c, "valid", abciproto.CHECK_TX_TYPE_RECHECK); resp.Code != 0 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if mp.txs["valid"] {
+		t.Fatal("expected the rechecked tx not to be inserted")
+	}
+
+	if _, err := c.CheckTx(context.Background(), &abciproto.CheckTxRequest{}); err == nil {
+		t.Fatal("expected an error for an undecodable tx")
+	}
+}
+
+func TestCheckTxRecheckRemove(t *testing.T) {
+	c, mp := newTestConsensus(t)
+	checkTx(t, c, "a", abciproto.CHECK_TX_TYPE_CHECK)
+	checkTx(t, c, "b", abciproto.CHECK_TX_TYPE_CHECK)
+
+	// the txs invalidated by the new state are removed on recheck
+	c.app, _ = appmanager.Builder[testTx]{
+		STF: testSTF{invalid: map[string]bool{"a": true, "c": true}},
+		DB:  testStore{},
+	}.Build()
+	if resp := checkTx(t, c, "a", abciproto.CHECK_TX_TYPE_RECHECK); resp.Code == 0 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if resp := checkTx(t, c, "b", abciproto.CHECK_TX_TYPE_RECHECK); resp.Code != 0 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+	if mp.txs["a"] || !mp.txs["b"] {
+		t.Fatalf("expected only the invalidated tx to be removed, got %v", mp.txs)
+	}
+
+	// the txs missing from the mempool are ignored
+	if resp := checkTx(t, c, "c", abciproto.CHECK_TX_TYPE_RECHECK); resp.Code == 0 {
+		t.Fatalf("unexpected response %v", resp)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/viper"
 
 	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/server/v2/cometbft/handlers"
 )
 
 // Config is the configuration for the CometBFT application
@@ -23,6 +24,8 @@ func DefaultAppTomlConfig() *AppTomlConfig {
 		Transport:       "socket",
 		Trace:           false,
 		Standalone:      false,
+		MaxQueryPairs:   1000,
+		TxSelector:      handlers.DefaultTxSelectorConfig(),
 	}
 }
 
@@ -35,6 +38,10 @@ type AppTomlConfig struct {
 	Transport       string   `mapstructure:"transport" toml:"transport" comment:"transport defines the CometBFT RPC server transport protocol: socket, grpc"`
 	Trace           bool     `mapstructure:"trace" toml:"trace" comment:"trace enables the CometBFT RPC server to output trace information about its internal operations."`
 	Standalone      bool     `mapstructure:"standalone" toml:"standalone" comment:"standalone starts the application without the CometBFT node. The node should be started separately."`
+	MaxQueryPairs   int      `mapstructure:"max-query-pairs" toml:"max-query-pairs" comment:"max-query-pairs defines the maximum number of key/value pairs of the keys and subspace store queries, a query exceeding it fails. A value of 0 indicates no limit."`
+
+	// TxSelector defines the selection of the txs of the proposals.
+	TxSelector handlers.TxSelectorConfig `mapstructure:"tx-selector" toml:"tx-selector"`
 }
 
 // CfgOption is a function that allows to overwrite the default server configuration.
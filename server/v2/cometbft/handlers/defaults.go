@@ -32,6 +32,11 @@ func NewDefaultProposalHandler[T transaction.Tx](mp mempool.Mempool[T]) *Default
 	}
 }
 
+// SetTxSelector sets the TxSelector selecting the txs of the proposals.
+func (h *DefaultProposalHandler[T]) SetTxSelector(ts TxSelector[T]) {
+	h.txSelector = ts
+}
+
 func (h *DefaultProposalHandler[T]) PrepareHandler() PrepareHandler[T] {
 	return func(ctx context.Context, app AppManager[T], txs []T, req proto.Message) ([]T, error) {
 		abciReq, ok := req.(*abci.PrepareProposalRequest)
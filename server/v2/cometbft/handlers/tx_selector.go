@@ -3,8 +3,6 @@ package handlers
 import (
 	"context"
 
-	cmttypes "github.com/cometbft/cometbft/types"
-
 	"cosmossdk.io/core/transaction"
 )
 
@@ -49,7 +47,7 @@ func (ts *defaultTxSelector[T]) Clear() {
 }
 
 func (ts *defaultTxSelector[T]) SelectTxForProposal(_ context.Context, maxTxBytes, maxBlockGas uint64, tx T) bool {
-	txSize := uint64(cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{tx.Bytes()}))
+	txSize := txProtoSize(tx)
 	txGasLimit, err := tx.GetGasLimit()
 	if err != nil {
 		return false
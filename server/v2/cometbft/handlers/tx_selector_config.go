@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cosmossdk.io/core/transaction"
+)
+
+const (
+	// TxSelectorDefault selects the txs greedily until the block is full.
+	TxSelectorDefault = "default"
+	// TxSelectorFeeDensity selects the txs with the highest fee per gas first.
+	TxSelectorFeeDensity = "fee-density"
+	// TxSelectorSenderFairness caps the number of txs of each sender per block.
+	TxSelectorSenderFairness = "sender-fairness"
+	// TxSelectorLanes reserves block space for the txs of specific message types.
+	TxSelectorLanes = "lanes"
+)
+
+// TxSelectorConfig defines the configuration of the TxSelector of the default
+// proposal handler.
+type TxSelectorConfig struct {
+	Strategy        string       `mapstructure:"strategy" toml:"strategy" comment:"strategy is the strategy selecting the txs of a proposal: default, fee-density, sender-fairness or lanes. A strategy other than default makes the server use the default proposal handler with the app mempool, it requires an app mempool and no custom prepare proposal handler."`
+	FeeDenom        string       `mapstructure:"fee-denom" toml:"fee-denom" comment:"fee-denom is the denom of the fees compared by the fee-density strategy."`
+	MaxTxsPerSender int          `mapstructure:"max-txs-per-sender" toml:"max-txs-per-sender" comment:"max-txs-per-sender is the maximum number of txs of a sender in a block with the sender-fairness strategy."`
+	Lanes           []LaneConfig `mapstructure:"lanes" toml:"lanes" comment:"lanes are the lanes of the lanes strategy, which reserve a share of the block space for the txs of their message types."`
+}
+
+// LaneConfig defines a lane of the lanes TxSelector.
+type LaneConfig struct {
+	Name          string   `mapstructure:"name" toml:"name" comment:"name is the name of the lane."`
+	MsgTypes      []string `mapstructure:"msg-types" toml:"msg-types" comment:"msg-types are the type URLs of the messages of the lane, e.g. /cosmos.gov.v1.MsgVote. The txs whose messages are all of these types belong to the lane."`
+	ReservedSpace uint64   `mapstructure:"reserved-space" toml:"reserved-space" comment:"reserved-space is the percentage of the block bytes and gas reserved to the txs of the lane."`
+}
+
+// DefaultTxSelectorConfig returns the default TxSelectorConfig.
+func DefaultTxSelectorConfig() TxSelectorConfig {
+	return TxSelectorConfig{
+		Strategy: TxSelectorDefault,
+		Lanes:    make([]LaneConfig, 0),
+	}
+}
+
+// Validate validates the TxSelectorConfig.
+func (cfg TxSelectorConfig) Validate() error {
+	switch cfg.Strategy {
+	case "", TxSelectorDefault:
+	case TxSelectorFeeDensity:
+		if cfg.FeeDenom == "" {
+			return errors.New("the fee-density tx selector requires a fee denom")
+		}
+	case TxSelectorSenderFairness:
+		if cfg.MaxTxsPerSender <= 0 {
+			return errors.New("the sender-fairness tx selector requires a positive max txs per sender")
+		}
+	case TxSelectorLanes:
+		if len(cfg.Lanes) == 0 {
+			return errors.New("the lanes tx selector requires at least one lane")
+		}
+		var reserved uint64
+		msgTypes := make(map[string]string)
+		for _, lane := range cfg.Lanes {
+			if lane.ReservedSpace == 0 || len(lane.MsgTypes) == 0 {
+				return fmt.Errorf("lane %q requires message types and a reserved space", lane.Name)
+			}
+			for _, msgType := range lane.MsgTypes {
+				msgType = normalizeMsgType(msgType)
+				if other, ok := msgTypes[msgType]; ok {
+					return fmt.Errorf("message type %s is in lanes %q and %q", msgType, other, lane.Name)
+				}
+				msgTypes[msgType] = lane.Name
+			}
+			reserved += lane.ReservedSpace
+		}
+		if reserved > 100 {
+			return fmt.Errorf("the lanes reserve %d%% of the block space", reserved)
+		}
+	default:
+		return fmt.Errorf("unknown tx selector strategy %q", cfg.Strategy)
+	}
+
+	return nil
+}
+
+// NewTxSelector returns the TxSelector of the strategy of the configuration.
+// The fee-density strategy requires txFee to get the fees of the txs.
+func NewTxSelector[T transaction.Tx](cfg TxSelectorConfig, txFee TxFeeFunc[T]) (TxSelector[T], error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Strategy {
+	case TxSelectorFeeDensity:
+		if txFee == nil {
+			return nil, errors.New("the fee-density tx selector requires a tx fee function")
+		}
+		return NewFeeDensityTxSelector[T](cfg.FeeDenom, txFee), nil
+	case TxSelectorSenderFairness:
+		return NewSenderFairnessTxSelector[T](cfg.MaxTxsPerSender), nil
+	case TxSelectorLanes:
+		return NewLaneTxSelector[T](cfg.Lanes), nil
+	default:
+		return NewDefaultTxSelector[T](), nil
+	}
+}
+
+// normalizeMsgType returns the message type URL without its leading slash.
+func normalizeMsgType(msgType string) string {
+	return strings.TrimPrefix(msgType, "/")
+}
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"container/heap"
+	"context"
+	"math/big"
+	"math/bits"
+
+	cmttypes "github.com/cometbft/cometbft/types"
+	gogoproto "github.com/cosmos/gogoproto/proto"
+
+	"cosmossdk.io/core/transaction"
+)
+
+var (
+	_ TxSelector[transaction.Tx] = (*feeDensityTxSelector[transaction.Tx])(nil)
+	_ TxSelector[transaction.Tx] = (*senderFairnessTxSelector[transaction.Tx])(nil)
+	_ TxSelector[transaction.Tx] = (*laneTxSelector[transaction.Tx])(nil)
+)
+
+// TxFeeFunc returns the amount in the given denom of the fees of a tx.
+type TxFeeFunc[T transaction.Tx] func(tx T, denom string) (*big.Int, error)
+
+// feeDensityTxSelector selects the txs with the highest fee per gas first, as a
+// greedy approximation of the knapsack of the txs maximizing the fees of the
+// block. The txs of a sender keep their relative order, so a tx is selected
+// only if the previous txs of its sender are selected.
+type feeDensityTxSelector[T transaction.Tx] struct {
+	feeDenom    string
+	txFee       TxFeeFunc[T]
+	maxTxBytes  uint64
+	maxBlockGas uint64
+	candidates  []T
+}
+
+// NewFeeDensityTxSelector returns a TxSelector selecting the txs by fee per gas,
+// the fees being the amount in the given denom of the fees returned by txFee.
+//
+// All the txs are considered before selecting the txs of the proposal.
+func NewFeeDensityTxSelector[T transaction.Tx](feeDenom string, txFee TxFeeFunc[T]) TxSelector[T] {
+	return &feeDensityTxSelector[T]{feeDenom: feeDenom, txFee: txFee}
+}
+
+func (ts *feeDensityTxSelector[T]) SelectedTxs(_ context.Context) []T {
+	// the txs of each sender, in their order
+	var senders [][]*feeCandidate[T]
+	senderIndexes := make(map[string]int)
+	for i, tx := range ts.candidates {
+		gas, err := tx.GetGasLimit()
+		if err != nil {
+			continue
+		}
+		// the txs whose fees can not be determined have no fees
+		fee, err := ts.txFee(tx, ts.feeDenom)
+		if err != nil || fee == nil || fee.Sign() < 0 {
+			fee = new(big.Int)
+		}
+		c := &feeCandidate[T]{
+			tx:    tx,
+			index: i,
+			size:  txProtoSize(tx),
+			gas:   gas,
+			fee:   fee,
+		}
+		sender := txSender(tx)
+		j, ok := senderIndexes[sender]
+		if !ok {
+			j = len(senders)
+			senderIndexes[sender] = j
+			senders = append(senders, nil)
+		}
+		c.sender = j
+		senders[j] = append(senders[j], c)
+	}
+
+	heads := make(feeCandidateHeap[T], 0, len(senders))
+	for _, queue := range senders {
+		heads = append(heads, queue[0])
+	}
+	heap.Init(&heads)
+
+	var (
+		selectedTxs          []T
+		totalBytes, totalGas uint64
+	)
+	for len(heads) > 0 {
+		c := heap.Pop(&heads).(*feeCandidate[T])
+		if totalBytes+c.size > ts.maxTxBytes || (ts.maxBlockGas > 0 && totalGas+c.gas > ts.maxBlockGas) {
+			// the following txs of the sender can not be selected either
+			continue
+		}
+		selectedTxs = append(selectedTxs, c.tx)
+		totalBytes += c.size
+		totalGas += c.gas
+
+		if next := c.pos + 1; next < len(senders[c.sender]) {
+			nextCandidate := senders[c.sender][next]
+			nextCandidate.pos = next
+			heap.Push(&heads, nextCandidate)
+		}
+	}
+
+	return selectedTxs
+}
+
+func (ts *feeDensityTxSelector[T]) Clear() {
+	ts.maxTxBytes = 0
+	ts.maxBlockGas = 0
+	ts.candidates = nil
+}
+
+func (ts *feeDensityTxSelector[T]) SelectTxForProposal(_ context.Context, maxTxBytes, maxBlockGas uint64, tx T) bool {
+	ts.maxTxBytes = maxTxBytes
+	ts.maxBlockGas = maxBlockGas
+	ts.candidates = append(ts.candidates, tx)
+	return false
+}
+
+// feeCandidate is a tx considered by the feeDensityTxSelector.
+type feeCandidate[T transaction.Tx] struct {
+	tx     T
+	index  int // the index of the tx in the candidates
+	size   uint64
+	gas    uint64
+	fee    *big.Int
+	sender int // the index of the sender of the tx
+	pos    int // the position of the tx in the txs of its sender
+}
+
+// feeCandidateHeap is a heap of the next tx of each sender, ordered by fee per
+// gas, then by order of the candidates.
+type feeCandidateHeap[T transaction.Tx] []*feeCandidate[T]
+
+func (h feeCandidateHeap[T]) Len() int      { return len(h) }
+func (h feeCandidateHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h feeCandidateHeap[T]) Less(i, j int) bool {
+	// compare fee_i / gas_i with fee_j / gas_j, a zero gas counting as one
+	a := new(big.Int).Mul(h[i].fee, new(big.Int).SetUint64(max(h[j].gas, 1)))
+	b := new(big.Int).Mul(h[j].fee, new(big.Int).SetUint64(max(h[i].gas, 1)))
+	if c := a.Cmp(b); c != 0 {
+		return c > 0
+	}
+	return h[i].index < h[j].index
+}
+
+func (h *feeCandidateHeap[T]) Push(x any) { *h = append(*h, x.(*feeCandidate[T])) }
+
+func (h *feeCandidateHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// senderFairnessTxSelector selects the txs greedily like the default TxSelector,
+// but selects at most maxTxsPerSender txs of each sender.
+type senderFairnessTxSelector[T transaction.Tx] struct {
+	*defaultTxSelector[T]
+	maxTxsPerSender int
+	senderTxs       map[string]int
+}
+
+// NewSenderFairnessTxSelector returns a TxSelector selecting at most the given
+// number of txs of each sender.
+func NewSenderFairnessTxSelector[T transaction.Tx](maxTxsPerSender int) TxSelector[T] {
+	return &senderFairnessTxSelector[T]{
+		defaultTxSelector: &defaultTxSelector[T]{},
+		maxTxsPerSender:   maxTxsPerSender,
+		senderTxs:         make(map[string]int),
+	}
+}
+
+func (ts *senderFairnessTxSelector[T]) Clear() {
+	ts.defaultTxSelector.Clear()
+	ts.senderTxs = make(map[string]int)
+}
+
+func (ts *senderFairnessTxSelector[T]) SelectTxForProposal(ctx context.Context, maxTxBytes, maxBlockGas uint64, tx T) bool {
+	sender := txSender(tx)
+	if ts.senderTxs[sender] >= ts.maxTxsPerSender {
+		return false
+	}
+
+	selected := len(ts.selectedTxs)
+	stop := ts.defaultTxSelector.SelectTxForProposal(ctx, maxTxBytes, maxBlockGas, tx)
+	if len(ts.selectedTxs) > selected {
+		ts.senderTxs[sender]++
+	}
+	return stop
+}
+
+// laneTxSelector selects the txs greedily, reserving a share of the block bytes
+// and gas to the txs of each lane. The txs of a lane which do not fit in its
+// reserved space use the space which is not reserved, like the other txs.
+type laneTxSelector[T transaction.Tx] struct {
+	lanes  []LaneConfig
+	laneOf map[string]int // the lane of each message type
+
+	// the bytes and gas used by the txs of each lane, the last element being the
+	// space which is not reserved
+	usedBytes   []uint64
+	usedGas     []uint64
+	totalBytes  uint64
+	totalGas    uint64
+	selectedTxs []T
+}
+
+// NewLaneTxSelector returns a TxSelector reserving block space to the lanes.
+func NewLaneTxSelector[T transaction.Tx](lanes []LaneConfig) TxSelector[T] {
+	laneOf := make(map[string]int)
+	for i, lane := range lanes {
+		for _, msgType := range lane.MsgTypes {
+			laneOf[normalizeMsgType(msgType)] = i
+		}
+	}
+
+	return &laneTxSelector[T]{
+		lanes:     lanes,
+		laneOf:    laneOf,
+		usedBytes: make([]uint64, len(lanes)+1),
+		usedGas:   make([]uint64, len(lanes)+1),
+	}
+}
+
+func (ts *laneTxSelector[T]) SelectedTxs(_ context.Context) []T {
+	txs := make([]T, len(ts.selectedTxs))
+	copy(txs, ts.selectedTxs)
+	return txs
+}
+
+func (ts *laneTxSelector[T]) Clear() {
+	clear(ts.usedBytes)
+	clear(ts.usedGas)
+	ts.totalBytes = 0
+	ts.totalGas = 0
+	ts.selectedTxs = nil
+}
+
+func (ts *laneTxSelector[T]) SelectTxForProposal(_ context.Context, maxTxBytes, maxBlockGas uint64, tx T) bool {
+	txSize := txProtoSize(tx)
+	txGasLimit, err := tx.GetGasLimit()
+	if err != nil {
+		return false
+	}
+
+	// the space of a lane is the share of the block it reserves, and the space
+	// which is not reserved is the rest of the block
+	space := func(i int, limit uint64) uint64 {
+		if i < len(ts.lanes) {
+			return percentOf(limit, ts.lanes[i].ReservedSpace)
+		}
+		reserved := uint64(0)
+		for _, lane := range ts.lanes {
+			reserved += percentOf(limit, lane.ReservedSpace)
+		}
+		return limit - reserved
+	}
+	fits := func(i int) bool {
+		return ts.usedBytes[i]+txSize <= space(i, maxTxBytes) &&
+			(maxBlockGas == 0 || ts.usedGas[i]+txGasLimit <= space(i, maxBlockGas))
+	}
+
+	unreserved := len(ts.lanes)
+	target := -1
+	if lane := ts.txLane(tx); lane < unreserved && fits(lane) {
+		target = lane
+	} else if fits(unreserved) {
+		target = unreserved
+	}
+	if target >= 0 {
+		ts.usedBytes[target] += txSize
+		ts.usedGas[target] += txGasLimit
+		ts.totalBytes += txSize
+		ts.totalGas += txGasLimit
+		ts.selectedTxs = append(ts.selectedTxs, tx)
+	}
+
+	// check if we've reached capacity; if so, we cannot select any more transactions
+	return ts.totalBytes >= maxTxBytes || (maxBlockGas > 0 && (ts.totalGas >= maxBlockGas))
+}
+
+// txLane returns the lane of the tx, which is the lane of all its messages, or
+// the number of lanes if the tx does not belong to a lane.
+func (ts *laneTxSelector[T]) txLane(tx T) int {
+	msgs, err := tx.GetMessages()
+	if err != nil || len(msgs) == 0 {
+		return len(ts.lanes)
+	}
+
+	lane := -1
+	for _, msg := range msgs {
+		msgLane, ok := ts.laneOf[gogoproto.MessageName(msg)]
+		if !ok || (lane >= 0 && msgLane != lane) {
+			return len(ts.lanes)
+		}
+		lane = msgLane
+	}
+	return lane
+}
+
+// percentOf returns the given percentage of the value, rounded down.
+func percentOf(value, percent uint64) uint64 {
+	hi, lo := bits.Mul64(value, percent)
+	quo, _ := bits.Div64(hi, lo, 100)
+	return quo
+}
+
+// txProtoSize returns the size of the tx in a proposal.
+func txProtoSize[T transaction.Tx](tx T) uint64 {
+	return uint64(cmttypes.ComputeProtoSizeForTxs([]cmttypes.Tx{tx.Bytes()}))
+}
+
+// txSender returns the first sender of the tx, or its hash if the tx has no
+// sender, so that it is considered as its own sender.
+func txSender[T transaction.Tx](tx T) string {
+	senders, err := tx.GetSenders()
+	if err != nil || len(senders) == 0 {
+		hash := tx.Hash()
+		return string(hash[:])
+	}
+	return string(senders[0])
+}
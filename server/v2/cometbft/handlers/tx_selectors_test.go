@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	gogotypes "github.com/cosmos/gogoproto/types"
+
+	"cosmossdk.io/core/transaction"
+)
+
+type testTx struct {
+	name   string
+	sender string
+	gas    uint64
+	fee    string // the fee amount in "stake"
+	msgs   []transaction.Msg
+	size   int // the number of padding bytes
+}
+
+func (tx testTx) Hash() [32]byte                          { return sha256.Sum256(tx.Bytes()) }
+func (tx testTx) GetMessages() ([]transaction.Msg, error) { return tx.msgs, nil }
+func (tx testTx) GetGasLimit() (uint64, error)            { return tx.gas, nil }
+
+func (tx testTx) GetSenders() ([]transaction.Identity, error) {
+	return []transaction.Identity{[]byte(tx.sender)}, nil
+}
+
+// Bytes encodes the tx with its name and padding.
+func (tx testTx) Bytes() []byte { return []byte(tx.name + string(make([]byte, tx.size))) }
+
+// testTxFee returns the fee of the tx, which is in "stake".
+func testTxFee(tx testTx, denom string) (*big.Int, error) {
+	fee, ok := new(big.Int).SetString(tx.fee, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid fee %q", tx.fee)
+	}
+	if denom != "stake" {
+		return new(big.Int), nil
+	}
+	return fee, nil
+}
+
+func selectTxs(ts TxSelector[testTx], maxTxBytes, maxBlockGas uint64, txs []testTx) []string {
+	defer ts.Clear()
+	for _, tx := range txs {
+		if ts.SelectTxForProposal(context.Background(), maxTxBytes, maxBlockGas, tx) {
+			break
+		}
+	}
+
+	var names []string
+	for _, tx := range ts.SelectedTxs(context.Background()) {
+		names = append(names, tx.name)
+	}
+	return names
+}
+
+func requireNames(t *testing.T, expected, actual []string) {
+	t.Helper()
+	if fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Fatalf("expected txs %v, got %v", expected, actual)
+	}
+}
+
+func TestFeeDensityTxSelector(t *testing.T) {
+	txs := []testTx{
+		{name: "a1", sender: "a", gas: 100, fee: "100"},
+		{name: "a2", sender: "a", gas: 100, fee: "1000"},
+		{name: "b1", sender: "b", gas: 100, fee: "500"},
+		{name: "c1", sender: "c", gas: 50, fee: "300"},
+		{name: "d1", sender: "d", gas: 400, fee: "10000"},
+	}
+
+	// the densest txs are selected first, after the previous txs of their sender
+	ts := NewFeeDensityTxSelector[testTx]("stake", testTxFee)
+	requireNames(t, []string{"d1", "c1", "b1", "a1", "a2"}, selectTxs(ts, 1_000_000, 0, txs))
+
+	// the txs which do not fit are skipped, with the following txs of their sender
+	requireNames(t, []string{"d1", "c1", "b1"}, selectTxs(ts, 1_000_000, 600, txs))
+	requireNames(t, []string{"c1", "b1", "a1"}, selectTxs(ts, 1_000_000, 300, txs[:4]))
+
+	// the block bytes are limited too
+	maxTxBytes := txProtoSize(txs[4]) + txProtoSize(txs[3])
+	requireNames(t, []string{"d1", "c1"}, selectTxs(ts, maxTxBytes, 0, txs))
+
+	// the txs whose fees can not be determined, or are in another denom, have no fees
+	txs = append(txs, testTx{name: "e1", sender: "e", gas: 1, fee: "invalid"})
+	requireNames(t, []string{"d1", "c1", "b1", "a1", "a2", "e1"}, selectTxs(ts, 1_000_000, 0, txs))
+	ts = NewFeeDensityTxSelector[testTx]("atom", testTxFee)
+	requireNames(t, []string{"a1", "a2", "b1", "c1", "d1", "e1"}, selectTxs(ts, 1_000_000, 0, txs))
+}
+
+func TestSenderFairnessTxSelector(t *testing.T) {
+	txs := []testTx{
+		{name: "a1", sender: "a", gas: 10},
+		{name: "a2", sender: "a", gas: 10},
+		{name: "a3", sender: "a", gas: 10},
+		{name: "b1", sender: "b", gas: 10},
+		{name: "a4", sender: "a", gas: 10},
+		{name: "b2", sender: "b", gas: 10},
+		{name: "b3", sender: "b", gas: 10},
+	}
+
+	ts := NewSenderFairnessTxSelector[testTx](2)
+	requireNames(t, []string{"a1", "a2", "b1", "b2"}, selectTxs(ts, 1_000_000, 0, txs))
+
+	// the selector is cleared between proposals
+	requireNames(t, []string{"a1", "a2", "b1", "b2"}, selectTxs(ts, 1_000_000, 0, txs))
+}
+
+func TestLaneTxSelector(t *testing.T) {
+	oracleMsg := &gogotypes.BoolValue{}
+	govMsg := &gogotypes.StringValue{}
+	otherMsg := &gogotypes.Int64Value{}
+	cfg := TxSelectorConfig{
+		Strategy: TxSelectorLanes,
+		Lanes: []LaneConfig{
+			{Name: "oracle", MsgTypes: []string{"/google.protobuf.BoolValue"}, ReservedSpace: 20},
+			{Name: "gov", MsgTypes: []string{"google.protobuf.StringValue"}, ReservedSpace: 30},
+		},
+	}
+	ts, err := NewTxSelector[testTx](cfg, testTxFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unreserved half of the block gas is used by the other txs, and the txs
+	// of the lanes use their reserved space first
+	var txs []testTx
+	for i := 0; i < 6; i++ {
+		txs = append(txs, testTx{name: fmt.Sprintf("other%d", i), sender: "o", gas: 10, msgs: []transaction.Msg{otherMsg}})
+	}
+	txs = append(txs,
+		testTx{name: "oracle1", sender: "x", gas: 10, msgs: []transaction.Msg{oracleMsg}},
+		testTx{name: "oracle2", sender: "x", gas: 10, msgs: []transaction.Msg{oracleMsg}},
+		testTx{name: "oracle3", sender: "x", gas: 10, msgs: []transaction.Msg{oracleMsg}},
+		testTx{name: "mixed", sender: "y", gas: 10, msgs: []transaction.Msg{oracleMsg, govMsg}},
+		testTx{name: "gov1", sender: "z", gas: 30, msgs: []transaction.Msg{govMsg}},
+	)
+	requireNames(t,
+		[]string{"other0", "other1", "other2", "other3", "other4", "oracle1", "oracle2", "gov1"},
+		selectTxs(ts, 1_000_000, 100, txs),
+	)
+
+	// the txs of a lane which do not fit in its reserved space use the unreserved space
+	requireNames(t,
+		[]string{"oracle1", "oracle2", "oracle3", "mixed", "gov1"},
+		selectTxs(ts, 1_000_000, 100, txs[6:]),
+	)
+}
+
+func TestTxSelectorConfig(t *testing.T) {
+	testCases := map[string]struct {
+		cfg   TxSelectorConfig
+		valid bool
+	}{
+		"default":                       {cfg: DefaultTxSelectorConfig(), valid: true},
+		"empty strategy":                {cfg: TxSelectorConfig{}, valid: true},
+		"unknown strategy":              {cfg: TxSelectorConfig{Strategy: "random"}},
+		"fee density":                   {cfg: TxSelectorConfig{Strategy: TxSelectorFeeDensity, FeeDenom: "stake"}, valid: true},
+		"fee density without denom":     {cfg: TxSelectorConfig{Strategy: TxSelectorFeeDensity}},
+		"sender fairness":               {cfg: TxSelectorConfig{Strategy: TxSelectorSenderFairness, MaxTxsPerSender: 1}, valid: true},
+		"sender fairness without max":   {cfg: TxSelectorConfig{Strategy: TxSelectorSenderFairness}},
+		"lanes without lanes":           {cfg: TxSelectorConfig{Strategy: TxSelectorLanes}},
+		"lane without reserved space":   {cfg: TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{MsgTypes: []string{"a"}}}}},
+		"lane without message types":    {cfg: TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{ReservedSpace: 10}}}},
+		"message type in two lanes":     {cfg: TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{MsgTypes: []string{"a"}, ReservedSpace: 10}, {MsgTypes: []string{"/a"}, ReservedSpace: 10}}}},
+		"lanes reserving too much":      {cfg: TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{MsgTypes: []string{"a"}, ReservedSpace: 60}, {MsgTypes: []string{"b"}, ReservedSpace: 50}}}},
+		"lanes reserving all the block": {cfg: TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{MsgTypes: []string{"a"}, ReservedSpace: 50}, {MsgTypes: []string{"b"}, ReservedSpace: 50}}}, valid: true},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTxSelector[testTx](tc.cfg, testTxFee)
+			if tc.valid && err != nil {
+				t.Fatalf("expected a valid config, got %v", err)
+			}
+			if !tc.valid && err == nil {
+				t.Fatal("expected an invalid config")
+			}
+		})
+	}
+
+	// the fee-density strategy requires the fees of the txs
+	if _, err := NewTxSelector[testTx](TxSelectorConfig{Strategy: TxSelectorFeeDensity, FeeDenom: "stake"}, nil); err == nil {
+		t.Fatal("expected an error without tx fee function")
+	}
+	if _, err := NewTxSelector[testTx](TxSelectorConfig{Strategy: TxSelectorLanes, Lanes: []LaneConfig{{MsgTypes: []string{"a"}, ReservedSpace: 10}}}, nil); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	abci "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cmtcrypto "github.com/cometbft/cometbft/crypto"
+	protoio "github.com/cosmos/gogoproto/io"
+
+	"cosmossdk.io/core/store"
+)
+
+// VoteExtender defines the interface of an app whose modules produce and verify
+// vote extensions. It is implemented by the runtime/v2 App.
+type VoteExtender interface {
+	// ExtendVote returns the vote extension of the validator for the block with
+	// the given height and hash.
+	ExtendVote(ctx context.Context, state store.ReaderMap, height int64, hash []byte) ([]byte, error)
+	// VerifyVoteExtension verifies the vote extension of the validator with the
+	// given address.
+	VerifyVoteExtension(ctx context.Context, state store.ReaderMap, height int64, validatorAddress, extension []byte) error
+}
+
+// ValidatorStore defines the interface required for verifying the vote extension
+// signatures. It returns the CometBFT public key of the validator with the given
+// consensus address.
+type ValidatorStore interface {
+	GetPubKeyByConsAddr(ctx context.Context, consAddr []byte) (cmtcrypto.PubKey, error)
+}
+
+// NewExtendVoteHandler returns an ExtendVoteHandler extending the votes with the
+// vote extensions of the app.
+func NewExtendVoteHandler(app VoteExtender) ExtendVoteHandler {
+	return func(ctx context.Context, state store.ReaderMap, req *abci.ExtendVoteRequest) (*abci.ExtendVoteResponse, error) {
+		extension, err := app.ExtendVote(ctx, state, req.Height, req.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		return &abci.ExtendVoteResponse{VoteExtension: extension}, nil
+	}
+}
+
+// NewVerifyVoteExtensionHandler returns a VerifyVoteExtensionhandler accepting
+// the vote extensions verified by the app.
+func NewVerifyVoteExtensionHandler(app VoteExtender) VerifyVoteExtensionhandler {
+	return func(ctx context.Context, state store.ReaderMap, req *abci.VerifyVoteExtensionRequest) (*abci.VerifyVoteExtensionResponse, error) {
+		if err := app.VerifyVoteExtension(ctx, state, req.Height, req.ValidatorAddress, req.VoteExtension); err != nil {
+			return nil, err
+		}
+
+		return &abci.VerifyVoteExtensionResponse{Status: abci.VERIFY_VOTE_EXTENSION_STATUS_ACCEPT}, nil
+	}
+}
+
+// ValidateVoteExtensions validates the extended commit info of the previous block
+// injected in a proposal at the given height against its last commit. It checks
+// that the commit votes carry vote extension signatures, verified with valStore,
+// from at least 2/3 of the voting power. The commit votes whose vote extension
+// was removed by PruneVoteExtensions are not counted. A valStore is required, the
+// vote extensions cannot be trusted without verifying their signatures.
+func ValidateVoteExtensions(
+	ctx context.Context,
+	valStore ValidatorStore,
+	chainID string,
+	height int64,
+	extCommit abci.ExtendedCommitInfo,
+	lastCommit abci.CommitInfo,
+) error {
+	if valStore == nil {
+		return errors.New("a validator store is required to verify the vote extension signatures")
+	}
+
+	if err := validateExtendedCommitAgainstLastCommit(extCommit, lastCommit); err != nil {
+		return err
+	}
+
+	var (
+		// Total voting power of all vote extensions.
+		totalVP int64
+		// Total voting power of all validators that submitted valid vote extensions.
+		sumVP int64
+	)
+
+	for _, vote := range extCommit.Votes {
+		totalVP += vote.Validator.Power
+
+		// Only check + include power if the vote is a commit vote. There must be super-majority, otherwise the
+		// previous block (the block the vote is for) could not have been committed.
+		if vote.BlockIdFlag != cmtproto.BlockIDFlagCommit {
+			continue
+		}
+
+		if isPruned(vote) {
+			continue
+		}
+
+		if len(vote.ExtensionSignature) == 0 {
+			return fmt.Errorf("vote extensions enabled; received empty vote extension signature at height %d", height)
+		}
+
+		if err := verifyVoteExtensionSignature(ctx, valStore, chainID, height, extCommit.Round, vote); err != nil {
+			return err
+		}
+
+		sumVP += vote.Validator.Power
+	}
+
+	// This check is probably unnecessary, but better safe than sorry.
+	if totalVP <= 0 {
+		return fmt.Errorf("total voting power must be positive, got: %d", totalVP)
+	}
+
+	// If the sum of the voting power has not reached (2/3 + 1) we need to error.
+	if requiredVP := ((totalVP * 2) / 3) + 1; sumVP < requiredVP {
+		return fmt.Errorf(
+			"insufficient cumulative voting power received to verify vote extensions; got: %d, expected: >=%d",
+			sumVP, requiredVP,
+		)
+	}
+
+	return nil
+}
+
+// PruneVoteExtensions returns the extended commit info of the previous block to
+// inject in a proposal at the given height, without the vote extensions of the
+// commit votes whose signatures are missing or do not verify with valStore. The
+// votes are kept so that the extended commit info still matches the last commit,
+// and the pruned vote extensions are not counted by ValidateVoteExtensions, so a
+// single invalid vote extension doesn't cause the proposal to be rejected.
+func PruneVoteExtensions(
+	ctx context.Context,
+	valStore ValidatorStore,
+	chainID string,
+	height int64,
+	extCommit abci.ExtendedCommitInfo,
+) abci.ExtendedCommitInfo {
+	pruned := abci.ExtendedCommitInfo{
+		Round: extCommit.Round,
+		Votes: make([]abci.ExtendedVoteInfo, len(extCommit.Votes)),
+	}
+	for i, vote := range extCommit.Votes {
+		if vote.BlockIdFlag == cmtproto.BlockIDFlagCommit &&
+			(len(vote.ExtensionSignature) == 0 || verifyVoteExtensionSignature(ctx, valStore, chainID, height, extCommit.Round, vote) != nil) {
+			vote.VoteExtension = nil
+			vote.ExtensionSignature = nil
+		}
+		pruned.Votes[i] = vote
+	}
+
+	return pruned
+}
+
+// isPruned returns whether the vote extension of the vote was removed by
+// PruneVoteExtensions.
+func isPruned(vote abci.ExtendedVoteInfo) bool {
+	return len(vote.VoteExtension) == 0 && len(vote.ExtensionSignature) == 0
+}
+
+// verifyVoteExtensionSignature verifies the vote extension signature of the vote
+// of the previous block injected in a proposal at the given height.
+func verifyVoteExtensionSignature(
+	ctx context.Context,
+	valStore ValidatorStore,
+	chainID string,
+	height int64,
+	round int32,
+	vote abci.ExtendedVoteInfo,
+) error {
+	pubKey, err := valStore.GetPubKeyByConsAddr(ctx, vote.Validator.Address)
+	if err != nil {
+		return fmt.Errorf("failed to get validator %X public key: %w", vote.Validator.Address, err)
+	}
+
+	cve := cmtproto.CanonicalVoteExtension{
+		Extension: vote.VoteExtension,
+		Height:    height - 1, // the vote extension was signed in the previous height
+		Round:     int64(round),
+		ChainId:   chainID,
+	}
+
+	var buf bytes.Buffer
+	if err := protoio.NewDelimitedWriter(&buf).WriteMsg(&cve); err != nil {
+		return fmt.Errorf("failed to encode CanonicalVoteExtension: %w", err)
+	}
+
+	if !pubKey.VerifySignature(buf.Bytes(), vote.ExtensionSignature) {
+		return fmt.Errorf("failed to verify validator %X vote extension signature", vote.Validator.Address)
+	}
+
+	return nil
+}
+
+// validateExtendedCommitAgainstLastCommit validates an ExtendedCommitInfo against a LastCommit. Specifically,
+// it checks that the ExtendedCommit + LastCommit (for the same height), are consistent with each other + that
+// they are ordered correctly (by voting power) in accordance with
+// [comet](https://github.com/cometbft/cometbft/blob/4ce0277b35f31985bbf2c25d3806a184a4510010/types/validator_set.go#L784).
+func validateExtendedCommitAgainstLastCommit(ec abci.ExtendedCommitInfo, lc abci.CommitInfo) error {
+	// check that the rounds are the same
+	if ec.Round != lc.Round {
+		return fmt.Errorf("extended commit round %d does not match last commit round %d", ec.Round, lc.Round)
+	}
+
+	// check that the # of votes are the same
+	if len(ec.Votes) != len(lc.Votes) {
+		return fmt.Errorf("extended commit votes length %d does not match last commit votes length %d", len(ec.Votes), len(lc.Votes))
+	}
+
+	// check sort order of extended commit votes
+	if !slices.IsSortedFunc(ec.Votes, func(vote1, vote2 abci.ExtendedVoteInfo) int {
+		if vote1.Validator.Power == vote2.Validator.Power {
+			return bytes.Compare(vote1.Validator.Address, vote2.Validator.Address) // addresses sorted in ascending order (used to break vp conflicts)
+		}
+		return -int(vote1.Validator.Power - vote2.Validator.Power) // vp sorted in descending order
+	}) {
+		return errors.New("extended commit votes are not sorted by voting power")
+	}
+
+	addressCache := make(map[string]struct{}, len(ec.Votes))
+	// check consistency between LastCommit and ExtendedCommit
+	for i, vote := range ec.Votes {
+		// cache addresses to check for duplicates
+		if _, ok := addressCache[string(vote.Validator.Address)]; ok {
+			return fmt.Errorf("extended commit vote address %X is duplicated", vote.Validator.Address)
+		}
+		addressCache[string(vote.Validator.Address)] = struct{}{}
+
+		if !bytes.Equal(vote.Validator.Address, lc.Votes[i].Validator.Address) {
+			return fmt.Errorf("extended commit vote address %X does not match last commit vote address %X", vote.Validator.Address, lc.Votes[i].Validator.Address)
+		}
+		if vote.Validator.Power != lc.Votes[i].Validator.Power {
+			return fmt.Errorf("extended commit vote power %d does not match last commit vote power %d", vote.Validator.Power, lc.Votes[i].Validator.Power)
+		}
+		if vote.BlockIdFlag != lc.Votes[i].BlockIdFlag {
+			return fmt.Errorf("extended commit vote block id flag %d does not match last commit vote block id flag %d", vote.BlockIdFlag, lc.Votes[i].BlockIdFlag)
+		}
+	}
+
+	return nil
+}
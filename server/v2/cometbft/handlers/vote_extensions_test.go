@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	abci "github.com/cometbft/cometbft/api/cometbft/abci/v1"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
+	cmtcrypto "github.com/cometbft/cometbft/crypto"
+	"github.com/cometbft/cometbft/crypto/ed25519"
+	protoio "github.com/cosmos/gogoproto/io"
+)
+
+type testValidatorStore map[string]cmtcrypto.PubKey
+
+func (s testValidatorStore) GetPubKeyByConsAddr(_ context.Context, consAddr []byte) (cmtcrypto.PubKey, error) {
+	pk, ok := s[string(consAddr)]
+	if !ok {
+		return nil, fmt.Errorf("unknown validator %X", consAddr)
+	}
+	return pk, nil
+}
+
+// extendedVote returns the commit vote of the validator with a signed vote
+// extension for the given height.
+func extendedVote(t *testing.T, pk cmtcrypto.PrivKey, power, height int64, extension string) abci.ExtendedVoteInfo {
+	t.Helper()
+	cve := cmtproto.CanonicalVoteExtension{
+		Extension: []byte(extension),
+		Height:    height,
+		ChainId:   "test-chain",
+	}
+	var buf bytes.Buffer
+	if err := protoio.NewDelimitedWriter(&buf).WriteMsg(&cve); err != nil {
+		t.Fatal(err)
+	}
+	sig, err := pk.Sign(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return abci.ExtendedVoteInfo{
+		Validator:          abci.Validator{Address: pk.PubKey().Address(), Power: power},
+		VoteExtension:      []byte(extension),
+		ExtensionSignature: sig,
+		BlockIdFlag:        cmtproto.BlockIDFlagCommit,
+	}
+}
+
+func lastCommitOf(extCommit abci.ExtendedCommitInfo) abci.CommitInfo {
+	lastCommit := abci.CommitInfo{Round: extCommit.Round}
+	for _, vote := range extCommit.Votes {
+		lastCommit.Votes = append(lastCommit.Votes, abci.VoteInfo{Validator: vote.Validator, BlockIdFlag: vote.BlockIdFlag})
+	}
+	return lastCommit
+}
+
+func TestValidateVoteExtensions(t *testing.T) {
+	pk1, pk2, pk3 := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	valStore := testValidatorStore{
+		string(pk1.PubKey().Address()): pk1.PubKey(),
+		string(pk2.PubKey().Address()): pk2.PubKey(),
+		string(pk3.PubKey().Address()): pk3.PubKey(),
+	}
+
+	// the votes are sorted by voting power
+	validCommit := func() abci.ExtendedCommitInfo {
+		return abci.ExtendedCommitInfo{Votes: []abci.ExtendedVoteInfo{
+			extendedVote(t, pk1, 30, 9, "a"),
+			extendedVote(t, pk2, 20, 9, "b"),
+			extendedVote(t, pk3, 10, 9, "c"),
+		}}
+	}
+
+	testCases := map[string]struct {
+		malleate    func(*abci.ExtendedCommitInfo, *abci.CommitInfo)
+		noValStore  bool
+		expectedErr string
+	}{
+		"valid": {},
+		"absent vote below the threshold": {
+			malleate: func(ec *abci.ExtendedCommitInfo, lc *abci.CommitInfo) {
+				ec.Votes[2] = abci.ExtendedVoteInfo{Validator: ec.Votes[2].Validator, BlockIdFlag: cmtproto.BlockIDFlagAbsent}
+				lc.Votes[2].BlockIdFlag = cmtproto.BlockIDFlagAbsent
+			},
+		},
+		"pruned vote extension below the threshold": {
+			malleate: func(ec *abci.ExtendedCommitInfo, _ *abci.CommitInfo) {
+				ec.Votes[2].VoteExtension = nil
+				ec.Votes[2].ExtensionSignature = nil
+			},
+		},
+		"pruned vote extensions above the threshold": {
+			malleate: func(ec *abci.ExtendedCommitInfo, _ *abci.CommitInfo) {
+				ec.Votes[1].VoteExtension = nil
+				ec.Votes[1].ExtensionSignature = nil
+			},
+			expectedErr: "insufficient cumulative voting power",
+		},
+		"insufficient voting power": {
+			malleate: func(ec *abci.ExtendedCommitInfo, lc *abci.CommitInfo) {
+				ec.Votes[1] = abci.ExtendedVoteInfo{Validator: ec.Votes[1].Validator, BlockIdFlag: cmtproto.BlockIDFlagAbsent}
+				lc.Votes[1].BlockIdFlag = cmtproto.BlockIDFlagAbsent
+			},
+			expectedErr: "insufficient cumulative voting power",
+		},
+		"forged vote extension": {
+			malleate: func(ec *abci.ExtendedCommitInfo, _ *abci.CommitInfo) {
+				ec.Votes[0].VoteExtension = []byte("forged")
+			},
+			expectedErr: "failed to verify validator",
+		},
+		"without validator store": {
+			noValStore:  true,
+			expectedErr: "validator store is required",
+		},
+		"missing signature": {
+			malleate: func(ec *abci.ExtendedCommitInfo, _ *abci.CommitInfo) {
+				ec.Votes[0].ExtensionSignature = nil
+			},
+			expectedErr: "empty vote extension signature",
+		},
+		"votes not matching the last commit": {
+			malleate: func(_ *abci.ExtendedCommitInfo, lc *abci.CommitInfo) {
+				lc.Votes = lc.Votes[:2]
+			},
+			expectedErr: "does not match last commit votes length",
+		},
+		"unsorted votes": {
+			malleate: func(ec *abci.ExtendedCommitInfo, lc *abci.CommitInfo) {
+				ec.Votes[0], ec.Votes[1] = ec.Votes[1], ec.Votes[0]
+				lc.Votes[0], lc.Votes[1] = lc.Votes[1], lc.Votes[0]
+			},
+			expectedErr: "not sorted by voting power",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			extCommit := validCommit()
+			lastCommit := lastCommitOf(extCommit)
+			if tc.malleate != nil {
+				tc.malleate(&extCommit, &lastCommit)
+			}
+			var vs ValidatorStore = valStore
+			if tc.noValStore {
+				vs = nil
+			}
+
+			err := ValidateVoteExtensions(context.Background(), vs, "test-chain", 10, extCommit, lastCommit)
+			if tc.expectedErr == "" && err != nil {
+				t.Fatalf("expected valid vote extensions, got %v", err)
+			}
+			if tc.expectedErr != "" && (err == nil || !strings.Contains(err.Error(), tc.expectedErr)) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestPruneVoteExtensions(t *testing.T) {
+	pk1, pk2, pk3, pk4 := ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey(), ed25519.GenPrivKey()
+	valStore := testValidatorStore{
+		string(pk1.PubKey().Address()): pk1.PubKey(),
+		string(pk2.PubKey().Address()): pk2.PubKey(),
+		string(pk3.PubKey().Address()): pk3.PubKey(),
+		string(pk4.PubKey().Address()): pk4.PubKey(),
+	}
+
+	extCommit := abci.ExtendedCommitInfo{Votes: []abci.ExtendedVoteInfo{
+		extendedVote(t, pk1, 40, 9, "a"),
+		extendedVote(t, pk2, 30, 9, "b"),
+		extendedVote(t, pk3, 20, 9, "c"),
+		extendedVote(t, pk4, 10, 9, "d"),
+	}}
+	lastCommit := lastCommitOf(extCommit)
+	// a forged vote extension and a missing signature are rejected as a whole
+	extCommit.Votes[2].VoteExtension = []byte("forged")
+	extCommit.Votes[3].ExtensionSignature = nil
+	if err := ValidateVoteExtensions(context.Background(), valStore, "test-chain", 10, extCommit, lastCommit); err == nil {
+		t.Fatal("expected invalid vote extensions")
+	}
+
+	// only the invalid vote extensions are pruned
+	pruned := PruneVoteExtensions(context.Background(), valStore, "test-chain", 10, extCommit)
+	if len(pruned.Votes) != len(extCommit.Votes) {
+		t.Fatalf("expected %d votes, got %d", len(extCommit.Votes), len(pruned.Votes))
+	}
+	for i, extension := range []string{"a", "b", "", ""} {
+		if string(pruned.Votes[i].VoteExtension) != extension {
+			t.Fatalf("expected vote extension %q, got %q", extension, pruned.Votes[i].VoteExtension)
+		}
+	}
+	if string(extCommit.Votes[2].VoteExtension) != "forged" {
+		t.Fatal("expected the extended commit info not to be modified")
+	}
+	if err := ValidateVoteExtensions(context.Background(), valStore, "test-chain", 10, pruned, lastCommit); err != nil {
+		t.Fatalf("expected valid vote extensions, got %v", err)
+	}
+}
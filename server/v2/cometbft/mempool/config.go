@@ -8,4 +8,8 @@ type Config struct {
 	// unbounded in how many txs it may contain, and a positive value indicates
 	// the maximum amount of txs it may contain.
 	MaxTxs int `mapstructure:"max-txs"`
+
+	// MaxBytes defines the maximum total size in bytes of the txs the mempool may
+	// contain, zero indicates that the mempool is unbounded in size.
+	MaxBytes uint64 `mapstructure:"max-bytes"`
 }
@@ -1,6 +1,21 @@
 /*
 The mempool package defines a few mempool services which can be used in conjunction with your consensus implementation
 
+The PriorityNonceMempool orders the txs by priority, typically their fee per gas unit, while sequencing the txs of
+each sender by nonce. It is bounded in number of txs and in bytes by its Config, evicting its lowest priority txs
+for the txs with a higher priority. The priority, the sender and the nonce of the txs are provided by the
+application with a TxInfoFn:
+
+	mp := mempool.NewPriorityNonceMempool[T](mempool.Config{MaxTxs: 5000}, txInfo)
+	serverOptions.Mempool = mp
+
+The app mempool follows the CometBFT mempool, it does not validate its txs itself:
+  - CheckTx inserts the txs which are valid against the latest state in the app mempool. A tx rejected by the app
+    mempool, e.g. because it is full of txs with a higher priority, is rejected by CheckTx.
+  - FinalizeBlock removes the txs of the block from the app mempool.
+  - After each block, CometBFT re-checks the txs remaining in its mempool against the new state with CheckTx
+    requests of type RECHECK, when recheck is enabled in the mempool section of its config.toml. The txs which are
+    no longer valid are removed from the app mempool.
 */
 
 package mempool
@@ -10,6 +10,7 @@ import (
 var (
 	ErrTxNotFound           = errors.New("tx not found in mempool")
 	ErrMempoolTxMaxCapacity = errors.New("pool reached max tx capacity")
+	ErrTxReplacement        = errors.New("tx does not replace the tx with the same sender and nonce")
 )
 
 // Mempool defines the required methods of an application's mempool.
@@ -0,0 +1,311 @@
+package mempool
+
+import (
+	"cmp"
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"cosmossdk.io/core/transaction"
+)
+
+var (
+	_ Mempool[transaction.Tx]  = (*PriorityNonceMempool[transaction.Tx])(nil)
+	_ Iterator[transaction.Tx] = (*priorityNonceIterator[transaction.Tx])(nil)
+)
+
+// TxInfo defines the information of a tx used by the PriorityNonceMempool to
+// order its txs.
+type TxInfo struct {
+	// Sender is the sender of the tx, whose txs are sequenced by nonce.
+	Sender string
+	// Nonce is the sequence number of the tx for its sender.
+	Nonce uint64
+	// Priority is the priority of the tx, typically its fee per gas unit.
+	Priority int64
+}
+
+// TxInfoFn returns the TxInfo of a tx.
+type TxInfoFn[T transaction.Tx] func(ctx context.Context, tx T) (TxInfo, error)
+
+// PriorityNonceMempool is a mempool implementation that orders its txs by
+// priority while sequencing the txs of each sender by nonce: a tx is selected
+// only after the txs of its sender with a lower nonce, and the txs with the
+// same priority are selected in their order of insertion.
+//
+// When the mempool is full, the txs with the lowest priority are evicted to
+// insert a tx with a higher priority. Only the txs with the highest nonce of
+// their sender are evicted, so that no nonce gap is created. A tx with the same
+// sender and nonce as a tx of the mempool replaces it if its priority is higher.
+type PriorityNonceMempool[T transaction.Tx] struct {
+	mtx     sync.Mutex
+	cfg     Config
+	txInfo  TxInfoFn[T]
+	senders map[string][]*mempoolTx[T] // the txs of each sender sorted by nonce
+	txs     map[[32]byte]*mempoolTx[T]
+	bytes   uint64
+	seq     uint64
+}
+
+// mempoolTx is a tx of the PriorityNonceMempool.
+type mempoolTx[T transaction.Tx] struct {
+	tx   T
+	hash [32]byte
+	info TxInfo
+	size uint64
+	// seq is the insertion sequence of the tx, used to order the txs with the
+	// same priority.
+	seq uint64
+}
+
+// NewPriorityNonceMempool returns a new PriorityNonceMempool with the given
+// configuration, ordering the txs with the TxInfo returned by txInfo.
+func NewPriorityNonceMempool[T transaction.Tx](cfg Config, txInfo TxInfoFn[T]) *PriorityNonceMempool[T] {
+	return &PriorityNonceMempool[T]{
+		cfg:     cfg,
+		txInfo:  txInfo,
+		senders: make(map[string][]*mempoolTx[T]),
+		txs:     make(map[[32]byte]*mempoolTx[T]),
+	}
+}
+
+// Insert inserts the tx in the mempool, evicting the txs with a lower priority
+// when the mempool is full. It returns ErrMempoolTxMaxCapacity when the tx does
+// not fit in the mempool, and ErrTxReplacement when the tx has the same sender
+// and nonce as a tx of the mempool with a higher or equal priority.
+func (mp *PriorityNonceMempool[T]) Insert(ctx context.Context, tx T) error {
+	if mp.cfg.MaxTxs < 0 {
+		return nil
+	}
+
+	info, err := mp.txInfo(ctx, tx)
+	if err != nil {
+		return err
+	}
+	newTx := &mempoolTx[T]{
+		tx:   tx,
+		hash: tx.Hash(),
+		info: info,
+		size: uint64(len(tx.Bytes())),
+	}
+	if mp.cfg.MaxBytes > 0 && newTx.size > mp.cfg.MaxBytes {
+		return fmt.Errorf("%w: tx size %d exceeds the max bytes %d", ErrMempoolTxMaxCapacity, newTx.size, mp.cfg.MaxBytes)
+	}
+
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	if _, ok := mp.txs[newTx.hash]; ok {
+		return nil
+	}
+
+	queue := mp.senders[info.Sender]
+	i, found := slices.BinarySearchFunc(queue, info.Nonce, compareNonce[T])
+	var replaced *mempoolTx[T]
+	if found {
+		replaced = queue[i]
+		if info.Priority <= replaced.info.Priority {
+			return fmt.Errorf("%w: sender %s, nonce %d", ErrTxReplacement, info.Sender, info.Nonce)
+		}
+	}
+
+	evicted, ok := mp.evictions(newTx, replaced)
+	if !ok {
+		return ErrMempoolTxMaxCapacity
+	}
+	for _, mt := range append(evicted, replaced) {
+		if mt != nil {
+			mp.removeTx(mt)
+		}
+	}
+
+	mp.seq++
+	newTx.seq = mp.seq
+	queue = mp.senders[info.Sender]
+	i, _ = slices.BinarySearchFunc(queue, info.Nonce, compareNonce[T])
+	mp.senders[info.Sender] = slices.Insert(queue, i, newTx)
+	mp.txs[newTx.hash] = newTx
+	mp.bytes += newTx.size
+
+	return nil
+}
+
+// evictions returns the txs to evict to insert the new tx, replacing the given
+// tx if not nil. It returns false if the new tx does not fit in the mempool.
+func (mp *PriorityNonceMempool[T]) evictions(newTx, replaced *mempoolTx[T]) ([]*mempoolTx[T], bool) {
+	count, bytes := len(mp.txs)+1, mp.bytes+newTx.size
+	if replaced != nil {
+		count, bytes = count-1, bytes-replaced.size
+	}
+
+	var evicted []*mempoolTx[T]
+	remaining := make(map[string]int) // the number of txs of a sender left after the evictions
+	for (mp.cfg.MaxTxs > 0 && count > mp.cfg.MaxTxs) || (mp.cfg.MaxBytes > 0 && bytes > mp.cfg.MaxBytes) {
+		// evict the lowest priority tx among the txs with the highest nonce of
+		// their sender, which can not be the new tx
+		var victim *mempoolTx[T]
+		for sender, queue := range mp.senders {
+			n, ok := remaining[sender]
+			if !ok {
+				n = len(queue)
+			}
+			if n == 0 {
+				continue
+			}
+			tail := queue[n-1]
+			if sender == newTx.info.Sender && tail.info.Nonce <= newTx.info.Nonce {
+				continue
+			}
+			if victim == nil || compareSelection(tail, victim) > 0 {
+				victim = tail
+			}
+		}
+		if victim == nil || victim.info.Priority >= newTx.info.Priority {
+			return nil, false
+		}
+
+		n, ok := remaining[victim.info.Sender]
+		if !ok {
+			n = len(mp.senders[victim.info.Sender])
+		}
+		remaining[victim.info.Sender] = n - 1
+		evicted = append(evicted, victim)
+		count, bytes = count-1, bytes-victim.size
+	}
+
+	return evicted, true
+}
+
+// removeTx removes the tx from the mempool.
+func (mp *PriorityNonceMempool[T]) removeTx(mt *mempoolTx[T]) {
+	queue := mp.senders[mt.info.Sender]
+	i, found := slices.BinarySearchFunc(queue, mt.info.Nonce, compareNonce[T])
+	if !found || queue[i] != mt {
+		return
+	}
+
+	queue = slices.Delete(queue, i, i+1)
+	if len(queue) == 0 {
+		delete(mp.senders, mt.info.Sender)
+	} else {
+		mp.senders[mt.info.Sender] = queue
+	}
+	delete(mp.txs, mt.hash)
+	mp.bytes -= mt.size
+}
+
+// Select returns an iterator over the txs of the mempool at the time of the
+// call, ordered by priority and sequenced by nonce. The given txs are ignored.
+// It returns nil if the mempool is empty.
+func (mp *PriorityNonceMempool[T]) Select(_ context.Context, _ []T) Iterator[T] {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	if len(mp.txs) == 0 {
+		return nil
+	}
+
+	it := &priorityNonceIterator[T]{
+		queues: make([][]*mempoolTx[T], 0, len(mp.senders)),
+		heads:  make(txHeap[T], 0, len(mp.senders)),
+	}
+	for _, queue := range mp.senders {
+		it.queues = append(it.queues, slices.Clone(queue))
+		it.heads = append(it.heads, txHead[T]{tx: queue[0], queue: len(it.queues) - 1})
+	}
+	heap.Init(&it.heads)
+	it.current = heap.Pop(&it.heads).(txHead[T])
+
+	return it
+}
+
+// Remove removes the txs from the mempool, the txs which are not in the mempool
+// are ignored.
+func (mp *PriorityNonceMempool[T]) Remove(txs []T) error {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	for _, tx := range txs {
+		if mt, ok := mp.txs[tx.Hash()]; ok {
+			mp.removeTx(mt)
+		}
+	}
+
+	return nil
+}
+
+// CountTx returns the number of txs in the mempool.
+func (mp *PriorityNonceMempool[T]) CountTx() int {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	return len(mp.txs)
+}
+
+// Bytes returns the total size in bytes of the txs in the mempool.
+func (mp *PriorityNonceMempool[T]) Bytes() uint64 {
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+	return mp.bytes
+}
+
+// priorityNonceIterator iterates over a snapshot of the txs of the
+// PriorityNonceMempool.
+type priorityNonceIterator[T transaction.Tx] struct {
+	queues  [][]*mempoolTx[T]
+	heads   txHeap[T] // the next tx of each sender
+	current txHead[T]
+}
+
+func (it *priorityNonceIterator[T]) Next() Iterator[T] {
+	queue := it.queues[it.current.queue]
+	if next := it.current.pos + 1; next < len(queue) {
+		heap.Push(&it.heads, txHead[T]{tx: queue[next], queue: it.current.queue, pos: next})
+	}
+	if len(it.heads) == 0 {
+		return nil
+	}
+	it.current = heap.Pop(&it.heads).(txHead[T])
+
+	return it
+}
+
+func (it *priorityNonceIterator[T]) Tx() T {
+	return it.current.tx.tx
+}
+
+// txHead is the next tx of a sender in the iterator.
+type txHead[T transaction.Tx] struct {
+	tx    *mempoolTx[T]
+	queue int
+	pos   int
+}
+
+// txHeap is a heap of the next tx of each sender, ordered by selection order.
+type txHeap[T transaction.Tx] []txHead[T]
+
+func (h txHeap[T]) Len() int           { return len(h) }
+func (h txHeap[T]) Less(i, j int) bool { return compareSelection(h[i].tx, h[j].tx) < 0 }
+func (h txHeap[T]) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *txHeap[T]) Push(x any)        { *h = append(*h, x.(txHead[T])) }
+
+func (h *txHeap[T]) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// compareSelection compares the txs by selection order: the txs with a higher
+// priority, then the txs inserted first, are selected first.
+func compareSelection[T transaction.Tx](a, b *mempoolTx[T]) int {
+	if c := cmp.Compare(b.info.Priority, a.info.Priority); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+func compareNonce[T transaction.Tx](mt *mempoolTx[T], nonce uint64) int {
+	return cmp.Compare(mt.info.Nonce, nonce)
+}
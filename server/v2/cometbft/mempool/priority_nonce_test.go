@@ -0,0 +1,175 @@
+package mempool
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cosmossdk.io/core/transaction"
+)
+
+type testTx struct {
+	sender   string
+	nonce    uint64
+	priority int64
+	size     int
+}
+
+func (tx testTx) Hash() [32]byte                              { return sha256.Sum256(tx.Bytes()) }
+func (tx testTx) GetMessages() ([]transaction.Msg, error)     { return nil, nil }
+func (tx testTx) GetSenders() ([]transaction.Identity, error) { return nil, nil }
+func (tx testTx) GetGasLimit() (uint64, error)                { return 0, nil }
+
+func (tx testTx) Bytes() []byte {
+	bz := []byte(fmt.Sprintf("%s/%d/%d", tx.sender, tx.nonce, tx.priority))
+	if tx.size > len(bz) {
+		bz = append(bz, make([]byte, tx.size-len(bz))...)
+	}
+	return bz
+}
+
+func testTxInfo(_ context.Context, tx testTx) (TxInfo, error) {
+	return TxInfo{Sender: tx.sender, Nonce: tx.nonce, Priority: tx.priority}, nil
+}
+
+func selectAll(mp Mempool[testTx]) []testTx {
+	var txs []testTx
+	for it := mp.Select(context.Background(), nil); it != nil; it = it.Next() {
+		txs = append(txs, it.Tx())
+	}
+	return txs
+}
+
+func requireTxs(t *testing.T, expected, actual []testTx) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d txs %v, got %d txs %v", len(expected), expected, len(actual), actual)
+	}
+	for i := range expected {
+		if expected[i] != actual[i] {
+			t.Fatalf("expected tx %d to be %v, got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestPriorityNonceMempool_Select(t *testing.T) {
+	mp := NewPriorityNonceMempool(Config{}, testTxInfo)
+	if it := mp.Select(context.Background(), nil); it != nil {
+		t.Fatal("expected a nil iterator for an empty mempool")
+	}
+
+	txs := []testTx{
+		{sender: "a", nonce: 1, priority: 10},
+		{sender: "a", nonce: 0, priority: 1},
+		{sender: "b", nonce: 0, priority: 5},
+		{sender: "c", nonce: 0, priority: 5},
+		{sender: "b", nonce: 1, priority: 20},
+	}
+	for _, tx := range txs {
+		if err := mp.Insert(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the txs of a sender are sequenced by nonce, the txs with the same priority
+	// are ordered by insertion
+	requireTxs(t, []testTx{txs[2], txs[4], txs[3], txs[1], txs[0]}, selectAll(mp))
+
+	// inserting the same tx again is a no-op
+	if err := mp.Insert(context.Background(), txs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if mp.CountTx() != len(txs) {
+		t.Fatalf("expected %d txs, got %d", len(txs), mp.CountTx())
+	}
+
+	// a tx with the same sender and nonce replaces the tx with a lower priority
+	err := mp.Insert(context.Background(), testTx{sender: "c", nonce: 0, priority: 5, size: 64})
+	if !errors.Is(err, ErrTxReplacement) {
+		t.Fatalf("expected ErrTxReplacement, got %v", err)
+	}
+	replacement := testTx{sender: "c", nonce: 0, priority: 30}
+	if err := mp.Insert(context.Background(), replacement); err != nil {
+		t.Fatal(err)
+	}
+	requireTxs(t, []testTx{replacement, txs[2], txs[4], txs[1], txs[0]}, selectAll(mp))
+
+	// removing the txs does not affect a running iterator
+	it := mp.Select(context.Background(), nil)
+	if err := mp.Remove([]testTx{replacement, txs[2], {sender: "d"}}); err != nil {
+		t.Fatal(err)
+	}
+	var iterated []testTx
+	for ; it != nil; it = it.Next() {
+		iterated = append(iterated, it.Tx())
+	}
+	requireTxs(t, []testTx{replacement, txs[2], txs[4], txs[1], txs[0]}, iterated)
+	requireTxs(t, []testTx{txs[4], txs[1], txs[0]}, selectAll(mp))
+}
+
+func TestPriorityNonceMempool_Eviction(t *testing.T) {
+	mp := NewPriorityNonceMempool(Config{MaxTxs: 3}, testTxInfo)
+	txs := []testTx{
+		{sender: "a", nonce: 0, priority: 10},
+		{sender: "a", nonce: 1, priority: 1},
+		{sender: "b", nonce: 0, priority: 5},
+	}
+	for _, tx := range txs {
+		if err := mp.Insert(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a tx with a priority not higher than the evictable txs is rejected
+	err := mp.Insert(context.Background(), testTx{sender: "c", nonce: 0, priority: 1})
+	if !errors.Is(err, ErrMempoolTxMaxCapacity) {
+		t.Fatalf("expected ErrMempoolTxMaxCapacity, got %v", err)
+	}
+
+	// the lowest priority tx with the highest nonce of its sender is evicted
+	high := testTx{sender: "c", nonce: 0, priority: 7}
+	if err := mp.Insert(context.Background(), high); err != nil {
+		t.Fatal(err)
+	}
+	requireTxs(t, []testTx{txs[0], high, txs[2]}, selectAll(mp))
+
+	// the tx with the lowest priority, a[0], can not be evicted before a[1]
+	err = mp.Insert(context.Background(), testTx{sender: "b", nonce: 1, priority: 6})
+	if !errors.Is(err, ErrMempoolTxMaxCapacity) {
+		t.Fatalf("expected ErrMempoolTxMaxCapacity, got %v", err)
+	}
+
+	// the byte limit evicts as many txs as needed
+	mp = NewPriorityNonceMempool(Config{MaxBytes: 100}, testTxInfo)
+	for _, tx := range []testTx{
+		{sender: "a", nonce: 0, priority: 1, size: 40},
+		{sender: "b", nonce: 0, priority: 2, size: 40},
+	} {
+		if err := mp.Insert(context.Background(), tx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	big := testTx{sender: "c", nonce: 0, priority: 3, size: 90}
+	if err := mp.Insert(context.Background(), big); err != nil {
+		t.Fatal(err)
+	}
+	requireTxs(t, []testTx{big}, selectAll(mp))
+	if mp.Bytes() != 90 {
+		t.Fatalf("expected 90 bytes, got %d", mp.Bytes())
+	}
+	err = mp.Insert(context.Background(), testTx{sender: "d", nonce: 0, priority: 10, size: 101})
+	if !errors.Is(err, ErrMempoolTxMaxCapacity) {
+		t.Fatalf("expected ErrMempoolTxMaxCapacity, got %v", err)
+	}
+
+	// a negative max txs disables the mempool
+	mp = NewPriorityNonceMempool(Config{MaxTxs: -1}, testTxInfo)
+	if err := mp.Insert(context.Background(), big); err != nil {
+		t.Fatal(err)
+	}
+	if mp.CountTx() != 0 {
+		t.Fatalf("expected an empty mempool, got %d txs", mp.CountTx())
+	}
+}
@@ -11,10 +11,12 @@ import (
 // ServerOptions defines the options for the CometBFT server.
 type ServerOptions[T transaction.Tx] struct {
 	Mempool                    mempool.Mempool[T]
-	PrepareProposalHandler     handlers.PrepareHandler[T]
+	PrepareProposalHandler     handlers.PrepareHandler[T] // custom prepare proposal handler, can not be set with a tx selector other than default
 	ProcessProposalHandler     handlers.ProcessHandler[T]
 	VerifyVoteExtensionHandler handlers.VerifyVoteExtensionhandler
 	ExtendVoteHandler          handlers.ExtendVoteHandler
+	ValidatorStore             handlers.ValidatorStore // verifies the vote extension signatures of the proposals, required when vote extensions are enabled
+	TxFee                      handlers.TxFeeFunc[T]   // returns the fees of the txs, required by the fee-density tx selector
 
 	SnapshotOptions snapshots.SnapshotOptions
 
@@ -23,14 +25,20 @@ type ServerOptions[T transaction.Tx] struct {
 }
 
 // DefaultServerOptions returns the default server options.
-// It defaults to a NoOpMempool and NoOp handlers.
+// It defaults to a NoOpMempool and NoOp proposal handlers, the prepare proposal
+// handler being the default proposal handler of the app mempool when a tx
+// selector other than default is configured. The vote extensions are produced
+// and verified by the modules of the app. A ValidatorStore must be set when vote
+// extensions are enabled, otherwise the server fails to start.
 func DefaultServerOptions[T transaction.Tx]() ServerOptions[T] {
 	return ServerOptions[T]{
 		Mempool:                    mempool.NoOpMempool[T]{},
-		PrepareProposalHandler:     handlers.NoOpPrepareProposal[T](),
+		PrepareProposalHandler:     nil,
 		ProcessProposalHandler:     handlers.NoOpProcessProposal[T](),
-		VerifyVoteExtensionHandler: handlers.NoOpVerifyVoteExtensionHandler(),
-		ExtendVoteHandler:          handlers.NoOpExtendVote(),
+		VerifyVoteExtensionHandler: nil,
+		ExtendVoteHandler:          nil,
+		ValidatorStore:             nil,
+		TxFee:                      nil,
 		SnapshotOptions:            snapshots.NewSnapshotOptions(0, 0),
 		AddrPeerFilter:             nil,
 		IdPeerFilter:               nil,
@@ -1,7 +1,9 @@
 package cometbft
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"strings"
 
 	abci "github.com/cometbft/cometbft/api/cometbft/abci/v1"
@@ -10,6 +12,9 @@ import (
 	errorsmod "cosmossdk.io/errors"
 	"cosmossdk.io/server/v2/cometbft/types"
 	cometerrors "cosmossdk.io/server/v2/cometbft/types/errors"
+	storev2 "cosmossdk.io/store/v2"
+	storeerrors "cosmossdk.io/store/v2/errors"
+	"cosmossdk.io/store/v2/proof"
 )
 
 func (c *Consensus[T]) handleQueryP2P(path []string) (*abci.QueryResponse, error) {
@@ -84,6 +89,12 @@ func (c *Consensus[T]) handlerQueryApp(ctx context.Context, path []string, req *
 	return nil, errorsmod.Wrapf(cometerrors.ErrUnknownRequest, "unknown query: %s", path)
 }
 
+// handleQueryStore handles the store queries, the path is either
+// "/store/<storeName>/key" to query the key in the request data,
+// "/store/<storeName>/keys" to query the keys encoded as kv.Pairs in the request
+// data, or "/store/<storeName>/subspace" to query the keys prefixed by the
+// request data. The pairs of the batch and subspace queries are returned encoded
+// as kv.Pairs, along with their batch proof when requested.
 func (c *Consensus[T]) handleQueryStore(path []string, _ types.Store, req *abci.QueryRequest) (*abci.QueryResponse, error) {
 	req.Path = "/" + strings.Join(path[1:], "/")
 	if req.Height <= 1 && req.Prove {
@@ -96,6 +107,10 @@ func (c *Consensus[T]) handleQueryStore(path []string, _ types.Store, req *abci.
 	// "/store/<storeName>" for store queries
 	storeName := path[1]
 	storeNameBz := []byte(storeName) // TODO fastpath?
+	if len(path) > 2 && (path[2] == "keys" || path[2] == "subspace") {
+		return c.handleQueryStoreBatch(path[2], storeNameBz, req)
+	}
+
 	qRes, err := c.store.Query(storeNameBz, uint64(req.Height), req.Data, req.Prove)
 	if err != nil {
 		return nil, err
@@ -109,23 +124,108 @@ func (c *Consensus[T]) handleQueryStore(path []string, _ types.Store, req *abci.
 	}
 
 	if req.Prove {
-		for _, proof := range qRes.ProofOps {
-			bz, err := proof.Proof.Marshal()
-			if err != nil {
-				return nil, errorsmod.Wrap(err, "failed to marshal proof")
-			}
+		res.ProofOps, err = intoABCIProofOps(qRes.ProofOps)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-			res.ProofOps = &crypto.ProofOps{
-				Ops: []crypto.ProofOp{
-					{
-						Type: proof.Type,
-						Key:  proof.Key,
-						Data: bz,
-					},
-				},
-			}
+	return res, nil
+}
+
+// handleQueryStoreBatch handles the "keys" and "subspace" store queries. The
+// queries of more pairs than the max-query-pairs config fail, so that a query
+// e.g. of the whole store with an empty subspace is bounded.
+func (c *Consensus[T]) handleQueryStoreBatch(queryType string, storeName []byte, req *abci.QueryRequest) (*abci.QueryResponse, error) {
+	querier, ok := c.store.(storev2.BatchQuerier)
+	if !ok {
+		return nil, errorsmod.Wrapf(cometerrors.ErrUnknownRequest, "store does not support %s queries", queryType)
+	}
+
+	var (
+		qRes     storev2.BatchQueryResult
+		err      error
+		maxPairs = c.cfg.AppTomlConfig.MaxQueryPairs
+	)
+	switch queryType {
+	case "keys":
+		pairs, decodeErr := proof.UnmarshalKVPairs(req.Data)
+		if decodeErr != nil {
+			return nil, errorsmod.Wrap(cometerrors.ErrInvalidRequest, "failed to decode the keys")
+		}
+		if maxPairs > 0 && len(pairs) > maxPairs {
+			return nil, errorsmod.Wrapf(cometerrors.ErrInvalidRequest, "the query contains %d keys, the maximum is %d", len(pairs), maxPairs)
+		}
+		keys := make([][]byte, len(pairs))
+		for i, pair := range pairs {
+			keys[i] = pair.Key
+		}
+		qRes, err = querier.QueryBatch(storeName, uint64(req.Height), keys, req.Prove)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		qRes, err = querier.QueryRange(storeName, uint64(req.Height), req.Data, prefixEndBytes(req.Data), maxPairs, req.Prove)
+		if errors.Is(err, storeerrors.ErrTooManyPairs) {
+			return nil, errorsmod.Wrapf(cometerrors.ErrInvalidRequest, "the subspace contains more than %d pairs, query a longer prefix", maxPairs)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := &abci.QueryResponse{
+		Codespace: cometerrors.RootCodespace,
+		Height:    int64(qRes.Version),
+		Key:       req.Data,
+		Value:     proof.MarshalKVPairs(qRes.Pairs),
+	}
+
+	if req.Prove {
+		res.ProofOps, err = intoABCIProofOps(qRes.ProofOps)
+		if err != nil {
+			return nil, err
 		}
 	}
 
 	return res, nil
 }
+
+// intoABCIProofOps converts the store proof ops into ABCI proof ops.
+func intoABCIProofOps(ops []proof.CommitmentOp) (*crypto.ProofOps, error) {
+	proofOps := &crypto.ProofOps{Ops: make([]crypto.ProofOp, 0, len(ops))}
+	for _, op := range ops {
+		bz, err := op.Proof.Marshal()
+		if err != nil {
+			return nil, errorsmod.Wrap(err, "failed to marshal proof")
+		}
+
+		proofOps.Ops = append(proofOps.Ops, crypto.ProofOp{
+			Type: op.Type,
+			Key:  op.Key,
+			Data: bz,
+		})
+	}
+
+	return proofOps, nil
+}
+
+// prefixEndBytes returns the end of the range of the keys with the given
+// prefix, or nil if the range is unbounded.
+func prefixEndBytes(prefix []byte) []byte {
+	if len(prefix) == 0 {
+		return nil
+	}
+
+	end := bytes.Clone(prefix)
+	for len(end) > 0 {
+		if end[len(end)-1] != 0xff {
+			end[len(end)-1]++
+			return end
+		}
+		end = end[:len(end)-1]
+	}
+
+	return nil
+}
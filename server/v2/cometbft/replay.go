@@ -0,0 +1,265 @@
+package cometbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cmtcfg "github.com/cometbft/cometbft/config"
+	sm "github.com/cometbft/cometbft/state"
+	cmtstore "github.com/cometbft/cometbft/store"
+	cmttypes "github.com/cometbft/cometbft/types"
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/core/comet"
+	"cosmossdk.io/core/event"
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/core/store"
+	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/server/v2/cometbft/types"
+	storev2 "cosmossdk.io/store/v2"
+)
+
+// ReplayBlockCmd returns a command replaying a block of the CometBFT block store
+// against the app state of the previous height, to debug app hash mismatches.
+// Unlike the CLICommands, it needs to create the app, so it must be added to the
+// root command by the application.
+func (s *CometBFTServer[T]) ReplayBlockCmd(newApp serverv2.AppCreator[T]) *cobra.Command {
+	return &cobra.Command{
+		Use:   "replay-block <height>",
+		Short: "Replay a committed block and print its state changes, gas usage and events",
+		Long: `Replay a block of the CometBFT block store against the app state of the previous height, without committing anything.
+The key-level state changes of each step of the block are printed along with the gas usage and the events of its transactions,
+and the resulting app hash is compared to the one recorded by CometBFT.
+
+The app hash can only be computed when the previous height is the latest height of the app state, which is the case
+when the node halted on an app hash mismatch of the block. The replayed changes are discarded once the app hash is
+computed, the app state is left untouched. The node must be stopped while replaying a block.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			height, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid height %s: %w", args[0], err)
+			}
+
+			v := serverv2.GetViperFromCmd(cmd)
+			app := newApp(serverv2.GetLoggerFromCmd(cmd), v)
+
+			return s.replayBlock(cmd, app, getConfigTomlFromViper(v), height)
+		},
+	}
+}
+
+// replayBlock replays the block at the given height and prints its results.
+func (s *CometBFTServer[T]) replayBlock(cmd *cobra.Command, app serverv2.AppI[T], cfg *cmtcfg.Config, height int64) error {
+	appStore, ok := app.GetStore().(types.Store)
+	if !ok {
+		return fmt.Errorf("unsupported app store %T", app.GetStore())
+	}
+
+	blockStoreDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "blockstore", Config: cfg})
+	if err != nil {
+		return fmt.Errorf("unable to open the block store: %w", err)
+	}
+	defer blockStoreDB.Close()
+	blockStore := cmtstore.NewBlockStore(blockStoreDB)
+
+	stateDB, err := cmtcfg.DefaultDBProvider(&cmtcfg.DBContext{ID: "state", Config: cfg})
+	if err != nil {
+		return fmt.Errorf("unable to open the state store: %w", err)
+	}
+	defer stateDB.Close()
+	stateStore := sm.NewStore(stateDB, sm.StoreOptions{})
+
+	state, err := stateStore.Load()
+	if err != nil {
+		return fmt.Errorf("unable to load the state: %w", err)
+	}
+	// the block at the initial height is not executed, see FinalizeBlock
+	if height <= state.InitialHeight {
+		return fmt.Errorf("cannot replay block %d, it must be higher than the initial height %d", height, state.InitialHeight)
+	}
+
+	block, _ := blockStore.LoadBlock(height)
+	if block == nil {
+		return fmt.Errorf("block %d not found, the block store contains the blocks %d to %d", height, blockStore.Base(), blockStore.Height())
+	}
+	lastValSet, err := stateStore.LoadValidators(height - 1)
+	if err != nil {
+		return fmt.Errorf("unable to load the validators of height %d: %w", height-1, err)
+	}
+
+	// the consensus params are taken from the state of the previous height, like
+	// in FinalizeBlock
+	cp, err := queryConsensusParams(cmd.Context(), app.GetAppManager(), uint64(height-1))
+	if err != nil {
+		return err
+	}
+
+	rawTxs := block.Txs.ToSliceOfBytes()
+	var extCommit *comet.ExtendedCommitInfo
+	if voteExtensionsEnabled(cp, height-1) {
+		abciExtCommit, err := extendedCommitInfoFromTxs(rawTxs)
+		if err != nil {
+			return err
+		}
+		coreExtCommit := toCoreVoteExtensions(abciExtCommit)
+		extCommit = &coreExtCommit
+		rawTxs = rawTxs[1:]
+	}
+
+	decodedTxs, err := decodeTxs(rawTxs, s.initTxCodec)
+	if err != nil {
+		return err
+	}
+
+	blockReq := &server.BlockRequest[T]{
+		Height:  uint64(height),
+		Time:    block.Time,
+		Hash:    block.Hash(),
+		AppHash: block.AppHash,
+		ChainId: block.ChainID,
+		Txs:     decodedTxs,
+
+		ExtendedCommitInfo: extCommit,
+	}
+
+	ciCtx := contextWithCometInfo(cmd.Context(), comet.Info{
+		Evidence:        toCoreEvidence(block.Evidence.Evidence.ToABCI()),
+		ValidatorsHash:  block.NextValidatorsHash,
+		ProposerAddress: block.ProposerAddress,
+		LastCommit:      toCoreCommitInfo(sm.BuildLastCommitInfo(block, lastValSet, state.InitialHeight)),
+	})
+
+	resp, newState, trace, err := app.GetAppManager().ReplayBlock(ciCtx, blockReq)
+	if err != nil {
+		return err
+	}
+
+	cmd.Printf("block %d %X with %d txs\n", height, block.Hash(), len(block.Txs))
+	printReplayStep(cmd, "begin block", append(resp.PreBlockEvents, resp.BeginBlockEvents...), trace.BeginBlock)
+	offset := 0
+	if extCommit != nil {
+		offset = 1
+		cmd.Printf("tx 0 %X: extended commit info of the previous block, not executed\n", cmttypes.Tx(block.Txs[0]).Hash())
+	}
+	for i, txResult := range resp.TxResults {
+		printReplayTx(cmd, i+offset, block.Txs[i+offset], txResult, trace.Txs[i])
+	}
+	printReplayStep(cmd, fmt.Sprintf("end block with %d validator updates", len(resp.ValidatorUpdates)), resp.EndBlockEvents, trace.EndBlock)
+
+	return printReplayAppHash(cmd, appStore, stateStore, blockStore, height, newState)
+}
+
+// printReplayAppHash computes the app hash of the replayed block and compares it
+// to the app hashes recorded by CometBFT.
+func printReplayAppHash(
+	cmd *cobra.Command,
+	appStore types.Store,
+	stateStore sm.Store,
+	blockStore *cmtstore.BlockStore,
+	height int64,
+	newState store.WriterMap,
+) error {
+	latestVersion, err := appStore.GetLatestVersion()
+	if err != nil {
+		return err
+	}
+	if latestVersion != uint64(height-1) {
+		cmd.Printf("app hash not computed, the latest height of the app state is %d instead of %d\n", latestVersion, height-1)
+		return nil
+	}
+
+	sc := appStore.GetStateCommitment()
+	rollbackable, ok := sc.(storev2.RollbackableCommitter)
+	if !ok {
+		cmd.Printf("app hash not computed, the state commitment %T can not discard the replayed changes\n", sc)
+		return nil
+	}
+	stateChanges, err := newState.GetStateChanges()
+	if err != nil {
+		return err
+	}
+
+	// the changes are written to the working trees of the state commitment to
+	// compute the app hash, then discarded
+	var appHash []byte
+	err = sc.WriteChangeset(&store.Changeset{Changes: stateChanges})
+	if err == nil {
+		appHash = sc.WorkingCommitInfo(uint64(height)).Hash()
+	}
+	if rollbackErr := rollbackable.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("unable to discard the replayed changes: %w", errors.Join(err, rollbackErr))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write the changeset: %w", err)
+	}
+	cmd.Printf("app hash %X\n", appHash)
+
+	compare := func(source string, recorded []byte) {
+		result := "match"
+		if !bytes.Equal(appHash, recorded) {
+			result = "MISMATCH"
+		}
+		cmd.Printf("  %s: %X %s\n", source, recorded, result)
+	}
+	if res, err := stateStore.LoadFinalizeBlockResponse(height); err == nil {
+		compare("finalize block response of the node", res.AppHash)
+	}
+	if next, _ := blockStore.LoadBlock(height + 1); next != nil {
+		compare(fmt.Sprintf("header of block %d", height+1), next.AppHash)
+	}
+
+	return nil
+}
+
+// printReplayTx prints the result and the state changes of a replayed tx.
+func printReplayTx(cmd *cobra.Command, index int, rawTx []byte, txResult server.TxResult, diff []server.StateDiff) {
+	cmd.Printf("tx %d %X: gas used %d of %d\n", index, cmttypes.Tx(rawTx).Hash(), txResult.GasUsed, txResult.GasWanted)
+	if txResult.Error != nil {
+		cmd.Printf("  failed with code %d: %v\n", txResult.Code, txResult.Error)
+	}
+	printReplayEvents(cmd, txResult.Events)
+	printReplayStateDiff(cmd, diff)
+}
+
+// printReplayStep prints the events and the state changes of a step of a
+// replayed block.
+func printReplayStep(cmd *cobra.Command, step string, events []event.Event, diff []server.StateDiff) {
+	cmd.Printf("%s:\n", step)
+	printReplayEvents(cmd, events)
+	printReplayStateDiff(cmd, diff)
+}
+
+func printReplayEvents(cmd *cobra.Command, events []event.Event) {
+	if len(events) == 0 {
+		return
+	}
+	cmd.Println("  events:")
+	for _, e := range events {
+		attrs := make([]string, len(e.Attributes))
+		for i, attr := range e.Attributes {
+			attrs[i] = attr.Key + "=" + attr.Value
+		}
+		cmd.Printf("    %s %s\n", e.Type, strings.Join(attrs, " "))
+	}
+}
+
+func printReplayStateDiff(cmd *cobra.Command, diff []server.StateDiff) {
+	if len(diff) == 0 {
+		return
+	}
+	cmd.Println("  state changes:")
+	for _, d := range diff {
+		previous, value := fmt.Sprintf("%X", d.Previous), fmt.Sprintf("%X", d.Value)
+		if d.Previous == nil {
+			previous = "<none>"
+		}
+		if d.Remove {
+			value = "<removed>"
+		}
+		cmd.Printf("    %s %X: %s -> %s\n", d.Actor, d.Key, previous, value)
+	}
+}
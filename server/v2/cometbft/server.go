@@ -4,11 +4,15 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math"
 	"os"
 	"path/filepath"
 
 	abciserver "github.com/cometbft/cometbft/abci/server"
+	cmtproto "github.com/cometbft/cometbft/api/cometbft/types/v1"
 	cmtcmd "github.com/cometbft/cometbft/cmd/cometbft/commands"
 	cmtcfg "github.com/cometbft/cometbft/config"
 	"github.com/cometbft/cometbft/node"
@@ -22,7 +26,10 @@ import (
 	"cosmossdk.io/core/transaction"
 	"cosmossdk.io/log"
 	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/server/v2/appmanager"
+	"cosmossdk.io/server/v2/cometbft/handlers"
 	cometlog "cosmossdk.io/server/v2/cometbft/log"
+	"cosmossdk.io/server/v2/cometbft/mempool"
 	"cosmossdk.io/server/v2/cometbft/types"
 	"cosmossdk.io/store/v2/snapshots"
 
@@ -105,9 +112,57 @@ func (s *CometBFTServer[T]) Init(appI serverv2.AppI[T], v *viper.Viper, logger l
 		chainID,
 	)
 	consensus.prepareProposalHandler = s.serverOptions.PrepareProposalHandler
+	if strategy := s.config.AppTomlConfig.TxSelector.Strategy; strategy != "" && strategy != handlers.TxSelectorDefault {
+		// the configured tx selector selects the txs of the app mempool with the
+		// default proposal handler, which would replace a custom handler
+		if s.serverOptions.PrepareProposalHandler != nil {
+			return fmt.Errorf("the %s tx selector can not be used with a custom prepare proposal handler", strategy)
+		}
+		if _, ok := s.serverOptions.Mempool.(mempool.NoOpMempool[T]); ok || s.serverOptions.Mempool == nil {
+			return fmt.Errorf("the %s tx selector requires an app mempool, set the Mempool server option", strategy)
+		}
+		txSelector, err := handlers.NewTxSelector[T](s.config.AppTomlConfig.TxSelector, s.serverOptions.TxFee)
+		if err != nil {
+			return fmt.Errorf("invalid tx selector config: %w", err)
+		}
+		proposalHandler := handlers.NewDefaultProposalHandler(s.serverOptions.Mempool)
+		proposalHandler.SetTxSelector(txSelector)
+		consensus.prepareProposalHandler = proposalHandler.PrepareHandler()
+		s.logger.Info("using the default prepare proposal handler", "tx-selector", strategy)
+	} else if consensus.prepareProposalHandler == nil {
+		consensus.prepareProposalHandler = handlers.NoOpPrepareProposal[T]()
+	}
 	consensus.processProposalHandler = s.serverOptions.ProcessProposalHandler
 	consensus.verifyVoteExt = s.serverOptions.VerifyVoteExtensionHandler
 	consensus.extendVote = s.serverOptions.ExtendVoteHandler
+	if app, ok := appI.(handlers.VoteExtender); ok {
+		// the modules of the app handle the vote extensions unless overridden
+		if consensus.verifyVoteExt == nil {
+			consensus.verifyVoteExt = handlers.NewVerifyVoteExtensionHandler(app)
+		}
+		if consensus.extendVote == nil {
+			consensus.extendVote = handlers.NewExtendVoteHandler(app)
+		}
+	}
+	if consensus.verifyVoteExt == nil {
+		consensus.verifyVoteExt = handlers.NoOpVerifyVoteExtensionHandler()
+	}
+	if consensus.extendVote == nil {
+		consensus.extendVote = handlers.NoOpExtendVote()
+	}
+	consensus.valStore = s.serverOptions.ValidatorStore
+	if consensus.valStore == nil {
+		// the proposals carrying vote extensions cannot be verified, which would
+		// halt the chain
+		enabled, err := voteExtensionsConfigured(appI.GetAppManager(), store, filepath.Join(v.GetString(serverv2.FlagHome), "config", "genesis.json"))
+		if err != nil {
+			return err
+		}
+		if enabled {
+			return errors.New("vote extensions are enabled but no validator store is set, set the ValidatorStore server option")
+		}
+		s.logger.Warn("no validator store set, the proposals will be rejected if vote extensions are enabled")
+	}
 	consensus.addrPeerFilter = s.serverOptions.AddrPeerFilter
 	consensus.idPeerFilter = s.serverOptions.IdPeerFilter
 
@@ -118,6 +173,7 @@ func (s *CometBFTServer[T]) Init(appI serverv2.AppI[T], v *viper.Viper, logger l
 	if err != nil {
 		return err
 	}
+	snapshotStore.SetChunkDedup(s.serverOptions.SnapshotOptions.ChunkDedup)
 	consensus.snapshotManager = snapshots.NewManager(snapshotStore, s.serverOptions.SnapshotOptions, sc, ss, nil, s.logger)
 
 	s.Consensus = consensus
@@ -125,6 +181,38 @@ func (s *CometBFTServer[T]) Init(appI serverv2.AppI[T], v *viper.Viper, logger l
 	return nil
 }
 
+// voteExtensionsConfigured returns whether the vote extensions are enabled at
+// some height by the consensus params of the latest committed state, or of the
+// genesis before the first block.
+func voteExtensionsConfigured[T transaction.Tx](app *appmanager.AppManager[T], store types.Store, genesisPath string) (bool, error) {
+	version, err := store.GetLatestVersion()
+	if err != nil {
+		return false, err
+	}
+
+	var cp *cmtproto.ConsensusParams
+	if version > 0 {
+		if cp, err = queryConsensusParams(context.Background(), app, version); err != nil {
+			return false, fmt.Errorf("failed to query the consensus params: %w", err)
+		}
+	} else {
+		appGenesis, err := genutiltypes.AppGenesisFromFile(genesisPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if appGenesis.Consensus == nil || appGenesis.Consensus.Params == nil {
+			return false, nil
+		}
+		params := appGenesis.Consensus.Params.ToProto()
+		cp = &params
+	}
+
+	return voteExtensionsEnabled(cp, math.MaxInt64), nil
+}
+
 func (s *CometBFTServer[T]) Name() string {
 	return ServerName
 }
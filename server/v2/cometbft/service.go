@@ -68,3 +68,26 @@ func toCoreExtendedCommitInfo(commit abci.ExtendedCommitInfo) comet.CommitInfo {
 
 	return ci
 }
+
+// toCoreVoteExtensions takes comet extended commit info and returns sdk extended
+// commit info with the vote extensions
+func toCoreVoteExtensions(commit abci.ExtendedCommitInfo) comet.ExtendedCommitInfo {
+	ci := comet.ExtendedCommitInfo{
+		Round: commit.Round,
+		Votes: make([]comet.ExtendedVoteInfo, len(commit.Votes)),
+	}
+
+	for i, v := range commit.Votes {
+		ci.Votes[i] = comet.ExtendedVoteInfo{
+			Validator: comet.Validator{
+				Address: v.Validator.Address,
+				Power:   v.Validator.Power,
+			},
+			VoteExtension:      v.VoteExtension,
+			ExtensionSignature: v.ExtensionSignature,
+			BlockIDFlag:        comet.BlockIDFlag(v.BlockIdFlag),
+		}
+	}
+
+	return ci
+}
@@ -78,6 +78,11 @@ func (c *Consensus[T]) ListSnapshots(_ context.Context, ctx *abci.ListSnapshotsR
 
 	resp := &abci.ListSnapshotsResponse{}
 	for _, snapshot := range snapshots {
+		// a delta snapshot can only be restored on top of its base snapshots,
+		// which a syncing node doesn't have
+		if snapshot.Format == snapshottypes.DeltaFormat {
+			continue
+		}
 		abciSnapshot, err := snapshotToABCI(snapshot)
 		if err != nil {
 			c.logger.Error("failed to convert ABCI snapshots", "err", err)
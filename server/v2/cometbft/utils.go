@@ -23,6 +23,7 @@ import (
 	"cosmossdk.io/core/server"
 	"cosmossdk.io/core/transaction"
 	errorsmod "cosmossdk.io/errors"
+	"cosmossdk.io/server/v2/appmanager"
 	consensus "cosmossdk.io/x/consensus/types"
 )
 
@@ -289,6 +290,18 @@ func (c *Consensus[T]) validateFinalizeBlockHeight(req *abci.FinalizeBlockReques
 	return nil
 }
 
+// voteExtensionsEnabled returns whether the votes of the block at the given
+// height are extended. Since Abci was deprecated, both the Feature and the Abci
+// params are checked.
+func voteExtensionsEnabled(cp *cmtproto.ConsensusParams, height int64) bool {
+	if cp.Feature != nil && cp.Feature.VoteExtensionsEnableHeight != nil && cp.Feature.VoteExtensionsEnableHeight.Value != 0 &&
+		height >= cp.Feature.VoteExtensionsEnableHeight.Value {
+		return true
+	}
+	// check abci params
+	return cp.Abci != nil && cp.Abci.VoteExtensionsEnableHeight != 0 && height >= cp.Abci.VoteExtensionsEnableHeight
+}
+
 // GetConsensusParams makes a query to the consensus module in order to get the latest consensus
 // parameters from committed state
 func (c *Consensus[T]) GetConsensusParams(ctx context.Context) (*cmtproto.ConsensusParams, error) {
@@ -297,7 +310,17 @@ func (c *Consensus[T]) GetConsensusParams(ctx context.Context) (*cmtproto.Consen
 		return nil, err
 	}
 
-	res, err := c.app.Query(ctx, latestVersion, &consensus.QueryParamsRequest{})
+	return queryConsensusParams(ctx, c.app, latestVersion)
+}
+
+// queryConsensusParams makes a query to the consensus module in order to get the
+// consensus parameters from the committed state at the given version
+func queryConsensusParams[T transaction.Tx](
+	ctx context.Context,
+	app *appmanager.AppManager[T],
+	version uint64,
+) (*cmtproto.ConsensusParams, error) {
+	res, err := app.Query(ctx, version, &consensus.QueryParamsRequest{})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,239 @@
+package stf
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"cosmossdk.io/core/header"
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/core/store"
+	"cosmossdk.io/core/transaction"
+)
+
+// deliverTxsParallel executes the txs of a block optimistically in parallel, in
+// the style of Block-STM. Every tx is first executed speculatively on its own
+// branch of the state, recording the keys and the ranges it reads from the state
+// of the block. The txs are then committed in block order: a tx whose reads were
+// written by a tx committed before it is re-executed on the committed state, so
+// that the results and the new state are identical to the sequential execution.
+//
+// The state must support concurrent reads, which is the case of the default
+// branch implementation as long as it is not written to.
+func (s STF[T]) deliverTxsParallel(
+	ctx context.Context,
+	state store.WriterMap,
+	txs []T,
+	hi header.Info,
+) ([]server.TxResult, error) {
+	// execute the txs speculatively on the state as it is before the txs
+	execs := make([]txExecution, len(txs))
+	baseState := &syncReaderMap{parent: state}
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < min(s.parallelWorkers, len(txs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				execs[i] = s.executeTx(ctx, baseState, txs[i], hi, readSet{})
+			}
+		}()
+	}
+
+	var err error
+	for i := range txs {
+		// check if we need to return early or continue delivering txs
+		if err = isCtxCancelled(ctx); err != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	// commit the txs in block order, re-executing the ones which conflict with
+	// the txs committed before them
+	txResults := make([]server.TxResult, len(txs))
+	written := writeSet{}
+	for i, exec := range execs {
+		if err = isCtxCancelled(ctx); err != nil {
+			return nil, err
+		}
+		if exec.err != nil || written.conflicts(exec.reads) {
+			exec = s.executeTx(ctx, state, txs[i], hi, nil)
+			if exec.err != nil {
+				return nil, exec.err
+			}
+		}
+		if err = state.ApplyStateChanges(exec.changes); err != nil {
+			return nil, err
+		}
+		written.add(exec.changes)
+		txResults[i] = exec.result
+	}
+
+	return txResults, nil
+}
+
+// txExecution is the result of the execution of a tx on its own branch.
+type txExecution struct {
+	result  server.TxResult
+	changes []store.StateChanges
+	// reads are the reads of the tx from the state it was executed on, nil
+	// when they were not tracked.
+	reads readSet
+	// err is the error of getting the state changes of the tx.
+	err error
+}
+
+// executeTx executes the tx on a branch of the provided state and returns its
+// result and state changes. The reads of the tx are tracked if reads is not nil.
+func (s STF[T]) executeTx(
+	ctx context.Context,
+	state store.ReaderMap,
+	tx T,
+	hi header.Info,
+	reads readSet,
+) txExecution {
+	if reads != nil {
+		state = trackedReaderMap{parent: state, reads: reads}
+	}
+	txState := s.branchFn(state)
+	result := s.deliverTx(ctx, txState, tx, transaction.ExecModeFinalize, hi)
+	changes, err := txState.GetStateChanges()
+
+	return txExecution{
+		result:  result,
+		changes: changes,
+		reads:   reads,
+		err:     err,
+	}
+}
+
+// readSet records the keys and the ranges read by a tx for each actor.
+type readSet map[string]*actorReads
+
+// actorReads are the keys and the ranges read from the state of an actor.
+type actorReads struct {
+	keys   map[string]struct{}
+	ranges []keyRange
+}
+
+// keyRange is the range [start, end) of an iterator, a nil start or end leaves
+// the range unbounded on that side.
+type keyRange struct {
+	start, end []byte
+}
+
+// contains reports whether the key is in the range.
+func (r keyRange) contains(key []byte) bool {
+	return (r.start == nil || bytes.Compare(key, r.start) >= 0) &&
+		(r.end == nil || bytes.Compare(key, r.end) < 0)
+}
+
+// writeSet records the keys written by the committed txs for each actor.
+type writeSet map[string]map[string]struct{}
+
+// add records the keys of the state changes.
+func (w writeSet) add(changes []store.StateChanges) {
+	for _, sc := range changes {
+		if len(sc.StateChanges) == 0 {
+			continue
+		}
+		keys, ok := w[string(sc.Actor)]
+		if !ok {
+			keys = make(map[string]struct{}, len(sc.StateChanges))
+			w[string(sc.Actor)] = keys
+		}
+		for _, kv := range sc.StateChanges {
+			keys[string(kv.Key)] = struct{}{}
+		}
+	}
+}
+
+// conflicts reports whether any of the reads was written.
+func (w writeSet) conflicts(reads readSet) bool {
+	for actor, ar := range reads {
+		keys := w[actor]
+		if len(keys) == 0 {
+			continue
+		}
+		for key := range ar.keys {
+			if _, ok := keys[key]; ok {
+				return true
+			}
+		}
+		for _, r := range ar.ranges {
+			for key := range keys {
+				if r.contains([]byte(key)) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// syncReaderMap serializes the calls to GetReader of a store.ReaderMap, which
+// may memoize the readers of the actors, so that it can be read concurrently.
+type syncReaderMap struct {
+	mu     sync.Mutex
+	parent store.ReaderMap
+}
+
+func (m *syncReaderMap) GetReader(actor []byte) (store.Reader, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.parent.GetReader(actor)
+}
+
+// trackedReaderMap is a store.ReaderMap recording the reads in a readSet.
+type trackedReaderMap struct {
+	parent store.ReaderMap
+	reads  readSet
+}
+
+func (m trackedReaderMap) GetReader(actor []byte) (store.Reader, error) {
+	reader, err := m.parent.GetReader(actor)
+	if err != nil {
+		return nil, err
+	}
+	ar, ok := m.reads[string(actor)]
+	if !ok {
+		ar = &actorReads{keys: make(map[string]struct{})}
+		m.reads[string(actor)] = ar
+	}
+
+	return trackedReader{parent: reader, reads: ar}, nil
+}
+
+// trackedReader is a store.Reader recording the reads of an actor.
+type trackedReader struct {
+	parent store.Reader
+	reads  *actorReads
+}
+
+func (r trackedReader) Has(key []byte) (bool, error) {
+	r.reads.keys[string(key)] = struct{}{}
+	return r.parent.Has(key)
+}
+
+func (r trackedReader) Get(key []byte) ([]byte, error) {
+	r.reads.keys[string(key)] = struct{}{}
+	return r.parent.Get(key)
+}
+
+func (r trackedReader) Iterator(start, end []byte) (store.Iterator, error) {
+	r.reads.ranges = append(r.reads.ranges, keyRange{start: bytes.Clone(start), end: bytes.Clone(end)})
+	return r.parent.Iterator(start, end)
+}
+
+func (r trackedReader) ReverseIterator(start, end []byte) (store.Iterator, error) {
+	r.reads.ranges = append(r.reads.ranges, keyRange{start: bytes.Clone(start), end: bytes.Clone(end)})
+	return r.parent.ReverseIterator(start, end)
+}
@@ -0,0 +1,172 @@
+package stf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	gogotypes "github.com/cosmos/gogoproto/types"
+
+	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/core/store"
+	"cosmossdk.io/server/v2/stf/branch"
+	"cosmossdk.io/server/v2/stf/gas"
+	"cosmossdk.io/server/v2/stf/mock"
+)
+
+func TestDeliverBlockParallel(t *testing.T) {
+	s := &STF[mock.Tx]{
+		doPreBlock:        func(ctx context.Context, txs []mock.Tx) error { return nil },
+		doBeginBlock:      func(ctx context.Context) error { return nil },
+		doEndBlock:        func(ctx context.Context) error { return nil },
+		doValidatorUpdate: func(ctx context.Context) ([]appmodulev2.ValidatorUpdate, error) { return nil, nil },
+		doTxValidation: func(ctx context.Context, tx mock.Tx) error {
+			kvSet(t, ctx, "validate")
+			return nil
+		},
+		postTxExec: func(ctx context.Context, tx mock.Tx, success bool) error {
+			kvSet(t, ctx, "post-tx-exec")
+			return nil
+		},
+		branchFn:            branch.DefaultNewWriterMap,
+		makeGasMeter:        gas.DefaultGasMeter,
+		makeGasMeteredState: gas.DefaultWrapWithGasMeter,
+	}
+
+	// the handler appends a byte to the value of the key of the message, so that
+	// the responses and the state depend on the order of execution
+	addMsgHandlerToSTF(t, s, func(ctx context.Context, msg *gogotypes.StringValue) (*gogotypes.StringValue, error) {
+		if msg.Value == "fail" {
+			return nil, errors.New("failure")
+		}
+		state, err := ctx.(*executionContext).state.GetWriter(actorName)
+		if err != nil {
+			return nil, err
+		}
+		value, err := state.Get([]byte(msg.Value))
+		if err != nil {
+			return nil, err
+		}
+		value = append(bytes.Clone(value), 'x')
+		if err := state.Set([]byte(msg.Value), value); err != nil {
+			return nil, err
+		}
+		return &gogotypes.StringValue{Value: string(value)}, nil
+	})
+
+	var txs []mock.Tx
+	for _, key := range []string{"a", "b", "a", "c", "fail", "a", "d", "b", "e", "a"} {
+		txs = append(txs, mock.Tx{
+			Sender:   []byte("sender"),
+			Msg:      &gogotypes.StringValue{Value: key},
+			GasLimit: 100_000,
+		})
+	}
+	sum := sha256.Sum256([]byte("test-hash"))
+	block := &server.BlockRequest[mock.Tx]{
+		Height:  1,
+		Time:    time.Date(2024, 2, 3, 18, 23, 0, 0, time.UTC),
+		AppHash: sum[:],
+		Hash:    sum[:],
+		Txs:     txs,
+	}
+
+	seqResult, seqState, err := s.DeliverBlock(context.Background(), block, mock.DB())
+	if err != nil {
+		t.Fatalf("DeliverBlock error: %v", err)
+	}
+
+	for _, workers := range []int{2, 4, 16} {
+		parallel := s.clone()
+		parallel.SetParallelExecution(workers)
+		result, newState, err := parallel.DeliverBlock(context.Background(), block, mock.DB())
+		if err != nil {
+			t.Fatalf("DeliverBlock error: %v", err)
+		}
+
+		if !reflect.DeepEqual(seqResult.TxResults, result.TxResults) {
+			t.Errorf("workers %d: tx results differ from the sequential execution", workers)
+		}
+		if !reflect.DeepEqual(sortedStateChanges(t, seqState), sortedStateChanges(t, newState)) {
+			t.Errorf("workers %d: state differs from the sequential execution", workers)
+		}
+
+		reader, err := newState.GetReader(actorName)
+		if err != nil {
+			t.Fatalf("GetReader error: %v", err)
+		}
+		value, err := reader.Get([]byte("a"))
+		if err != nil {
+			t.Fatalf("Get error: %v", err)
+		}
+		if string(value) != "xxxx" {
+			t.Errorf("workers %d: expected value xxxx, got %s", workers, value)
+		}
+	}
+}
+
+func TestWriteSetConflicts(t *testing.T) {
+	written := writeSet{}
+	written.add([]store.StateChanges{{
+		Actor:        []byte("actor"),
+		StateChanges: []store.KVPair{{Key: []byte("b")}, {Key: []byte("d"), Remove: true}},
+	}})
+
+	testCases := map[string]struct {
+		reads     readSet
+		conflicts bool
+	}{
+		"no reads": {reads: readSet{}},
+		"other actor": {
+			reads: readSet{"other": {keys: map[string]struct{}{"b": {}}}},
+		},
+		"other key": {
+			reads: readSet{"actor": {keys: map[string]struct{}{"a": {}}}},
+		},
+		"same key": {
+			reads:     readSet{"actor": {keys: map[string]struct{}{"b": {}}}},
+			conflicts: true,
+		},
+		"removed key": {
+			reads:     readSet{"actor": {keys: map[string]struct{}{"d": {}}}},
+			conflicts: true,
+		},
+		"range excluding the keys": {
+			reads: readSet{"actor": {ranges: []keyRange{{start: []byte("c"), end: []byte("d")}}}},
+		},
+		"range including a key": {
+			reads:     readSet{"actor": {ranges: []keyRange{{start: []byte("c"), end: []byte("e")}}}},
+			conflicts: true,
+		},
+		"unbounded range": {
+			reads:     readSet{"actor": {ranges: []keyRange{{}}}},
+			conflicts: true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if got := written.conflicts(tc.reads); got != tc.conflicts {
+				t.Errorf("expected conflicts %v, got %v", tc.conflicts, got)
+			}
+		})
+	}
+}
+
+func sortedStateChanges(t *testing.T, state store.WriterMap) []store.StateChanges {
+	t.Helper()
+	changes, err := state.GetStateChanges()
+	if err != nil {
+		t.Fatalf("GetStateChanges error: %v", err)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return bytes.Compare(changes[i].Actor, changes[j].Actor) < 0
+	})
+	return changes
+}
@@ -0,0 +1,97 @@
+package stf
+
+import (
+	"bytes"
+	"context"
+	"slices"
+
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/core/store"
+)
+
+// ReplayBlock executes the block with DeliverBlock and additionally returns the
+// state changes of each step of the execution, which is used to debug the
+// execution of historical blocks. The txs of the block are always executed
+// sequentially, so that the changes of each tx can be isolated.
+func (s STF[T]) ReplayBlock(
+	ctx context.Context,
+	block *server.BlockRequest[T],
+	state store.ReaderMap,
+) (*server.BlockResponse, store.WriterMap, *server.BlockTrace, error) {
+	trace := &server.BlockTrace{}
+	blockResult, newState, err := s.deliverBlock(ctx, block, state, trace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return blockResult, newState, trace, nil
+}
+
+// blockTracer records the state changes of each step of the execution of a
+// block, which are the changes of the block state since the previous step.
+type blockTracer struct {
+	before store.ReaderMap // the state before the block
+	state  store.WriterMap // the state of the block
+	// the changes of the state of the block at the previous step, by actor and key
+	changes map[string]map[string]store.KVPair
+}
+
+func newBlockTracer(before store.ReaderMap, state store.WriterMap) *blockTracer {
+	return &blockTracer{before: before, state: state}
+}
+
+// step returns the state changes since the previous step, along with the values
+// of the keys before them, sorted by actor and key.
+func (t *blockTracer) step() ([]server.StateDiff, error) {
+	changes, err := t.state.GetStateChanges()
+	if err != nil {
+		return nil, err
+	}
+
+	var diff []server.StateDiff
+	current := make(map[string]map[string]store.KVPair, len(changes))
+	for _, sc := range changes {
+		previousChanges := t.changes[string(sc.Actor)]
+		actorChanges := make(map[string]store.KVPair, len(sc.StateChanges))
+		for _, kv := range sc.StateChanges {
+			actorChanges[string(kv.Key)] = kv
+			prev, changed := previousChanges[string(kv.Key)]
+			if changed && prev.Remove == kv.Remove && bytes.Equal(prev.Value, kv.Value) {
+				continue
+			}
+
+			var previous []byte
+			if changed {
+				if !prev.Remove {
+					previous = prev.Value
+				}
+			} else {
+				reader, err := t.before.GetReader(sc.Actor)
+				if err != nil {
+					return nil, err
+				}
+				if previous, err = reader.Get(kv.Key); err != nil {
+					return nil, err
+				}
+			}
+			diff = append(diff, server.StateDiff{
+				Actor:    sc.Actor,
+				Key:      kv.Key,
+				Previous: previous,
+				Value:    kv.Value,
+				Remove:   kv.Remove,
+			})
+		}
+		current[string(sc.Actor)] = actorChanges
+	}
+	t.changes = current
+
+	slices.SortFunc(diff, func(a, b server.StateDiff) int {
+		if c := bytes.Compare(a.Actor, b.Actor); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.Key, b.Key)
+	})
+
+	return diff, nil
+}
@@ -0,0 +1,145 @@
+package stf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"reflect"
+	"testing"
+	"time"
+
+	gogotypes "github.com/cosmos/gogoproto/types"
+
+	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/server"
+	"cosmossdk.io/server/v2/stf/branch"
+	"cosmossdk.io/server/v2/stf/gas"
+	"cosmossdk.io/server/v2/stf/mock"
+)
+
+func TestReplayBlock(t *testing.T) {
+	s := &STF[mock.Tx]{
+		doPreBlock: func(ctx context.Context, txs []mock.Tx) error { return nil },
+		doBeginBlock: func(ctx context.Context) error {
+			kvSet(t, ctx, "begin-block")
+			return nil
+		},
+		// the end block sees the state changes of the txs
+		doEndBlock: func(ctx context.Context) error {
+			state, err := ctx.(*executionContext).state.GetWriter(actorName)
+			if err != nil {
+				return err
+			}
+			value, err := state.Get([]byte("a"))
+			if err != nil {
+				return err
+			}
+			return state.Set([]byte("end-block"), value)
+		},
+		doValidatorUpdate:   func(ctx context.Context) ([]appmodulev2.ValidatorUpdate, error) { return nil, nil },
+		doTxValidation:      func(ctx context.Context, tx mock.Tx) error { return nil },
+		postTxExec:          func(ctx context.Context, tx mock.Tx, success bool) error { return nil },
+		branchFn:            branch.DefaultNewWriterMap,
+		makeGasMeter:        gas.DefaultGasMeter,
+		makeGasMeteredState: gas.DefaultWrapWithGasMeter,
+	}
+
+	// the handler appends a byte to the value of the key of the message
+	addMsgHandlerToSTF(t, s, func(ctx context.Context, msg *gogotypes.StringValue) (*gogotypes.StringValue, error) {
+		state, err := ctx.(*executionContext).state.GetWriter(actorName)
+		if err != nil {
+			return nil, err
+		}
+		value, err := state.Get([]byte(msg.Value))
+		if err != nil {
+			return nil, err
+		}
+		value = append(bytes.Clone(value), 'x')
+		if err := state.Set([]byte(msg.Value), value); err != nil {
+			return nil, err
+		}
+		return &gogotypes.StringValue{Value: string(value)}, nil
+	})
+
+	var txs []mock.Tx
+	for _, key := range []string{"a", "b", "a"} {
+		txs = append(txs, mock.Tx{
+			Sender:   []byte("sender"),
+			Msg:      &gogotypes.StringValue{Value: key},
+			GasLimit: 100_000,
+		})
+	}
+	sum := sha256.Sum256([]byte("test-hash"))
+	block := &server.BlockRequest[mock.Tx]{
+		Height:  1,
+		Time:    time.Date(2024, 2, 3, 18, 23, 0, 0, time.UTC),
+		AppHash: sum[:],
+		Hash:    sum[:],
+		Txs:     txs,
+	}
+
+	deliverResult, deliverState, err := s.DeliverBlock(context.Background(), block, mock.DB())
+	if err != nil {
+		t.Fatalf("DeliverBlock error: %v", err)
+	}
+	result, newState, trace, err := s.ReplayBlock(context.Background(), block, mock.DB())
+	if err != nil {
+		t.Fatalf("ReplayBlock error: %v", err)
+	}
+
+	if !reflect.DeepEqual(deliverResult, result) {
+		t.Errorf("block results differ from DeliverBlock")
+	}
+	if !reflect.DeepEqual(sortedStateChanges(t, deliverState), sortedStateChanges(t, newState)) {
+		t.Errorf("state differs from DeliverBlock")
+	}
+
+	// actorDiff returns the changes of the actor of the test keys
+	actorDiff := func(diff []server.StateDiff) []server.StateDiff {
+		var res []server.StateDiff
+		for _, d := range diff {
+			if bytes.Equal(d.Actor, actorName) {
+				res = append(res, d)
+			}
+		}
+		return res
+	}
+	expectDiff := func(step string, diff []server.StateDiff, expected ...server.StateDiff) {
+		t.Helper()
+		for i := range expected {
+			expected[i].Actor = actorName
+		}
+		if got := actorDiff(diff); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected state diff %v, got %v", step, expected, got)
+		}
+	}
+
+	expectDiff("begin block", trace.BeginBlock,
+		server.StateDiff{Key: []byte("begin-block"), Value: []byte("begin-block")})
+	if len(trace.Txs) != len(txs) {
+		t.Fatalf("expected %d tx state diffs, got %d", len(txs), len(trace.Txs))
+	}
+	expectDiff("tx 0", trace.Txs[0], server.StateDiff{Key: []byte("a"), Value: []byte("x")})
+	expectDiff("tx 1", trace.Txs[1], server.StateDiff{Key: []byte("b"), Value: []byte("x")})
+	expectDiff("tx 2", trace.Txs[2], server.StateDiff{Key: []byte("a"), Previous: []byte("x"), Value: []byte("xx")})
+	expectDiff("end block", trace.EndBlock,
+		server.StateDiff{Key: []byte("end-block"), Value: []byte("xx")})
+
+	// the txs are replayed sequentially when the parallel execution is enabled
+	s.SetParallelExecution(4)
+	deliverResult, deliverState, err = s.DeliverBlock(context.Background(), block, mock.DB())
+	if err != nil {
+		t.Fatalf("DeliverBlock error: %v", err)
+	}
+	result, newState, trace, err = s.ReplayBlock(context.Background(), block, mock.DB())
+	if err != nil {
+		t.Fatalf("ReplayBlock error: %v", err)
+	}
+	if !reflect.DeepEqual(deliverResult, result) {
+		t.Errorf("block results differ from the parallel DeliverBlock")
+	}
+	if !reflect.DeepEqual(sortedStateChanges(t, deliverState), sortedStateChanges(t, newState)) {
+		t.Errorf("state differs from the parallel DeliverBlock")
+	}
+	expectDiff("tx 2", trace.Txs[2], server.StateDiff{Key: []byte("a"), Previous: []byte("x"), Value: []byte("xx")})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/comet"
 	corecontext "cosmossdk.io/core/context"
 	"cosmossdk.io/core/event"
 	"cosmossdk.io/core/gas"
@@ -41,6 +42,10 @@ type STF[T transaction.Tx] struct {
 	branchFn            branchFn // branchFn is a function that given a readonly state it returns a writable version of it.
 	makeGasMeter        makeGasMeterFn
 	makeGasMeteredState makeGasMeteredStateFn
+
+	// parallelWorkers is the number of workers executing the txs of a block in
+	// parallel, the txs are executed sequentially when it is lower than 2.
+	parallelWorkers int
 }
 
 // NewSTF returns a new STF instance.
@@ -81,6 +86,14 @@ func NewSTF[T transaction.Tx](
 	}, nil
 }
 
+// SetParallelExecution enables the optimistic parallel execution of the txs of
+// a block on the given number of workers, a value lower than 2 disables it.
+// The results of the parallel execution are identical to the sequential one,
+// however the modules must support being executed concurrently.
+func (s *STF[T]) SetParallelExecution(workers int) {
+	s.parallelWorkers = workers
+}
+
 // DeliverBlock is our state transition function.
 // It takes a read only view of the state to apply the block to,
 // executes the block and returns the block results and the new state.
@@ -88,6 +101,17 @@ func (s STF[T]) DeliverBlock(
 	ctx context.Context,
 	block *server.BlockRequest[T],
 	state store.ReaderMap,
+) (blockResult *server.BlockResponse, newState store.WriterMap, err error) {
+	return s.deliverBlock(ctx, block, state, nil)
+}
+
+// deliverBlock executes the block, the state changes of each step of the
+// execution are recorded in trace if it is not nil.
+func (s STF[T]) deliverBlock(
+	ctx context.Context,
+	block *server.BlockRequest[T],
+	state store.ReaderMap,
+	trace *server.BlockTrace,
 ) (blockResult *server.BlockResponse, newState store.WriterMap, err error) {
 	// creates a new branchFn state, from the readonly view of the state
 	// that can be written to.
@@ -111,7 +135,7 @@ func (s STF[T]) DeliverBlock(
 	// reset events
 	exCtx.events = make([]event.Event, 0)
 	// pre block is called separate from begin block in order to prepopulate state
-	preBlockEvents, err := s.preBlock(exCtx, block.Txs)
+	preBlockEvents, err := s.preBlock(exCtx, block.Txs, block.ExtendedCommitInfo)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -138,15 +162,38 @@ func (s STF[T]) DeliverBlock(
 		return nil, nil, err
 	}
 
-	// execute txs
-	txResults := make([]server.TxResult, len(block.Txs))
-	// TODO: skip first tx if vote extensions are enabled (marko)
-	for i, txBytes := range block.Txs {
-		// check if we need to return early or continue delivering txs
-		if err = isCtxCancelled(ctx); err != nil {
+	var tracer *blockTracer
+	if trace != nil {
+		tracer = newBlockTracer(state, newState)
+		trace.BeginBlock, err = tracer.step()
+		if err != nil {
+			return nil, nil, err
+		}
+		trace.Txs = make([][]server.StateDiff, len(block.Txs))
+	}
+
+	// execute txs, sequentially when tracing to isolate the changes of each tx
+	var txResults []server.TxResult
+	if s.parallelWorkers > 1 && len(block.Txs) > 1 && tracer == nil {
+		txResults, err = s.deliverTxsParallel(exCtx, newState, block.Txs, hi)
+		if err != nil {
 			return nil, nil, err
 		}
-		txResults[i] = s.deliverTx(exCtx, newState, txBytes, transaction.ExecModeFinalize, hi)
+	} else {
+		txResults = make([]server.TxResult, len(block.Txs))
+		for i, txBytes := range block.Txs {
+			// check if we need to return early or continue delivering txs
+			if err = isCtxCancelled(ctx); err != nil {
+				return nil, nil, err
+			}
+			txResults[i] = s.deliverTx(exCtx, newState, txBytes, transaction.ExecModeFinalize, hi)
+			if tracer != nil {
+				trace.Txs[i], err = tracer.step()
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+		}
 	}
 	// reset events
 	exCtx.events = make([]event.Event, 0)
@@ -156,6 +203,13 @@ func (s STF[T]) DeliverBlock(
 		return nil, nil, err
 	}
 
+	if tracer != nil {
+		trace.EndBlock, err = tracer.step()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	return &server.BlockResponse{
 		ValidatorUpdates: valset,
 		PreBlockEvents:   preBlockEvents,
@@ -332,8 +386,16 @@ func (s STF[T]) runTxMsgs(
 func (s STF[T]) preBlock(
 	ctx *executionContext,
 	txs []T,
+	extCommit *comet.ExtendedCommitInfo,
 ) ([]event.Event, error) {
-	err := s.doPreBlock(ctx, txs)
+	var preBlockCtx context.Context = ctx
+	if extCommit != nil {
+		// expose the vote extensions of the previous block to the pre blocker of the
+		// app, which dispatches them to the modules
+		preBlockCtx = context.WithValue(ctx, corecontext.ExtendedCommitInfoKey, *extCommit)
+	}
+
+	err := s.doPreBlock(preBlockCtx, txs)
 	if err != nil {
 		return nil, err
 	}
@@ -456,6 +518,44 @@ func (s STF[T]) Query(
 	return s.queryRouter.Invoke(queryCtx, req)
 }
 
+// RunExtendVote runs the closure producing the vote extension of the validator
+// on a branch of the provided state, whose changes are discarded.
+func (s STF[T]) RunExtendVote(
+	ctx context.Context,
+	state store.ReaderMap,
+	closure func(ctx context.Context) error,
+) error {
+	return s.runReadOnly(ctx, state, internal.ExecModeVoteExtension, closure)
+}
+
+// RunVerifyVoteExtension runs the closure verifying the vote extension of a
+// validator on a branch of the provided state, whose changes are discarded.
+func (s STF[T]) RunVerifyVoteExtension(
+	ctx context.Context,
+	state store.ReaderMap,
+	closure func(ctx context.Context) error,
+) error {
+	return s.runReadOnly(ctx, state, internal.ExecModeVerifyVoteExtension, closure)
+}
+
+// runReadOnly runs the closure on a branch of the provided state with the header
+// info of the last block.
+func (s STF[T]) runReadOnly(
+	ctx context.Context,
+	state store.ReaderMap,
+	execMode transaction.ExecMode,
+	closure func(ctx context.Context) error,
+) error {
+	branchedState := s.branchFn(state)
+	hi, err := s.getHeaderInfo(branchedState)
+	if err != nil {
+		return err
+	}
+	execCtx := s.makeContext(ctx, nil, branchedState, execMode)
+	execCtx.setHeaderInfo(hi)
+	return closure(execCtx)
+}
+
 // RunWithCtx is made to support genesis, if genesis was just the execution of messages instead
 // of being something custom then we would not need this. PLEASE DO NOT USE.
 // TODO: Remove
@@ -484,6 +584,7 @@ func (s STF[T]) clone() STF[T] {
 		branchFn:            s.branchFn,
 		makeGasMeter:        s.makeGasMeter,
 		makeGasMeteredState: s.makeGasMeteredState,
+		parallelWorkers:     s.parallelWorkers,
 	}
 }
 
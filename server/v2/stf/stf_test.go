@@ -11,6 +11,8 @@ import (
 	gogotypes "github.com/cosmos/gogoproto/types"
 
 	appmodulev2 "cosmossdk.io/core/appmodule/v2"
+	"cosmossdk.io/core/comet"
+	corecontext "cosmossdk.io/core/context"
 	coregas "cosmossdk.io/core/gas"
 	"cosmossdk.io/core/server"
 	"cosmossdk.io/core/store"
@@ -137,6 +139,35 @@ func TestSTF(t *testing.T) {
 		}
 	})
 
+	t.Run("vote extensions in pre block", func(t *testing.T) {
+		s := s.clone()
+		extCommit := &comet.ExtendedCommitInfo{
+			Round: 1,
+			Votes: []comet.ExtendedVoteInfo{{VoteExtension: []byte("price")}},
+		}
+		var preBlockCommit *comet.ExtendedCommitInfo
+		s.doPreBlock = func(ctx context.Context, txs []mock.Tx) error {
+			if ci, ok := ctx.Value(corecontext.ExtendedCommitInfoKey).(comet.ExtendedCommitInfo); ok {
+				preBlockCommit = &ci
+			}
+			return nil
+		}
+
+		_, _, err := s.DeliverBlock(context.Background(), &server.BlockRequest[mock.Tx]{
+			Height:             uint64(1),
+			Time:               time.Date(2024, 2, 3, 18, 23, 0, 0, time.UTC),
+			AppHash:            sum[:],
+			Hash:               sum[:],
+			ExtendedCommitInfo: extCommit,
+		}, state)
+		if err != nil {
+			t.Errorf("DeliverBlock error: %v", err)
+		}
+		if preBlockCommit == nil || string(preBlockCommit.Votes[0].VoteExtension) != "price" {
+			t.Errorf("Expected the extended commit info in pre block, got %v", preBlockCommit)
+		}
+	})
+
 	t.Run("exec tx out of gas", func(t *testing.T) {
 		s := s.clone()
 
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/log"
 	serverv2 "cosmossdk.io/server/v2"
 	storev2 "cosmossdk.io/store/v2"
@@ -82,18 +83,7 @@ Supported app-db-backend types include 'goleveldb', 'rocksdb', 'pebbledb'.`,
 func createRootStore(cmd *cobra.Command, v *viper.Viper, logger log.Logger) (storev2.RootStore, uint64, error) {
 	tempViper := v
 	rootDir := v.GetString(serverv2.FlagHome)
-	// handle FlagAppDBBackend
-	var dbType db.DBType
-	if cmd.Flags().Changed(FlagAppDBBackend) {
-		dbStr, err := cmd.Flags().GetString(FlagAppDBBackend)
-		if err != nil {
-			return nil, 0, err
-		}
-		dbType = db.DBType(dbStr)
-	} else {
-		dbType = db.DBType(v.GetString("store.app-db-backend"))
-	}
-	scRawDb, err := db.NewDB(dbType, "application", filepath.Join(rootDir, "data"), nil)
+	scRawDb, err := openSCRawDB(cmd, v)
 	if err != nil {
 		panic(err)
 	}
@@ -141,6 +131,23 @@ func createRootStore(cmd *cobra.Command, v *viper.Viper, logger log.Logger) (sto
 	return store, tempViper.GetUint64("store.options.sc-pruning-option.keep-recent"), err
 }
 
+// openSCRawDB opens the application database, the FlagAppDBBackend overrides
+// the configured backend type.
+func openSCRawDB(cmd *cobra.Command, v *viper.Viper) (corestore.KVStoreWithBatch, error) {
+	var dbType db.DBType
+	if cmd.Flags().Changed(FlagAppDBBackend) {
+		dbStr, err := cmd.Flags().GetString(FlagAppDBBackend)
+		if err != nil {
+			return nil, err
+		}
+		dbType = db.DBType(dbStr)
+	} else {
+		dbType = db.DBType(v.GetString("store.app-db-backend"))
+	}
+
+	return db.NewDB(dbType, "application", filepath.Join(v.GetString(serverv2.FlagHome), "data"), nil)
+}
+
 func overrideKeepRecent(configPath string, keepRecent uint64) error {
 	bz, err := os.ReadFile(filepath.Join(configPath, "app.toml"))
 	if err != nil {
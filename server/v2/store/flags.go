@@ -4,4 +4,10 @@ const (
 	FlagAppDBBackend = "app-db-backend"
 	FlagKeepRecent   = "keep-recent"
 	FlagInterval     = "interval"
+	FlagHeight       = "height"
+	FlagRepair       = "repair"
+	FlagCompact      = "compact"
+	FlagDelta        = "delta"
+	FlagCompression  = "compression"
+	FlagStatus       = "status"
 )
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/log"
+	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/store/v2/migration"
+	"cosmossdk.io/store/v2/root"
+)
+
+// MigrateCmd implements the command resuming and completing the migration of a
+// store v1 database to store/v2.
+func (s *StoreComponent[T]) MigrateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Resume and complete the migration of a store v1 database to store/v2",
+		Long: `Resume and complete the migration of a store v1 (rootmulti) database to store/v2,
+which is enabled by 'migrate-from-v1' in the store options of app.toml.
+The state snapshot is restored, or the interrupted migration is resumed from the last
+migrated height, then the migrated app hash is verified against the store v1 app hash
+at the latest height before switching to store/v2.
+
+With '--status', the progress of the migration is printed without migrating.
+
+Note: When the --app-db-backend flag is not specified, the default backend type is 'goleveldb'.`,
+		Example: fmt.Sprintf("%s migrate --status", "<appd>"),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vp := serverv2.GetViperFromCmd(cmd)
+			if err := vp.BindPFlags(cmd.Flags()); err != nil {
+				return err
+			}
+
+			status, err := cmd.Flags().GetBool(FlagStatus)
+			if err != nil {
+				return err
+			}
+			if status {
+				scRawDb, err := openSCRawDB(cmd, vp)
+				if err != nil {
+					return err
+				}
+				defer scRawDb.Close()
+
+				progress, err := migration.LoadProgress(scRawDb)
+				if err != nil {
+					return err
+				}
+				switch progress.Phase {
+				case migration.PhaseNone:
+					cmd.Println("the migration has not started")
+				case migration.PhaseRestore:
+					cmd.Printf("the migration was interrupted while restoring the state snapshot at height %d\n", progress.StartVersion)
+				case migration.PhaseCatchUp:
+					cmd.Printf("the state snapshot was restored at height %d, migrated up to height %d\n", progress.StartVersion, progress.MigratedVersion)
+				case migration.PhaseDone:
+					cmd.Printf("the migration was completed at height %d\n", progress.MigratedVersion)
+				}
+				return nil
+			}
+
+			logger := log.NewLogger(cmd.OutOrStdout())
+			rootStore, _, err := createRootStore(cmd, vp, logger)
+			if err != nil {
+				return fmt.Errorf("can not create root store %w", err)
+			}
+			defer rootStore.Close()
+
+			store, ok := rootStore.(*root.Store)
+			if !ok {
+				return errors.New("the root store does not support migration")
+			}
+			if err := store.LoadLatestVersion(); err != nil {
+				return err
+			}
+			if _, ok := store.MigrationProgress(); !ok {
+				return errors.New("the store is not migrating, the migration is either completed or not enabled with 'migrate-from-v1'")
+			}
+
+			if err := store.CompleteMigration(); err != nil {
+				return fmt.Errorf("failed to complete the migration: %w", err)
+			}
+
+			latestHeight, err := store.GetLatestVersion()
+			if err != nil {
+				return err
+			}
+			cmd.Printf("successfully migrated to store/v2 at height %d\n", latestHeight)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(FlagAppDBBackend, "", "The type of database for application and snapshots databases")
+	cmd.Flags().Bool(FlagStatus, false, "Print the progress of the migration without migrating")
+
+	return cmd
+}
@@ -51,6 +51,8 @@ func (s *StoreComponent[T]) CLICommands() serverv2.CLIConfig {
 	return serverv2.CLIConfig{
 		Commands: []*cobra.Command{
 			s.PrunesCmd(),
+			s.VerifyOfflineCmd(),
+			s.MigrateCmd(),
 			s.ExportSnapshotCmd(),
 			s.DeleteSnapshotCmd(),
 			s.ListSnapshotsCmd(),
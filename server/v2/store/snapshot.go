@@ -59,7 +59,16 @@ func (s *StoreComponent[T]) ExportSnapshotCmd() *cobra.Command {
 				return err
 			}
 
-			snapshot, err := sm.Create(uint64(height))
+			delta, err := cmd.Flags().GetBool(FlagDelta)
+			if err != nil {
+				return err
+			}
+
+			create := sm.Create
+			if delta {
+				create = sm.CreateDelta
+			}
+			snapshot, err := create(uint64(height))
 			if err != nil {
 				return err
 			}
@@ -71,6 +80,8 @@ func (s *StoreComponent[T]) ExportSnapshotCmd() *cobra.Command {
 
 	addSnapshotFlagsToCmd(cmd)
 	cmd.Flags().Int64("height", 0, "Height to export, default to latest state height")
+	cmd.Flags().Bool(FlagDelta, false, "Export a delta snapshot applied to the latest snapshot")
+	cmd.Flags().String(FlagCompression, types.CompressionZlib, "Compression of the snapshot chunks (zlib|zstd|gzip|none)")
 
 	return cmd
 }
@@ -128,6 +139,10 @@ func (s *StoreComponent[T]) ListSnapshotsCmd() *cobra.Command {
 				return fmt.Errorf("failed to list snapshots: %w", err)
 			}
 			for _, snapshot := range snapshots {
+				if snapshot.Format == types.DeltaFormat {
+					cmd.Println("height:", snapshot.Height, "format:", snapshot.Format, "chunks:", snapshot.Chunks, "base height:", snapshot.Metadata.BaseHeight)
+					continue
+				}
 				cmd.Println("height:", snapshot.Height, "format:", snapshot.Format, "chunks:", snapshot.Chunks)
 			}
 
@@ -306,7 +321,7 @@ func (s *StoreComponent[T]) LoadArchiveCmd() *cobra.Command {
 			go func() {
 				defer close(quitChan)
 
-				savedSnapshot, err := snapshotStore.Save(snapshot.Height, snapshot.Format, chunks)
+				savedSnapshot, err := snapshotStore.SaveWithMetadata(snapshot.Height, snapshot.Format, snapshot.Metadata, chunks)
 				if err != nil {
 					cmd.Println("failed to save snapshot", err)
 					return
@@ -371,7 +386,15 @@ func createSnapshotsManager(cmd *cobra.Command, v *viper.Viper, logger log.Logge
 		}
 	}
 
-	sm := snapshots.NewManager(snapshotStore, snapshots.NewSnapshotOptions(interval, uint32(keepRecent)), store.GetStateCommitment().(snapshots.CommitSnapshotter), store.GetStateStorage().(snapshots.StorageSnapshotter), nil, logger)
+	snapshotOpts := snapshots.NewSnapshotOptions(interval, uint32(keepRecent))
+	if cmd.Flags().Changed(FlagCompression) {
+		snapshotOpts.Compression, err = cmd.Flags().GetString(FlagCompression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sm := snapshots.NewManager(snapshotStore, snapshotOpts, store.GetStateCommitment().(snapshots.CommitSnapshotter), store.GetStateStorage().(snapshots.StorageSnapshotter), nil, logger)
 	return sm, nil
 }
 
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"cosmossdk.io/log"
+	serverv2 "cosmossdk.io/server/v2"
+	"cosmossdk.io/store/v2/root"
+	"cosmossdk.io/store/v2/storage"
+)
+
+// VerifyOfflineCmd implements the command verifying that the state storage
+// agrees with the state commitment. It opens the databases of the node itself,
+// so it can only be run while the node is stopped.
+func (s *StoreComponent[T]) VerifyOfflineCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify-offline",
+		Short: "Verify that the state storage agrees with the state commitment for a height, while the node is stopped",
+		Long: `Verify that the state storage (SS) agrees with the state commitment (SC) for a height.
+The command opens the databases of the node itself, so the node must be stopped.
+Every key of every store is walked in both SS and SC, and the keys which are missing
+from either of them or whose values differ are reported.
+
+With '--repair', SS is fixed from SC, which is only supported for the latest height.
+With '--compact', the SS database is compacted once verified.
+
+Note: When the --app-db-backend flag is not specified, the default backend type is 'goleveldb'.`,
+		Example: fmt.Sprintf("%s verify-offline --height 100 --repair", "<appd>"),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vp := serverv2.GetViperFromCmd(cmd)
+			if err := vp.BindPFlags(cmd.Flags()); err != nil {
+				return err
+			}
+
+			height, err := cmd.Flags().GetUint64(FlagHeight)
+			if err != nil {
+				return err
+			}
+			repair, err := cmd.Flags().GetBool(FlagRepair)
+			if err != nil {
+				return err
+			}
+			compact, err := cmd.Flags().GetBool(FlagCompact)
+			if err != nil {
+				return err
+			}
+
+			logger := log.NewLogger(cmd.OutOrStdout())
+			rootStore, _, err := createRootStore(cmd, vp, logger)
+			if err != nil {
+				return fmt.Errorf("can not create root store %w", err)
+			}
+			defer rootStore.Close()
+
+			if err := rootStore.LoadLatestVersion(); err != nil {
+				return err
+			}
+			if height == 0 {
+				height, err = rootStore.GetLatestVersion()
+				if err != nil {
+					return err
+				}
+			}
+
+			store, ok := rootStore.(*root.Store)
+			if !ok {
+				return errors.New("the root store does not support verification")
+			}
+
+			mismatches := 0
+			err = store.VerifyStorage(height, repair, func(m root.StorageMismatch) {
+				mismatches++
+				switch {
+				case m.SSValue == nil:
+					cmd.Printf("store %s: key %X missing from SS\n", m.StoreKey, m.Key)
+				case m.SCValue == nil:
+					cmd.Printf("store %s: key %X missing from SC\n", m.StoreKey, m.Key)
+				default:
+					cmd.Printf("store %s: key %X mismatch, SS value %X, SC value %X\n", m.StoreKey, m.Key, m.SSValue, m.SCValue)
+				}
+			})
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case mismatches == 0:
+				cmd.Printf("SS and SC agree at height %d\n", height)
+			case repair:
+				cmd.Printf("repaired %d keys of SS from SC at height %d\n", mismatches, height)
+			default:
+				cmd.Printf("found %d keys which differ between SS and SC at height %d\n", mismatches, height)
+			}
+
+			if compact {
+				compactor, ok := store.GetStateStorage().(storage.Compactor)
+				if !ok {
+					return errors.New("the state storage does not support compaction")
+				}
+				if err := compactor.Compact(); err != nil {
+					return fmt.Errorf("failed to compact SS: %w", err)
+				}
+				cmd.Println("successfully compacted the state storage")
+			}
+
+			if mismatches > 0 && !repair {
+				return fmt.Errorf("SS and SC differ at height %d", height)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String(FlagAppDBBackend, "", "The type of database for application and snapshots databases")
+	cmd.Flags().Uint64(FlagHeight, 0, "Height to verify, default to latest state height")
+	cmd.Flags().Bool(FlagRepair, false, "Repair the state storage from the state commitment")
+	cmd.Flags().Bool(FlagCompact, false, "Compact the state storage database once verified")
+
+	return cmd
+}
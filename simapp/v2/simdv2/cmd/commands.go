@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -49,9 +50,15 @@ func initRootCmd[T transaction.Tx](
 	cfg := sdk.GetConfig()
 	cfg.Seal()
 
+	cometBFTOptions := cometbft.DefaultServerOptions[T]()
+	cometBFTOptions.TxFee = txFee[T]
+	cometBFTServer := cometbft.New(&genericTxDecoder[T]{txConfig}, cometBFTOptions)
+	debugCmd := debug.Cmd()
+	debugCmd.AddCommand(cometBFTServer.ReplayBlockCmd(newApp))
+
 	rootCmd.AddCommand(
 		genutilcli.InitCmd(moduleManager),
-		debug.Cmd(),
+		debugCmd,
 		confixcmd.ConfigCommand(),
 		NewTestnetCmd(moduleManager),
 	)
@@ -76,7 +83,7 @@ func initRootCmd[T transaction.Tx](
 		newApp,
 		logger,
 		initServerConfig(),
-		cometbft.New(&genericTxDecoder[T]{txConfig}, cometbft.DefaultServerOptions[T]()),
+		cometBFTServer,
 		grpc.New[T](),
 		store.New[T](newApp),
 	); err != nil {
@@ -166,6 +173,15 @@ func appExport[T transaction.Tx](
 	return simApp.ExportAppStateAndValidators(jailAllowedAddrs)
 }
 
+// txFee returns the amount in the given denom of the fees of an SDK tx.
+func txFee[T transaction.Tx](tx T, denom string) (*big.Int, error) {
+	feeTx, ok := any(tx).(sdk.FeeTx)
+	if !ok {
+		return nil, errors.New("tx is not a fee tx")
+	}
+	return feeTx.GetFee().AmountOf(denom).BigInt(), nil
+}
+
 var _ transaction.Codec[transaction.Tx] = &genericTxDecoder[transaction.Tx]{}
 
 type genericTxDecoder[T transaction.Tx] struct {
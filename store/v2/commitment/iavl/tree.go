@@ -14,6 +14,7 @@ import (
 
 var (
 	_ commitment.Tree      = (*IavlTree)(nil)
+	_ commitment.RangeTree = (*IavlTree)(nil)
 	_ store.PausablePruner = (*IavlTree)(nil)
 )
 
@@ -55,6 +56,13 @@ func (t *IavlTree) WorkingHash() []byte {
 	return t.tree.WorkingHash()
 }
 
+// Rollback discards the changes of the working tree since the latest saved
+// version.
+func (t *IavlTree) Rollback() error {
+	t.tree.Rollback()
+	return nil
+}
+
 // LoadVersion loads the state at the given version.
 func (t *IavlTree) LoadVersion(version uint64) error {
 	return t.tree.LoadVersionForOverwriting(int64(version))
@@ -85,6 +93,17 @@ func (t *IavlTree) Get(version uint64, key []byte) ([]byte, error) {
 	return immutableTree.Get(key)
 }
 
+// Iterator returns an iterator over the range [start, end) of the tree at the
+// given version.
+func (t *IavlTree) Iterator(version uint64, start, end []byte, ascending bool) (corestore.Iterator, error) {
+	immutableTree, err := t.tree.GetImmutable(int64(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get immutable tree at version %d: %w", version, err)
+	}
+
+	return immutableTree.Iterator(start, end, ascending)
+}
+
 // GetLatestVersion returns the latest version of the tree.
 func (t *IavlTree) GetLatestVersion() (uint64, error) {
 	v, err := t.tree.GetLatestVersion()
@@ -30,6 +30,11 @@ func (t *Tree) WorkingHash() []byte {
 	return nil
 }
 
+// Rollback is a no-op, the in-memory tree is not versioned.
+func (t *Tree) Rollback() error {
+	return nil
+}
+
 func (t *Tree) LoadVersion(version uint64) error {
 	return nil
 }
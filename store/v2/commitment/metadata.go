@@ -13,11 +13,15 @@ const (
 	commitInfoKeyFmt      = "c/%d" // c/<version>
 	latestVersionKey      = "c/latest"
 	removedStoreKeyPrefix = "c/removed/" // c/removed/<version>/<store-name>
+	changesetKeyPrefix    = "c/cs/"      // c/cs/<version>/
 )
 
 // MetadataStore is a store for metadata related to the commitment store.
 type MetadataStore struct {
 	kv corestore.KVStoreWithBatch
+	// v1 reflects whether the latest version and the commit infos are stored in
+	// the store v1 (rootmulti) format, see NewV1MetadataStore.
+	v1 bool
 }
 
 // NewMetadataStore creates a new MetadataStore.
@@ -27,15 +31,27 @@ func NewMetadataStore(kv corestore.KVStoreWithBatch) *MetadataStore {
 	}
 }
 
+// NewV1MetadataStore creates a new MetadataStore which reads and writes the
+// latest version and the commit infos of a store v1 (rootmulti) database.
+func NewV1MetadataStore(kv corestore.KVStoreWithBatch) *MetadataStore {
+	return &MetadataStore{
+		kv: kv,
+		v1: true,
+	}
+}
+
 // GetLatestVersion returns the latest committed version.
 func (m *MetadataStore) GetLatestVersion() (uint64, error) {
-	value, err := m.kv.Get([]byte(latestVersionKey))
+	value, err := m.kv.Get(m.latestVersionKey())
 	if err != nil {
 		return 0, err
 	}
 	if value == nil {
 		return 0, nil
 	}
+	if m.v1 {
+		return unmarshalV1Version(value)
+	}
 
 	version, _, err := encoding.DecodeUvarint(value)
 	if err != nil {
@@ -46,24 +62,52 @@ func (m *MetadataStore) GetLatestVersion() (uint64, error) {
 }
 
 func (m *MetadataStore) setLatestVersion(version uint64) error {
+	value, err := m.marshalVersion(version)
+	if err != nil {
+		return err
+	}
+	return m.kv.Set(m.latestVersionKey(), value)
+}
+
+func (m *MetadataStore) latestVersionKey() []byte {
+	if m.v1 {
+		return []byte(v1LatestVersionKey)
+	}
+	return []byte(latestVersionKey)
+}
+
+func (m *MetadataStore) commitInfoKey(version uint64) []byte {
+	if m.v1 {
+		return []byte(fmt.Sprintf(v1CommitInfoKeyFmt, version))
+	}
+	return []byte(fmt.Sprintf(commitInfoKeyFmt, version))
+}
+
+func (m *MetadataStore) marshalVersion(version uint64) ([]byte, error) {
+	if m.v1 {
+		return marshalV1Version(version), nil
+	}
+
 	var buf bytes.Buffer
 	buf.Grow(encoding.EncodeUvarintSize(version))
 	if err := encoding.EncodeUvarint(&buf, version); err != nil {
-		return err
+		return nil, err
 	}
-	return m.kv.Set([]byte(latestVersionKey), buf.Bytes())
+	return buf.Bytes(), nil
 }
 
 // GetCommitInfo returns the commit info for the given version.
 func (m *MetadataStore) GetCommitInfo(version uint64) (*proof.CommitInfo, error) {
-	key := []byte(fmt.Sprintf(commitInfoKeyFmt, version))
-	value, err := m.kv.Get(key)
+	value, err := m.kv.Get(m.commitInfoKey(version))
 	if err != nil {
 		return nil, err
 	}
 	if value == nil {
 		return nil, nil
 	}
+	if m.v1 {
+		return unmarshalV1CommitInfo(value)
+	}
 
 	cInfo := &proof.CommitInfo{}
 	if err := cInfo.Unmarshal(value); err != nil {
@@ -86,21 +130,24 @@ func (m *MetadataStore) flushCommitInfo(version uint64, cInfo *proof.CommitInfo)
 			err = cErr
 		}
 	}()
-	cInfoKey := []byte(fmt.Sprintf(commitInfoKeyFmt, version))
-	value, err := cInfo.Marshal()
-	if err != nil {
-		return err
+	var value []byte
+	if m.v1 {
+		value = marshalV1CommitInfo(cInfo)
+	} else {
+		value, err = cInfo.Marshal()
+		if err != nil {
+			return err
+		}
 	}
-	if err := batch.Set(cInfoKey, value); err != nil {
+	if err := batch.Set(m.commitInfoKey(version), value); err != nil {
 		return err
 	}
 
-	var buf bytes.Buffer
-	buf.Grow(encoding.EncodeUvarintSize(version))
-	if err := encoding.EncodeUvarint(&buf, version); err != nil {
+	versionBz, err := m.marshalVersion(version)
+	if err != nil {
 		return err
 	}
-	if err := batch.Set([]byte(latestVersionKey), buf.Bytes()); err != nil {
+	if err := batch.Set(m.latestVersionKey(), versionBz); err != nil {
 		return err
 	}
 
@@ -172,6 +219,66 @@ func (m *MetadataStore) deleteRemovedStoreKeys(version uint64, removeStore func(
 }
 
 func (m *MetadataStore) deleteCommitInfo(version uint64) error {
-	cInfoKey := []byte(fmt.Sprintf(commitInfoKeyFmt, version))
-	return m.kv.Delete(cInfoKey)
+	return m.kv.Delete(m.commitInfoKey(version))
+}
+
+// GetChangeset returns the retained changeset of the given version, or nil if
+// it does not exist.
+func (m *MetadataStore) GetChangeset(version uint64) (*corestore.Changeset, error) {
+	value, err := m.kv.Get(encoding.BuildPrefixWithVersion(changesetKeyPrefix, version))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	cs := corestore.NewChangeset()
+	if err := encoding.UnmarshalChangeset(cs, value); err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+func (m *MetadataStore) flushChangeset(version uint64, cs *corestore.Changeset) error {
+	value, err := encoding.MarshalChangeset(cs)
+	if err != nil {
+		return err
+	}
+
+	return m.kv.Set(encoding.BuildPrefixWithVersion(changesetKeyPrefix, version), value)
+}
+
+func (m *MetadataStore) deleteChangesets(version uint64) (err error) {
+	end := encoding.BuildPrefixWithVersion(changesetKeyPrefix, version+1)
+	iter, err := m.kv.Iterator([]byte(changesetKeyPrefix), end)
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	// the iterator must be closed before writing the batch
+	if err := iter.Close(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	batch := m.kv.NewBatch()
+	defer func() {
+		if berr := batch.Close(); berr != nil && err == nil {
+			err = berr
+		}
+	}()
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return batch.Write()
 }
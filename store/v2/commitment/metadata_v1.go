@@ -0,0 +1,214 @@
+package commitment
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"cosmossdk.io/store/v2/proof"
+)
+
+// The store v1 (rootmulti) layout of the metadata and the IAVL trees, kept to
+// migrate a store v1 database to store v2.
+const (
+	v1Prefix           = "s/"
+	v1CommitInfoKeyFmt = "s/%d" // s/<version>
+	v1LatestVersionKey = "s/latest"
+	v1StorePrefixFmt   = "s/k:%s/" // s/k:<storeKey>/
+)
+
+// V1Prefix returns the prefix of all the store v1 (rootmulti) data, i.e. the
+// metadata and the IAVL trees.
+func V1Prefix() []byte {
+	return []byte(v1Prefix)
+}
+
+// V1StorePrefix returns the prefix of the IAVL tree of the given store key in a
+// store v1 (rootmulti) database.
+func V1StorePrefix(storeKey string) []byte {
+	return []byte(fmt.Sprintf(v1StorePrefixFmt, storeKey))
+}
+
+// marshalV1Version encodes the version as a gogoproto Int64Value, as the store
+// v1 latest version.
+func marshalV1Version(version uint64) []byte {
+	if version == 0 {
+		return []byte{}
+	}
+	bz := protowire.AppendTag(nil, 1, protowire.VarintType)
+	return protowire.AppendVarint(bz, version)
+}
+
+func unmarshalV1Version(bz []byte) (uint64, error) {
+	var version uint64
+	err := consumeFields(bz, func(num protowire.Number, typ protowire.Type, value []byte) (int, error) {
+		if num != 1 || typ != protowire.VarintType {
+			return protowire.ConsumeFieldValue(num, typ, value), nil
+		}
+		v, n := protowire.ConsumeVarint(value)
+		version = v
+		return n, nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode v1 latest version: %w", err)
+	}
+
+	return version, nil
+}
+
+// marshalV1CommitInfo encodes the CommitInfo as the store v1 CommitInfo proto
+// message.
+func marshalV1CommitInfo(ci *proof.CommitInfo) []byte {
+	var bz []byte
+	if ci.Version != 0 {
+		bz = protowire.AppendTag(bz, 1, protowire.VarintType)
+		bz = protowire.AppendVarint(bz, ci.Version)
+	}
+	for _, si := range ci.StoreInfos {
+		var commitID []byte
+		if ci.Version != 0 {
+			commitID = protowire.AppendTag(commitID, 1, protowire.VarintType)
+			commitID = protowire.AppendVarint(commitID, ci.Version)
+		}
+		if len(si.CommitID.Hash) > 0 {
+			commitID = protowire.AppendTag(commitID, 2, protowire.BytesType)
+			commitID = protowire.AppendBytes(commitID, si.CommitID.Hash)
+		}
+
+		var storeInfo []byte
+		if len(si.Name) > 0 {
+			storeInfo = protowire.AppendTag(storeInfo, 1, protowire.BytesType)
+			storeInfo = protowire.AppendBytes(storeInfo, si.Name)
+		}
+		storeInfo = protowire.AppendTag(storeInfo, 2, protowire.BytesType)
+		storeInfo = protowire.AppendBytes(storeInfo, commitID)
+
+		bz = protowire.AppendTag(bz, 2, protowire.BytesType)
+		bz = protowire.AppendBytes(bz, storeInfo)
+	}
+
+	var timestamp []byte
+	if seconds := ci.Timestamp.Unix(); seconds != 0 {
+		timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(seconds))
+	}
+	if nanos := ci.Timestamp.Nanosecond(); nanos != 0 {
+		timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(nanos))
+	}
+	bz = protowire.AppendTag(bz, 3, protowire.BytesType)
+	return protowire.AppendBytes(bz, timestamp)
+}
+
+func unmarshalV1CommitInfo(bz []byte) (*proof.CommitInfo, error) {
+	ci := &proof.CommitInfo{}
+	var seconds, nanos int64
+	err := consumeFields(bz, func(num protowire.Number, typ protowire.Type, value []byte) (int, error) {
+		switch {
+		case num == 1 && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(value)
+			ci.Version = v
+			return n, nil
+
+		case num == 2 && typ == protowire.BytesType:
+			storeInfo, n := protowire.ConsumeBytes(value)
+			if n < 0 {
+				return n, nil
+			}
+			si, err := unmarshalV1StoreInfo(storeInfo)
+			if err != nil {
+				return 0, err
+			}
+			ci.StoreInfos = append(ci.StoreInfos, si)
+			return n, nil
+
+		case num == 3 && typ == protowire.BytesType:
+			timestamp, n := protowire.ConsumeBytes(value)
+			if n < 0 {
+				return n, nil
+			}
+			err := consumeFields(timestamp, func(num protowire.Number, typ protowire.Type, value []byte) (int, error) {
+				if typ != protowire.VarintType || (num != 1 && num != 2) {
+					return protowire.ConsumeFieldValue(num, typ, value), nil
+				}
+				v, n := protowire.ConsumeVarint(value)
+				if num == 1 {
+					seconds = int64(v)
+				} else {
+					nanos = int64(int32(v))
+				}
+				return n, nil
+			})
+			return n, err
+
+		default:
+			return protowire.ConsumeFieldValue(num, typ, value), nil
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode v1 commit info: %w", err)
+	}
+
+	ci.Timestamp = time.Unix(seconds, nanos).UTC()
+	for i := range ci.StoreInfos {
+		ci.StoreInfos[i].CommitID.Version = ci.Version
+	}
+
+	return ci, nil
+}
+
+func unmarshalV1StoreInfo(bz []byte) (proof.StoreInfo, error) {
+	si := proof.StoreInfo{}
+	err := consumeFields(bz, func(num protowire.Number, typ protowire.Type, value []byte) (int, error) {
+		if typ != protowire.BytesType || (num != 1 && num != 2) {
+			return protowire.ConsumeFieldValue(num, typ, value), nil
+		}
+		v, n := protowire.ConsumeBytes(value)
+		if n < 0 {
+			return n, nil
+		}
+		if num == 1 {
+			si.Name = append([]byte{}, v...)
+			return n, nil
+		}
+		// the commit id, whose version is the version of the commit info
+		return n, consumeFields(v, func(num protowire.Number, typ protowire.Type, value []byte) (int, error) {
+			if num != 2 || typ != protowire.BytesType {
+				return protowire.ConsumeFieldValue(num, typ, value), nil
+			}
+			hash, n := protowire.ConsumeBytes(value)
+			si.CommitID.Hash = append([]byte{}, hash...)
+			return n, nil
+		})
+	})
+
+	return si, err
+}
+
+// consumeFields calls fn with the value of each field of the proto message, fn
+// returns the length of the value it consumed or a negative protowire error code.
+func consumeFields(bz []byte, fn func(num protowire.Number, typ protowire.Type, value []byte) (int, error)) error {
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		n, err := fn(num, typ, bz)
+		if err != nil {
+			return err
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if n > len(bz) {
+			return errors.New("invalid field length")
+		}
+		bz = bz[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package commitment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	dbm "cosmossdk.io/store/v2/db"
+	"cosmossdk.io/store/v2/proof"
+)
+
+func TestV1MetadataStore(t *testing.T) {
+	db := dbm.NewMemDB()
+	m := NewV1MetadataStore(db)
+
+	// a store v1 commit info, as encoded by gogoproto
+	v1CommitInfo := []byte{
+		0x08, 0x05, // version
+		0x12, 0x0e, // store info
+		0x0a, 0x04, 'b', 'a', 'n', 'k', // name
+		0x12, 0x06, 0x08, 0x05, 0x12, 0x02, 0x01, 0x02, // commit id
+		0x1a, 0x02, 0x08, 0x64, // timestamp
+	}
+	require.NoError(t, db.Set([]byte("s/5"), v1CommitInfo))
+	require.NoError(t, db.Set([]byte("s/latest"), []byte{0x08, 0x05}))
+
+	version, err := m.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), version)
+
+	cInfo, err := m.GetCommitInfo(5)
+	require.NoError(t, err)
+	expected := &proof.CommitInfo{
+		Version: 5,
+		StoreInfos: []proof.StoreInfo{
+			{Name: []byte("bank"), CommitID: proof.CommitID{Version: 5, Hash: []byte{0x01, 0x02}}},
+		},
+		Timestamp: time.Unix(100, 0).UTC(),
+	}
+	require.Equal(t, expected, cInfo)
+
+	// the commit infos are written in the store v1 format
+	require.NoError(t, m.flushCommitInfo(5, cInfo))
+	bz, err := db.Get([]byte("s/5"))
+	require.NoError(t, err)
+	require.Equal(t, v1CommitInfo, bz)
+	bz, err = db.Get([]byte("s/latest"))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x08, 0x05}, bz)
+
+	// the store v2 metadata is left untouched
+	has, err := db.Has([]byte(latestVersionKey))
+	require.NoError(t, err)
+	require.False(t, has)
+
+	require.NoError(t, m.deleteCommitInfo(5))
+	cInfo, err = m.GetCommitInfo(5)
+	require.NoError(t, err)
+	require.Nil(t, cInfo)
+}
@@ -0,0 +1,72 @@
+package smt
+
+import (
+	"container/list"
+	"sync"
+)
+
+// nodeCache is a LRU cache of persisted nodes, safe for concurrent use.
+type nodeCache struct {
+	mtx   sync.Mutex
+	size  int
+	ll    *list.List
+	items map[nodeKey]*list.Element
+}
+
+func newNodeCache(size int) *nodeCache {
+	return &nodeCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[nodeKey]*list.Element),
+	}
+}
+
+func (c *nodeCache) get(nk nodeKey) *node {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[nk]; ok {
+		c.ll.MoveToFront(e)
+		return e.Value.(*node)
+	}
+	return nil
+}
+
+func (c *nodeCache) add(n *node) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[n.nodeKey]; ok {
+		e.Value = n
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[n.nodeKey] = c.ll.PushFront(n)
+	if c.ll.Len() > c.size {
+		last := c.ll.Back()
+		c.ll.Remove(last)
+		delete(c.items, last.Value.(*node).nodeKey)
+	}
+}
+
+func (c *nodeCache) remove(nk nodeKey) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.items[nk]; ok {
+		c.ll.Remove(e)
+		delete(c.items, nk)
+	}
+}
+
+func (c *nodeCache) reset() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[nodeKey]*list.Element)
+}
@@ -0,0 +1,96 @@
+package smt
+
+import (
+	"errors"
+
+	"cosmossdk.io/store/v2/commitment"
+	snapshotstypes "cosmossdk.io/store/v2/snapshots/types"
+)
+
+// importBatchSize is the number of leaves after which the importer writes the
+// nodes it built so far, to bound its memory usage.
+const importBatchSize = 10_000
+
+// Exporter exports the leaves of a version of the tree in key hash order.
+// The inner nodes are not exported since the tree shape only depends on the leaves.
+type Exporter struct {
+	tree    *Tree
+	version uint64
+	stack   []*node
+}
+
+// Next returns the next leaf in the exporter.
+func (e *Exporter) Next() (*snapshotstypes.SnapshotIAVLItem, error) {
+	for len(e.stack) > 0 {
+		n := e.stack[len(e.stack)-1]
+		e.stack = e.stack[:len(e.stack)-1]
+		if n.leaf {
+			return &snapshotstypes.SnapshotIAVLItem{
+				Key:     n.key,
+				Value:   n.value,
+				Version: int64(e.version),
+				Height:  0,
+			}, nil
+		}
+		for _, c := range []*child{n.right, n.left} {
+			if c == nil {
+				continue
+			}
+			cn, err := e.tree.getChild(c)
+			if err != nil {
+				return nil, err
+			}
+			e.stack = append(e.stack, cn)
+		}
+	}
+	return nil, commitment.ErrorExportDone
+}
+
+// Close closes the exporter.
+func (e *Exporter) Close() error {
+	e.stack = nil
+	return nil
+}
+
+// Importer restores a version of the tree from the leaves of an Exporter.
+type Importer struct {
+	tree    *Tree
+	version uint64
+	count   int
+}
+
+// Add adds the given leaf to the importer.
+func (i *Importer) Add(item *snapshotstypes.SnapshotIAVLItem) error {
+	if item.Height != 0 {
+		return errors.New("only leaves can be imported into a sparse Merkle tree")
+	}
+	if err := i.tree.Set(item.Key, item.Value); err != nil {
+		return err
+	}
+	i.count++
+	if i.count%importBatchSize != 0 {
+		return nil
+	}
+
+	// write the nodes built so far at the imported version, they only become
+	// reachable once the root is written by Commit.
+	if err := i.tree.applyPending(); err != nil {
+		return err
+	}
+	batch := i.tree.db.NewBatch()
+	defer batch.Close()
+	if err := i.tree.saveNodes(batch, i.version); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// Commit commits the imported version of the tree.
+func (i *Importer) Commit() error {
+	return i.tree.commit(i.version)
+}
+
+// Close closes the importer.
+func (i *Importer) Close() error {
+	return nil
+}
@@ -0,0 +1,221 @@
+package smt
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	leafPrefix  = byte(0x00)
+	innerPrefix = byte(0x01)
+
+	hashSize    = sha256.Size
+	nodeKeySize = 12
+)
+
+// emptyHash is the hash of an empty subtree, it is the placeholder used for the
+// empty children of inner nodes and the hash of an empty tree.
+var emptyHash = make([]byte, hashSize)
+
+// nodeKey identifies a persisted node: it is the version at which the node was
+// created followed by a sequence number within that version, both big endian.
+type nodeKey [nodeKeySize]byte
+
+func newNodeKey(version uint64, seq uint32) nodeKey {
+	var nk nodeKey
+	binary.BigEndian.PutUint64(nk[:8], version)
+	binary.BigEndian.PutUint32(nk[8:], seq)
+	return nk
+}
+
+func (nk nodeKey) version() uint64 {
+	return binary.BigEndian.Uint64(nk[:8])
+}
+
+// node is either a leaf, holding a key and its value, or an inner node with at
+// least two leaves in its subtree. A subtree with a single leaf is always
+// represented by the leaf itself, at the highest possible depth.
+type node struct {
+	hash      []byte
+	nodeKey   nodeKey
+	persisted bool
+
+	// leaf fields
+	leaf    bool
+	key     []byte
+	keyHash []byte
+	value   []byte
+
+	// inner fields, a nil child is an empty subtree
+	left, right *child
+}
+
+// child is a reference from an inner node to one of its children. Persisted
+// children are loaded from the database by node key, children which are not
+// persisted yet are kept in memory.
+type child struct {
+	nodeKey nodeKey
+	hash    []byte
+	node    *node
+}
+
+func newLeaf(key, value []byte) *node {
+	keyHash := sha256.Sum256(key)
+	return &node{
+		leaf:    true,
+		key:     key,
+		keyHash: keyHash[:],
+		value:   value,
+		hash:    leafHash(keyHash[:], value),
+	}
+}
+
+func newInner(left, right *child) *node {
+	n := &node{
+		left:  left,
+		right: right,
+	}
+	n.hash = innerHash(n.left.getHash(), n.right.getHash())
+	return n
+}
+
+func toChild(n *node) *child {
+	if n == nil {
+		return nil
+	}
+	if n.persisted {
+		return &child{nodeKey: n.nodeKey, hash: n.hash}
+	}
+	return &child{hash: n.hash, node: n}
+}
+
+func (c *child) getHash() []byte {
+	if c == nil {
+		return emptyHash
+	}
+	return c.hash
+}
+
+// leafHash returns the hash of a leaf as defined by the ics23 SMT proof spec:
+// sha256(0x00 || sha256(key) || sha256(value)).
+func leafHash(keyHash, value []byte) []byte {
+	valueHash := sha256.Sum256(value)
+	h := sha256.New()
+	h.Write([]byte{leafPrefix})
+	h.Write(keyHash)
+	h.Write(valueHash[:])
+	return h.Sum(nil)
+}
+
+// innerHash returns the hash of an inner node as defined by the ics23 SMT proof
+// spec: sha256(0x01 || left || right).
+func innerHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{innerPrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// bit returns the bit of the key hash at the given depth, which selects the
+// child of the inner node at that depth to walk into.
+func bit(keyHash []byte, depth int) byte {
+	return (keyHash[depth/8] >> (7 - depth%8)) & 1
+}
+
+// encode encodes a node. Leaves are encoded as their key and value, inner nodes
+// as the node keys and hashes of their non-empty children.
+func (n *node) encode() []byte {
+	if n.leaf {
+		buf := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(n.key)+len(n.value))
+		buf = append(buf, leafPrefix)
+		buf = binary.AppendUvarint(buf, uint64(len(n.key)))
+		buf = append(buf, n.key...)
+		buf = binary.AppendUvarint(buf, uint64(len(n.value)))
+		return append(buf, n.value...)
+	}
+
+	var flags byte
+	if n.left != nil {
+		flags |= 0x1
+	}
+	if n.right != nil {
+		flags |= 0x2
+	}
+	buf := make([]byte, 0, 2+2*(nodeKeySize+hashSize))
+	buf = append(buf, innerPrefix, flags)
+	for _, c := range []*child{n.left, n.right} {
+		if c != nil {
+			buf = append(buf, c.nodeKey[:]...)
+			buf = append(buf, c.hash...)
+		}
+	}
+	return buf
+}
+
+// decodeNode decodes a node encoded with encode.
+func decodeNode(nk nodeKey, bz []byte) (*node, error) {
+	if len(bz) == 0 {
+		return nil, errors.New("empty node")
+	}
+	switch bz[0] {
+	case leafPrefix:
+		bz = bz[1:]
+		key, bz, err := decodeBytes(bz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode leaf key: %w", err)
+		}
+		value, bz, err := decodeBytes(bz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode leaf value: %w", err)
+		}
+		if len(bz) != 0 {
+			return nil, fmt.Errorf("unexpected %d trailing bytes in leaf", len(bz))
+		}
+		n := newLeaf(key, value)
+		n.nodeKey, n.persisted = nk, true
+		return n, nil
+
+	case innerPrefix:
+		if len(bz) < 2 {
+			return nil, errors.New("missing inner node flags")
+		}
+		flags := bz[1]
+		bz = bz[2:]
+		n := &node{nodeKey: nk, persisted: true}
+		for i, c := range []**child{&n.left, &n.right} {
+			if flags&(1<<i) == 0 {
+				continue
+			}
+			if len(bz) < nodeKeySize+hashSize {
+				return nil, errors.New("invalid inner node child")
+			}
+			ref := &child{hash: bz[nodeKeySize : nodeKeySize+hashSize]}
+			copy(ref.nodeKey[:], bz[:nodeKeySize])
+			*c = ref
+			bz = bz[nodeKeySize+hashSize:]
+		}
+		if len(bz) != 0 {
+			return nil, fmt.Errorf("unexpected %d trailing bytes in inner node", len(bz))
+		}
+		n.hash = innerHash(n.left.getHash(), n.right.getHash())
+		return n, nil
+
+	default:
+		return nil, fmt.Errorf("unknown node type %d", bz[0])
+	}
+}
+
+func decodeBytes(bz []byte) ([]byte, []byte, error) {
+	size, n := binary.Uvarint(bz)
+	if n <= 0 {
+		return nil, nil, errors.New("invalid length prefix")
+	}
+	bz = bz[n:]
+	if uint64(len(bz)) < size {
+		return nil, nil, errors.New("length prefix exceeds the remaining bytes")
+	}
+	return bz[:size:size], bz[size:], nil
+}
@@ -0,0 +1,742 @@
+package smt
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	ics23 "github.com/cosmos/ics23/go"
+
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2/commitment"
+	"cosmossdk.io/store/v2/proof"
+)
+
+var (
+	_ commitment.Tree                 = (*Tree)(nil)
+	_ commitment.CommitmentOpProvider = (*Tree)(nil)
+)
+
+const (
+	nodePrefix    = 'n' // n<node key> -> node
+	rootPrefix    = 'r' // r<version> -> root node key, empty for an empty tree
+	orphanPrefix  = 'o' // o<version><node key> -> nil, the node is not part of the tree since version
+	latestVersion = 'l' // l -> latest version
+)
+
+// Tree is a versioned sparse Merkle tree implementing commitment.Tree. Keys are
+// placed in the tree at the path given by their sha256 hash, which makes the
+// tree shape, and so its root hash, independent of the insertion order: the tree
+// never needs to be rebalanced and the same state always yields the same root.
+// Proofs follow the ics23 SMT proof spec.
+//
+// Every version shares the nodes it did not update with the previous version.
+// The nodes replaced in a version are recorded as orphans of this version so
+// that Prune can delete them once no retained version refers to them.
+type Tree struct {
+	db    corestore.KVStoreWithBatch
+	cache *nodeCache
+
+	root           *node
+	hash           []byte
+	version        uint64
+	initialVersion uint64
+
+	// pending holds the updates since the last commit which were not applied
+	// to root yet, by key.
+	pending map[string]update
+	// orphans holds the persisted nodes replaced since the last commit.
+	orphans []nodeKey
+	// seq is the sequence number of the next node key of the working version.
+	seq uint32
+}
+
+// update is the update of a key, it either sets its value or removes it.
+type update struct {
+	keyHash []byte
+	key     []byte
+	value   []byte
+	remove  bool
+}
+
+// NewTree creates a new Tree instance. The tree is empty until a version is
+// loaded with LoadVersion.
+func NewTree(db corestore.KVStoreWithBatch, cfg *Config) *Tree {
+	return &Tree{
+		db:      db,
+		cache:   newNodeCache(cfg.CacheSize),
+		hash:    emptyHash,
+		pending: make(map[string]update),
+	}
+}
+
+// Set sets the given key-value pair in the tree.
+func (t *Tree) Set(key, value []byte) error {
+	if value == nil {
+		return errors.New("value must not be nil")
+	}
+	t.pending[string(key)] = update{key: key, value: value}
+	return nil
+}
+
+// Remove removes the given key from the tree.
+func (t *Tree) Remove(key []byte) error {
+	t.pending[string(key)] = update{key: key, remove: true}
+	return nil
+}
+
+// Hash returns the hash of the latest saved version of the tree.
+func (t *Tree) Hash() []byte {
+	return t.hash
+}
+
+// WorkingHash returns the working hash of the tree.
+func (t *Tree) WorkingHash() []byte {
+	if err := t.applyPending(); err != nil {
+		panic(fmt.Errorf("failed to apply pending updates: %w", err))
+	}
+	return rootHash(t.root)
+}
+
+// Rollback discards the changes of the working tree since the latest saved
+// version.
+func (t *Tree) Rollback() error {
+	// the cache may hold the nodes of the working version
+	t.cache.reset()
+	var root *node
+	if t.version > 0 {
+		var err error
+		root, err = t.getRoot(t.version)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.root = root
+	t.pending = make(map[string]update)
+	t.orphans = nil
+	t.seq = 0
+	return nil
+}
+
+// GetLatestVersion returns the latest version of the tree.
+func (t *Tree) GetLatestVersion() (uint64, error) {
+	bz, err := t.db.Get([]byte{latestVersion})
+	if err != nil {
+		return 0, err
+	}
+	if bz == nil {
+		return 0, nil
+	}
+	if len(bz) != 8 {
+		return 0, fmt.Errorf("invalid latest version %X", bz)
+	}
+	return binary.BigEndian.Uint64(bz), nil
+}
+
+// SetInitialVersion sets the initial version of the tree, which is the version
+// of the first commit of an empty tree.
+func (t *Tree) SetInitialVersion(version uint64) error {
+	t.initialVersion = version
+	return nil
+}
+
+// LoadVersion loads the state at the given version, discarding the working
+// state. The versions greater than the given version are deleted, so that
+// they can be committed again. Version 0 loads the latest version.
+func (t *Tree) LoadVersion(version uint64) error {
+	latest, err := t.GetLatestVersion()
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		version = latest
+	}
+	if version > latest {
+		return fmt.Errorf("version %d does not exist, latest version is %d", version, latest)
+	}
+
+	if version < latest {
+		if err := t.deleteVersionsFrom(version + 1); err != nil {
+			return err
+		}
+	}
+
+	t.cache.reset()
+	t.root = nil
+	if version > 0 {
+		t.root, err = t.getRoot(version)
+		if err != nil {
+			return err
+		}
+	}
+	t.hash = rootHash(t.root)
+	t.version = version
+	t.pending = make(map[string]update)
+	t.orphans = nil
+	t.seq = 0
+	return nil
+}
+
+// Commit commits the working state as a new version of the tree.
+func (t *Tree) Commit() ([]byte, uint64, error) {
+	version := t.version + 1
+	if t.version == 0 && t.initialVersion > 1 {
+		version = t.initialVersion
+	}
+	if err := t.commit(version); err != nil {
+		return nil, 0, err
+	}
+	return t.hash, t.version, nil
+}
+
+func (t *Tree) commit(version uint64) error {
+	if err := t.applyPending(); err != nil {
+		return err
+	}
+
+	batch := t.db.NewBatch()
+	defer batch.Close()
+
+	if err := t.saveNodes(batch, version); err != nil {
+		return err
+	}
+	var rootKey []byte
+	if t.root != nil {
+		rootKey = t.root.nodeKey[:]
+	}
+	if err := batch.Set(versionKey(rootPrefix, version), rootKey); err != nil {
+		return err
+	}
+	if err := batch.Set([]byte{latestVersion}, binary.BigEndian.AppendUint64(nil, version)); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	t.hash = rootHash(t.root)
+	t.version = version
+	t.seq = 0
+	return nil
+}
+
+// applyPending applies the pending updates to the working root.
+func (t *Tree) applyPending() error {
+	if len(t.pending) == 0 {
+		return nil
+	}
+
+	updates := make([]update, 0, len(t.pending))
+	for _, u := range t.pending {
+		keyHash := sha256.Sum256(u.key)
+		u.keyHash = keyHash[:]
+		updates = append(updates, u)
+	}
+	slices.SortFunc(updates, func(a, b update) int {
+		return bytes.Compare(a.keyHash, b.keyHash)
+	})
+
+	root, err := t.apply(t.root, 0, updates)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	t.pending = make(map[string]update)
+	return nil
+}
+
+// apply applies the updates, sorted by key hash, to the subtree rooted at n
+// and at the given depth, and returns the new root of the subtree.
+func (t *Tree) apply(n *node, depth int, updates []update) (*node, error) {
+	if len(updates) == 0 {
+		return n, nil
+	}
+
+	if n == nil || n.leaf {
+		leaves := make([]*node, 0, len(updates)+1)
+		existing := n
+		for _, u := range updates {
+			if existing != nil && bytes.Equal(u.keyHash, existing.keyHash) {
+				if !u.remove && bytes.Equal(u.value, existing.value) {
+					// the value did not change, keep the existing leaf
+					continue
+				}
+				t.orphan(existing)
+				existing = nil
+			}
+			if !u.remove {
+				leaves = append(leaves, newLeaf(u.key, u.value))
+			}
+		}
+		if existing != nil {
+			leaves = append(leaves, existing)
+			slices.SortFunc(leaves, func(a, b *node) int {
+				return bytes.Compare(a.keyHash, b.keyHash)
+			})
+		}
+		return build(depth, leaves), nil
+	}
+
+	split, _ := slices.BinarySearchFunc(updates, 1, func(u update, target byte) int {
+		return int(bit(u.keyHash, depth)) - int(target)
+	})
+	left, leftChanged, err := t.applyChild(n.left, depth+1, updates[:split])
+	if err != nil {
+		return nil, err
+	}
+	right, rightChanged, err := t.applyChild(n.right, depth+1, updates[split:])
+	if err != nil {
+		return nil, err
+	}
+	if !leftChanged && !rightChanged {
+		return n, nil
+	}
+	t.orphan(n)
+	if left == nil && right == nil {
+		return nil, nil
+	}
+	// a single leaf left in the subtree moves up to replace the inner node
+	if left == nil || right == nil {
+		remaining, err := t.getChild(cmp.Or(left, right))
+		if err != nil {
+			return nil, err
+		}
+		if remaining.leaf {
+			return remaining, nil
+		}
+	}
+	return newInner(left, right), nil
+}
+
+// applyChild applies the updates to the subtree of a child, and returns the new
+// child and whether it changed.
+func (t *Tree) applyChild(c *child, depth int, updates []update) (*child, bool, error) {
+	if len(updates) == 0 {
+		return c, false, nil
+	}
+	n, err := t.getChild(c)
+	if err != nil {
+		return nil, false, err
+	}
+	updated, err := t.apply(n, depth, updates)
+	if err != nil {
+		return nil, false, err
+	}
+	if updated == n {
+		return c, false, nil
+	}
+	return toChild(updated), true, nil
+}
+
+// build builds the subtree at the given depth holding the given leaves, sorted
+// by key hash.
+func build(depth int, leaves []*node) *node {
+	switch len(leaves) {
+	case 0:
+		return nil
+	case 1:
+		return leaves[0]
+	}
+	split, _ := slices.BinarySearchFunc(leaves, 1, func(n *node, target byte) int {
+		return int(bit(n.keyHash, depth)) - int(target)
+	})
+	return newInner(toChild(build(depth+1, leaves[:split])), toChild(build(depth+1, leaves[split:])))
+}
+
+// orphan records that a node was replaced in the working version.
+func (t *Tree) orphan(n *node) {
+	if n.persisted {
+		t.orphans = append(t.orphans, n.nodeKey)
+	}
+}
+
+// saveNodes writes the nodes of the working root which are not persisted yet,
+// and the orphans, to the batch at the given version.
+func (t *Tree) saveNodes(batch corestore.Batch, version uint64) error {
+	if t.root != nil {
+		if err := t.saveNode(batch, version, t.root); err != nil {
+			return err
+		}
+	}
+	for _, nk := range t.orphans {
+		// nodes created in the working version were never part of a committed
+		// version, ex. when importing, so they are deleted right away
+		if nk.version() == version {
+			t.cache.remove(nk)
+			if err := batch.Delete(append([]byte{nodePrefix}, nk[:]...)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := batch.Set(orphanKey(version, nk), []byte{}); err != nil {
+			return err
+		}
+	}
+	t.orphans = nil
+	return nil
+}
+
+func (t *Tree) saveNode(batch corestore.Batch, version uint64, n *node) error {
+	if n.persisted {
+		return nil
+	}
+	for _, c := range []*child{n.left, n.right} {
+		if c == nil || c.node == nil {
+			continue
+		}
+		if err := t.saveNode(batch, version, c.node); err != nil {
+			return err
+		}
+		c.nodeKey = c.node.nodeKey
+		c.node = nil
+	}
+	n.nodeKey = newNodeKey(version, t.seq)
+	n.persisted = true
+	t.seq++
+	if err := batch.Set(append([]byte{nodePrefix}, n.nodeKey[:]...), n.encode()); err != nil {
+		return err
+	}
+	t.cache.add(n)
+	return nil
+}
+
+// getChild returns the node of a child, loading it if it is persisted.
+func (t *Tree) getChild(c *child) (*node, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if c.node != nil {
+		return c.node, nil
+	}
+	return t.getNode(c.nodeKey)
+}
+
+func (t *Tree) getNode(nk nodeKey) (*node, error) {
+	if n := t.cache.get(nk); n != nil {
+		return n, nil
+	}
+	bz, err := t.db.Get(append([]byte{nodePrefix}, nk[:]...))
+	if err != nil {
+		return nil, err
+	}
+	if bz == nil {
+		return nil, fmt.Errorf("node %X not found", nk[:])
+	}
+	n, err := decodeNode(nk, bz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode node %X: %w", nk[:], err)
+	}
+	t.cache.add(n)
+	return n, nil
+}
+
+// getRoot returns the root of the given version, nil for an empty tree.
+func (t *Tree) getRoot(version uint64) (*node, error) {
+	bz, err := t.db.Get(versionKey(rootPrefix, version))
+	if err != nil {
+		return nil, err
+	}
+	if bz == nil {
+		return nil, fmt.Errorf("version %d does not exist", version)
+	}
+	if len(bz) == 0 {
+		return nil, nil
+	}
+	if len(bz) != nodeKeySize {
+		return nil, fmt.Errorf("invalid root node key %X", bz)
+	}
+	return t.getNode(nodeKey(bz))
+}
+
+// Get returns the value of the given key at the given version, nil if the key
+// does not exist.
+func (t *Tree) Get(version uint64, key []byte) ([]byte, error) {
+	root, err := t.getRoot(version)
+	if err != nil {
+		return nil, err
+	}
+	keyHash := sha256.Sum256(key)
+	leaf, _, err := t.walk(root, keyHash[:])
+	if err != nil || leaf == nil || !bytes.Equal(leaf.keyHash, keyHash[:]) {
+		return nil, err
+	}
+	return leaf.value, nil
+}
+
+// walk walks from root along the path of the key hash until it reaches a leaf or
+// an empty subtree. It returns the leaf, which may hold another key, and the
+// inner ops of the path from the leaf up to the root.
+func (t *Tree) walk(root *node, keyHash []byte) (*node, []*ics23.InnerOp, error) {
+	var path []*ics23.InnerOp
+	n := root
+	for depth := 0; n != nil && !n.leaf; depth++ {
+		next, sibling := n.left, n.right
+		op := &ics23.InnerOp{Hash: ics23.HashOp_SHA256, Prefix: []byte{innerPrefix}, Suffix: sibling.getHash()}
+		if bit(keyHash, depth) == 1 {
+			next, sibling = n.right, n.left
+			op = &ics23.InnerOp{Hash: ics23.HashOp_SHA256, Prefix: append([]byte{innerPrefix}, sibling.getHash()...)}
+		}
+		path = append(path, op)
+
+		var err error
+		n, err = t.getChild(next)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	slices.Reverse(path)
+	return n, path, nil
+}
+
+// Prune prunes all versions up to and including the provided version.
+func (t *Tree) Prune(version uint64) error {
+	latest, err := t.GetLatestVersion()
+	if err != nil {
+		return err
+	}
+
+	batch := t.db.NewBatch()
+	defer batch.Close()
+
+	// pruning every version, ex. the versions of a removed store, clears the tree
+	if version >= latest {
+		for _, prefix := range []byte{nodePrefix, rootPrefix, orphanPrefix, latestVersion} {
+			if err := t.iterate([]byte{prefix}, []byte{prefix + 1}, batch.Delete); err != nil {
+				return err
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		t.cache.reset()
+		t.root, t.hash, t.version = nil, emptyHash, 0
+		return nil
+	}
+
+	// nodes orphaned at version+1 or before are only part of pruned versions
+	err = t.iterate(versionKey(orphanPrefix, 0), versionKey(orphanPrefix, version+2), func(key []byte) error {
+		var nk nodeKey
+		copy(nk[:], key[9:])
+		t.cache.remove(nk)
+		if err := batch.Delete(append([]byte{nodePrefix}, nk[:]...)); err != nil {
+			return err
+		}
+		return batch.Delete(key)
+	})
+	if err != nil {
+		return err
+	}
+	err = t.iterate(versionKey(rootPrefix, 0), versionKey(rootPrefix, version+1), batch.Delete)
+	if err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// deleteVersionsFrom deletes the roots, nodes and orphan records of the versions
+// greater than or equal to the given version.
+func (t *Tree) deleteVersionsFrom(version uint64) error {
+	batch := t.db.NewBatch()
+	defer batch.Close()
+
+	for _, prefix := range []byte{nodePrefix, rootPrefix, orphanPrefix} {
+		if err := t.iterate(versionKey(prefix, version), []byte{prefix + 1}, batch.Delete); err != nil {
+			return err
+		}
+	}
+	if err := batch.Set([]byte{latestVersion}, binary.BigEndian.AppendUint64(nil, version-1)); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// iterate calls fn with the keys in the [start, end) range. The iterator is
+// closed before returning, so fn should only stage writes, ex. in a batch.
+func (t *Tree) iterate(start, end []byte, fn func(key []byte) error) error {
+	itr, err := t.db.Iterator(start, end)
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+
+	for ; itr.Valid(); itr.Next() {
+		if err := fn(slices.Clone(itr.Key())); err != nil {
+			return err
+		}
+	}
+	return itr.Error()
+}
+
+// GetProof returns a proof for the given key and version, following the ics23
+// SMT proof spec: an existence proof if the key exists, or a non-existence proof
+// made of the existence proofs of its neighbors in key hash order.
+func (t *Tree) GetProof(version uint64, key []byte) (*ics23.CommitmentProof, error) {
+	root, err := t.getRoot(version)
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, fmt.Errorf("cannot prove key %X in an empty tree", key)
+	}
+
+	keyHash := sha256.Sum256(key)
+	leaf, path, err := t.walk(root, keyHash[:])
+	if err != nil {
+		return nil, err
+	}
+	if leaf != nil && bytes.Equal(leaf.keyHash, keyHash[:]) {
+		return &ics23.CommitmentProof{
+			Proof: &ics23.CommitmentProof_Exist{
+				Exist: existenceProof(leaf, path),
+			},
+		}, nil
+	}
+
+	left, right, err := t.neighbors(root, 0, keyHash[:])
+	if err != nil {
+		return nil, err
+	}
+	nonExist := &ics23.NonExistenceProof{Key: key}
+	for _, neighbor := range []struct {
+		leaf  *node
+		proof **ics23.ExistenceProof
+	}{{left, &nonExist.Left}, {right, &nonExist.Right}} {
+		if neighbor.leaf == nil {
+			continue
+		}
+		_, path, err := t.walk(root, neighbor.leaf.keyHash)
+		if err != nil {
+			return nil, err
+		}
+		*neighbor.proof = existenceProof(neighbor.leaf, path)
+	}
+	return &ics23.CommitmentProof{
+		Proof: &ics23.CommitmentProof_Nonexist{
+			Nonexist: nonExist,
+		},
+	}, nil
+}
+
+func existenceProof(leaf *node, path []*ics23.InnerOp) *ics23.ExistenceProof {
+	return &ics23.ExistenceProof{
+		Key:   leaf.key,
+		Value: leaf.value,
+		Leaf:  ics23.SmtSpec.LeafSpec,
+		Path:  path,
+	}
+}
+
+// neighbors returns the leaves of the subtree rooted at n which are immediately
+// before and after the given key hash, which is not in the subtree.
+func (t *Tree) neighbors(n *node, depth int, keyHash []byte) (left, right *node, err error) {
+	if n == nil {
+		return nil, nil, nil
+	}
+	if n.leaf {
+		if bytes.Compare(n.keyHash, keyHash) < 0 {
+			return n, nil, nil
+		}
+		return nil, n, nil
+	}
+
+	near, far := n.left, n.right
+	if bit(keyHash, depth) == 1 {
+		near, far = n.right, n.left
+	}
+	nearNode, err := t.getChild(near)
+	if err != nil {
+		return nil, nil, err
+	}
+	left, right, err = t.neighbors(nearNode, depth+1, keyHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the neighbor missing from the near subtree is at the edge of the far subtree
+	last := bit(keyHash, depth) == 1
+	if (last && left != nil) || (!last && right != nil) {
+		return left, right, nil
+	}
+	farNode, err := t.getChild(far)
+	if err != nil {
+		return nil, nil, err
+	}
+	edge, err := t.edge(farNode, last)
+	if last {
+		return edge, right, err
+	}
+	return left, edge, err
+}
+
+// edge returns the leaf with the lowest key hash of the subtree rooted at n,
+// or the highest one if last is true.
+func (t *Tree) edge(n *node, last bool) (*node, error) {
+	for n != nil && !n.leaf {
+		c := n.left
+		if (last && n.right != nil) || c == nil {
+			c = n.right
+		}
+		var err error
+		n, err = t.getChild(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// CommitmentOp implements commitment.CommitmentOpProvider.
+func (t *Tree) CommitmentOp(key []byte, p *ics23.CommitmentProof) proof.CommitmentOp {
+	return proof.NewSMTCommitmentOp(key, p)
+}
+
+// Export exports the leaves of the tree at the given version.
+func (t *Tree) Export(version uint64) (commitment.Exporter, error) {
+	root, err := t.getRoot(version)
+	if err != nil {
+		return nil, err
+	}
+	e := &Exporter{tree: t, version: version}
+	if root != nil {
+		e.stack = []*node{root}
+	}
+	return e, nil
+}
+
+// Import returns an importer which restores the tree at the given version.
+// The tree must be empty.
+func (t *Tree) Import(version uint64) (commitment.Importer, error) {
+	latest, err := t.GetLatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	if latest != 0 || t.root != nil {
+		return nil, errors.New("cannot import into a non-empty tree")
+	}
+	return &Importer{tree: t, version: version}, nil
+}
+
+// Close closes the tree.
+func (t *Tree) Close() error {
+	t.cache.reset()
+	return nil
+}
+
+func rootHash(root *node) []byte {
+	if root == nil {
+		return emptyHash
+	}
+	return root.hash
+}
+
+func versionKey(prefix byte, version uint64) []byte {
+	return binary.BigEndian.AppendUint64([]byte{prefix}, version)
+}
+
+func orphanKey(version uint64, nk nodeKey) []byte {
+	return append(versionKey(orphanPrefix, version), nk[:]...)
+}
@@ -0,0 +1,363 @@
+package smt
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	ics23 "github.com/cosmos/ics23/go"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	corelog "cosmossdk.io/core/log"
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2/commitment"
+	dbm "cosmossdk.io/store/v2/db"
+	"cosmossdk.io/store/v2/proof"
+	snapshotstypes "cosmossdk.io/store/v2/snapshots/types"
+)
+
+func TestCommitterSuite(t *testing.T) {
+	s := &commitment.CommitStoreTestSuite{
+		NewStore: func(db corestore.KVStoreWithBatch, storeKeys, oldStoreKeys []string, logger corelog.Logger) (*commitment.CommitStore, error) {
+			multiTrees := make(map[string]commitment.Tree)
+			cfg := DefaultConfig()
+			mountTreeFn := func(storeKey string) (commitment.Tree, error) {
+				prefixDB := dbm.NewPrefixDB(db, []byte(storeKey))
+				return NewTree(prefixDB, cfg), nil
+			}
+			for _, storeKey := range storeKeys {
+				multiTrees[storeKey], _ = mountTreeFn(storeKey)
+			}
+			oldTrees := make(map[string]commitment.Tree)
+			for _, storeKey := range oldStoreKeys {
+				oldTrees[storeKey], _ = mountTreeFn(storeKey)
+			}
+
+			return commitment.NewCommitStore(multiTrees, oldTrees, db, logger)
+		},
+	}
+
+	suite.Run(t, s)
+}
+
+func generateTree(t *testing.T, db corestore.KVStoreWithBatch) *Tree {
+	t.Helper()
+	tree := NewTree(db, DefaultConfig())
+	require.NoError(t, tree.LoadVersion(0))
+	return tree
+}
+
+func TestTree(t *testing.T) {
+	db := dbm.NewMemDB()
+	tree := generateTree(t, db)
+
+	initVersion, err := tree.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), initVersion)
+	require.Equal(t, emptyHash, tree.WorkingHash())
+
+	// write a batch of version 1
+	require.NoError(t, tree.Set([]byte("key1"), []byte("value1")))
+	require.NoError(t, tree.Set([]byte("key2"), []byte("value2")))
+	require.NoError(t, tree.Set([]byte("key3"), []byte("value3")))
+
+	workingHash := tree.WorkingHash()
+	require.NotEqual(t, emptyHash, workingHash)
+	v, err := tree.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), v)
+
+	// commit the batch
+	commitHash, version, err := tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), version)
+	require.Equal(t, workingHash, commitHash)
+	require.Equal(t, commitHash, tree.Hash())
+
+	// ensure we can get expected values
+	bz, err := tree.Get(1, []byte("key1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), bz)
+	bz, err = tree.Get(1, []byte("key4"))
+	require.NoError(t, err)
+	require.Nil(t, bz)
+	_, err = tree.Get(2, []byte("key1"))
+	require.Error(t, err)
+
+	// write a batch of version 2
+	require.NoError(t, tree.Set([]byte("key4"), []byte("value4")))
+	require.NoError(t, tree.Set([]byte("key5"), []byte("value5")))
+	require.NoError(t, tree.Remove([]byte("key1")))
+	version2Hash := tree.WorkingHash()
+	commitHash, version, err = tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), version)
+	require.Equal(t, version2Hash, commitHash)
+
+	// the previous version is still readable
+	bz, err = tree.Get(1, []byte("key1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value1"), bz)
+	bz, err = tree.Get(2, []byte("key1"))
+	require.NoError(t, err)
+	require.Nil(t, bz)
+
+	// write a batch of version 3
+	require.NoError(t, tree.Set([]byte("key6"), []byte("value6")))
+	_, _, err = tree.Commit()
+	require.NoError(t, err)
+
+	// the latest version is loaded by a new tree
+	reopened := generateTree(t, db)
+	v, err = reopened.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), v)
+	require.Equal(t, tree.Hash(), reopened.Hash())
+
+	// prune version 1
+	require.NoError(t, tree.Prune(1))
+	_, err = tree.Get(1, []byte("key2"))
+	require.Error(t, err)
+	bz, err = tree.Get(2, []byte("key2"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value2"), bz)
+
+	// load version 2, which deletes version 3
+	require.NoError(t, tree.LoadVersion(2))
+	require.Equal(t, version2Hash, tree.WorkingHash())
+	_, err = tree.Get(3, []byte("key1"))
+	require.Error(t, err)
+
+	// version 3 can be committed again
+	require.NoError(t, tree.Set([]byte("key7"), []byte("value7")))
+	_, version, err = tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), version)
+	bz, err = tree.Get(3, []byte("key6"))
+	require.NoError(t, err)
+	require.Nil(t, bz)
+
+	// pruning every version clears the tree
+	require.NoError(t, tree.Prune(3))
+	_, err = tree.Get(3, []byte("key2"))
+	require.Error(t, err)
+	v, err = tree.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), v)
+	require.Equal(t, 0, countKeys(t, db, nodePrefix))
+
+	require.NoError(t, tree.Close())
+}
+
+func TestTreeInitialVersion(t *testing.T) {
+	tree := generateTree(t, dbm.NewMemDB())
+	require.NoError(t, tree.SetInitialVersion(5))
+	require.NoError(t, tree.Set([]byte("key"), []byte("value")))
+	_, version, err := tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), version)
+	_, version, err = tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), version)
+}
+
+// TestTreeHistoryIndependence checks that the root hash only depends on the state
+// of the tree, not on the order or the grouping of the updates which led to it.
+func TestTreeHistoryIndependence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := make([][]byte, 500)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key-%d", i))
+	}
+
+	// one commit with the final state
+	expected := generateTree(t, dbm.NewMemDB())
+	for _, key := range keys[:250] {
+		require.NoError(t, expected.Set(key, key))
+	}
+	expectedHash, _, err := expected.Commit()
+	require.NoError(t, err)
+
+	// many commits in random order, including keys which are removed later
+	tree := generateTree(t, dbm.NewMemDB())
+	for _, i := range rng.Perm(len(keys)) {
+		require.NoError(t, tree.Set(keys[i], []byte("tmp")))
+		if rng.Intn(10) == 0 {
+			_, _, err := tree.Commit()
+			require.NoError(t, err)
+		}
+	}
+	for i, key := range keys {
+		if i < 250 {
+			require.NoError(t, tree.Set(key, key))
+		} else {
+			require.NoError(t, tree.Remove(key))
+		}
+	}
+	hash, _, err := tree.Commit()
+	require.NoError(t, err)
+	require.Equal(t, expectedHash, hash)
+}
+
+func TestTreeGetProof(t *testing.T) {
+	tree := generateTree(t, dbm.NewMemDB())
+
+	// a single leaf
+	require.NoError(t, tree.Set([]byte("key-0"), []byte("value-0")))
+	_, _, err := tree.Commit()
+	require.NoError(t, err)
+	verifyProof(t, tree, 1, []byte("key-0"), []byte("value-0"))
+	verifyProof(t, tree, 1, []byte("key-1"), nil)
+
+	for i := 1; i < 100; i++ {
+		require.NoError(t, tree.Set([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	_, _, err = tree.Commit()
+	require.NoError(t, err)
+
+	for i := 0; i < 200; i++ {
+		key := []byte(fmt.Sprintf("key-%d", i))
+		var value []byte
+		if i < 100 {
+			value = []byte(fmt.Sprintf("value-%d", i))
+		}
+		verifyProof(t, tree, 2, key, value)
+	}
+
+	// proofs of previous versions are against their root
+	verifyProof(t, tree, 1, []byte("key-1"), nil)
+
+	_, err = generateTree(t, dbm.NewMemDB()).GetProof(0, []byte("key"))
+	require.Error(t, err)
+}
+
+func verifyProof(t *testing.T, tree *Tree, version uint64, key, value []byte) {
+	t.Helper()
+
+	p, err := tree.GetProof(version, key)
+	require.NoError(t, err)
+	require.Equal(t, value != nil, p.GetExist() != nil)
+	if exist := p.GetExist(); exist != nil {
+		require.NoError(t, exist.CheckAgainstSpec(ics23.SmtSpec))
+	}
+
+	root, err := tree.getRoot(version)
+	require.NoError(t, err)
+	args := [][]byte{value}
+	if value == nil {
+		args = nil
+		require.True(t, ics23.VerifyNonMembership(ics23.SmtSpec, root.hash, p, key))
+	} else {
+		require.True(t, ics23.VerifyMembership(ics23.SmtSpec, root.hash, p, key, value))
+	}
+
+	roots, err := tree.CommitmentOp(key, p).Run(args)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{root.hash}, roots)
+	require.Equal(t, proof.ProofOpSMTCommitment, tree.CommitmentOp(key, p).Type)
+}
+
+func TestTreeExportImport(t *testing.T) {
+	tree := generateTree(t, dbm.NewMemDB())
+	for i := 0; i < 2*importBatchSize+10; i++ {
+		require.NoError(t, tree.Set([]byte(fmt.Sprintf("key-%d", i)), []byte(fmt.Sprintf("value-%d", i))))
+	}
+	_, _, err := tree.Commit()
+	require.NoError(t, err)
+	// only the exported version must be imported
+	require.NoError(t, tree.Set([]byte("key-new"), []byte("value-new")))
+	require.NoError(t, tree.Remove([]byte("key-0")))
+	_, _, err = tree.Commit()
+	require.NoError(t, err)
+
+	exporter, err := tree.Export(1)
+	require.NoError(t, err)
+	target := generateTree(t, dbm.NewMemDB())
+	importer, err := target.Import(1)
+	require.NoError(t, err)
+
+	count := 0
+	for {
+		item, err := exporter.Next()
+		if errors.Is(err, commitment.ErrorExportDone) {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, int32(0), item.Height)
+		require.NoError(t, importer.Add(item))
+		count++
+	}
+	require.Equal(t, 2*importBatchSize+10, count)
+	require.NoError(t, exporter.Close())
+	require.NoError(t, importer.Commit())
+	require.NoError(t, importer.Close())
+
+	require.NoError(t, target.LoadVersion(1))
+	root, err := tree.getRoot(1)
+	require.NoError(t, err)
+	require.Equal(t, root.hash, target.Hash())
+	bz, err := target.Get(1, []byte("key-0"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("value-0"), bz)
+
+	// only leaves can be imported
+	_, err = target.Import(2)
+	require.Error(t, err)
+	importer, err = generateTree(t, dbm.NewMemDB()).Import(1)
+	require.NoError(t, err)
+	require.Error(t, importer.Add(&snapshotstypes.SnapshotIAVLItem{Key: []byte("key"), Height: 1}))
+}
+
+// TestTreePruneDeletesOrphans checks that pruning all but the latest version
+// leaves as many nodes as a tree committed once with the same state.
+func TestTreePruneDeletesOrphans(t *testing.T) {
+	db := dbm.NewMemDB()
+	tree := generateTree(t, db)
+	for version := 0; version < 20; version++ {
+		for i := 0; i < 50; i++ {
+			key := []byte(fmt.Sprintf("key-%d", (version*7+i)%100))
+			if i%5 == 0 {
+				require.NoError(t, tree.Remove(key))
+			} else {
+				require.NoError(t, tree.Set(key, []byte(fmt.Sprintf("value-%d-%d", version, i))))
+			}
+		}
+		_, _, err := tree.Commit()
+		require.NoError(t, err)
+	}
+	require.NoError(t, tree.Prune(19))
+
+	expectedDB := dbm.NewMemDB()
+	expected := generateTree(t, expectedDB)
+	exporter, err := tree.Export(20)
+	require.NoError(t, err)
+	for {
+		item, err := exporter.Next()
+		if errors.Is(err, commitment.ErrorExportDone) {
+			break
+		}
+		require.NoError(t, err)
+		require.NoError(t, expected.Set(item.Key, item.Value))
+	}
+	hash, _, err := expected.Commit()
+	require.NoError(t, err)
+	require.Equal(t, hash, tree.Hash())
+
+	require.Equal(t, countKeys(t, expectedDB, nodePrefix), countKeys(t, db, nodePrefix))
+	require.Equal(t, 0, countKeys(t, db, orphanPrefix))
+	require.Equal(t, 1, countKeys(t, db, rootPrefix))
+}
+
+func countKeys(t *testing.T, db corestore.KVStoreWithBatch, prefix byte) int {
+	t.Helper()
+	itr, err := db.Iterator([]byte{prefix}, []byte{prefix + 1})
+	require.NoError(t, err)
+	defer itr.Close()
+	count := 0
+	for ; itr.Valid(); itr.Next() {
+		count++
+	}
+	return count
+}
@@ -9,10 +9,13 @@ import (
 	"slices"
 
 	protoio "github.com/cosmos/gogoproto/io"
+	ics23 "github.com/cosmos/ics23/go"
+	"golang.org/x/sync/errgroup"
 
 	corelog "cosmossdk.io/core/log"
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
+	storeerrors "cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/internal"
 	"cosmossdk.io/store/v2/internal/conv"
 	"cosmossdk.io/store/v2/proof"
@@ -25,6 +28,10 @@ var (
 	_ store.UpgradeableStore      = (*CommitStore)(nil)
 	_ snapshots.CommitSnapshotter = (*CommitStore)(nil)
 	_ store.PausablePruner        = (*CommitStore)(nil)
+	_ store.StorePruner           = (*CommitStore)(nil)
+	_ store.ReplayableCommitter   = (*CommitStore)(nil)
+	_ store.RollbackableCommitter = (*CommitStore)(nil)
+	_ store.BatchProver           = (*CommitStore)(nil)
 )
 
 // MountTreeFn is a function that mounts a tree given a store key.
@@ -43,34 +50,96 @@ type CommitStore struct {
 	// oldTrees is a map of store keys to old trees that have been deleted or renamed.
 	// It is used to get the proof for the old store keys.
 	oldTrees map[string]Tree
+	// concurrency is the maximum number of trees written and committed in
+	// parallel. A value of 0 or 1 processes the trees sequentially.
+	concurrency int
+	// prunedVersions is the latest version each tree has been pruned to, the
+	// commit infos are only pruned up to the lowest one by PruneStores.
+	prunedVersions map[string]uint64
 }
 
 // NewCommitStore creates a new CommitStore instance.
 func NewCommitStore(trees, oldTrees map[string]Tree, db corestore.KVStoreWithBatch, logger corelog.Logger) (*CommitStore, error) {
 	return &CommitStore{
-		logger:     logger,
-		multiTrees: trees,
-		oldTrees:   oldTrees,
-		metadata:   NewMetadataStore(db),
+		logger:         logger,
+		multiTrees:     trees,
+		oldTrees:       oldTrees,
+		metadata:       NewMetadataStore(db),
+		prunedVersions: make(map[string]uint64),
 	}, nil
 }
 
+// NewV1CommitStore creates a new CommitStore instance on a store v1 (rootmulti)
+// database, which keeps the latest version and the commit infos in the store v1
+// format. The trees must be mounted under the V1StorePrefix of their store key.
+// It is used to keep committing to a store v1 database while it is migrated.
+func NewV1CommitStore(trees map[string]Tree, db corestore.KVStoreWithBatch, logger corelog.Logger) (*CommitStore, error) {
+	return &CommitStore{
+		logger:         logger,
+		multiTrees:     trees,
+		oldTrees:       make(map[string]Tree),
+		metadata:       NewV1MetadataStore(db),
+		prunedVersions: make(map[string]uint64),
+	}, nil
+}
+
+// SetCommitConcurrency sets the maximum number of trees which are written and
+// committed in parallel. Trees of distinct store keys are independent until the
+// CommitInfo is assembled, so they can be hashed and persisted concurrently.
+// A value of 0 or 1 processes the trees sequentially.
+func (c *CommitStore) SetCommitConcurrency(concurrency int) {
+	c.concurrency = concurrency
+}
+
 func (c *CommitStore) WriteChangeset(cs *corestore.Changeset) error {
+	if c.concurrency <= 1 {
+		for _, pairs := range cs.Changes {
+			tree, ok := c.multiTrees[conv.UnsafeBytesToStr(pairs.Actor)]
+			if !ok {
+				return fmt.Errorf("store key %s not found in multiTrees", pairs.Actor)
+			}
+			if err := writeStateChanges(tree, pairs.StateChanges); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// group the changes by store key, preserving their order, so that a single
+	// tree is never written from several goroutines
+	changes := make(map[string][]corestore.KVPair, len(cs.Changes))
+	storeKeys := make([]string, 0, len(cs.Changes))
 	for _, pairs := range cs.Changes {
 		key := conv.UnsafeBytesToStr(pairs.Actor)
-
-		tree, ok := c.multiTrees[key]
-		if !ok {
+		if _, ok := c.multiTrees[key]; !ok {
 			return fmt.Errorf("store key %s not found in multiTrees", key)
 		}
-		for _, kv := range pairs.StateChanges {
-			if kv.Remove {
-				if err := tree.Remove(kv.Key); err != nil {
-					return err
-				}
-			} else if err := tree.Set(kv.Key, kv.Value); err != nil {
+		if _, ok := changes[key]; !ok {
+			storeKeys = append(storeKeys, key)
+		}
+		changes[key] = append(changes[key], pairs.StateChanges...)
+	}
+
+	eg := new(errgroup.Group)
+	eg.SetLimit(c.concurrency)
+	for _, storeKey := range storeKeys {
+		eg.Go(func() error {
+			return writeStateChanges(c.multiTrees[storeKey], changes[storeKey])
+		})
+	}
+
+	return eg.Wait()
+}
+
+func writeStateChanges(tree Tree, kvPairs []corestore.KVPair) error {
+	for _, kv := range kvPairs {
+		if kv.Remove {
+			if err := tree.Remove(kv.Key); err != nil {
 				return err
 			}
+		} else if err := tree.Set(kv.Key, kv.Value); err != nil {
+			return err
 		}
 	}
 
@@ -99,6 +168,17 @@ func (c *CommitStore) WorkingCommitInfo(version uint64) *proof.CommitInfo {
 	}
 }
 
+// Rollback implements store.RollbackableCommitter.
+func (c *CommitStore) Rollback() error {
+	for storeKey, tree := range c.multiTrees {
+		if err := tree.Rollback(); err != nil {
+			return fmt.Errorf("failed to rollback the tree of %s: %w", storeKey, err)
+		}
+	}
+
+	return nil
+}
+
 func (c *CommitStore) LoadVersion(targetVersion uint64) error {
 	storeKeys := make([]string, 0, len(c.multiTrees))
 	for storeKey := range c.multiTrees {
@@ -187,40 +267,50 @@ func (c *CommitStore) loadVersion(targetVersion uint64, storeKeys []string) erro
 }
 
 func (c *CommitStore) Commit(version uint64) (*proof.CommitInfo, error) {
-	storeInfos := make([]proof.StoreInfo, 0, len(c.multiTrees))
-
-	for storeKey, tree := range c.multiTrees {
+	storeKeys := make([]string, 0, len(c.multiTrees))
+	for storeKey := range c.multiTrees {
 		if internal.IsMemoryStoreKey(storeKey) {
 			continue
 		}
-		// If a commit event execution is interrupted, a new iavl store's version
-		// will be larger than the RMS's metadata, when the block is replayed, we
-		// should avoid committing that iavl store again.
-		var commitID proof.CommitID
-		v, err := tree.GetLatestVersion()
-		if err != nil {
-			return nil, err
-		}
-		if v >= version {
-			commitID.Version = version
-			commitID.Hash = tree.Hash()
-		} else {
-			hash, cversion, err := tree.Commit()
+		storeKeys = append(storeKeys, storeKey)
+	}
+	// sort the store keys so the CommitInfo does not depend on the map order
+	// nor on the order in which the trees are committed
+	slices.Sort(storeKeys)
+
+	commitIDs := make([]proof.CommitID, len(storeKeys))
+	if c.concurrency <= 1 {
+		for i, storeKey := range storeKeys {
+			commitID, err := commitTree(c.multiTrees[storeKey], version)
 			if err != nil {
 				return nil, err
 			}
-			if cversion != version {
-				return nil, fmt.Errorf("commit version %d does not match the target version %d", cversion, version)
-			}
-			commitID = proof.CommitID{
-				Version: version,
-				Hash:    hash,
-			}
+			commitIDs[i] = commitID
 		}
-		storeInfos = append(storeInfos, proof.StoreInfo{
+	} else {
+		eg := new(errgroup.Group)
+		eg.SetLimit(c.concurrency)
+		for i, storeKey := range storeKeys {
+			eg.Go(func() error {
+				commitID, err := commitTree(c.multiTrees[storeKey], version)
+				if err != nil {
+					return fmt.Errorf("failed to commit store key %s: %w", storeKey, err)
+				}
+				commitIDs[i] = commitID
+				return nil
+			})
+		}
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+	}
+
+	storeInfos := make([]proof.StoreInfo, len(storeKeys))
+	for i, storeKey := range storeKeys {
+		storeInfos[i] = proof.StoreInfo{
 			Name:     []byte(storeKey),
-			CommitID: commitID,
-		})
+			CommitID: commitIDs[i],
+		}
 	}
 
 	cInfo := &proof.CommitInfo{
@@ -235,6 +325,52 @@ func (c *CommitStore) Commit(version uint64) (*proof.CommitInfo, error) {
 	return cInfo, nil
 }
 
+// commitTree commits the given tree at the target version and returns its
+// CommitID.
+func commitTree(tree Tree, version uint64) (proof.CommitID, error) {
+	// If a commit event execution is interrupted, a new iavl store's version
+	// will be larger than the RMS's metadata, when the block is replayed, we
+	// should avoid committing that iavl store again.
+	v, err := tree.GetLatestVersion()
+	if err != nil {
+		return proof.CommitID{}, err
+	}
+	if v >= version {
+		return proof.CommitID{
+			Version: version,
+			Hash:    tree.Hash(),
+		}, nil
+	}
+
+	hash, cversion, err := tree.Commit()
+	if err != nil {
+		return proof.CommitID{}, err
+	}
+	if cversion != version {
+		return proof.CommitID{}, fmt.Errorf("commit version %d does not match the target version %d", cversion, version)
+	}
+
+	return proof.CommitID{
+		Version: version,
+		Hash:    hash,
+	}, nil
+}
+
+// RetainChangeset implements store.ReplayableCommitter.
+func (c *CommitStore) RetainChangeset(version uint64, cs *corestore.Changeset) error {
+	return c.metadata.flushChangeset(version, cs)
+}
+
+// GetRetainedChangeset implements store.ReplayableCommitter.
+func (c *CommitStore) GetRetainedChangeset(version uint64) (*corestore.Changeset, error) {
+	return c.metadata.GetChangeset(version)
+}
+
+// ReleaseChangesets implements store.ReplayableCommitter.
+func (c *CommitStore) ReleaseChangesets(version uint64) error {
+	return c.metadata.deleteChangesets(version)
+}
+
 func (c *CommitStore) SetInitialVersion(version uint64) error {
 	for _, tree := range c.multiTrees {
 		if err := tree.SetInitialVersion(version); err != nil {
@@ -246,6 +382,155 @@ func (c *CommitStore) SetInitialVersion(version uint64) error {
 }
 
 func (c *CommitStore) GetProof(storeKey []byte, version uint64, key []byte) ([]proof.CommitmentOp, error) {
+	tree, err := c.getTree(storeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	iProof, err := tree.GetProof(version, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.proofOps(storeKey, version, tree, key, iProof)
+}
+
+// GetBatchProof returns the proofs of existence or non-existence of the given
+// keys combined into a single batch proof, followed by the proof of the store
+// in the CommitInfo.
+func (c *CommitStore) GetBatchProof(storeKey []byte, version uint64, keys [][]byte) ([]proof.CommitmentOp, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no keys to prove")
+	}
+	tree, err := c.getTree(storeKey)
+	if err != nil {
+		return nil, err
+	}
+
+	proofs := make([]*ics23.CommitmentProof, 0, len(keys))
+	for _, key := range keys {
+		iProof, err := tree.GetProof(version, key)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, iProof)
+	}
+	batchProof, err := ics23.CombineProofs(proofs)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.proofOps(storeKey, version, tree, nil, batchProof)
+}
+
+// GetRangeProof returns the key/value pairs of the range [start, end) of the
+// store and their existence proofs combined into a single batch proof, followed
+// by the proof of the store in the CommitInfo. The batch proof also contains
+// the existence proofs of the closest keys outside of the range, which prove
+// that no key of the range is omitted. It is only supported by the trees
+// ordered by key. ErrTooManyPairs is returned if the range contains more than
+// limit pairs, 0 means no limit.
+func (c *CommitStore) GetRangeProof(storeKey []byte, version uint64, start, end []byte, limit int) ([]proof.KVPair, []proof.CommitmentOp, error) {
+	tree, err := c.getTree(storeKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	rangeTree, ok := tree.(RangeTree)
+	if !ok {
+		return nil, nil, fmt.Errorf("store %s does not support range proofs", storeKey)
+	}
+
+	var (
+		pairs  []proof.KVPair
+		proofs []*ics23.CommitmentProof
+	)
+	addProof := func(key []byte) error {
+		iProof, err := tree.GetProof(version, key)
+		if err != nil {
+			return err
+		}
+		proofs = append(proofs, iProof)
+		return nil
+	}
+
+	// the closest key before the range
+	if start != nil {
+		key, err := firstKey(rangeTree, version, nil, start, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if key != nil {
+			if err := addProof(key); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	iter, err := rangeTree.Iterator(version, start, end, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	for ; iter.Valid(); iter.Next() {
+		if limit > 0 && len(pairs) == limit {
+			_ = iter.Close()
+			return nil, nil, fmt.Errorf("%w: the range contains more than %d pairs", storeerrors.ErrTooManyPairs, limit)
+		}
+		pairs = append(pairs, proof.KVPair{Key: iter.Key(), Value: iter.Value()})
+		if err := addProof(iter.Key()); err != nil {
+			_ = iter.Close()
+			return nil, nil, err
+		}
+	}
+	if err := errors.Join(iter.Error(), iter.Close()); err != nil {
+		return nil, nil, err
+	}
+
+	// the closest key after the range
+	if end != nil {
+		key, err := firstKey(rangeTree, version, end, nil, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if key != nil {
+			if err := addProof(key); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	if len(proofs) == 0 {
+		return nil, nil, fmt.Errorf("store %s is empty at version %d", storeKey, version)
+	}
+	batchProof, err := ics23.CombineProofs(proofs)
+	if err != nil {
+		return nil, nil, err
+	}
+	ops, err := c.proofOps(storeKey, version, tree, nil, batchProof)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pairs, ops, nil
+}
+
+// firstKey returns the first key of the range of the tree in the iteration
+// order, or nil if the range is empty.
+func firstKey(tree RangeTree, version uint64, start, end []byte, ascending bool) ([]byte, error) {
+	iter, err := tree.Iterator(version, start, end, ascending)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	if !iter.Valid() {
+		return nil, iter.Error()
+	}
+
+	return iter.Key(), nil
+}
+
+// getTree returns the tree of the store key, including the removed ones.
+func (c *CommitStore) getTree(storeKey []byte) (Tree, error) {
 	rawStoreKey := conv.UnsafeBytesToStr(storeKey)
 	tree, ok := c.multiTrees[rawStoreKey]
 	if !ok {
@@ -255,10 +540,12 @@ func (c *CommitStore) GetProof(storeKey []byte, version uint64, key []byte) ([]p
 		}
 	}
 
-	iProof, err := tree.GetProof(version, key)
-	if err != nil {
-		return nil, err
-	}
+	return tree, nil
+}
+
+// proofOps returns the CommitmentOp of the tree proof followed by the
+// CommitmentOp of the store in the CommitInfo of the version.
+func (c *CommitStore) proofOps(storeKey []byte, version uint64, tree Tree, key []byte, iProof *ics23.CommitmentProof) ([]proof.CommitmentOp, error) {
 	cInfo, err := c.metadata.GetCommitInfo(version)
 	if err != nil {
 		return nil, err
@@ -267,6 +554,9 @@ func (c *CommitStore) GetProof(storeKey []byte, version uint64, key []byte) ([]p
 		return nil, fmt.Errorf("commit info not found for version %d", version)
 	}
 	commitOp := proof.NewIAVLCommitmentOp(key, iProof)
+	if provider, ok := tree.(CommitmentOpProvider); ok {
+		commitOp = provider.CommitmentOp(key, iProof)
+	}
 	_, storeCommitmentOp, err := cInfo.GetStoreProof(storeKey)
 	if err != nil {
 		return nil, err
@@ -275,6 +565,32 @@ func (c *CommitStore) GetProof(storeKey []byte, version uint64, key []byte) ([]p
 	return []proof.CommitmentOp{commitOp, *storeCommitmentOp}, nil
 }
 
+// StoreKeys returns the sorted store keys of the committed trees, the memory
+// store keys are excluded.
+func (c *CommitStore) StoreKeys() []string {
+	storeKeys := make([]string, 0, len(c.multiTrees))
+	for storeKey := range c.multiTrees {
+		if internal.IsMemoryStoreKey(storeKey) {
+			continue
+		}
+		storeKeys = append(storeKeys, storeKey)
+	}
+	slices.Sort(storeKeys)
+
+	return storeKeys
+}
+
+// ExportStore returns an Exporter over the tree of the given store key at the
+// given version.
+func (c *CommitStore) ExportStore(storeKey string, version uint64) (Exporter, error) {
+	tree, ok := c.multiTrees[storeKey]
+	if !ok {
+		return nil, fmt.Errorf("store %s not found", storeKey)
+	}
+
+	return tree.Export(version)
+}
+
 func (c *CommitStore) Get(storeKey []byte, version uint64, key []byte) ([]byte, error) {
 	tree, ok := c.multiTrees[conv.UnsafeBytesToStr(storeKey)]
 	if !ok {
@@ -303,6 +619,9 @@ func (c *CommitStore) Prune(version uint64) error {
 			return err
 		}
 	}
+	for storeKey := range c.multiTrees {
+		c.prunedVersions[storeKey] = max(c.prunedVersions[storeKey], version)
+	}
 	// prune the removed store keys
 	if err := c.pruneRemovedStoreKeys(version); err != nil {
 		return err
@@ -311,6 +630,50 @@ func (c *CommitStore) Prune(version uint64) error {
 	return nil
 }
 
+// PruneStores implements store.StorePruner.
+func (c *CommitStore) PruneStores(version uint64, storeVersions map[string]uint64) error {
+	// prune the trees
+	for storeKey, tree := range c.multiTrees {
+		pruneTo, ok := storeVersions[storeKey]
+		if !ok {
+			pruneTo = version
+		}
+		if pruneTo == 0 {
+			continue
+		}
+		if err := tree.Prune(pruneTo); err != nil {
+			return err
+		}
+		c.prunedVersions[storeKey] = max(c.prunedVersions[storeKey], pruneTo)
+	}
+
+	// prune the metadata, a commit info is only deleted once all the trees
+	// have been pruned past its version
+	var minPruned uint64
+	for storeKey := range c.multiTrees {
+		pruned := c.prunedVersions[storeKey]
+		if pruned == 0 {
+			minPruned = 0
+			break
+		}
+		if minPruned == 0 || pruned < minPruned {
+			minPruned = pruned
+		}
+	}
+	for v := minPruned; v > 0; v-- {
+		if err := c.metadata.deleteCommitInfo(v); err != nil {
+			return err
+		}
+	}
+
+	// prune the removed store keys
+	if version > 0 {
+		return c.pruneRemovedStoreKeys(version)
+	}
+
+	return nil
+}
+
 func (c *CommitStore) pruneRemovedStoreKeys(version uint64) error {
 	clearKVStore := func(storeKey []byte, version uint64) (err error) {
 		tree, ok := c.oldTrees[string(storeKey)]
@@ -345,7 +708,11 @@ func (c *CommitStore) Snapshot(version uint64, protoWriter protoio.Writer) error
 		return fmt.Errorf("the snapshot version %d is greater than the latest version %d", version, latestVersion)
 	}
 
-	for storeKey, tree := range c.multiTrees {
+	// the stores are written in a deterministic order, which delta snapshots
+	// rely on to read their base snapshot in a single pass
+	storeKeys := slices.Sorted(maps.Keys(c.multiTrees))
+	for _, storeKey := range storeKeys {
+		tree := c.multiTrees[storeKey]
 		// TODO: check the parallelism of this loop
 		if err := func() error {
 			exporter, err := tree.Export(version)
@@ -6,6 +6,7 @@ import (
 	"io"
 	"sync"
 
+	ics23 "github.com/cosmos/ics23/go"
 	"github.com/stretchr/testify/suite"
 
 	corelog "cosmossdk.io/core/log"
@@ -13,6 +14,8 @@ import (
 	coretesting "cosmossdk.io/core/testing"
 	"cosmossdk.io/store/v2"
 	dbm "cosmossdk.io/store/v2/db"
+	storeerrors "cosmossdk.io/store/v2/errors"
+	"cosmossdk.io/store/v2/proof"
 	"cosmossdk.io/store/v2/snapshots"
 	snapshotstypes "cosmossdk.io/store/v2/snapshots/types"
 )
@@ -125,6 +128,72 @@ func (s *CommitStoreTestSuite) TestStore_Snapshotter() {
 	}
 }
 
+func (s *CommitStoreTestSuite) TestStore_DeltaSnapshot() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+
+	// the first version writes most of the state, the next ones update,
+	// remove and add a few keys
+	latestVersion := uint64(10)
+	for i := uint64(1); i <= latestVersion; i++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			if i == 1 {
+				for j := 0; j < 1000; j++ {
+					cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%04d", j)), []byte("value-1"), false)
+				}
+				continue
+			}
+			for j := 0; j < 10; j++ {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%04d", 97*int(i)+j)), []byte(fmt.Sprintf("value-%d", i)), false)
+			}
+			cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%04d", 31*int(i))), nil, true)
+			cs.Add([]byte(storeKey), []byte(fmt.Sprintf("new-key-%d", i)), []byte(fmt.Sprintf("value-%d", i)), false)
+		}
+		s.Require().NoError(commitStore.WriteChangeset(cs))
+		_, err = commitStore.Commit(i)
+		s.Require().NoError(err)
+	}
+	cInfo := commitStore.WorkingCommitInfo(latestVersion)
+
+	// a full snapshot followed by two delta snapshots
+	snapshotStore, err := snapshots.NewStore(s.T().TempDir())
+	s.Require().NoError(err)
+	manager := snapshots.NewManager(snapshotStore, snapshots.NewSnapshotOptions(0, 0), commitStore, nil, nil, coretesting.NewNopLogger())
+	_, err = manager.Create(5)
+	s.Require().NoError(err)
+	_, err = manager.CreateDelta(8)
+	s.Require().NoError(err)
+	delta, err := manager.CreateDelta(latestVersion)
+	s.Require().NoError(err)
+	s.Require().Equal(snapshotstypes.DeltaFormat, delta.Format)
+	s.Require().Equal(uint64(8), delta.Metadata.BaseHeight)
+
+	// the delta snapshot is never larger than the full snapshot
+	fullStore, err := snapshots.NewStore(s.T().TempDir())
+	s.Require().NoError(err)
+	full, err := snapshots.NewManager(fullStore, snapshots.NewSnapshotOptions(0, 0), commitStore, nil, nil, coretesting.NewNopLogger()).Create(latestVersion)
+	s.Require().NoError(err)
+	s.Require().LessOrEqual(snapshotSize(snapshotStore, delta), snapshotSize(fullStore, full))
+
+	// restore the base snapshot and the deltas into a new store
+	targetStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+	storageSnapshotter := &leavesSnapshotter{leaves: make(map[string]string)}
+	targetManager := snapshots.NewManager(snapshotStore, snapshots.NewSnapshotOptions(0, 0), targetStore, storageSnapshotter, nil, coretesting.NewNopLogger())
+	s.Require().NoError(targetManager.RestoreLocalSnapshot(latestVersion, snapshotstypes.DeltaFormat))
+
+	targetCommitInfo := targetStore.WorkingCommitInfo(latestVersion)
+	s.Require().Equal(cInfo.Hash(), targetCommitInfo.Hash())
+	for _, storeKey := range storeKeys {
+		s.Require().Equal("value-1", storageSnapshotter.leaves[storeKey+"_key-0000"])
+		s.Require().Equal("value-10", storageSnapshotter.leaves[storeKey+"_key-0970"])
+		s.Require().Equal("value-10", storageSnapshotter.leaves[storeKey+"_new-key-10"])
+		s.Require().NotContains(storageSnapshotter.leaves, storeKey+"_key-0310")
+	}
+}
+
 func (s *CommitStoreTestSuite) TestStore_LoadVersion() {
 	storeKeys := []string{storeKey1, storeKey2}
 	mdb := dbm.NewMemDB()
@@ -180,6 +249,62 @@ func (s *CommitStoreTestSuite) TestStore_LoadVersion() {
 	}
 }
 
+func (s *CommitStoreTestSuite) TestStore_Rollback() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+
+	changeset := func(version uint64) *corestore.Changeset {
+		kvPairs := make(map[string]corestore.KVPairs)
+		for _, storeKey := range storeKeys {
+			for j := 0; j < 10; j++ {
+				key := []byte(fmt.Sprintf("key-%d-%d", version, j))
+				value := []byte(fmt.Sprintf("value-%d-%d", version, j))
+				kvPairs[storeKey] = append(kvPairs[storeKey], corestore.KVPair{Key: key, Value: value})
+			}
+			// remove a key of the previous version
+			kvPairs[storeKey] = append(kvPairs[storeKey], corestore.KVPair{Key: []byte(fmt.Sprintf("key-%d-0", version-1)), Remove: true})
+		}
+		return corestore.NewChangesetWithPairs(kvPairs)
+	}
+	storeHashes := func(cInfo *proof.CommitInfo) map[string][]byte {
+		hashes := make(map[string][]byte)
+		for _, si := range cInfo.StoreInfos {
+			hashes[string(si.Name)] = si.CommitID.Hash
+		}
+		return hashes
+	}
+
+	s.Require().NoError(commitStore.WriteChangeset(changeset(1)))
+	committed, err := commitStore.Commit(1)
+	s.Require().NoError(err)
+
+	// the working changes are discarded
+	s.Require().NoError(commitStore.WriteChangeset(changeset(2)))
+	working := commitStore.WorkingCommitInfo(2)
+	s.Require().NotEqual(storeHashes(committed), storeHashes(working))
+	s.Require().NoError(commitStore.Rollback())
+	s.Require().Equal(storeHashes(committed), storeHashes(commitStore.WorkingCommitInfo(2)))
+
+	// writing the discarded changes again gives the same working hash
+	s.Require().NoError(commitStore.WriteChangeset(changeset(2)))
+	s.Require().Equal(storeHashes(working), storeHashes(commitStore.WorkingCommitInfo(2)))
+	s.Require().NoError(commitStore.Rollback())
+
+	// the discarded changes are not committed with the next changeset
+	s.Require().NoError(commitStore.WriteChangeset(changeset(3)))
+	_, err = commitStore.Commit(2)
+	s.Require().NoError(err)
+	for _, storeKey := range storeKeys {
+		val, err := commitStore.Get([]byte(storeKey), 2, []byte("key-2-1"))
+		s.Require().NoError(err)
+		s.Require().Nil(val)
+		val, err = commitStore.Get([]byte(storeKey), 2, []byte("key-3-1"))
+		s.Require().NoError(err)
+		s.Require().Equal([]byte("value-3-1"), val)
+	}
+}
+
 func (s *CommitStoreTestSuite) TestStore_Pruning() {
 	storeKeys := []string{storeKey1, storeKey2}
 	pruneOpts := store.NewPruningOptionWithCustom(10, 5)
@@ -221,6 +346,52 @@ func (s *CommitStoreTestSuite) TestStore_Pruning() {
 	}
 }
 
+func (s *CommitStoreTestSuite) TestStore_PruneStores() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+
+	latestVersion := uint64(100)
+	kvCount := 10
+	for i := uint64(1); i <= latestVersion; i++ {
+		kvPairs := make(map[string]corestore.KVPairs)
+		for _, storeKey := range storeKeys {
+			kvPairs[storeKey] = corestore.KVPairs{}
+			for j := 0; j < kvCount; j++ {
+				key := []byte(fmt.Sprintf("key-%d-%d", i, j))
+				value := []byte(fmt.Sprintf("value-%d-%d", i, j))
+				kvPairs[storeKey] = append(kvPairs[storeKey], corestore.KVPair{Key: key, Value: value})
+			}
+		}
+		s.Require().NoError(commitStore.WriteChangeset(corestore.NewChangesetWithPairs(kvPairs)))
+
+		_, err = commitStore.Commit(i)
+		s.Require().NoError(err)
+	}
+
+	checkCommitInfos := func(pruneVersion uint64) {
+		for i := uint64(1); i <= latestVersion; i++ {
+			commitInfo, _ := commitStore.GetCommitInfo(i)
+			if i <= pruneVersion {
+				s.Require().Nil(commitInfo)
+			} else {
+				s.Require().NotNil(commitInfo)
+			}
+		}
+	}
+
+	// the commit infos are kept as long as a store is not pruned
+	s.Require().NoError(commitStore.PruneStores(50, map[string]uint64{storeKey2: 0}))
+	checkCommitInfos(0)
+
+	// the commit infos are pruned up to the lowest pruned version
+	s.Require().NoError(commitStore.PruneStores(50, map[string]uint64{storeKey2: 30}))
+	checkCommitInfos(30)
+
+	s.Require().NoError(commitStore.Prune(60))
+	checkCommitInfos(60)
+}
+
 func (s *CommitStoreTestSuite) TestStore_GetProof() {
 	storeKeys := []string{storeKey1, storeKey2}
 	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
@@ -264,6 +435,165 @@ func (s *CommitStoreTestSuite) TestStore_GetProof() {
 	s.Require().Nil(commit)
 }
 
+func (s *CommitStoreTestSuite) TestStore_BatchProof() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+
+	toVersion := uint64(3)
+	keyCount := 5
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			for i := 0; i < keyCount; i++ {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d-%d", version, i)), []byte(fmt.Sprintf("value-%d-%d", version, i)), false)
+			}
+		}
+		s.Require().NoError(commitStore.WriteChangeset(cs))
+		_, err = commitStore.Commit(version)
+		s.Require().NoError(err)
+	}
+	cInfo, err := commitStore.GetCommitInfo(toVersion)
+	s.Require().NoError(err)
+
+	pairs := []proof.KVPair{
+		{Key: []byte("key-1-0"), Value: []byte("value-1-0")},
+		{Key: []byte("key-3-4"), Value: []byte("value-3-4")},
+	}
+	absentKeys := [][]byte{[]byte("key-0"), []byte("key-2-9"), []byte("key-9")}
+	keys := append([][]byte{pairs[0].Key, pairs[1].Key}, absentKeys...)
+	ops, err := commitStore.GetBatchProof([]byte(storeKey1), toVersion, keys)
+	s.Require().NoError(err)
+	s.Require().Len(ops, 2)
+	s.Require().NoError(proof.VerifyBatchProof(ops, cInfo.Hash(), []byte(storeKey1), pairs, absentKeys))
+
+	// the proof does not verify other values, keys, stores or app hashes
+	s.Require().Error(proof.VerifyBatchProof(ops, cInfo.Hash(), []byte(storeKey1), []proof.KVPair{{Key: pairs[0].Key, Value: []byte("value")}}, nil))
+	s.Require().Error(proof.VerifyBatchProof(ops, cInfo.Hash(), []byte(storeKey1), nil, [][]byte{pairs[0].Key}))
+	s.Require().Error(proof.VerifyBatchProof(ops, cInfo.Hash(), []byte(storeKey1), []proof.KVPair{{Key: []byte("key-2-0"), Value: []byte("value-2-0")}}, nil))
+	s.Require().Error(proof.VerifyBatchProof(ops, cInfo.Hash(), []byte(storeKey2), pairs, absentKeys))
+	s.Require().Error(proof.VerifyBatchProof(ops, []byte("invalid"), []byte(storeKey1), pairs, absentKeys))
+
+	_, err = commitStore.GetBatchProof([]byte(storeKey1), toVersion, nil)
+	s.Require().Error(err)
+}
+
+func (s *CommitStoreTestSuite) TestStore_RangeProof() {
+	storeKeys := []string{storeKey1, storeKey2}
+	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+
+	toVersion := uint64(3)
+	keyCount := 5
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			for i := 0; i < keyCount; i++ {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d-%d", version, i)), []byte(fmt.Sprintf("value-%d-%d", version, i)), false)
+			}
+		}
+		s.Require().NoError(commitStore.WriteChangeset(cs))
+		_, err = commitStore.Commit(version)
+		s.Require().NoError(err)
+	}
+	cInfo, err := commitStore.GetCommitInfo(toVersion)
+	s.Require().NoError(err)
+
+	tree := commitStore.multiTrees[storeKey1]
+	if _, ok := tree.(RangeTree); !ok {
+		_, _, err := commitStore.GetRangeProof([]byte(storeKey1), toVersion, nil, nil, 0)
+		s.Require().Error(err)
+		return
+	}
+
+	testCases := []struct {
+		start, end []byte
+		count      int
+	}{
+		{[]byte("key-2-"), []byte("key-3-"), keyCount},
+		{[]byte("key-2-2"), []byte("key-2-4"), 2},
+		{nil, []byte("key-2-"), keyCount},
+		{[]byte("key-3-"), nil, keyCount},
+		{nil, nil, int(toVersion) * keyCount},
+		{[]byte("key-9"), nil, 0},
+		{[]byte("key-2-5"), []byte("key-2-9"), 0},
+	}
+	for _, tc := range testCases {
+		pairs, ops, err := commitStore.GetRangeProof([]byte(storeKey1), toVersion, tc.start, tc.end, tc.count)
+		s.Require().NoError(err)
+		s.Require().Len(pairs, tc.count)
+		s.Require().NoError(proof.VerifyRangeProof(ops, cInfo.Hash(), []byte(storeKey1), tc.start, tc.end, pairs))
+
+		// the proof does not verify other ranges
+		if len(pairs) > 0 {
+			s.Require().Error(proof.VerifyRangeProof(ops, cInfo.Hash(), []byte(storeKey1), tc.start, tc.end, pairs[1:]))
+			s.Require().Error(proof.VerifyRangeProof(ops, cInfo.Hash(), []byte(storeKey1), tc.start, tc.end, pairs[:len(pairs)-1]))
+		}
+		s.Require().Error(proof.VerifyRangeProof(ops, cInfo.Hash(), []byte(storeKey2), tc.start, tc.end, pairs))
+	}
+
+	// a range exceeding the limit should error
+	_, _, err = commitStore.GetRangeProof([]byte(storeKey1), toVersion, []byte("key-2-"), []byte("key-3-"), keyCount-1)
+	s.Require().ErrorIs(err, storeerrors.ErrTooManyPairs)
+
+	// a proof omitting a key of the range does not verify
+	pairs, ops, err := commitStore.GetRangeProof([]byte(storeKey1), toVersion, []byte("key-2-"), []byte("key-3-"), 0)
+	s.Require().NoError(err)
+	pairs = append(pairs[:2], pairs[3:]...)
+	var proofs []*ics23.CommitmentProof
+	for _, key := range [][]byte{[]byte("key-1-4"), pairs[0].Key, pairs[1].Key, pairs[2].Key, pairs[3].Key, []byte("key-3-0")} {
+		p, err := tree.GetProof(toVersion, key)
+		s.Require().NoError(err)
+		proofs = append(proofs, p)
+	}
+	ops[0].Proof, err = ics23.CombineProofs(proofs)
+	s.Require().NoError(err)
+	s.Require().Error(proof.VerifyRangeProof(ops, cInfo.Hash(), []byte(storeKey1), []byte("key-2-"), []byte("key-3-"), pairs))
+}
+
+func (s *CommitStoreTestSuite) TestStore_ConcurrentCommit() {
+	storeKeys := []string{storeKey1, storeKey2, storeKey3}
+	sequentialStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+	concurrentStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
+	s.Require().NoError(err)
+	concurrentStore.SetCommitConcurrency(2)
+
+	toVersion := uint64(10)
+	keyCount := 10
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for i := 0; i < keyCount; i++ {
+			for _, storeKey := range storeKeys {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d-%d", version, i)), []byte(fmt.Sprintf("value-%d-%d", version, i)), false)
+			}
+		}
+		// remove a key written in the previous version
+		if version > 1 {
+			cs.Add([]byte(storeKey2), []byte(fmt.Sprintf("key-%d-%d", version-1, 0)), nil, true)
+		}
+		s.Require().NoError(sequentialStore.WriteChangeset(cs))
+		s.Require().NoError(concurrentStore.WriteChangeset(cs))
+
+		sequentialInfo, err := sequentialStore.Commit(version)
+		s.Require().NoError(err)
+		concurrentInfo, err := concurrentStore.Commit(version)
+		s.Require().NoError(err)
+
+		// the commit info must be identical, including the store infos order
+		s.Require().Equal(sequentialInfo, concurrentInfo)
+		s.Require().Len(concurrentInfo.StoreInfos, len(storeKeys))
+		for i, storeKey := range storeKeys {
+			s.Require().Equal([]byte(storeKey), concurrentInfo.StoreInfos[i].Name)
+		}
+	}
+
+	// committing to an unknown store key fails
+	cs := corestore.NewChangeset()
+	cs.Add([]byte("unknown"), []byte("key"), []byte("value"), false)
+	s.Require().Error(concurrentStore.WriteChangeset(cs))
+}
+
 func (s *CommitStoreTestSuite) TestStore_Get() {
 	storeKeys := []string{storeKey1, storeKey2}
 	commitStore, err := s.NewStore(dbm.NewMemDB(), storeKeys, nil, coretesting.NewNopLogger())
@@ -465,3 +795,35 @@ func (s *CommitStoreTestSuite) TestStore_Upgrades() {
 		}
 	}
 }
+
+// leavesSnapshotter collects the leaves restored from a snapshot.
+type leavesSnapshotter struct {
+	leaves map[string]string
+}
+
+func (l *leavesSnapshotter) Restore(_ uint64, chStorage <-chan *corestore.StateChanges) error {
+	for kv := range chStorage {
+		for _, pair := range kv.StateChanges {
+			l.leaves[fmt.Sprintf("%s_%s", kv.Actor, pair.Key)] = string(pair.Value)
+		}
+	}
+	return nil
+}
+
+// snapshotSize returns the size of the chunks of a snapshot.
+func snapshotSize(store *snapshots.Store, snapshot *snapshotstypes.Snapshot) int {
+	size := 0
+	for i := uint32(0); i < snapshot.Chunks; i++ {
+		chunk, err := store.LoadChunk(snapshot.Height, snapshot.Format, i)
+		if err != nil {
+			panic(err)
+		}
+		bz, err := io.ReadAll(chunk)
+		if err != nil {
+			panic(err)
+		}
+		_ = chunk.Close()
+		size += len(bz)
+	}
+	return size
+}
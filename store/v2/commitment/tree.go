@@ -6,6 +6,8 @@ import (
 
 	ics23 "github.com/cosmos/ics23/go"
 
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2/proof"
 	snapshotstypes "cosmossdk.io/store/v2/snapshots/types"
 )
 
@@ -24,6 +26,10 @@ type Tree interface {
 	// WorkingHash returns the working hash of the tree.
 	WorkingHash() []byte
 
+	// Rollback discards the changes of the working tree since the latest saved
+	// version.
+	Rollback() error
+
 	LoadVersion(version uint64) error
 	Commit() ([]byte, uint64, error)
 	SetInitialVersion(version uint64) error
@@ -42,6 +48,20 @@ type Tree interface {
 	io.Closer
 }
 
+// RangeTree is implemented by the trees ordered by key, whose key ranges can be
+// iterated and proven.
+type RangeTree interface {
+	// Iterator returns an iterator over the range [start, end) of the tree at
+	// the given version.
+	Iterator(version uint64, start, end []byte, ascending bool) (corestore.Iterator, error)
+}
+
+// CommitmentOpProvider is implemented by the trees whose proofs do not follow
+// the IAVL proof spec, in order to wrap them into the matching proof.CommitmentOp.
+type CommitmentOpProvider interface {
+	CommitmentOp(key []byte, proof *ics23.CommitmentProof) proof.CommitmentOp
+}
+
 // Exporter is the interface that wraps the basic Export methods.
 type Exporter interface {
 	Next() (*snapshotstypes.SnapshotIAVLItem, error)
@@ -33,6 +33,44 @@ type UpgradableDatabase interface {
 	PruneStoreKeys(storeKeys []string, version uint64) error
 }
 
+// ReplayableCommitter defines an API for a Committer which retains the changesets
+// of the committed versions until the SS backend has applied them, so that the
+// SS backend can catch up with the SC backend after a crash.
+type ReplayableCommitter interface {
+	// RetainChangeset stores the changeset of the given version. It must be
+	// called before the version is committed.
+	RetainChangeset(version uint64, cs *corestore.Changeset) error
+
+	// GetRetainedChangeset returns the retained changeset of the given version
+	// or nil if it does not exist.
+	GetRetainedChangeset(version uint64) (*corestore.Changeset, error)
+
+	// ReleaseChangesets deletes the retained changesets up to and including the
+	// given version.
+	ReleaseChangesets(version uint64) error
+}
+
+// RollbackableCommitter defines an API for a Committer which can discard the
+// changesets written since the latest commit, e.g. to compute a working hash
+// without committing it.
+type RollbackableCommitter interface {
+	// Rollback discards the changesets written since the latest commit.
+	Rollback() error
+}
+
+// BatchProver defines an API for proving several keys or a key range of a store
+// at once, which is implemented by the SC backends supporting it.
+type BatchProver interface {
+	// GetBatchProof returns the batch proof of existence or non-existence of the
+	// given keys.
+	GetBatchProof(storeKey []byte, version uint64, keys [][]byte) ([]proof.CommitmentOp, error)
+
+	// GetRangeProof returns the key/value pairs of the range [start, end) along
+	// with the proof that they are all the pairs of the range. ErrTooManyPairs is
+	// returned if the range contains more than limit pairs, 0 means no limit.
+	GetRangeProof(storeKey []byte, version uint64, start, end []byte, limit int) ([]proof.KVPair, []proof.CommitmentOp, error)
+}
+
 // Committer defines an API for committing state.
 type Committer interface {
 	// WriteChangeset writes the changeset to the commitment state.
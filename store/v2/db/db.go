@@ -2,6 +2,8 @@ package db
 
 import (
 	"fmt"
+	"slices"
+	"sync"
 
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
@@ -14,18 +16,57 @@ const (
 	DBTypeRocksDB   DBType = "rocksdb"
 	DBTypePebbleDB  DBType = "pebbledb"
 	DBTypePrefixDB  DBType = "prefixdb"
+	DBTypeMemDB     DBType = "memdb"
+	DBTypeSQLite    DBType = "sqlite"
 
 	DBFileSuffix string = ".db"
 )
 
-func NewDB(dbType DBType, name, dataDir string, opts store.DBOptions) (corestore.KVStoreWithBatch, error) {
-	switch dbType {
-	case DBTypeGoLevelDB:
-		return NewGoLevelDB(name, dataDir, opts)
+// DBConstructor creates a raw KV database with the given name in dataDir.
+type DBConstructor func(name, dataDir string, opts store.DBOptions) (corestore.KVStoreWithBatch, error)
+
+var (
+	registryMtx sync.RWMutex
+	registry    = make(map[DBType]DBConstructor)
+)
+
+// RegisterDB registers the constructor of a raw KV database backend. Backends
+// register themselves from an init function, the ones requiring a build tag are
+// only registered when built with it. It panics if the backend is already
+// registered.
+func RegisterDB(dbType DBType, constructor DBConstructor) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if _, ok := registry[dbType]; ok {
+		panic(fmt.Sprintf("db type %s is already registered", dbType))
+	}
+	registry[dbType] = constructor
+}
 
-	case DBTypePebbleDB:
-		return NewPebbleDB(name, dataDir)
+// RegisteredDBTypes returns the sorted types of the registered raw KV database
+// backends.
+func RegisteredDBTypes() []DBType {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	dbTypes := make([]DBType, 0, len(registry))
+	for dbType := range registry {
+		dbTypes = append(dbTypes, dbType)
+	}
+	slices.Sort(dbTypes)
+
+	return dbTypes
+}
+
+// NewDB creates a raw KV database of the given registered type.
+func NewDB(dbType DBType, name, dataDir string, opts store.DBOptions) (corestore.KVStoreWithBatch, error) {
+	registryMtx.RLock()
+	constructor, ok := registry[dbType]
+	registryMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported db type: %s", dbType)
 	}
 
-	return nil, fmt.Errorf("unsupported db type: %s", dbType)
+	return constructor(name, dataDir, opts)
 }
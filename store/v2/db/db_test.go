@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2"
 )
 
 type DBTestSuite struct {
@@ -107,26 +108,30 @@ func (s *DBTestSuite) TestIterator() {
 	s.Require().Equal(-1, index)
 }
 
-func TestMemDBSuite(t *testing.T) {
-	suite.Run(t, &DBTestSuite{
-		db: NewMemDB(),
-	})
-}
-
-func TestPebbleDBSuite(t *testing.T) {
-	db, err := NewPebbleDB("test", t.TempDir())
-	require.NoError(t, err)
+func TestRegisteredDBSuites(t *testing.T) {
+	for _, dbType := range RegisteredDBTypes() {
+		t.Run(string(dbType), func(t *testing.T) {
+			db, err := NewDB(dbType, "test", t.TempDir(), nil)
+			require.NoError(t, err)
 
-	suite.Run(t, &DBTestSuite{
-		db: db,
-	})
+			suite.Run(t, &DBTestSuite{
+				db: db,
+			})
+		})
+	}
 }
 
-func TestGoLevelDBSuite(t *testing.T) {
-	db, err := NewGoLevelDB("test", t.TempDir(), nil)
-	require.NoError(t, err)
-	suite.Run(t, &DBTestSuite{
-		db: db,
+func TestNewDB(t *testing.T) {
+	require.Contains(t, RegisteredDBTypes(), DBTypeGoLevelDB)
+	require.Contains(t, RegisteredDBTypes(), DBTypePebbleDB)
+	require.Contains(t, RegisteredDBTypes(), DBTypeMemDB)
+
+	_, err := NewDB(DBTypePrefixDB, "test", t.TempDir(), nil)
+	require.ErrorContains(t, err, "unsupported db type")
+	require.Panics(t, func() {
+		RegisterDB(DBTypeMemDB, func(_, _ string, _ store.DBOptions) (corestore.KVStoreWithBatch, error) {
+			return NewMemDB(), nil
+		})
 	})
 }
 
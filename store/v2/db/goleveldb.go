@@ -21,6 +21,12 @@ import (
 
 var _ corestore.KVStoreWithBatch = (*GoLevelDB)(nil)
 
+func init() {
+	RegisterDB(DBTypeGoLevelDB, func(name, dataDir string, opts store.DBOptions) (corestore.KVStoreWithBatch, error) {
+		return NewGoLevelDB(name, dataDir, opts)
+	})
+}
+
 // GoLevelDB implements corestore.KVStore using github.com/syndtr/goleveldb/leveldb.
 // It is used for only store v2 migration, since some clients use goleveldb as
 // the IAVL v0/v1 backend.
@@ -9,6 +9,7 @@ import (
 	"github.com/google/btree"
 
 	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2"
 	"cosmossdk.io/store/v2/errors"
 )
 
@@ -42,6 +43,12 @@ func newPair(key, value []byte) item {
 
 var _ corestore.KVStoreWithBatch = (*MemDB)(nil)
 
+func init() {
+	RegisterDB(DBTypeMemDB, func(_, _ string, _ store.DBOptions) (corestore.KVStoreWithBatch, error) {
+		return NewMemDB(), nil
+	})
+}
+
 // MemDB is an in-memory database backend using a B-tree for storage.
 //
 // For performance reasons, all given and returned keys and values are pointers to the in-memory
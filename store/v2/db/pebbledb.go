@@ -17,6 +17,12 @@ import (
 
 var _ corestore.KVStoreWithBatch = (*PebbleDB)(nil)
 
+func init() {
+	RegisterDB(DBTypePebbleDB, func(name, dataDir string, opts store.DBOptions) (corestore.KVStoreWithBatch, error) {
+		return NewPebbleDBWithOpts(name, dataDir, opts)
+	})
+}
+
 // PebbleDB implements `corestore.KVStoreWithBatch` using PebbleDB as the underlying storage engine.
 // It is used for only store v2 migration, since some clients use PebbleDB as
 // the IAVL v0/v1 backend.
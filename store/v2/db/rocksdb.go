@@ -13,6 +13,7 @@ import (
 	"github.com/linxGnu/grocksdb"
 
 	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2"
 	storeerrors "cosmossdk.io/store/v2/errors"
 )
 
@@ -22,6 +23,12 @@ var (
 	defaultReadOpts = grocksdb.NewDefaultReadOptions()
 )
 
+func init() {
+	RegisterDB(DBTypeRocksDB, func(name, dataDir string, _ store.DBOptions) (corestore.KVStoreWithBatch, error) {
+		return NewRocksDB(name, dataDir)
+	})
+}
+
 // RocksDB implements `corestore.KVStoreWithBatch` using RocksDB as the underlying storage engine.
 // It is used for only store v2 migration, since some clients use RocksDB as
 // the IAVL v0/v1 backend.
@@ -38,6 +38,10 @@ var (
 
 	// ErrValueNil is returned when attempting to set a nil value.
 	ErrValueNil = errors.New("value nil")
+
+	// ErrTooManyPairs is returned when a range query exceeds its maximum number
+	// of key/value pairs.
+	ErrTooManyPairs = errors.New("too many key/value pairs")
 )
 
 // ErrVersionPruned defines an error returned when a version queried is pruned
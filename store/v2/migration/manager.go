@@ -1,6 +1,7 @@
 package migration
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -25,9 +26,99 @@ const (
 	// defaultStorageBufferSize is the default buffer size for the storage snapshotter.
 	defaultStorageBufferSize = 1024
 
-	migrateChangesetKeyFmt = "m/cs_%x" // m/cs_<version>
+	migrateChangesetKeyFmt    = "m/cs_%x" // m/cs_<version>
+	migrateChangesetKeyPrefix = "m/cs_"
+	migrateStartVersionKey    = "m/start"    // the version the state snapshot is restored at
+	migratedVersionKey        = "m/migrated" // the latest version migrated to the SC and SS
+	migrateDoneKey            = "m/done"     // the version the migration is completed at
+
+	// progressLogInterval is the number of migrated versions between two progress
+	// logs while catching up.
+	progressLogInterval = 100
+	// progressLogPeriod is the period between two progress logs while restoring
+	// the state snapshot.
+	progressLogPeriod = 30 * time.Second
 )
 
+var (
+	// ErrRestoreInterrupted is returned when resuming a migration which was
+	// interrupted while restoring the state snapshot, the migrated SC and SS
+	// must be reset before restarting it.
+	ErrRestoreInterrupted = errors.New("the migration was interrupted while restoring the state snapshot")
+	// ErrAppHashMismatch is returned when the migrated state commitment does not
+	// produce the app hash of the original store.
+	ErrAppHashMismatch = errors.New("the migrated app hash does not match the original app hash")
+)
+
+// Phase defines the phase of the migration.
+type Phase uint8
+
+const (
+	// PhaseNone means the migration has not started.
+	PhaseNone Phase = iota
+	// PhaseRestore means the state snapshot is being restored.
+	PhaseRestore
+	// PhaseCatchUp means the changesets committed since the state snapshot are
+	// being applied.
+	PhaseCatchUp
+	// PhaseDone means the migration is completed.
+	PhaseDone
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseNone:
+		return "none"
+	case PhaseRestore:
+		return "restore"
+	case PhaseCatchUp:
+		return "catch-up"
+	case PhaseDone:
+		return "done"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(p))
+	}
+}
+
+// Progress reports the progress of the migration.
+type Progress struct {
+	Phase Phase
+	// StartVersion is the version the state snapshot is restored at.
+	StartVersion uint64
+	// MigratedVersion is the latest version migrated to the SC and SS.
+	MigratedVersion uint64
+	// RestoredItems is the number of state snapshot items restored, it is only
+	// tracked by the running Manager.
+	RestoredItems uint64
+}
+
+// LoadProgress loads the progress of the migration persisted in the db.
+func LoadProgress(db corestore.KVStore) (Progress, error) {
+	done, err := getVersion(db, migrateDoneKey)
+	if err != nil {
+		return Progress{}, err
+	}
+	if done > 0 {
+		return Progress{Phase: PhaseDone, MigratedVersion: done}, nil
+	}
+
+	progress := Progress{}
+	if progress.StartVersion, err = getVersion(db, migrateStartVersionKey); err != nil {
+		return Progress{}, err
+	}
+	if progress.MigratedVersion, err = getVersion(db, migratedVersionKey); err != nil {
+		return Progress{}, err
+	}
+	switch {
+	case progress.MigratedVersion > 0:
+		progress.Phase = PhaseCatchUp
+	case progress.StartVersion > 0:
+		progress.Phase = PhaseRestore
+	}
+
+	return progress, nil
+}
+
 // VersionedChangeset is a pair of version and Changeset.
 type VersionedChangeset struct {
 	Version   uint64
@@ -43,8 +134,11 @@ type Manager struct {
 	stateCommitment *commitment.CommitStore
 
 	db              corestore.KVStoreWithBatch
-	mtx             sync.Mutex // mutex for migratedVersion
+	mtx             sync.Mutex // mutex for migratedVersion, phase, startVersion and stream
 	migratedVersion uint64
+	phase           Phase
+	startVersion    uint64
+	stream          *MigrationStream
 
 	chChangeset <-chan *VersionedChangeset
 	chDone      <-chan struct{}
@@ -64,7 +158,8 @@ func NewManager(db corestore.KVStoreWithBatch, sm *snapshots.Manager, ss *storag
 }
 
 // Start starts the whole migration process.
-// It migrates the whole state at the given version to the new store/v2 (both SC and SS).
+// It migrates the whole state at the given version to the new store/v2 (both SC and SS),
+// or resumes the migration persisted in the db, see Run.
 // It also catches up the Changesets which are committed while the migration is in progress.
 // `chChangeset` is the channel to receive the committed Changesets from the RootStore.
 // `chDone` is the channel to receive the done signal from the RootStore.
@@ -79,13 +174,109 @@ func (m *Manager) Start(version uint64, chChangeset <-chan *VersionedChangeset,
 		}
 	}()
 
-	if err := m.Migrate(version); err != nil {
+	if err := m.Run(version); err != nil {
 		return fmt.Errorf("failed to migrate state: %w", err)
 	}
 
 	return m.Sync()
 }
 
+// Run migrates the whole state up to the given version synchronously. It
+// restores the state snapshot at the given version, or resumes the migration
+// persisted in the db by applying the buffered Changesets from the migrated
+// version up to the given version.
+func (m *Manager) Run(version uint64) error {
+	progress, err := LoadProgress(m.db)
+	if err != nil {
+		return fmt.Errorf("failed to load migration progress: %w", err)
+	}
+
+	switch progress.Phase {
+	case PhaseDone:
+		return fmt.Errorf("the migration is already completed at version %d", progress.MigratedVersion)
+	case PhaseRestore:
+		return fmt.Errorf("%w at version %d", ErrRestoreInterrupted, progress.StartVersion)
+	case PhaseCatchUp:
+		return m.resume(progress, version)
+	default:
+		return m.Migrate(version)
+	}
+}
+
+// resume resumes the migration from the persisted migrated version, applying
+// the buffered Changesets up to the given version.
+func (m *Manager) resume(progress Progress, version uint64) error {
+	if progress.MigratedVersion > version {
+		return fmt.Errorf("the migrated version %d is greater than the version %d", progress.MigratedVersion, version)
+	}
+	m.logger.Info("resuming migration", "migrated_version", progress.MigratedVersion, "version", version)
+
+	if m.stateCommitment != nil {
+		if err := m.stateCommitment.LoadVersion(progress.MigratedVersion); err != nil {
+			return fmt.Errorf("failed to load the migrated commitment: %w", err)
+		}
+	}
+
+	m.mtx.Lock()
+	m.phase = PhaseCatchUp
+	m.startVersion = progress.StartVersion
+	m.migratedVersion = progress.MigratedVersion
+	m.mtx.Unlock()
+
+	for v := progress.MigratedVersion + 1; v <= version; v++ {
+		cs, err := m.getChangeset(v)
+		if err != nil {
+			return err
+		}
+		if cs == nil {
+			return fmt.Errorf("failed to resume migration: the changeset of version %d is missing", v)
+		}
+		if err := m.applyChangeset(v, cs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Progress returns the progress of the migration.
+func (m *Manager) Progress() Progress {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	progress := Progress{
+		Phase:           m.phase,
+		StartVersion:    m.startVersion,
+		MigratedVersion: m.migratedVersion,
+	}
+	if m.stream != nil {
+		progress.RestoredItems = m.stream.ItemsRead()
+	}
+
+	return progress
+}
+
+// VerifyAppHash returns ErrAppHashMismatch if the commit info of the migrated
+// state commitment at the given version does not have the given app hash.
+func (m *Manager) VerifyAppHash(version uint64, appHash []byte) error {
+	if m.stateCommitment == nil {
+		return nil
+	}
+
+	cInfo, err := m.stateCommitment.GetCommitInfo(version)
+	if err != nil {
+		return fmt.Errorf("failed to get the migrated commit info: %w", err)
+	}
+	if cInfo == nil {
+		return fmt.Errorf("the migrated commit info of version %d is not found", version)
+	}
+	if !bytes.Equal(cInfo.Hash(), appHash) {
+		return fmt.Errorf("%w at version %d: got %X, expected %X", ErrAppHashMismatch, version, cInfo.Hash(), appHash)
+	}
+
+	return nil
+}
+
 // GetStateCommitment returns the state commitment.
 func (m *Manager) GetStateCommitment() *commitment.CommitStore {
 	return m.stateCommitment
@@ -93,14 +284,41 @@ func (m *Manager) GetStateCommitment() *commitment.CommitStore {
 
 // Migrate migrates the whole state at the given height to the new store/v2.
 func (m *Manager) Migrate(height uint64) error {
+	// persist the start version, so that an interrupted restore is detected
+	if err := setVersion(m.db, migrateStartVersionKey, height); err != nil {
+		return err
+	}
+
 	// create the migration stream and snapshot,
 	// which acts as protoio.Reader and snapshots.WriteCloser.
 	ms := NewMigrationStream(defaultChannelBufferSize)
 
+	m.mtx.Lock()
+	m.phase = PhaseRestore
+	m.startVersion = height
+	m.stream = ms
+	m.mtx.Unlock()
+	m.logger.Info("restoring state snapshot", "version", height)
+
 	if err := m.snapshotsManager.CreateMigration(height, ms); err != nil {
 		return err
 	}
 
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(progressLogPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				m.logger.Info("restoring state snapshot", "version", height, "items", ms.ItemsRead())
+			}
+		}
+	}()
+
 	// restore the snapshot
 	chStorage := make(chan *corestore.StateChanges, defaultStorageBufferSize)
 
@@ -161,9 +379,15 @@ func (m *Manager) Migrate(height uint64) error {
 		return err
 	}
 
+	if err := setVersion(m.db, migratedVersionKey, height); err != nil {
+		return err
+	}
+
 	m.mtx.Lock()
 	m.migratedVersion = height
+	m.phase = PhaseCatchUp
 	m.mtx.Unlock()
+	m.logger.Info("restored state snapshot", "version", height, "items", ms.ItemsRead())
 
 	return nil
 }
@@ -172,9 +396,7 @@ func (m *Manager) Migrate(height uint64) error {
 func (m *Manager) writeChangeset() error {
 	for vc := range m.chChangeset {
 		cs := vc.Changeset
-		buf := make([]byte, 8)
-		binary.BigEndian.PutUint64(buf, vc.Version)
-		csKey := []byte(fmt.Sprintf(migrateChangesetKeyFmt, buf))
+		csKey := changesetKey(vc.Version)
 		csBytes, err := encoding.MarshalChangeset(cs)
 		if err != nil {
 			return fmt.Errorf("failed to marshal changeset: %w", err)
@@ -229,49 +451,81 @@ func (m *Manager) Sync() error {
 		case <-m.chDone:
 			return nil
 		default:
-			buf := make([]byte, 8)
-			binary.BigEndian.PutUint64(buf, version)
-			csKey := []byte(fmt.Sprintf(migrateChangesetKeyFmt, buf))
-			csBytes, err := m.db.Get(csKey)
+			cs, err := m.getChangeset(version)
 			if err != nil {
-				return fmt.Errorf("failed to get changeset from db: %w", err)
+				return err
 			}
-			if csBytes == nil {
+			if cs == nil {
 				// wait for the next changeset
 				time.Sleep(100 * time.Millisecond)
 				continue
 			}
-
-			cs := corestore.NewChangeset()
-			if err := encoding.UnmarshalChangeset(cs, csBytes); err != nil {
-				return fmt.Errorf("failed to unmarshal changeset: %w", err)
-			}
-			if m.stateCommitment != nil {
-				if err := m.stateCommitment.WriteChangeset(cs); err != nil {
-					return fmt.Errorf("failed to write changeset to commitment: %w", err)
-				}
-				if _, err := m.stateCommitment.Commit(version); err != nil {
-					return fmt.Errorf("failed to commit changeset to commitment: %w", err)
-				}
-			}
-			if err := m.stateStorage.ApplyChangeset(version, cs); err != nil {
-				return fmt.Errorf("failed to write changeset to storage: %w", err)
+			if err := m.applyChangeset(version, cs); err != nil {
+				return err
 			}
 
-			m.mtx.Lock()
-			m.migratedVersion = version
-			m.mtx.Unlock()
-
 			version += 1
 		}
 	}
 }
 
-// Close closes the manager. It should be called after the migration is done.
-// It will close the db and notify the snapshotsManager that the migration is done.
+// getChangeset returns the buffered Changeset of the given version, or nil if
+// it is not buffered yet.
+func (m *Manager) getChangeset(version uint64) (*corestore.Changeset, error) {
+	csBytes, err := m.db.Get(changesetKey(version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changeset from db: %w", err)
+	}
+	if csBytes == nil {
+		return nil, nil
+	}
+
+	cs := corestore.NewChangeset()
+	if err := encoding.UnmarshalChangeset(cs, csBytes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal changeset: %w", err)
+	}
+
+	return cs, nil
+}
+
+// applyChangeset applies the Changeset of the given version to the SC and SS,
+// then persists the migrated version. The buffered Changesets are kept until
+// the migration is completed.
+func (m *Manager) applyChangeset(version uint64, cs *corestore.Changeset) error {
+	if m.stateCommitment != nil {
+		if err := m.stateCommitment.WriteChangeset(cs); err != nil {
+			return fmt.Errorf("failed to write changeset to commitment: %w", err)
+		}
+		if _, err := m.stateCommitment.Commit(version); err != nil {
+			return fmt.Errorf("failed to commit changeset to commitment: %w", err)
+		}
+	}
+	if err := m.stateStorage.ApplyChangeset(version, cs); err != nil {
+		return fmt.Errorf("failed to write changeset to storage: %w", err)
+	}
+	if err := setVersion(m.db, migratedVersionKey, version); err != nil {
+		return fmt.Errorf("failed to write migration progress: %w", err)
+	}
+
+	m.mtx.Lock()
+	m.migratedVersion = version
+	m.mtx.Unlock()
+
+	if version%progressLogInterval == 0 {
+		m.logger.Info("migration progress", "migrated_version", version)
+	}
+
+	return nil
+}
+
+// Close completes the migration. It persists the migration as done, drops the
+// remaining buffered Changesets and notifies the snapshotsManager that the
+// migration is done. It should be called after the migration is done.
+//
+// NOTE: The db is not closed, as it may be shared with the migrated SC.
 func (m *Manager) Close() error {
-	if err := m.db.Close(); err != nil {
-		return fmt.Errorf("failed to close db: %w", err)
+	if err := m.complete(); err != nil {
+		return fmt.Errorf("failed to complete migration: %w", err)
 	}
 	if m.stateCommitment != nil {
 		m.snapshotsManager.EndMigration(m.stateCommitment)
@@ -279,3 +533,77 @@ func (m *Manager) Close() error {
 
 	return nil
 }
+
+func (m *Manager) complete() (err error) {
+	iter, err := m.db.Iterator([]byte(migrateChangesetKeyPrefix), storePrefixEnd([]byte(migrateChangesetKeyPrefix)))
+	if err != nil {
+		return err
+	}
+	var keys [][]byte
+	for ; iter.Valid(); iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	// the iterator must be closed before writing the batch
+	if err := iter.Close(); err != nil {
+		return err
+	}
+
+	batch := m.db.NewBatch()
+	defer func() {
+		cErr := batch.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+	for _, key := range append(keys, []byte(migrateStartVersionKey), []byte(migratedVersionKey)) {
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+	}
+	if err := batch.Set([]byte(migrateDoneKey), encodeVersion(m.GetMigratedVersion())); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+
+	m.mtx.Lock()
+	m.phase = PhaseDone
+	m.stream = nil
+	m.mtx.Unlock()
+
+	return nil
+}
+
+func changesetKey(version uint64) []byte {
+	return []byte(fmt.Sprintf(migrateChangesetKeyFmt, encodeVersion(version)))
+}
+
+func encodeVersion(version uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, version)
+	return buf
+}
+
+func setVersion(db corestore.KVStore, key string, version uint64) error {
+	return db.Set([]byte(key), encodeVersion(version))
+}
+
+func getVersion(db corestore.KVStore, key string) (uint64, error) {
+	bz, err := db.Get([]byte(key))
+	if err != nil {
+		return 0, err
+	}
+	if len(bz) != 8 {
+		return 0, nil
+	}
+
+	return binary.BigEndian.Uint64(bz), nil
+}
+
+// storePrefixEnd returns the smallest key greater than all the keys with the prefix.
+func storePrefixEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	end[len(end)-1]++
+	return end
+}
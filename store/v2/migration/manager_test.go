@@ -12,6 +12,7 @@ import (
 	"cosmossdk.io/store/v2/commitment"
 	"cosmossdk.io/store/v2/commitment/iavl"
 	dbm "cosmossdk.io/store/v2/db"
+	"cosmossdk.io/store/v2/internal/encoding"
 	"cosmossdk.io/store/v2/snapshots"
 	"cosmossdk.io/store/v2/storage"
 	"cosmossdk.io/store/v2/storage/pebbledb"
@@ -159,7 +160,7 @@ func TestStartMigrateState(t *testing.T) {
 			go func() {
 				for {
 					migrateVersion := m.GetMigratedVersion()
-					if migrateVersion == toVersion-1 {
+					if migrateVersion >= toVersion-1 {
 						break
 					}
 				}
@@ -218,3 +219,81 @@ func TestStartMigrateState(t *testing.T) {
 		})
 	}
 }
+
+func TestResumeMigration(t *testing.T) {
+	m, orgCommitStore := setupMigrationManager(t, false)
+
+	// apply changeset
+	toVersion := uint64(10)
+	keyCount := 5
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			for i := 0; i < keyCount; i++ {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d-%d", version, i)), []byte(fmt.Sprintf("value-%d-%d", version, i)), false)
+			}
+		}
+		require.NoError(t, orgCommitStore.WriteChangeset(cs))
+		_, err := orgCommitStore.Commit(version)
+		require.NoError(t, err)
+
+		// buffer the changesets committed after the snapshot version
+		if version > 5 {
+			bz, err := encoding.MarshalChangeset(cs)
+			require.NoError(t, err)
+			require.NoError(t, m.db.Set(changesetKey(version), bz))
+		}
+	}
+
+	progress, err := LoadProgress(m.db)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Phase: PhaseNone}, progress)
+
+	// the migration is interrupted after restoring the snapshot
+	require.NoError(t, m.Run(5))
+	require.Equal(t, PhaseCatchUp, m.Progress().Phase)
+	require.Equal(t, uint64(5), m.Progress().MigratedVersion)
+	progress, err = LoadProgress(m.db)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Phase: PhaseCatchUp, StartVersion: 5, MigratedVersion: 5}, progress)
+
+	// resume the migration with a new manager
+	m = NewManager(m.db, m.snapshotsManager, m.stateStorage, m.stateCommitment, coretesting.NewNopLogger())
+	require.NoError(t, m.Run(toVersion))
+	require.Equal(t, toVersion, m.GetMigratedVersion())
+
+	for version := uint64(1); version <= toVersion; version++ {
+		for _, storeKey := range storeKeys {
+			val, err := m.stateStorage.Get([]byte(storeKey), toVersion, []byte(fmt.Sprintf("key-%d-0", version)))
+			require.NoError(t, err)
+			require.Equal(t, []byte(fmt.Sprintf("value-%d-0", version)), val)
+		}
+	}
+
+	// the migrated app hash matches the original one
+	cInfo, err := orgCommitStore.GetCommitInfo(toVersion)
+	require.NoError(t, err)
+	require.NoError(t, m.VerifyAppHash(toVersion, cInfo.Hash()))
+	require.ErrorIs(t, m.VerifyAppHash(toVersion, []byte("invalid")), ErrAppHashMismatch)
+
+	// complete the migration
+	require.NoError(t, m.Close())
+	progress, err = LoadProgress(m.db)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Phase: PhaseDone, MigratedVersion: toVersion}, progress)
+	csVal, err := m.db.Get(changesetKey(toVersion))
+	require.NoError(t, err)
+	require.Nil(t, csVal)
+	require.Error(t, m.Run(toVersion))
+}
+
+func TestResumeInterruptedRestore(t *testing.T) {
+	m, _ := setupMigrationManager(t, false)
+
+	require.NoError(t, setVersion(m.db, migrateStartVersionKey, 5))
+	progress, err := LoadProgress(m.db)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Phase: PhaseRestore, StartVersion: 5}, progress)
+
+	require.ErrorIs(t, m.Run(5), ErrRestoreInterrupted)
+}
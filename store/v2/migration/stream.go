@@ -23,6 +23,8 @@ var (
 type MigrationStream struct {
 	chBuffer chan proto.Message
 	err      atomic.Value // atomic error
+	// read is the number of snapshot items read from the stream.
+	read atomic.Uint64
 }
 
 // NewMigrationStream returns a new MigrationStream.
@@ -68,10 +70,16 @@ func (ms *MigrationStream) ReadMsg(msg proto.Message) error {
 	if err != nil {
 		return err.(error)
 	}
+	ms.read.Add(1)
 
 	return nil
 }
 
+// ItemsRead returns the number of snapshot items read from the stream.
+func (ms *MigrationStream) ItemsRead() uint64 {
+	return ms.read.Load()
+}
+
 // Close implements io.Closer interface.
 func (ms *MigrationStream) Close() error {
 	close(ms.chBuffer)
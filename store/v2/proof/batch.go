@@ -0,0 +1,221 @@
+package proof
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	ics23 "github.com/cosmos/ics23/go"
+
+	errors "cosmossdk.io/errors/v2"
+	storeerrors "cosmossdk.io/store/v2/errors"
+)
+
+// KVPair defines a key/value pair proven by a batch or a range proof.
+type KVPair struct {
+	Key   []byte
+	Value []byte
+}
+
+// VerifyBatchProof verifies that the proof ops of a batch query, i.e. the batch
+// proof of the store tree and the proof of the store in the CommitInfo, prove
+// that the pairs exist and that the absent keys do not exist in the store under
+// the given app hash.
+func VerifyBatchProof(ops []CommitmentOp, appHash, storeKey []byte, pairs []KVPair, absentKeys [][]byte) error {
+	treeOp, root, err := verifyStoreProof(ops, appHash, storeKey)
+	if err != nil {
+		return err
+	}
+
+	items := make(map[string][]byte, len(pairs))
+	for _, pair := range pairs {
+		items[string(pair.Key)] = pair.Value
+	}
+	if !ics23.BatchVerifyMembership(treeOp.Spec, root, treeOp.Proof, items) {
+		return errors.Wrap(storeerrors.ErrInvalidProof, "proof did not verify the existence of the pairs")
+	}
+	if !ics23.BatchVerifyNonMembership(treeOp.Spec, root, treeOp.Proof, absentKeys) {
+		return errors.Wrap(storeerrors.ErrInvalidProof, "proof did not verify the absence of the keys")
+	}
+
+	return nil
+}
+
+// VerifyRangeProof verifies that the proof ops of a range query prove that the
+// pairs, sorted by key, are all the key/value pairs of the range [start, end)
+// of the store under the given app hash. A nil start or end leaves the range
+// unbounded on that side.
+//
+// The proof must contain the existence proofs of the pairs and of the closest
+// keys outside of the range, which are verified to be neighbors in the tree so
+// that no key of the range can be omitted.
+func VerifyRangeProof(ops []CommitmentOp, appHash, storeKey, start, end []byte, pairs []KVPair) error {
+	treeOp, root, err := verifyStoreProof(ops, appHash, storeKey)
+	if err != nil {
+		return err
+	}
+
+	proofs, err := existenceProofs(treeOp.Spec, root, treeOp.Proof)
+	if err != nil {
+		return err
+	}
+
+	// split the proven keys into the ones before, in and after the range
+	var left, right *ics23.ExistenceProof
+	inRange := make([]*ics23.ExistenceProof, 0, len(pairs))
+	for _, p := range proofs {
+		switch {
+		case start != nil && bytes.Compare(p.Key, start) < 0:
+			left = p
+		case end != nil && bytes.Compare(p.Key, end) >= 0:
+			if right == nil {
+				right = p
+			}
+		default:
+			inRange = append(inRange, p)
+		}
+	}
+
+	if len(inRange) != len(pairs) {
+		return errors.Wrapf(storeerrors.ErrInvalidProof, "proof has %d pairs in the range, got %d", len(inRange), len(pairs))
+	}
+	for i, p := range inRange {
+		if !bytes.Equal(p.Key, pairs[i].Key) || !bytes.Equal(p.Value, pairs[i].Value) {
+			return errors.Wrapf(storeerrors.ErrInvalidProof, "proof did not verify the pair of key %X", pairs[i].Key)
+		}
+	}
+
+	// the proven keys must be neighbors, and the outermost ones must be the
+	// first and the last keys of the tree when the range has no neighbor
+	chain := inRange
+	if left != nil {
+		chain = append([]*ics23.ExistenceProof{left}, chain...)
+	}
+	if right != nil {
+		chain = append(chain, right)
+	}
+	if len(chain) == 0 {
+		return errors.Wrap(storeerrors.ErrInvalidProof, "proof is empty")
+	}
+	if left == nil && !ics23.IsLeftMost(treeOp.Spec.InnerSpec, chain[0].Path) {
+		return errors.Wrap(storeerrors.ErrInvalidProof, "proof is missing the left neighbor of the range")
+	}
+	if right == nil && !ics23.IsRightMost(treeOp.Spec.InnerSpec, chain[len(chain)-1].Path) {
+		return errors.Wrap(storeerrors.ErrInvalidProof, "proof is missing the right neighbor of the range")
+	}
+	for i := 1; i < len(chain); i++ {
+		if !isLeftNeighbor(treeOp.Spec.InnerSpec, chain[i-1].Path, chain[i].Path) {
+			return errors.Wrapf(storeerrors.ErrInvalidProof, "proof keys %X and %X are not neighbors", chain[i-1].Key, chain[i].Key)
+		}
+	}
+
+	return nil
+}
+
+// verifyStoreProof verifies the proof of the store in the CommitInfo against
+// the app hash, and returns the proof op and the root of the store tree.
+func verifyStoreProof(ops []CommitmentOp, appHash, storeKey []byte) (CommitmentOp, []byte, error) {
+	if len(ops) != 2 {
+		return CommitmentOp{}, nil, errors.Wrapf(storeerrors.ErrInvalidProof, "expected 2 proof ops, got %d", len(ops))
+	}
+	treeOp, storeOp := ops[0], ops[1]
+	if treeOp.Spec == nil || treeOp.Proof == nil || storeOp.Proof == nil {
+		return CommitmentOp{}, nil, errors.Wrap(storeerrors.ErrInvalidProof, "proof op is missing its spec or proof")
+	}
+	if !bytes.Equal(storeOp.Key, storeKey) {
+		return CommitmentOp{}, nil, errors.Wrapf(storeerrors.ErrInvalidProof, "proof is for store %s, expected %s", storeOp.Key, storeKey)
+	}
+
+	root, err := treeOp.Proof.Calculate()
+	if err != nil {
+		return CommitmentOp{}, nil, errors.Wrapf(storeerrors.ErrInvalidProof, "could not calculate root for proof: %v", err)
+	}
+	appRoot, err := storeOp.Run([][]byte{root})
+	if err != nil {
+		return CommitmentOp{}, nil, err
+	}
+	if !bytes.Equal(appRoot[0], appHash) {
+		return CommitmentOp{}, nil, errors.Wrapf(storeerrors.ErrInvalidProof, "proof root %X does not match the app hash %X", appRoot[0], appHash)
+	}
+
+	return treeOp, root, nil
+}
+
+// existenceProofs returns the existence proofs contained in the proof, including
+// the neighbors of its non-existence proofs, verified against the root and
+// sorted by key.
+func existenceProofs(spec *ics23.ProofSpec, root []byte, p *ics23.CommitmentProof) ([]*ics23.ExistenceProof, error) {
+	var all []*ics23.ExistenceProof
+	addNonExist := func(nonExist *ics23.NonExistenceProof) {
+		if nonExist.Left != nil {
+			all = append(all, nonExist.Left)
+		}
+		if nonExist.Right != nil {
+			all = append(all, nonExist.Right)
+		}
+	}
+
+	p = ics23.Decompress(p)
+	switch {
+	case p.GetExist() != nil:
+		all = append(all, p.GetExist())
+	case p.GetNonexist() != nil:
+		addNonExist(p.GetNonexist())
+	case p.GetBatch() != nil:
+		for _, entry := range p.GetBatch().Entries {
+			if exist := entry.GetExist(); exist != nil {
+				all = append(all, exist)
+			} else if nonExist := entry.GetNonexist(); nonExist != nil {
+				addNonExist(nonExist)
+			}
+		}
+	}
+
+	proofs := make(map[string]*ics23.ExistenceProof, len(all))
+	for _, ep := range all {
+		if err := ep.Verify(spec, root, ep.Key, ep.Value); err != nil {
+			return nil, errors.Wrapf(storeerrors.ErrInvalidProof, "proof did not verify existence of key %X: %v", ep.Key, err)
+		}
+		proofs[string(ep.Key)] = ep
+	}
+
+	sorted := make([]*ics23.ExistenceProof, 0, len(proofs))
+	for _, ep := range proofs {
+		sorted = append(sorted, ep)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Key, sorted[j].Key) < 0
+	})
+
+	return sorted, nil
+}
+
+// isLeftNeighbor wraps ics23.IsLeftNeighbor, which panics on the paths which
+// do not diverge.
+func isLeftNeighbor(spec *ics23.InnerSpec, left, right []*ics23.InnerOp) (ok bool) {
+	if len(left) == 0 || len(right) == 0 {
+		return false
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			ok = false
+		}
+	}()
+
+	return ics23.IsLeftNeighbor(spec, left, right)
+}
+
+// NewCommitmentOp returns the CommitmentOp of the given proof type, mapping the
+// type to its proof spec. It is used to decode the proof ops of a query.
+func NewCommitmentOp(typ string, key []byte, proof *ics23.CommitmentProof) (CommitmentOp, error) {
+	switch typ {
+	case ProofOpIAVLCommitment:
+		return NewIAVLCommitmentOp(key, proof), nil
+	case ProofOpSimpleMerkleCommitment:
+		return NewSimpleMerkleCommitmentOp(key, proof), nil
+	case ProofOpSMTCommitment:
+		return NewSMTCommitmentOp(key, proof), nil
+	default:
+		return CommitmentOp{}, fmt.Errorf("unknown proof op type %s", typ)
+	}
+}
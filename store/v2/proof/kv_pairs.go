@@ -0,0 +1,83 @@
+package proof
+
+import "google.golang.org/protobuf/encoding/protowire"
+
+// MarshalKVPairs encodes the pairs as the kv.Pairs proto message of the SDK,
+// which is the value of the batch and subspace store queries.
+func MarshalKVPairs(pairs []KVPair) []byte {
+	var bz []byte
+	for _, pair := range pairs {
+		var pairBz []byte
+		if len(pair.Key) > 0 {
+			pairBz = protowire.AppendTag(pairBz, 1, protowire.BytesType)
+			pairBz = protowire.AppendBytes(pairBz, pair.Key)
+		}
+		if len(pair.Value) > 0 {
+			pairBz = protowire.AppendTag(pairBz, 2, protowire.BytesType)
+			pairBz = protowire.AppendBytes(pairBz, pair.Value)
+		}
+		bz = protowire.AppendTag(bz, 1, protowire.BytesType)
+		bz = protowire.AppendBytes(bz, pairBz)
+	}
+
+	return bz
+}
+
+// UnmarshalKVPairs decodes the pairs encoded by MarshalKVPairs.
+func UnmarshalKVPairs(bz []byte) ([]KVPair, error) {
+	var pairs []KVPair
+	err := consumeBytesFields(bz, func(num protowire.Number, value []byte) error {
+		if num != 1 {
+			return nil
+		}
+		pair := KVPair{}
+		err := consumeBytesFields(value, func(num protowire.Number, value []byte) error {
+			switch num {
+			case 1:
+				pair.Key = value
+			case 2:
+				pair.Value = value
+			}
+			return nil
+		})
+		pairs = append(pairs, pair)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pairs, nil
+}
+
+// consumeBytesFields calls fn with the value of each length-delimited field of
+// the proto message, the other fields are skipped.
+func consumeBytesFields(bz []byte, fn func(num protowire.Number, value []byte) error) error {
+	for len(bz) > 0 {
+		num, typ, n := protowire.ConsumeTag(bz)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		bz = bz[n:]
+
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, bz)
+			if n < 0 {
+				return protowire.ParseError(n)
+			}
+			bz = bz[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(bz)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		if err := fn(num, value); err != nil {
+			return err
+		}
+		bz = bz[n:]
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package proof
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKVPairs(t *testing.T) {
+	pairs := []KVPair{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key2")},
+	}
+
+	// encoded as the kv.Pairs proto message
+	bz := MarshalKVPairs(pairs)
+	require.Equal(t, []byte{
+		0x0a, 0x0e, 0x0a, 0x04, 'k', 'e', 'y', '1', 0x12, 0x06, 'v', 'a', 'l', 'u', 'e', '1',
+		0x0a, 0x06, 0x0a, 0x04, 'k', 'e', 'y', '2',
+	}, bz)
+
+	decoded, err := UnmarshalKVPairs(bz)
+	require.NoError(t, err)
+	require.Equal(t, pairs, decoded)
+
+	decoded, err = UnmarshalKVPairs(nil)
+	require.NoError(t, err)
+	require.Empty(t, decoded)
+
+	_, err = UnmarshalKVPairs(bz[:len(bz)-1])
+	require.Error(t, err)
+}
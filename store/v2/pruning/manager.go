@@ -1,6 +1,8 @@
 package pruning
 
 import (
+	"fmt"
+
 	"cosmossdk.io/store/v2"
 )
 
@@ -14,6 +16,12 @@ type Manager struct {
 	ssPruner store.Pruner
 	// ssPruningOption are the pruning options for the SS.
 	ssPruningOption *store.PruningOption
+	// scStorePruningOptions are the per store key pruning options for the SC,
+	// overriding scPruningOption.
+	scStorePruningOptions map[string]*store.PruningOption
+	// ssStorePruningOptions are the per store key pruning options for the SS,
+	// overriding ssPruningOption.
+	ssStorePruningOptions map[string]*store.PruningOption
 }
 
 // NewManager creates a new Pruning Manager.
@@ -26,29 +34,88 @@ func NewManager(scPruner, ssPruner store.Pruner, scPruningOption, ssPruningOptio
 	}
 }
 
+// SetStorePruningOptions sets the per store key pruning options for the SC and
+// SS. A store key present in the options is pruned with its own option instead
+// of the default one, a nil option disables pruning for the store key. The
+// corresponding pruner must implement the StorePruner interface.
+func (m *Manager) SetStorePruningOptions(scStorePruningOptions, ssStorePruningOptions map[string]*store.PruningOption) error {
+	if len(scStorePruningOptions) > 0 {
+		if _, ok := m.scPruner.(store.StorePruner); !ok {
+			return fmt.Errorf("SC pruner %T does not support store pruning options", m.scPruner)
+		}
+	}
+	if len(ssStorePruningOptions) > 0 {
+		if _, ok := m.ssPruner.(store.StorePruner); !ok {
+			return fmt.Errorf("SS pruner %T does not support store pruning options", m.ssPruner)
+		}
+	}
+
+	m.scStorePruningOptions = scStorePruningOptions
+	m.ssStorePruningOptions = ssStorePruningOptions
+
+	return nil
+}
+
 // Prune prunes the SC and SS to the provided version.
 //
 // NOTE: It can be called outside of the store manually.
 func (m *Manager) Prune(version uint64) error {
-	// Prune the SC.
-	if m.scPruningOption != nil {
-		if prune, pruneTo := m.scPruningOption.ShouldPrune(version); prune {
-			if err := m.scPruner.Prune(pruneTo); err != nil {
-				return err
-			}
+	if err := m.PruneSC(version); err != nil {
+		return err
+	}
+
+	return m.PruneSS(version)
+}
+
+// PruneSC prunes the SC to the provided version.
+func (m *Manager) PruneSC(version uint64) error {
+	return prune(m.scPruner, m.scPruningOption, m.scStorePruningOptions, version)
+}
+
+// PruneSS prunes the SS to the provided version.
+func (m *Manager) PruneSS(version uint64) error {
+	return prune(m.ssPruner, m.ssPruningOption, m.ssStorePruningOptions, version)
+}
+
+// prune prunes the pruner at the provided version according to the default
+// pruning option and the per store key pruning options.
+func prune(pruner store.Pruner, opt *store.PruningOption, storeOpts map[string]*store.PruningOption, version uint64) error {
+	var (
+		shouldPrune bool
+		pruneTo     uint64
+	)
+	if opt != nil {
+		shouldPrune, pruneTo = opt.ShouldPrune(version)
+		if !shouldPrune {
+			pruneTo = 0
 		}
 	}
 
-	// Prune the SS.
-	if m.ssPruningOption != nil {
-		if prune, pruneTo := m.ssPruningOption.ShouldPrune(version); prune {
-			if err := m.ssPruner.Prune(pruneTo); err != nil {
-				return err
-			}
+	if len(storeOpts) == 0 {
+		if !shouldPrune {
+			return nil
 		}
+		return pruner.Prune(pruneTo)
 	}
 
-	return nil
+	storeVersions := make(map[string]uint64, len(storeOpts))
+	for storeKey, storeOpt := range storeOpts {
+		storeVersions[storeKey] = 0
+		if storeOpt == nil {
+			continue
+		}
+		if prune, storePruneTo := storeOpt.ShouldPrune(version); prune {
+			shouldPrune = true
+			storeVersions[storeKey] = storePruneTo
+		}
+	}
+
+	if !shouldPrune {
+		return nil
+	}
+
+	// SetStorePruningOptions ensures the pruner is a StorePruner.
+	return pruner.(store.StorePruner).PruneStores(pruneTo, storeVersions)
 }
 
 // SignalCommit signals to the manager that a commit has started or finished.
@@ -56,12 +123,7 @@ func (m *Manager) Prune(version uint64) error {
 // It pauses or resumes the pruning of the SC and SS if the pruner implements
 // the PausablePruner interface.
 func (m *Manager) SignalCommit(start bool, version uint64) error {
-	if scPausablePruner, ok := m.scPruner.(store.PausablePruner); ok {
-		scPausablePruner.PausePruning(start)
-	}
-	if ssPausablePruner, ok := m.ssPruner.(store.PausablePruner); ok {
-		ssPausablePruner.PausePruning(start)
-	}
+	m.pausePruning(start)
 
 	if !start {
 		return m.Prune(version)
@@ -69,3 +131,23 @@ func (m *Manager) SignalCommit(start bool, version uint64) error {
 
 	return nil
 }
+
+// SignalSCCommitDone signals to the manager that a commit has finished, when the
+// SS is written asynchronously. Only the SC is pruned, the SS must be pruned
+// with PruneSS once the committed version is applied to it.
+func (m *Manager) SignalSCCommitDone(version uint64) error {
+	m.pausePruning(false)
+
+	return m.PruneSC(version)
+}
+
+// pausePruning pauses or resumes the pruning of the SC and SS if the pruner
+// implements the PausablePruner interface.
+func (m *Manager) pausePruning(pause bool) {
+	if scPausablePruner, ok := m.scPruner.(store.PausablePruner); ok {
+		scPausablePruner.PausePruning(pause)
+	}
+	if ssPausablePruner, ok := m.ssPruner.(store.PausablePruner); ok {
+		ssPausablePruner.PausePruning(pause)
+	}
+}
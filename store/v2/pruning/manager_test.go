@@ -106,6 +106,56 @@ func (s *PruningManagerTestSuite) TestPrune() {
 	}
 }
 
+func (s *PruningManagerTestSuite) TestPruneStores() {
+	// store2 keeps more versions in the SS and store3 is never pruned
+	s.Require().NoError(s.manager.SetStorePruningOptions(
+		map[string]*store.PruningOption{"store3": nil},
+		map[string]*store.PruningOption{"store2": store.NewPruningOptionWithCustom(20, 10), "store3": nil},
+	))
+
+	// commit changesets with pruning
+	toVersion := uint64(100)
+	keyCount := 10
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			for i := 0; i < keyCount; i++ {
+				cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d-%d", version, i)), []byte(fmt.Sprintf("value-%d-%d", version, i)), false)
+			}
+		}
+		s.Require().NoError(s.sc.WriteChangeset(cs))
+		_, err := s.sc.Commit(version)
+		s.Require().NoError(err)
+
+		s.Require().NoError(s.ss.ApplyChangeset(version, cs))
+
+		s.Require().NoError(s.manager.Prune(version))
+	}
+
+	// the commit infos are kept as long as store3 is not pruned
+	for version := uint64(1); version <= toVersion; version++ {
+		commitInfo, err := s.sc.GetCommitInfo(version)
+		s.Require().NoError(err)
+		s.Require().NotNil(commitInfo)
+	}
+
+	// check the storage store
+	pruneVersions := map[string]uint64{"store1": 94, "store2": 79, "store3": 0}
+	for version := uint64(1); version <= toVersion; version++ {
+		for _, storeKey := range storeKeys {
+			key := []byte(fmt.Sprintf("key-%d-%d", version, 0))
+			value, err := s.ss.Get([]byte(storeKey), version, key)
+			if version <= pruneVersions[storeKey] {
+				s.Require().Nil(value)
+				s.Require().Error(err)
+			} else {
+				s.Require().NoError(err)
+				s.Require().Equal([]byte(fmt.Sprintf("value-%d-%d", version, 0)), value)
+			}
+		}
+	}
+}
+
 func TestPruningOption(t *testing.T) {
 	testCases := []struct {
 		name         string
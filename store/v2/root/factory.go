@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"cosmossdk.io/core/log"
 	corestore "cosmossdk.io/core/store"
@@ -11,12 +12,16 @@ import (
 	"cosmossdk.io/store/v2/commitment"
 	"cosmossdk.io/store/v2/commitment/iavl"
 	"cosmossdk.io/store/v2/commitment/mem"
+	"cosmossdk.io/store/v2/commitment/smt"
 	"cosmossdk.io/store/v2/db"
 	"cosmossdk.io/store/v2/internal"
+	"cosmossdk.io/store/v2/migration"
 	"cosmossdk.io/store/v2/pruning"
+	"cosmossdk.io/store/v2/snapshots"
 	"cosmossdk.io/store/v2/storage"
-	"cosmossdk.io/store/v2/storage/pebbledb"
-	"cosmossdk.io/store/v2/storage/sqlite"
+	_ "cosmossdk.io/store/v2/storage/pebbledb"
+	_ "cosmossdk.io/store/v2/storage/rocksdb"
+	_ "cosmossdk.io/store/v2/storage/sqlite"
 )
 
 type (
@@ -30,15 +35,37 @@ const (
 	SSTypeRocks  SSType = 2
 	SCTypeIavl   SCType = 0
 	SCTypeIavlV2 SCType = 1
+	SCTypeSMT    SCType = 2
 )
 
+// deleteBatchSize is the maximum number of keys deleted in a single batch when
+// removing the data of a migration.
+const deleteBatchSize = 10_000
+
+// ssBackends maps the SS types to the registered storage backends and to the
+// directory of their data.
+var ssBackends = map[SSType]struct {
+	dbType db.DBType
+	dir    string
+}{
+	SSTypeSQLite: {db.DBTypeSQLite, "sqlite"},
+	SSTypePebble: {db.DBTypePebbleDB, "pebble"},
+	SSTypeRocks:  {db.DBTypeRocksDB, "rocksdb"},
+}
+
 // app.toml config options
 type Options struct {
-	SSType          SSType               `mapstructure:"ss-type" toml:"ss-type" comment:"State storage database type. Currently we support: 0 for SQLite, 1 for Pebble"`
-	SCType          SCType               `mapstructure:"sc-type" toml:"sc-type" comment:"State commitment database type. Currently we support:0 for iavl, 1 for iavl v2"`
-	SSPruningOption *store.PruningOption `mapstructure:"ss-pruning-option" toml:"ss-pruning-option" comment:"Pruning options for state storage"`
-	SCPruningOption *store.PruningOption `mapstructure:"sc-pruning-option" toml:"sc-pruning-option" comment:"Pruning options for state commitment"`
-	IavlConfig      *iavl.Config         `mapstructure:"iavl-config" toml:"iavl-config"`
+	SSType                SSType                          `mapstructure:"ss-type" toml:"ss-type" comment:"State storage database type. Currently we support: 0 for SQLite, 1 for Pebble, 2 for RocksDB (requires the rocksdb build tag)"`
+	SCType                SCType                          `mapstructure:"sc-type" toml:"sc-type" comment:"State commitment database type. Currently we support: 0 for iavl, 1 for iavl v2, 2 for sparse merkle tree"`
+	SSPruningOption       *store.PruningOption            `mapstructure:"ss-pruning-option" toml:"ss-pruning-option" comment:"Pruning options for state storage"`
+	SSStorePruningOptions map[string]*store.PruningOption `mapstructure:"ss-store-pruning-options" toml:"ss-store-pruning-options" comment:"Pruning options for state storage per store key, overriding ss-pruning-option for the listed store keys. An interval of 0 disables the pruning of a store key. It is not supported by RocksDB"`
+	SSAsyncBuffer         int                             `mapstructure:"ss-async-buffer" toml:"ss-async-buffer" comment:"Number of committed versions which may be pending in the state storage when it is written asynchronously to the state commitment. 0 writes the state storage synchronously. It must not exceed the keep-recent of the state storage pruning options"`
+	SCPruningOption       *store.PruningOption            `mapstructure:"sc-pruning-option" toml:"sc-pruning-option" comment:"Pruning options for state commitment"`
+	SCStorePruningOptions map[string]*store.PruningOption `mapstructure:"sc-store-pruning-options" toml:"sc-store-pruning-options" comment:"Pruning options for state commitment per store key, overriding sc-pruning-option for the listed store keys. An interval of 0 disables the pruning of a store key"`
+	SCConcurrency         int                             `mapstructure:"sc-commit-concurrency" toml:"sc-commit-concurrency" comment:"Maximum number of state commitment trees written and committed in parallel. 0 or 1 commits them sequentially"`
+	IavlConfig            *iavl.Config                    `mapstructure:"iavl-config" toml:"iavl-config"`
+	SMTConfig             *smt.Config                     `mapstructure:"smt-config" toml:"smt-config"`
+	MigrateFromV1         bool                            `mapstructure:"migrate-from-v1" toml:"migrate-from-v1" comment:"Migrate a store v1 (rootmulti) database to store/v2 in the background. The node keeps running on store v1 until the migrated state catches up and its app hash is verified, the store v1 data is removed at the next start. It requires the iavl SC type"`
 }
 
 type FactoryOptions struct {
@@ -65,7 +92,38 @@ func DefaultStoreOptions() Options {
 			CacheSize:              100_000,
 			SkipFastStorageUpgrade: true,
 		},
+		SMTConfig: smt.DefaultConfig(),
+	}
+}
+
+// validateSSAsyncBuffer returns an error if the versions pending in the state
+// storage could be pruned, i.e. the async buffer exceeds the keep-recent of a
+// state storage pruning option.
+func (o Options) validateSSAsyncBuffer() error {
+	if o.SSAsyncBuffer < 0 {
+		return fmt.Errorf("invalid SS async buffer %d, it must not be negative", o.SSAsyncBuffer)
+	}
+	if o.SSAsyncBuffer == 0 {
+		return nil
+	}
+
+	check := func(name string, opt *store.PruningOption) error {
+		// an interval of 0 disables pruning
+		if opt != nil && opt.Interval > 0 && opt.KeepRecent < uint64(o.SSAsyncBuffer) {
+			return fmt.Errorf("SS async buffer %d exceeds the keep-recent %d of the %s", o.SSAsyncBuffer, opt.KeepRecent, name)
+		}
+		return nil
+	}
+	if err := check("SS pruning option", o.SSPruningOption); err != nil {
+		return err
+	}
+	for storeKey, opt := range o.SSStorePruningOptions {
+		if err := check(fmt.Sprintf("SS pruning option of store %s", storeKey), opt); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 // CreateRootStore is a convenience function to create a root store based on the
@@ -87,29 +145,36 @@ func CreateRootStore(opts *FactoryOptions) (store.RootStore, error) {
 	)
 
 	storeOpts := opts.Options
-	switch storeOpts.SSType {
-	case SSTypeSQLite:
-		dir := fmt.Sprintf("%s/data/ss/sqlite", opts.RootDir)
-		if err = ensureDir(dir); err != nil {
-			return nil, err
+	if err := storeOpts.validateSSAsyncBuffer(); err != nil {
+		return nil, err
+	}
+	backend, ok := ssBackends[storeOpts.SSType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported SS type: %d", storeOpts.SSType)
+	}
+	dir := fmt.Sprintf("%s/data/ss/%s", opts.RootDir, backend.dir)
+
+	// the SC raw db may be a store v1 database to migrate
+	var progress migration.Progress
+	migrating := false
+	if storeOpts.MigrateFromV1 {
+		if storeOpts.SCType != SCTypeIavl {
+			return nil, fmt.Errorf("SC type %d does not support the migration from store v1", storeOpts.SCType)
 		}
-		ssDb, err = sqlite.New(dir)
-	case SSTypePebble:
-		dir := fmt.Sprintf("%s/data/ss/pebble", opts.RootDir)
-		if err = ensureDir(dir); err != nil {
+		if migrating, progress, err = loadMigration(opts.SCRawDB); err != nil {
 			return nil, err
 		}
-		ssDb, err = pebbledb.New(dir)
-	case SSTypeRocks:
-		// TODO: rocksdb requires build tags so is not supported here by default
-		return nil, errors.New("rocksdb not supported")
-	}
-	if err != nil {
-		return nil, err
+		if progress.Phase == migration.PhaseDone {
+			if err := removeV1Data(opts); err != nil {
+				return nil, err
+			}
+		}
 	}
-	ss = storage.NewStorageStore(ssDb, opts.Logger)
 
 	metadata := commitment.NewMetadataStore(opts.SCRawDB)
+	if migrating {
+		metadata = commitment.NewV1MetadataStore(opts.SCRawDB)
+	}
 	latestVersion, err := metadata.GetLatestVersion()
 	if err != nil {
 		return nil, err
@@ -131,6 +196,34 @@ func CreateRootStore(opts *FactoryOptions) (store.RootStore, error) {
 		return nil, err
 	}
 
+	if migrating {
+		for _, key := range opts.StoreKeys {
+			// the store v2 trees are mounted at their store key, which must not
+			// overlap the store v1 keys
+			if key == "s" || strings.HasPrefix(key, "s/") {
+				return nil, fmt.Errorf("store key %s conflicts with the store v1 layout", key)
+			}
+		}
+		if progress.Phase == migration.PhaseRestore {
+			if err := resetMigration(opts, dir); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = ensureDir(dir); err != nil {
+		return nil, err
+	}
+	// rocksdb is only registered when built with the rocksdb build tag
+	ssDb, err = storage.NewDatabase(backend.dbType, dir, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ssDb.(store.StorePruner); !ok && len(storeOpts.SSStorePruningOptions) > 0 {
+		return nil, fmt.Errorf("SS type %d does not support store pruning options", storeOpts.SSType)
+	}
+	ss = storage.NewStorageStore(ssDb, opts.Logger)
+
 	newTreeFn := func(key string) (commitment.Tree, error) {
 		if internal.IsMemoryStoreKey(key) {
 			return mem.New(), nil
@@ -140,6 +233,8 @@ func CreateRootStore(opts *FactoryOptions) (store.RootStore, error) {
 				return iavl.NewIavlTree(db.NewPrefixDB(opts.SCRawDB, []byte(key)), opts.Logger, storeOpts.IavlConfig), nil
 			case SCTypeIavlV2:
 				return nil, errors.New("iavl v2 not supported")
+			case SCTypeSMT:
+				return smt.NewTree(db.NewPrefixDB(opts.SCRawDB, []byte(key)), storeOpts.SMTConfig), nil
 			default:
 				return nil, errors.New("unsupported commitment store type")
 			}
@@ -166,8 +261,151 @@ func CreateRootStore(opts *FactoryOptions) (store.RootStore, error) {
 	if err != nil {
 		return nil, err
 	}
+	sc.SetCommitConcurrency(storeOpts.SCConcurrency)
 
 	pm := pruning.NewManager(sc, ss, storeOpts.SCPruningOption, storeOpts.SSPruningOption)
+	if err := pm.SetStorePruningOptions(storeOpts.SCStorePruningOptions, storeOpts.SSStorePruningOptions); err != nil {
+		return nil, err
+	}
+
+	if migrating {
+		return createMigratingStore(opts, ss, sc, pm)
+	}
+
+	rs, err := New(opts.Logger, ss, sc, pm, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if storeOpts.SSAsyncBuffer > 0 {
+		rs.(*Store).EnableAsyncStorageWrites(storeOpts.SSAsyncBuffer)
+	}
+
+	return rs, nil
+}
+
+// loadMigration returns whether the SC raw db is a store v1 database which is
+// not completely migrated yet, and the progress of its migration.
+func loadMigration(rawDB corestore.KVStoreWithBatch) (bool, migration.Progress, error) {
+	v1Version, err := commitment.NewV1MetadataStore(rawDB).GetLatestVersion()
+	if err != nil {
+		return false, migration.Progress{}, err
+	}
+	if v1Version == 0 {
+		return false, migration.Progress{}, nil
+	}
+	progress, err := migration.LoadProgress(rawDB)
+	if err != nil {
+		return false, migration.Progress{}, err
+	}
+
+	return progress.Phase != migration.PhaseDone, progress, nil
+}
+
+// resetMigration removes the partially migrated SS and SC of a migration which
+// was interrupted while restoring the state snapshot, so that it is restarted.
+func resetMigration(opts *FactoryOptions, ssDir string) error {
+	opts.Logger.Info("resetting the migration interrupted while restoring the state snapshot")
+	if err := os.RemoveAll(ssDir); err != nil {
+		return fmt.Errorf("failed to remove the migrated SS: %w", err)
+	}
+
+	// the trees, the metadata and the migration progress of store/v2
+	prefixes := []string{"c/", "m/"}
+	for _, key := range opts.StoreKeys {
+		if !internal.IsMemoryStoreKey(key) {
+			prefixes = append(prefixes, key)
+		}
+	}
+	for _, prefix := range prefixes {
+		if err := deletePrefix(db.NewPrefixDB(opts.SCRawDB, []byte(prefix))); err != nil {
+			return fmt.Errorf("failed to remove the migrated SC: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// removeV1Data removes the store v1 trees and metadata, which are not used
+// anymore once the migration is completed, i.e. the migrated store is verified
+// and the node runs on it.
+func removeV1Data(opts *FactoryOptions) error {
+	opts.Logger.Info("removing the store v1 data of the completed migration")
+	if err := deletePrefix(db.NewPrefixDB(opts.SCRawDB, commitment.V1Prefix())); err != nil {
+		return fmt.Errorf("failed to remove the store v1 data: %w", err)
+	}
+
+	return nil
+}
+
+// deletePrefix deletes all the keys of the prefix db, in batches of at most
+// deleteBatchSize keys so that large stores are not loaded in memory.
+func deletePrefix(kv *db.PrefixDB) error {
+	for {
+		n, err := deleteKeys(kv)
+		if err != nil {
+			return err
+		}
+		if n < deleteBatchSize {
+			return nil
+		}
+	}
+}
+
+// deleteKeys deletes the first deleteBatchSize keys of the prefix db and returns
+// the number of deleted keys.
+func deleteKeys(kv *db.PrefixDB) (n int, err error) {
+	iter, err := kv.Iterator(nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	var keys [][]byte
+	for ; iter.Valid() && len(keys) < deleteBatchSize; iter.Next() {
+		keys = append(keys, iter.Key())
+	}
+	// the iterator must be closed before writing the batch
+	if err := iter.Close(); err != nil {
+		return 0, err
+	}
+
+	batch := kv.NewBatch()
+	defer func() {
+		cErr := batch.Close()
+		if err == nil {
+			err = cErr
+		}
+	}()
+	for _, key := range keys {
+		if err := batch.Delete(key); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(keys), batch.Write()
+}
+
+// createMigratingStore creates a root store which keeps running on the store v1
+// database while it is migrated to the given SS and SC.
+func createMigratingStore(opts *FactoryOptions, ss *storage.StorageStore, sc *commitment.CommitStore, pm *pruning.Manager) (store.RootStore, error) {
+	trees := make(map[string]commitment.Tree, len(opts.StoreKeys))
+	for _, key := range opts.StoreKeys {
+		if internal.IsMemoryStoreKey(key) {
+			trees[key] = mem.New()
+			continue
+		}
+		trees[key] = iavl.NewIavlTree(db.NewPrefixDB(opts.SCRawDB, commitment.V1StorePrefix(key)), opts.Logger, opts.Options.IavlConfig)
+	}
+	v1SC, err := commitment.NewV1CommitStore(trees, opts.SCRawDB, opts.Logger)
+	if err != nil {
+		return nil, err
+	}
+	v1SC.SetCommitConcurrency(opts.Options.SCConcurrency)
+
+	snapshotsStore, err := snapshots.NewStore(fmt.Sprintf("%s/data/migration", opts.RootDir))
+	if err != nil {
+		return nil, err
+	}
+	sm := snapshots.NewManager(snapshotsStore, snapshots.SnapshotOptions{}, v1SC, nil, nil, opts.Logger)
+	mm := migration.NewManager(opts.SCRawDB, sm, ss, sc, opts.Logger)
 
-	return New(opts.Logger, ss, sc, pm, nil, nil)
+	return New(opts.Logger, ss, v1SC, pm, mm, nil)
 }
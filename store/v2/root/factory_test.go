@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	coretesting "cosmossdk.io/core/testing"
+	"cosmossdk.io/store/v2"
 	"cosmossdk.io/store/v2/db"
 )
 
@@ -22,6 +23,31 @@ func TestFactory(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, f)
 
+	// the pending versions must not be pruned from the SS
+	fop.Options.SSAsyncBuffer = 4
+	fop.SCRawDB = db.NewMemDB()
+	f, err = CreateRootStore(&fop)
+	require.ErrorContains(t, err, "exceeds the keep-recent 2 of the SS pruning option")
+	require.Nil(t, f)
+
+	fop.Options.SSAsyncBuffer = 2
+	f, err = CreateRootStore(&fop)
+	require.NoError(t, err)
+	require.Equal(t, 2, f.(*Store).ssAsyncBufferSize)
+
+	fop.Options.SCStorePruningOptions = map[string]*store.PruningOption{storeKeys[0]: store.NewPruningOptionWithCustom(10, 10)}
+	fop.Options.SSStorePruningOptions = map[string]*store.PruningOption{storeKeys[0]: store.NewPruningOptionWithCustom(0, 0)}
+	fop.SCRawDB = db.NewMemDB()
+	f, err = CreateRootStore(&fop)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	fop.Options.SCType = SCTypeSMT
+	fop.SCRawDB = db.NewMemDB()
+	f, err = CreateRootStore(&fop)
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
 	fop.Options.SCType = SCTypeIavlV2
 	f, err = CreateRootStore(&fop)
 	require.Error(t, err)
@@ -1,10 +1,12 @@
 package root
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
 	corestore "cosmossdk.io/core/store"
@@ -161,3 +163,142 @@ func (s *MigrateStoreTestSuite) TestMigrateState() {
 	s.Require().NoError(err)
 	s.Require().Equal(latestVersion+10, version)
 }
+
+func (s *MigrateStoreTestSuite) TestCompleteMigration() {
+	err := s.rootStore.LoadLatestVersion()
+	s.Require().NoError(err)
+	latestVersion, err := s.rootStore.GetLatestVersion()
+	s.Require().NoError(err)
+
+	rs := s.rootStore.(*Store)
+	_, ok := rs.MigrationProgress()
+	s.Require().True(ok)
+
+	// complete the migration without committing new versions
+	s.Require().NoError(rs.CompleteMigration())
+	_, ok = rs.MigrationProgress()
+	s.Require().False(ok)
+	s.Require().Error(rs.CompleteMigration())
+
+	ver, err := s.rootStore.GetStateStorage().GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(latestVersion, ver)
+
+	res, err := s.rootStore.Query([]byte(storeKeys[0]), latestVersion, []byte(fmt.Sprintf("key-%d-0", latestVersion)), true)
+	s.Require().NoError(err)
+	s.Require().Equal([]byte(fmt.Sprintf("value-%d-0", latestVersion)), res.Value)
+
+	// commit against the migrated store
+	cs := corestore.NewChangeset()
+	cs.Add([]byte(storeKeys[0]), []byte("key"), []byte("value"), false)
+	_, err = s.rootStore.Commit(cs)
+	s.Require().NoError(err)
+	ver, err = s.rootStore.GetStateStorage().GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(latestVersion+1, ver)
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	nopLog := coretesting.NewNopLogger()
+	rawDB := dbm.NewMemDB()
+
+	// commit against a store v1 database
+	trees := make(map[string]commitment.Tree)
+	for _, storeKey := range storeKeys {
+		trees[storeKey] = iavl.NewIavlTree(dbm.NewPrefixDB(rawDB, commitment.V1StorePrefix(storeKey)), nopLog, iavl.DefaultConfig())
+	}
+	v1SC, err := commitment.NewV1CommitStore(trees, rawDB, nopLog)
+	require.NoError(t, err)
+	toVersion := uint64(20)
+	for version := uint64(1); version <= toVersion; version++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range storeKeys {
+			cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key-%d", version)), []byte(fmt.Sprintf("value-%d", version)), false)
+		}
+		require.NoError(t, v1SC.WriteChangeset(cs))
+		_, err = v1SC.Commit(version)
+		require.NoError(t, err)
+	}
+
+	fop := FactoryOptions{
+		Logger:  nopLog,
+		RootDir: t.TempDir(),
+		Options: DefaultStoreOptions(),
+		SCRawDB: rawDB,
+	}
+	fop.Options.MigrateFromV1 = true
+	rs, err := CreateRootStore(&fop)
+	require.NoError(t, err)
+	require.ElementsMatch(t, storeKeys, fop.StoreKeys)
+	require.NoError(t, rs.LoadLatestVersion())
+	latestVersion, err := rs.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, toVersion, latestVersion)
+
+	// keep committing against the store v1 database until the migration is done
+	for rs.(*Store).isMigrating {
+		cs := corestore.NewChangeset()
+		cs.Add([]byte(storeKeys[0]), []byte("key"), []byte(fmt.Sprintf("value-%d", latestVersion+1)), false)
+		_, err = rs.Commit(cs)
+		require.NoError(t, err)
+		latestVersion++
+		require.Less(t, latestVersion, 10*toVersion)
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, rs.(*Store).migrationErr)
+	progress, err := migration.LoadProgress(rawDB)
+	require.NoError(t, err)
+	require.Equal(t, migration.PhaseDone, progress.Phase)
+
+	// the store v2 database is loaded once the migration is done
+	rs, err = CreateRootStore(&fop)
+	require.NoError(t, err)
+	require.NoError(t, rs.LoadLatestVersion())
+	version, err := rs.GetLatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, latestVersion, version)
+	require.Nil(t, rs.(*Store).migrationManager)
+	// the store v1 data is removed
+	iter, err := dbm.NewPrefixDB(rawDB, commitment.V1Prefix()).Iterator(nil, nil)
+	require.NoError(t, err)
+	require.False(t, iter.Valid())
+	require.NoError(t, iter.Close())
+	for version := uint64(1); version <= toVersion; version++ {
+		res, err := rs.Query([]byte(storeKeys[1]), latestVersion, []byte(fmt.Sprintf("key-%d", version)), true)
+		require.NoError(t, err)
+		require.Equal(t, []byte(fmt.Sprintf("value-%d", version)), res.Value)
+	}
+	res, err := rs.Query([]byte(storeKeys[0]), latestVersion, []byte("key"), false)
+	require.NoError(t, err)
+	require.Equal(t, []byte(fmt.Sprintf("value-%d", latestVersion)), res.Value)
+}
+
+func (s *MigrateStoreTestSuite) TestMigrationError() {
+	err := s.rootStore.LoadLatestVersion()
+	s.Require().NoError(err)
+	latestVersion, err := s.rootStore.GetLatestVersion()
+	s.Require().NoError(err)
+
+	// the error of the migration process is returned by the next commit
+	rs := s.rootStore.(*Store)
+	s.Require().Eventually(func() bool {
+		return rs.migrationManager.GetMigratedVersion() == latestVersion
+	}, 10*time.Second, 10*time.Millisecond)
+	rs.chMigrationErr <- errors.New("migration failure")
+	cs := corestore.NewChangeset()
+	cs.Add([]byte(storeKeys[0]), []byte("key"), []byte("value"), false)
+	_, err = s.rootStore.Commit(cs)
+	s.Require().ErrorContains(err, "migration failure")
+	_, ok := rs.MigrationProgress()
+	s.Require().False(ok)
+
+	// the commit is retried against the original store
+	_, err = s.rootStore.Commit(cs)
+	s.Require().NoError(err)
+	version, err := s.rootStore.GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(latestVersion+1, version)
+	res, err := s.rootStore.Query([]byte(storeKeys[0]), version, []byte("key"), true)
+	s.Require().NoError(err)
+	s.Require().Equal([]byte("value"), res.Value)
+}
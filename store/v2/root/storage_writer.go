@@ -0,0 +1,150 @@
+package root
+
+import (
+	"fmt"
+	"sync"
+
+	corelog "cosmossdk.io/core/log"
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2"
+)
+
+// versionedChangeset is a changeset committed at a version.
+type versionedChangeset struct {
+	version   uint64
+	changeset *corestore.Changeset
+}
+
+// storageWriter applies the committed changesets to the SS backend on a
+// background goroutine. At most bufferSize changesets are pending, committing
+// more blocks until the SS backend has caught up.
+type storageWriter struct {
+	logger       corelog.Logger
+	stateStorage store.VersionedDatabase
+	// replayable is used to release the changesets retained by the SC backend
+	// once they are applied, it can be nil.
+	replayable store.ReplayableCommitter
+	// pruneSS prunes the SS backend once a version is applied, so that it is
+	// never pruned past the versions which are still pending. It can be nil.
+	pruneSS func(version uint64) error
+
+	chChangeset chan versionedChangeset
+	done        chan struct{}
+
+	mtx  sync.Mutex
+	cond *sync.Cond
+	// version is the latest version applied to the SS backend
+	version uint64
+	// enqueued is the latest version scheduled to be applied
+	enqueued uint64
+	// err is the first error returned by the SS backend, once set no further
+	// changeset is applied
+	err error
+}
+
+func newStorageWriter(
+	logger corelog.Logger,
+	ss store.VersionedDatabase,
+	replayable store.ReplayableCommitter,
+	pruneSS func(version uint64) error,
+	version uint64,
+	bufferSize int,
+) *storageWriter {
+	w := &storageWriter{
+		logger:       logger,
+		stateStorage: ss,
+		replayable:   replayable,
+		pruneSS:      pruneSS,
+		chChangeset:  make(chan versionedChangeset, bufferSize),
+		done:         make(chan struct{}),
+		version:      version,
+		enqueued:     version,
+	}
+	w.cond = sync.NewCond(&w.mtx)
+
+	go w.run()
+
+	return w
+}
+
+func (w *storageWriter) run() {
+	defer close(w.done)
+
+	for vc := range w.chChangeset {
+		// keep draining the channel after a failure so that the committer is
+		// never blocked, the missing versions are replayed on restart
+		if w.getErr() != nil {
+			continue
+		}
+
+		err := w.stateStorage.ApplyChangeset(vc.version, vc.changeset)
+		if err != nil {
+			w.logger.Error("failed to apply changeset to SS", "version", vc.version, "err", err)
+			err = fmt.Errorf("failed to commit SS at version %d: %w", vc.version, err)
+		} else {
+			if w.replayable != nil {
+				if rErr := w.replayable.ReleaseChangesets(vc.version); rErr != nil {
+					w.logger.Error("failed to release retained changesets", "version", vc.version, "err", rErr)
+				}
+			}
+			if w.pruneSS != nil {
+				if pErr := w.pruneSS(vc.version); pErr != nil {
+					w.logger.Error("failed to prune SS", "version", vc.version, "err", pErr)
+				}
+			}
+		}
+
+		w.mtx.Lock()
+		if err != nil {
+			w.err = err
+		} else {
+			w.version = vc.version
+		}
+		w.cond.Broadcast()
+		w.mtx.Unlock()
+	}
+}
+
+func (w *storageWriter) getErr() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.err
+}
+
+// enqueue schedules the changeset to be applied to the SS backend at the given
+// version. It blocks if the buffer of pending changesets is full.
+func (w *storageWriter) enqueue(version uint64, cs *corestore.Changeset) error {
+	w.mtx.Lock()
+	if w.err != nil {
+		w.mtx.Unlock()
+		return w.err
+	}
+	w.enqueued = version
+	w.mtx.Unlock()
+
+	w.chChangeset <- versionedChangeset{version: version, changeset: cs}
+	return nil
+}
+
+// wait blocks until the SS backend has applied the given version or an error
+// occurred while applying a changeset. A version which has not been enqueued
+// is only waited for up to the latest enqueued version.
+func (w *storageWriter) wait(version uint64) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	version = min(version, w.enqueued)
+	for w.version < version && w.err == nil {
+		w.cond.Wait()
+	}
+
+	return w.err
+}
+
+// close waits for the pending changesets to be applied and stops the writer.
+func (w *storageWriter) close() error {
+	close(w.chChangeset)
+	<-w.done
+
+	return w.getErr()
+}
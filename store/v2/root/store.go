@@ -14,6 +14,7 @@ import (
 	corelog "cosmossdk.io/core/log"
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
+	storeerrors "cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/metrics"
 	"cosmossdk.io/store/v2/migration"
 	"cosmossdk.io/store/v2/proof"
@@ -23,6 +24,7 @@ import (
 var (
 	_ store.RootStore        = (*Store)(nil)
 	_ store.UpgradeableStore = (*Store)(nil)
+	_ store.BatchQuerier     = (*Store)(nil)
 )
 
 // Store defines the SDK's default RootStore implementation. It contains a single
@@ -62,6 +64,21 @@ type Store struct {
 	chDone chan struct{}
 	// isMigrating reflects whether the store is currently migrating
 	isMigrating bool
+	// migrationMtx guards the switch to the migrated backends against the
+	// concurrent queries
+	migrationMtx sync.RWMutex
+	// migrationErr reflects the reason the migration was aborted, if any, in
+	// which case the store keeps running on the original SC backend
+	migrationErr error
+	// chMigrationErr receives the error of the migration process, if any
+	chMigrationErr chan error
+
+	// ssAsyncBufferSize reflects the number of committed versions which may be
+	// pending in the SS backend, 0 means the SS backend is written synchronously
+	ssAsyncBufferSize int
+	// storageWriter applies the committed changesets to the SS backend in the
+	// background, it is only set when SS writes are asynchronous
+	storageWriter *storageWriter
 }
 
 // New creates a new root Store instance.
@@ -90,6 +107,10 @@ func New(
 // Close closes the store and resets all internal fields. Note, Close() is NOT
 // idempotent and should only be called once.
 func (s *Store) Close() (err error) {
+	if s.storageWriter != nil {
+		err = errors.Join(err, s.storageWriter.close())
+		s.storageWriter = nil
+	}
 	err = errors.Join(err, s.stateStorage.Close())
 	err = errors.Join(err, s.stateCommitment.Close())
 
@@ -105,6 +126,32 @@ func (s *Store) SetMetrics(m metrics.Metrics) {
 	s.telemetry = m
 }
 
+// EnableAsyncStorageWrites makes Commit write the changesets to the SS backend
+// on a background goroutine, while the SC backend is committed synchronously to
+// produce the app hash. At most bufferSize committed versions may be pending in
+// the SS backend before Commit blocks. Reads through Query, StateAt and
+// StateLatest wait for the SS backend to catch up to the read version.
+//
+// If the SC backend implements store.ReplayableCommitter, the changesets are
+// retained by the SC backend until they are applied, and the versions missing
+// in the SS backend are replayed when a version is loaded.
+//
+// NOTE: It must be called before loading a version.
+func (s *Store) EnableAsyncStorageWrites(bufferSize int) {
+	s.ssAsyncBufferSize = bufferSize
+}
+
+// WaitForStorage blocks until the SS backend has caught up to the given version
+// when the SS writes are asynchronous. A version greater than the latest
+// committed version is only waited for up to the latest committed version.
+func (s *Store) WaitForStorage(version uint64) error {
+	if s.storageWriter == nil {
+		return nil
+	}
+
+	return s.storageWriter.wait(version)
+}
+
 func (s *Store) SetInitialVersion(v uint64) error {
 	s.initialVersion = v
 
@@ -116,6 +163,9 @@ func (s *Store) StateLatest() (uint64, corestore.ReaderMap, error) {
 	if err != nil {
 		return 0, nil, err
 	}
+	if err := s.WaitForStorage(v); err != nil {
+		return 0, nil, err
+	}
 
 	return v, NewReaderMap(v, s), nil
 }
@@ -125,9 +175,15 @@ func (s *Store) StateAt(v uint64) (corestore.ReaderMap, error) {
 	// we should add a VersionExists() method to the VersionedDatabase interface.
 	//
 	// Ref: https://github.com/cosmos/cosmos-sdk/issues/19091
-	if cInfo, err := s.stateCommitment.GetCommitInfo(v); err != nil || cInfo == nil {
+	s.migrationMtx.RLock()
+	cInfo, err := s.stateCommitment.GetCommitInfo(v)
+	s.migrationMtx.RUnlock()
+	if err != nil || cInfo == nil {
 		return nil, fmt.Errorf("failed to get commit info for version %d: %w", v, err)
 	}
+	if err := s.WaitForStorage(v); err != nil {
+		return nil, err
+	}
 
 	return NewReaderMap(v, s), nil
 }
@@ -176,6 +232,10 @@ func (s *Store) Query(storeKey []byte, version uint64, key []byte, prove bool) (
 		defer s.telemetry.MeasureSince(now, "root_store", "query")
 	}
 
+	// the backends must not be switched while querying
+	s.migrationMtx.RLock()
+	defer s.migrationMtx.RUnlock()
+
 	var val []byte
 	var err error
 	if s.isMigrating { // if we're migrating, we need to query the SC backend
@@ -184,6 +244,9 @@ func (s *Store) Query(storeKey []byte, version uint64, key []byte, prove bool) (
 			return store.QueryResult{}, fmt.Errorf("failed to query SC store: %w", err)
 		}
 	} else {
+		if err := s.WaitForStorage(version); err != nil {
+			return store.QueryResult{}, err
+		}
 		val, err = s.stateStorage.Get(storeKey, version, key)
 		if err != nil {
 			return store.QueryResult{}, fmt.Errorf("failed to query SS store: %w", err)
@@ -217,6 +280,88 @@ func (s *Store) Query(storeKey []byte, version uint64, key []byte, prove bool) (
 	return result, nil
 }
 
+// QueryBatch queries the given keys of a store, see store.BatchQuerier.
+func (s *Store) QueryBatch(storeKey []byte, version uint64, keys [][]byte, prove bool) (store.BatchQueryResult, error) {
+	result := store.BatchQueryResult{Version: version}
+	for _, key := range keys {
+		res, err := s.Query(storeKey, version, key, false)
+		if err != nil {
+			return store.BatchQueryResult{}, err
+		}
+		if res.Value != nil {
+			result.Pairs = append(result.Pairs, proof.KVPair{Key: key, Value: res.Value})
+		}
+	}
+
+	if prove {
+		s.migrationMtx.RLock()
+		defer s.migrationMtx.RUnlock()
+
+		prover, ok := s.stateCommitment.(store.BatchProver)
+		if !ok {
+			return store.BatchQueryResult{}, errors.New("SC store does not support batch proofs")
+		}
+		var err error
+		result.ProofOps, err = prover.GetBatchProof(storeKey, version, keys)
+		if err != nil {
+			return store.BatchQueryResult{}, fmt.Errorf("failed to get SC store batch proof: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// QueryRange queries the key/value pairs of the range [start, end) of a store,
+// see store.BatchQuerier. The pairs are read from the SC backend along with the
+// proof when prove is true or while migrating, from the SS backend otherwise.
+func (s *Store) QueryRange(storeKey []byte, version uint64, start, end []byte, limit int, prove bool) (store.BatchQueryResult, error) {
+	if s.telemetry != nil {
+		now := time.Now()
+		defer s.telemetry.MeasureSince(now, "root_store", "query_range")
+	}
+
+	s.migrationMtx.RLock()
+	defer s.migrationMtx.RUnlock()
+
+	if prove || s.isMigrating {
+		prover, ok := s.stateCommitment.(store.BatchProver)
+		if !ok {
+			return store.BatchQueryResult{}, errors.New("SC store does not support range proofs")
+		}
+		pairs, proofOps, err := prover.GetRangeProof(storeKey, version, start, end, limit)
+		if err != nil {
+			return store.BatchQueryResult{}, fmt.Errorf("failed to get SC store range proof: %w", err)
+		}
+		result := store.BatchQueryResult{Pairs: pairs, Version: version}
+		if prove {
+			result.ProofOps = proofOps
+		}
+		return result, nil
+	}
+
+	if err := s.WaitForStorage(version); err != nil {
+		return store.BatchQueryResult{}, err
+	}
+	iter, err := s.stateStorage.Iterator(storeKey, version, start, end)
+	if err != nil {
+		return store.BatchQueryResult{}, fmt.Errorf("failed to query SS store: %w", err)
+	}
+	defer iter.Close()
+
+	result := store.BatchQueryResult{Version: version}
+	for ; iter.Valid(); iter.Next() {
+		if limit > 0 && len(result.Pairs) == limit {
+			return store.BatchQueryResult{}, fmt.Errorf("%w: the range contains more than %d pairs", storeerrors.ErrTooManyPairs, limit)
+		}
+		result.Pairs = append(result.Pairs, proof.KVPair{
+			Key:   bytes.Clone(iter.Key()),
+			Value: bytes.Clone(iter.Value()),
+		})
+	}
+
+	return result, iter.Error()
+}
+
 func (s *Store) LoadLatestVersion() error {
 	if s.telemetry != nil {
 		now := time.Now()
@@ -302,11 +447,61 @@ func (s *Store) loadVersion(v uint64, upgrades *corestore.StoreUpgrades) error {
 	// if we're migrating, we need to start the migration process
 	if s.isMigrating {
 		s.startMigration()
+	} else if s.ssAsyncBufferSize > 0 {
+		if err := s.startStorageWriter(v); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// startStorageWriter replays into the SS backend the versions up to v which are
+// retained by the SC backend, and starts applying the next committed versions
+// in the background.
+func (s *Store) startStorageWriter(v uint64) error {
+	if s.storageWriter != nil {
+		if err := s.storageWriter.close(); err != nil {
+			s.logger.Error("failed to close the SS writer", "err", err)
+		}
+		s.storageWriter = nil
+	}
+
+	ssVersion, err := s.stateStorage.GetLatestVersion()
+	if err != nil {
+		return fmt.Errorf("failed to get SS latest version: %w", err)
+	}
+
+	replayable, _ := s.stateCommitment.(store.ReplayableCommitter)
+	if replayable != nil && ssVersion < v {
+		start := ssVersion + 1
+		if ssVersion == 0 {
+			start = max(start, s.initialVersion)
+		}
+		for version := start; version <= v; version++ {
+			cs, err := replayable.GetRetainedChangeset(version)
+			if err != nil {
+				return fmt.Errorf("failed to get retained changeset for version %d: %w", version, err)
+			}
+			if cs == nil {
+				return fmt.Errorf("failed to replay SS version %d: changeset not retained by the SC store", version)
+			}
+			if err := s.stateStorage.ApplyChangeset(version, cs); err != nil {
+				return fmt.Errorf("failed to replay SS version %d: %w", version, err)
+			}
+			s.logger.Info("replayed SS version from SC", "version", version)
+		}
+		ssVersion = v
+		if err := replayable.ReleaseChangesets(v); err != nil {
+			return fmt.Errorf("failed to release retained changesets: %w", err)
+		}
+	}
+
+	s.storageWriter = newStorageWriter(s.logger, s.stateStorage, replayable, s.pruningManager.PruneSS, ssVersion, s.ssAsyncBufferSize)
+
+	return nil
+}
+
 func (s *Store) SetCommitHeader(h *coreheader.Info) {
 	s.commitHeader = h
 }
@@ -319,6 +514,11 @@ func (s *Store) WorkingHash(cs *corestore.Changeset) ([]byte, error) {
 		defer s.telemetry.MeasureSince(now, "root_store", "working_hash")
 	}
 
+	// do not write the SS backend while it is still applying committed versions
+	if err := s.WaitForStorage(s.lastCommitInfo.GetVersion()); err != nil {
+		return nil, err
+	}
+
 	// write the changeset to the SC and SS backends
 	eg := new(errgroup.Group)
 	eg.Go(func() error {
@@ -373,6 +573,10 @@ func (s *Store) Commit(cs *corestore.Changeset) ([]byte, error) {
 		s.logger.Error("failed to signal commit to pruning manager", "err", err)
 	}
 
+	if s.storageWriter != nil && !s.isMigrating {
+		return s.commitAsync(version, cs)
+	}
+
 	eg := new(errgroup.Group)
 
 	// if we're migrating, we don't want to commit to the state storage to avoid
@@ -413,6 +617,39 @@ func (s *Store) Commit(cs *corestore.Changeset) ([]byte, error) {
 	return s.lastCommitInfo.Hash(), nil
 }
 
+// commitAsync commits the SC backend and schedules the changeset to be applied
+// to the SS backend in the background. It blocks while the SS backend has too
+// many pending versions.
+func (s *Store) commitAsync(version uint64, cs *corestore.Changeset) ([]byte, error) {
+	// retain the changeset before committing, so that it can be replayed into
+	// the SS backend if the process stops before it is applied
+	if replayable, ok := s.stateCommitment.(store.ReplayableCommitter); ok {
+		if err := replayable.RetainChangeset(version, cs); err != nil {
+			return nil, fmt.Errorf("failed to retain changeset: %w", err)
+		}
+	}
+
+	if err := s.commitSC(); err != nil {
+		return nil, fmt.Errorf("failed to commit SC: %w", err)
+	}
+
+	if err := s.storageWriter.enqueue(version, cs); err != nil {
+		return nil, err
+	}
+
+	// signal to the pruning manager that the commit is done, the SS is pruned by
+	// the storage writer once the version is applied
+	if err := s.pruningManager.SignalSCCommitDone(version); err != nil {
+		s.logger.Error("failed to signal commit done to pruning manager", "err", err)
+	}
+
+	if s.commitHeader != nil {
+		s.lastCommitInfo.Timestamp = s.commitHeader.Time
+	}
+
+	return s.lastCommitInfo.Hash(), nil
+}
+
 // startMigration starts a migration process to migrate the RootStore/v1 to the
 // SS and SC backends of store/v2 and initializes the channels.
 // It runs in a separate goroutine and replaces the current RootStore with the
@@ -425,6 +662,7 @@ func (s *Store) startMigration() {
 	s.chChangeset = make(chan *migration.VersionedChangeset, 1)
 	// it is used to signal the migration manager that the migration is done
 	s.chDone = make(chan struct{})
+	s.chMigrationErr = make(chan error, 1)
 
 	mtx := sync.Mutex{}
 	mtx.Lock()
@@ -434,6 +672,7 @@ func (s *Store) startMigration() {
 		mtx.Unlock()
 		if err := s.migrationManager.Start(version, s.chChangeset, s.chDone); err != nil {
 			s.logger.Error("failed to start migration", "err", err)
+			s.chMigrationErr <- err
 		}
 	}()
 
@@ -442,33 +681,125 @@ func (s *Store) startMigration() {
 	defer mtx.Unlock()
 }
 
+// switchMigration closes the channels of the migration manager and verifies the
+// app hash of the migrated SC backend at the last committed version. If it
+// matches, the queries are atomically switched to the migrated backends,
+// otherwise the migration is aborted and the store keeps running on the original
+// SC backend.
+func (s *Store) switchMigration() error {
+	version := s.lastCommitInfo.Version
+	close(s.chDone)
+	close(s.chChangeset)
+
+	if err := s.migrationManager.VerifyAppHash(version, s.lastCommitInfo.Hash()); err != nil {
+		s.logger.Error("migration aborted, keep running on the original store", "version", version, "err", err)
+		s.migrationErr = err
+		return nil
+	}
+
+	s.migrationMtx.Lock()
+	defer s.migrationMtx.Unlock()
+
+	s.isMigrating = false
+	// close the old state commitment and replace it with the new one
+	if err := s.stateCommitment.Close(); err != nil {
+		return fmt.Errorf("failed to close the old SC store: %w", err)
+	}
+	newStateCommitment := s.migrationManager.GetStateCommitment()
+	if newStateCommitment != nil {
+		s.stateCommitment = newStateCommitment
+	}
+	if err := s.migrationManager.Close(); err != nil {
+		return fmt.Errorf("failed to close migration manager: %w", err)
+	}
+	s.logger.Info("migration completed", "version", version)
+
+	return nil
+}
+
+// abortMigration records the error of the migration process, so that the store
+// keeps running on the original SC backend, and returns it. The changeset is not
+// written, hence the commit can be retried.
+func (s *Store) abortMigration(err error) error {
+	s.logger.Error("migration aborted, keep running on the original store", "version", s.lastCommitInfo.Version, "err", err)
+	close(s.chDone)
+	close(s.chChangeset)
+	s.migrationMtx.Lock()
+	s.migrationErr = err
+	s.migrationMtx.Unlock()
+
+	return fmt.Errorf("migration aborted: %w", err)
+}
+
+// MigrationProgress returns the progress of the migration, and false if the
+// store is not migrating.
+func (s *Store) MigrationProgress() (migration.Progress, bool) {
+	s.migrationMtx.RLock()
+	defer s.migrationMtx.RUnlock()
+
+	if !s.isMigrating || s.migrationErr != nil {
+		return migration.Progress{}, false
+	}
+
+	return s.migrationManager.Progress(), true
+}
+
+// CompleteMigration waits for the migration to catch up with the last committed
+// version and switches to the migrated backends. It is used to complete the
+// migration while no version is committed, e.g. offline.
+//
+// NOTE: It must be called after loading the store.
+func (s *Store) CompleteMigration() error {
+	if !s.isMigrating || s.lastCommitInfo == nil {
+		return errors.New("the store is not migrating")
+	}
+	if s.migrationErr != nil {
+		return s.migrationErr
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for s.migrationManager.GetMigratedVersion() != s.lastCommitInfo.Version {
+		select {
+		case err := <-s.chMigrationErr:
+			return s.abortMigration(err)
+		case <-ticker.C:
+		}
+	}
+
+	if err := s.switchMigration(); err != nil {
+		return err
+	}
+
+	return s.migrationErr
+}
+
 // writeSC accepts a Changeset and writes that as a batch to the underlying SC
 // tree, which allows us to retrieve the working hash of the SC tree. Finally,
 // we construct a *CommitInfo and set that as lastCommitInfo. Note, this should
 // only be called once per block!
 // If migration is in progress, the changeset is sent to the migration manager.
 func (s *Store) writeSC(cs *corestore.Changeset) error {
-	if s.isMigrating {
-		// if the migration manager has already migrated to the version, close the
-		// channels and replace the state commitment
+	if s.isMigrating && s.migrationErr == nil {
+		// the changesets are not buffered anymore once the migration failed
+		select {
+		case err := <-s.chMigrationErr:
+			return s.abortMigration(err)
+		default:
+		}
+
+		// if the migration manager has already migrated to the version, switch to
+		// the migrated backends
 		if s.migrationManager.GetMigratedVersion() == s.lastCommitInfo.Version {
-			close(s.chDone)
-			close(s.chChangeset)
-			s.isMigrating = false
-			// close the old state commitment and replace it with the new one
-			if err := s.stateCommitment.Close(); err != nil {
-				return fmt.Errorf("failed to close the old SC store: %w", err)
-			}
-			newStateCommitment := s.migrationManager.GetStateCommitment()
-			if newStateCommitment != nil {
-				s.stateCommitment = newStateCommitment
-			}
-			if err := s.migrationManager.Close(); err != nil {
-				return fmt.Errorf("failed to close migration manager: %w", err)
+			if err := s.switchMigration(); err != nil {
+				return err
 			}
-			s.logger.Info("migration completed", "version", s.lastCommitInfo.Version)
 		} else {
-			s.chChangeset <- &migration.VersionedChangeset{Version: s.lastCommitInfo.Version + 1, Changeset: cs}
+			select {
+			case s.chChangeset <- &migration.VersionedChangeset{Version: s.lastCommitInfo.Version + 1, Changeset: cs}:
+			case err := <-s.chMigrationErr:
+				return s.abortMigration(err)
+			}
 		}
 	}
 
@@ -515,5 +846,10 @@ func (s *Store) commitSC() error {
 }
 
 func (s *Store) Prune(version uint64) error {
+	// the SS is not pruned past the versions which are still pending
+	if err := s.WaitForStorage(version); err != nil {
+		return err
+	}
+
 	return s.pruningManager.Prune(version)
 }
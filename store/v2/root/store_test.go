@@ -3,6 +3,7 @@ package root
 import (
 	"crypto/sha256"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -15,6 +16,7 @@ import (
 	"cosmossdk.io/store/v2/commitment"
 	"cosmossdk.io/store/v2/commitment/iavl"
 	dbm "cosmossdk.io/store/v2/db"
+	storeerrors "cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/proof"
 	"cosmossdk.io/store/v2/pruning"
 	"cosmossdk.io/store/v2/storage"
@@ -231,6 +233,58 @@ func (s *RootStoreTestSuite) TestQueryProof() {
 	s.Require().Equal(expRoots[0], cInfo.Hash())
 }
 
+func (s *RootStoreTestSuite) TestQueryBatchAndRange() {
+	cs := corestore.NewChangeset()
+	cs.Add(testStoreKeyBytes, []byte("key1"), []byte("value1"), false)
+	cs.Add(testStoreKeyBytes, []byte("key2"), []byte("value2"), false)
+	cs.Add(testStoreKeyBytes, []byte("key3"), []byte("value3"), false)
+	cs.Add(testStoreKey2Bytes, []byte("key4"), []byte("value4"), false)
+
+	_, err := s.rootStore.Commit(cs)
+	s.Require().NoError(err)
+	cInfo, err := s.rootStore.GetStateCommitment().GetCommitInfo(1)
+	s.Require().NoError(err)
+
+	querier, ok := s.rootStore.(store.BatchQuerier)
+	s.Require().True(ok)
+
+	// batch query with the absent keys omitted
+	keys := [][]byte{[]byte("key1"), []byte("key3"), []byte("key4")}
+	result, err := querier.QueryBatch(testStoreKeyBytes, 1, keys, false)
+	s.Require().NoError(err)
+	s.Require().Nil(result.ProofOps)
+	pairs := []proof.KVPair{
+		{Key: []byte("key1"), Value: []byte("value1")},
+		{Key: []byte("key3"), Value: []byte("value3")},
+	}
+	s.Require().Equal(pairs, result.Pairs)
+
+	result, err = querier.QueryBatch(testStoreKeyBytes, 1, keys, true)
+	s.Require().NoError(err)
+	s.Require().NoError(proof.VerifyBatchProof(result.ProofOps, cInfo.Hash(), testStoreKeyBytes, result.Pairs, [][]byte{[]byte("key4")}))
+
+	// range query from SS, and from SC with the proof
+	result, err = querier.QueryRange(testStoreKeyBytes, 1, []byte("key2"), nil, 2, false)
+	s.Require().NoError(err)
+	s.Require().Nil(result.ProofOps)
+	pairs = []proof.KVPair{
+		{Key: []byte("key2"), Value: []byte("value2")},
+		{Key: []byte("key3"), Value: []byte("value3")},
+	}
+	s.Require().Equal(pairs, result.Pairs)
+
+	result, err = querier.QueryRange(testStoreKeyBytes, 1, []byte("key2"), nil, 0, true)
+	s.Require().NoError(err)
+	s.Require().Equal(pairs, result.Pairs)
+	s.Require().NoError(proof.VerifyRangeProof(result.ProofOps, cInfo.Hash(), testStoreKeyBytes, []byte("key2"), nil, result.Pairs))
+
+	// a range exceeding the limit should error
+	_, err = querier.QueryRange(testStoreKeyBytes, 1, nil, nil, 2, false)
+	s.Require().ErrorIs(err, storeerrors.ErrTooManyPairs)
+	_, err = querier.QueryRange(testStoreKeyBytes, 1, nil, nil, 2, true)
+	s.Require().ErrorIs(err, storeerrors.ErrTooManyPairs)
+}
+
 func (s *RootStoreTestSuite) TestLoadVersion() {
 	// write and commit a few changesets
 	for v := 1; v <= 5; v++ {
@@ -819,3 +873,257 @@ func (s *RootStoreTestSuite) TestHashStableWithEmptyCommitAndRestart() {
 	s.Require().NoError(err)
 	s.Require().Equal(lastCommitID.Hash, hash)
 }
+
+// failingStorage is a state storage which fails to apply the changesets from a
+// given version.
+type failingStorage struct {
+	store.VersionedDatabase
+
+	failFrom uint64
+}
+
+func (f *failingStorage) ApplyChangeset(version uint64, cs *corestore.Changeset) error {
+	if version >= f.failFrom {
+		return fmt.Errorf("failed to apply version %d", version)
+	}
+
+	return f.VersionedDatabase.ApplyChangeset(version, cs)
+}
+
+func (s *RootStoreTestSuite) TestAsyncStorageWrites() {
+	s.rootStore.(*Store).EnableAsyncStorageWrites(2)
+	s.Require().NoError(s.rootStore.LoadLatestVersion())
+
+	toVersion := uint64(10)
+	for v := uint64(1); v <= toVersion; v++ {
+		cs := corestore.NewChangeset()
+		cs.Add(testStoreKeyBytes, []byte(fmt.Sprintf("key%03d", v)), []byte(fmt.Sprintf("val%03d", v)), false)
+		if v > 1 {
+			cs.Add(testStoreKey2Bytes, []byte(fmt.Sprintf("key%03d", v-1)), nil, true)
+		}
+		cs.Add(testStoreKey2Bytes, []byte(fmt.Sprintf("key%03d", v)), []byte(fmt.Sprintf("val%03d", v)), false)
+
+		cHash, err := s.rootStore.Commit(cs)
+		s.Require().NoError(err)
+		s.Require().NotNil(cHash)
+
+		// queries wait for the SS backend to catch up
+		res, err := s.rootStore.Query(testStoreKeyBytes, v, []byte(fmt.Sprintf("key%03d", v)), false)
+		s.Require().NoError(err)
+		s.Require().Equal([]byte(fmt.Sprintf("val%03d", v)), res.Value)
+	}
+
+	s.Require().NoError(s.rootStore.(*Store).WaitForStorage(toVersion))
+	ssVersion, err := s.rootStore.GetStateStorage().GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(toVersion, ssVersion)
+
+	for v := uint64(1); v <= toVersion; v++ {
+		ro, err := s.rootStore.StateAt(v)
+		s.Require().NoError(err)
+		reader, err := ro.GetReader(testStoreKey2Bytes)
+		s.Require().NoError(err)
+		has, err := reader.Has([]byte(fmt.Sprintf("key%03d", v)))
+		s.Require().NoError(err)
+		s.Require().True(has)
+		if v > 1 {
+			has, err = reader.Has([]byte(fmt.Sprintf("key%03d", v-1)))
+			s.Require().NoError(err)
+			s.Require().False(has)
+		}
+	}
+
+	// the applied changesets are no longer retained by the SC backend
+	cs, err := s.rootStore.GetStateCommitment().(store.ReplayableCommitter).GetRetainedChangeset(toVersion)
+	s.Require().NoError(err)
+	s.Require().Nil(cs)
+}
+
+func (s *RootStoreTestSuite) TestAsyncStorageWritesRecovery() {
+	noopLog := coretesting.NewNopLogger()
+
+	sqliteDB, err := sqlite.New(s.T().TempDir())
+	s.Require().NoError(err)
+	ss := storage.NewStorageStore(sqliteDB, noopLog)
+
+	treeDB := dbm.NewMemDB()
+	metadataDB := dbm.NewMemDB()
+	newCommitStore := func() *commitment.CommitStore {
+		multiTrees := make(map[string]commitment.Tree)
+		for _, storeKey := range testStoreKeys {
+			prefixDB := dbm.NewPrefixDB(treeDB, []byte(storeKey))
+			multiTrees[storeKey] = iavl.NewIavlTree(prefixDB, noopLog, iavl.DefaultConfig())
+		}
+		sc, err := commitment.NewCommitStore(multiTrees, nil, metadataDB, noopLog)
+		s.Require().NoError(err)
+		return sc
+	}
+
+	// the SS backend stops applying changesets from version 4 on, which
+	// simulates a process stopped before the pending versions were applied
+	failingSS := &failingStorage{VersionedDatabase: ss, failFrom: 4}
+	sc := newCommitStore()
+	s.newStoreWithBackendMount(failingSS, sc, pruning.NewManager(sc, ss, nil, nil))
+	s.rootStore.(*Store).EnableAsyncStorageWrites(1)
+	s.Require().NoError(s.rootStore.LoadLatestVersion())
+
+	toVersion := uint64(5)
+	for v := uint64(1); v <= toVersion; v++ {
+		cs := corestore.NewChangeset()
+		cs.Add(testStoreKeyBytes, []byte(fmt.Sprintf("key%03d", v)), []byte(fmt.Sprintf("val%03d", v)), false)
+		_, err := s.rootStore.Commit(cs)
+		if v < toVersion {
+			s.Require().NoError(err)
+			if v == 4 {
+				s.Require().Error(s.rootStore.(*Store).WaitForStorage(v))
+			}
+			continue
+		}
+		// the SS failure is reported by the next commit, after the SC backend
+		// is committed
+		s.Require().Error(err)
+	}
+
+	ssVersion, err := ss.GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(uint64(3), ssVersion)
+	scVersion, err := sc.GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(toVersion, scVersion)
+
+	// "restart", the missing versions are replayed from the SC backend
+	sc = newCommitStore()
+	s.newStoreWithBackendMount(ss, sc, pruning.NewManager(sc, ss, nil, nil))
+	s.rootStore.(*Store).EnableAsyncStorageWrites(1)
+	s.Require().NoError(s.rootStore.LoadLatestVersion())
+
+	ssVersion, err = ss.GetLatestVersion()
+	s.Require().NoError(err)
+	s.Require().Equal(toVersion, ssVersion)
+	for v := uint64(1); v <= toVersion; v++ {
+		val, err := ss.Get(testStoreKeyBytes, toVersion, []byte(fmt.Sprintf("key%03d", v)))
+		s.Require().NoError(err)
+		s.Require().Equal([]byte(fmt.Sprintf("val%03d", v)), val)
+	}
+	cs, err := sc.GetRetainedChangeset(toVersion)
+	s.Require().NoError(err)
+	s.Require().Nil(cs)
+}
+
+// slowStorage is a state storage which applies the changesets slowly and
+// records the latest applied version.
+type slowStorage struct {
+	store.VersionedDatabase
+
+	applied atomic.Uint64
+}
+
+func (ss *slowStorage) ApplyChangeset(version uint64, cs *corestore.Changeset) error {
+	time.Sleep(time.Millisecond)
+	if err := ss.VersionedDatabase.ApplyChangeset(version, cs); err != nil {
+		return err
+	}
+	ss.applied.Store(version)
+	return nil
+}
+
+// checkedPruner is a SS pruner recording the versions which were pruned before
+// the next keepRecent versions were applied.
+type checkedPruner struct {
+	store.Pruner
+
+	ss         *slowStorage
+	keepRecent uint64
+	early      []uint64
+}
+
+func (p *checkedPruner) Prune(version uint64) error {
+	if p.ss.applied.Load() <= version+p.keepRecent {
+		p.early = append(p.early, version)
+	}
+	return p.Pruner.Prune(version)
+}
+
+func (s *RootStoreTestSuite) TestAsyncStorageWritesPruning() {
+	noopLog := coretesting.NewNopLogger()
+
+	sqliteDB, err := sqlite.New(s.T().TempDir())
+	s.Require().NoError(err)
+	storageStore := storage.NewStorageStore(sqliteDB, noopLog)
+	ss := &slowStorage{VersionedDatabase: storageStore}
+
+	multiTrees := make(map[string]commitment.Tree)
+	treeDB := dbm.NewMemDB()
+	for _, storeKey := range testStoreKeys {
+		prefixDB := dbm.NewPrefixDB(treeDB, []byte(storeKey))
+		multiTrees[storeKey] = iavl.NewIavlTree(prefixDB, noopLog, iavl.DefaultConfig())
+	}
+	sc, err := commitment.NewCommitStore(multiTrees, nil, dbm.NewMemDB(), noopLog)
+	s.Require().NoError(err)
+
+	keepRecent := uint64(2)
+	pruner := &checkedPruner{Pruner: storageStore, ss: ss, keepRecent: keepRecent}
+	s.newStoreWithBackendMount(ss, sc, pruning.NewManager(sc, pruner, nil, store.NewPruningOptionWithCustom(keepRecent, 1)))
+	s.rootStore.(*Store).EnableAsyncStorageWrites(int(keepRecent))
+	s.Require().NoError(s.rootStore.LoadLatestVersion())
+
+	toVersion := uint64(20)
+	for v := uint64(1); v <= toVersion; v++ {
+		cs := corestore.NewChangeset()
+		cs.Add(testStoreKeyBytes, []byte(fmt.Sprintf("key%03d", v)), []byte(fmt.Sprintf("val%03d", v)), false)
+		_, err := s.rootStore.Commit(cs)
+		s.Require().NoError(err)
+	}
+	s.Require().NoError(s.rootStore.(*Store).WaitForStorage(toVersion))
+
+	// the SS is only pruned once the pruned versions are applied
+	s.Require().Empty(pruner.early)
+}
+
+func (s *RootStoreTestSuite) TestVerifyStorage() {
+	s.Require().NoError(s.rootStore.LoadLatestVersion())
+
+	toVersion := uint64(5)
+	for v := uint64(1); v <= toVersion; v++ {
+		cs := corestore.NewChangeset()
+		for _, storeKey := range testStoreKeys {
+			cs.Add([]byte(storeKey), []byte(fmt.Sprintf("key%03d", v)), []byte(fmt.Sprintf("val%03d", v)), false)
+		}
+		_, err := s.rootStore.Commit(cs)
+		s.Require().NoError(err)
+	}
+
+	rs := s.rootStore.(*Store)
+	var mismatches []StorageMismatch
+	collect := func(m StorageMismatch) { mismatches = append(mismatches, m) }
+	s.Require().NoError(rs.VerifyStorage(toVersion, false, collect))
+	s.Require().Empty(mismatches)
+
+	// make SS drift from SC at the latest version
+	cs := corestore.NewChangeset()
+	cs.Add(testStoreKeyBytes, []byte("key001"), []byte("wrong"), false)
+	cs.Add(testStoreKey2Bytes, []byte("key002"), nil, true)
+	cs.Add(testStoreKey3Bytes, []byte("extra"), []byte("extra"), false)
+	s.Require().NoError(rs.GetStateStorage().ApplyChangeset(toVersion, cs))
+
+	s.Require().NoError(rs.VerifyStorage(toVersion, false, collect))
+	s.Require().Equal([]StorageMismatch{
+		{StoreKey: testStoreKeyBytes, Key: []byte("key001"), SSValue: []byte("wrong"), SCValue: []byte("val001")},
+		{StoreKey: testStoreKey2Bytes, Key: []byte("key002"), SCValue: []byte("val002")},
+		{StoreKey: testStoreKey3Bytes, Key: []byte("extra"), SSValue: []byte("extra")},
+	}, mismatches)
+
+	// the previous versions are not affected, but cannot be repaired
+	mismatches = nil
+	s.Require().NoError(rs.VerifyStorage(toVersion-1, false, collect))
+	s.Require().Empty(mismatches)
+	s.Require().Error(rs.VerifyStorage(toVersion-1, true, collect))
+
+	// repair SS from SC
+	mismatches = nil
+	s.Require().NoError(rs.VerifyStorage(toVersion, true, collect))
+	s.Require().Len(mismatches, 3)
+	mismatches = nil
+	s.Require().NoError(rs.VerifyStorage(toVersion, false, collect))
+	s.Require().Empty(mismatches)
+}
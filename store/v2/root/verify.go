@@ -0,0 +1,144 @@
+package root
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	corestore "cosmossdk.io/core/store"
+	"cosmossdk.io/store/v2/commitment"
+)
+
+// StorageMismatch describes a key whose value in the SS backend differs from its
+// value in the SC backend at a given version.
+type StorageMismatch struct {
+	StoreKey []byte
+	Key      []byte
+	// SSValue is the value in the SS backend, nil if the key is missing.
+	SSValue []byte
+	// SCValue is the value in the SC backend, nil if the key is missing.
+	SCValue []byte
+}
+
+// exportableCommitter is implemented by the SC backends exposing the trees of
+// their store keys, e.g. commitment.CommitStore.
+type exportableCommitter interface {
+	StoreKeys() []string
+	ExportStore(storeKey string, version uint64) (commitment.Exporter, error)
+}
+
+// VerifyStorage walks the given version of every store key in both the SS and
+// SC backends, and calls onMismatch for every key which is missing from either
+// backend or whose values differ.
+//
+// If repair is true, the SS backend is fixed from the SC backend, the values of
+// the SC backend are written and the keys missing from it are deleted. Since the
+// SS backend can only be written at its latest version, repairing an older
+// version is not supported.
+func (s *Store) VerifyStorage(version uint64, repair bool, onMismatch func(StorageMismatch)) error {
+	sc, ok := s.stateCommitment.(exportableCommitter)
+	if !ok {
+		return errors.New("SC store does not support exporting its trees")
+	}
+	if err := s.WaitForStorage(version); err != nil {
+		return err
+	}
+
+	if repair {
+		ssVersion, err := s.stateStorage.GetLatestVersion()
+		if err != nil {
+			return fmt.Errorf("failed to get SS latest version: %w", err)
+		}
+		if version != ssVersion {
+			return fmt.Errorf("cannot repair version %d, only the SS latest version %d can be repaired", version, ssVersion)
+		}
+	}
+
+	repairs := corestore.NewChangeset()
+	for _, storeKey := range sc.StoreKeys() {
+		pairs, err := s.verifyStoreKey(sc, []byte(storeKey), version, onMismatch)
+		if err != nil {
+			return fmt.Errorf("failed to verify store %s: %w", storeKey, err)
+		}
+		if len(pairs) > 0 {
+			repairs.Changes = append(repairs.Changes, corestore.StateChanges{
+				Actor:        []byte(storeKey),
+				StateChanges: pairs,
+			})
+		}
+	}
+
+	if !repair || repairs.Size() == 0 {
+		return nil
+	}
+	if err := s.stateStorage.ApplyChangeset(version, repairs); err != nil {
+		return fmt.Errorf("failed to repair SS: %w", err)
+	}
+
+	s.logger.Info("repaired SS from SC", "version", version, "keys", repairs.Size())
+
+	return nil
+}
+
+// verifyStoreKey compares a store key in the SS and SC backends, and returns the
+// pairs to write to the SS backend to repair it.
+func (s *Store) verifyStoreKey(
+	sc exportableCommitter,
+	storeKey []byte,
+	version uint64,
+	onMismatch func(StorageMismatch),
+) ([]corestore.KVPair, error) {
+	var pairs []corestore.KVPair
+
+	// every SC leaf must be present in SS with the same value
+	exporter, err := sc.ExportStore(string(storeKey), version)
+	if err != nil {
+		return nil, err
+	}
+	defer exporter.Close()
+	for {
+		item, err := exporter.Next()
+		if errors.Is(err, commitment.ErrorExportDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if item.Height != 0 {
+			continue
+		}
+
+		ssValue, err := s.stateStorage.Get(storeKey, version, item.Key)
+		if err != nil {
+			return nil, err
+		}
+		if ssValue != nil && bytes.Equal(ssValue, item.Value) {
+			continue
+		}
+
+		onMismatch(StorageMismatch{StoreKey: storeKey, Key: item.Key, SSValue: ssValue, SCValue: item.Value})
+		pairs = append(pairs, corestore.KVPair{Key: item.Key, Value: item.Value})
+	}
+
+	// every SS key must be present in SC, the values were compared above
+	itr, err := s.stateStorage.Iterator(storeKey, version, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	for ; itr.Valid(); itr.Next() {
+		scValue, err := s.stateCommitment.Get(storeKey, version, itr.Key())
+		if err != nil {
+			return nil, err
+		}
+		if scValue != nil {
+			continue
+		}
+
+		key := bytes.Clone(itr.Key())
+		onMismatch(StorageMismatch{StoreKey: storeKey, Key: key, SSValue: bytes.Clone(itr.Value())})
+		pairs = append(pairs, corestore.KVPair{Key: key, Remove: true})
+	}
+
+	return pairs, itr.Error()
+}
@@ -0,0 +1,151 @@
+package snapshots
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/klauspost/compress/zstd"
+
+	"cosmossdk.io/errors/v2"
+	"cosmossdk.io/store/v2/snapshots/types"
+)
+
+// IsCompressionSupported returns if the snapshot stream compression is supported.
+func IsCompressionSupported(compression string) bool {
+	switch compression {
+	case "", types.CompressionZlib, types.CompressionZstd, types.CompressionGzip, types.CompressionNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// metadataCompression returns the compression recorded in the snapshot metadata,
+// zlib is recorded as an empty compression to keep the metadata of the snapshots
+// taken before the compression was configurable.
+func metadataCompression(compression string) string {
+	if compression == types.CompressionZlib {
+		return ""
+	}
+	return compression
+}
+
+// snapshotFormats are the formats of the snapshots which can be restored.
+var snapshotFormats = []uint32{
+	types.CurrentFormat, types.ZstdFormat, types.GzipFormat, types.UncompressedFormat, types.DeltaFormat,
+}
+
+// isSnapshotFormat returns if the snapshots of the format can be restored.
+func isSnapshotFormat(format uint32) bool {
+	return slices.Contains(snapshotFormats, format)
+}
+
+// snapshotFormat returns the format of a snapshot compressed with the given
+// compression, or of a delta snapshot if delta is true.
+func snapshotFormat(compression string, delta bool) uint32 {
+	if delta {
+		return types.DeltaFormat
+	}
+	switch compression {
+	case types.CompressionZstd:
+		return types.ZstdFormat
+	case types.CompressionGzip:
+		return types.GzipFormat
+	case types.CompressionNone:
+		return types.UncompressedFormat
+	default:
+		return types.CurrentFormat
+	}
+}
+
+// checkFormatCompression returns an error if the compression recorded in the
+// metadata doesn't match the compression of the snapshot format. The formats
+// which don't define the compression, e.g. DeltaFormat, accept any compression.
+func checkFormatCompression(format uint32, compression string) error {
+	switch format {
+	case types.CurrentFormat, types.ZstdFormat, types.GzipFormat, types.UncompressedFormat:
+		if snapshotFormat(compression, false) != format {
+			return fmt.Errorf("snapshot compression %q does not match the format %d", compression, format)
+		}
+	}
+
+	return nil
+}
+
+// newCompressWriter returns a writer compressing the snapshot stream to w.
+func newCompressWriter(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "", types.CompressionZlib:
+		zWriter, err := zlib.NewWriterLevel(w, snapshotCompressionLevel)
+		if err != nil {
+			return nil, errors.Wrap(err, "zlib failure")
+		}
+		return zWriter, nil
+
+	case types.CompressionZstd:
+		// a single goroutine keeps the output identical across nodes
+		zWriter, err := zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault), zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrap(err, "zstd failure")
+		}
+		return zWriter, nil
+
+	case types.CompressionGzip:
+		zWriter, err := gzip.NewWriterLevel(w, snapshotCompressionLevel)
+		if err != nil {
+			return nil, errors.Wrap(err, "gzip failure")
+		}
+		return zWriter, nil
+
+	case types.CompressionNone:
+		return nopWriteCloser{w}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown snapshot compression %q", compression)
+	}
+}
+
+// newDecompressReader returns a reader decompressing the snapshot stream from r.
+func newDecompressReader(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case "", types.CompressionZlib:
+		zReader, err := zlib.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "zlib failure")
+		}
+		return zReader, nil
+
+	case types.CompressionZstd:
+		zReader, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, errors.Wrap(err, "zstd failure")
+		}
+		return zReader.IOReadCloser(), nil
+
+	case types.CompressionGzip:
+		zReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, errors.Wrap(err, "gzip failure")
+		}
+		return zReader, nil
+
+	case types.CompressionNone:
+		return io.NopCloser(r), nil
+
+	default:
+		return nil, fmt.Errorf("unknown snapshot compression %q", compression)
+	}
+}
+
+// nopWriteCloser is an io.WriteCloser for the uncompressed snapshot streams.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer.
+func (nopWriteCloser) Close() error {
+	return nil
+}
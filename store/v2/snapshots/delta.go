@@ -0,0 +1,360 @@
+package snapshots
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	protoio "github.com/cosmos/gogoproto/io"
+	"github.com/cosmos/gogoproto/proto"
+
+	"cosmossdk.io/store/v2/snapshots/types"
+)
+
+// A delta snapshot is the snapshot of a height written relative to a base
+// snapshot at a lower height. Its stream has the same items as a full snapshot
+// stream, except that every subtree whose nodes were all created at or before
+// the base height is replaced by a SnapshotIAVLReference item.
+//
+// Since the tree nodes are immutable, such a subtree is also part of the base
+// snapshot, and since the nodes are exported in post-order, its items are a
+// contiguous range of the base snapshot items starting at its leftmost leaf.
+// The references of a store follow the order of the base snapshot items, so
+// that a delta snapshot is restored in a single pass over its base snapshot.
+//
+// The trees which do not export the versions of their nodes, e.g. the sparse
+// Merkle tree exporting its leaves at the snapshot version, are written in full.
+
+// subtree is an exported subtree whose parent was not exported yet.
+type subtree struct {
+	// key is the key of the leftmost leaf of an unchanged subtree.
+	key []byte
+	// items is the number of items of an unchanged subtree.
+	items uint64
+	// changed is the number of consecutive subtrees already written that the
+	// entry stands for, it is 0 for an unchanged subtree.
+	changed uint64
+}
+
+// deltaWriter writes a delta snapshot from the items of a full snapshot.
+type deltaWriter struct {
+	protoio.Writer
+
+	baseHeight uint64
+	// subtrees holds the exported subtrees waiting for their parent, the
+	// unchanged ones are only written once a changed node is exported.
+	subtrees []subtree
+	// unchanged is the number of unchanged entries of subtrees.
+	unchanged int
+}
+
+var _ protoio.Writer = (*deltaWriter)(nil)
+
+func newDeltaWriter(w protoio.Writer, baseHeight uint64) *deltaWriter {
+	return &deltaWriter{
+		Writer:     w,
+		baseHeight: baseHeight,
+	}
+}
+
+// WriteMsg implements protoio.Writer.
+func (w *deltaWriter) WriteMsg(msg proto.Message) error {
+	item, ok := msg.(*types.SnapshotItem)
+	if !ok {
+		return fmt.Errorf("unexpected snapshot message %T", msg)
+	}
+	node := item.GetIAVL()
+	if node == nil {
+		// a new store or the extensions start, the pending subtrees are complete
+		if err := w.flush(); err != nil {
+			return err
+		}
+		w.subtrees = w.subtrees[:0]
+		return w.Writer.WriteMsg(item)
+	}
+
+	unchanged := node.Version > 0 && uint64(node.Version) <= w.baseHeight
+	if node.Height == 0 {
+		if unchanged {
+			w.push(subtree{key: node.Key, items: 1})
+			return nil
+		}
+		if err := w.flush(); err != nil {
+			return err
+		}
+		w.push(subtree{changed: 1})
+		return w.Writer.WriteMsg(item)
+	}
+
+	if len(w.subtrees) == 0 {
+		return errors.New("received an inner node without children")
+	}
+	// a node is unchanged only if its children are, but the children of a
+	// changed node can be either
+	if unchanged && w.unchanged >= 2 && w.peek(0).changed == 0 && w.peek(1).changed == 0 {
+		right, left := w.pop(), w.pop()
+		w.push(subtree{key: left.key, items: left.items + right.items + 1})
+		return nil
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.pop()
+	w.pop()
+	w.push(subtree{changed: 1})
+	return w.Writer.WriteMsg(item)
+}
+
+// Flush writes the references to the pending subtrees, it must be called once
+// all the items are written.
+func (w *deltaWriter) Flush() error {
+	return w.flush()
+}
+
+func (w *deltaWriter) peek(i int) subtree {
+	return w.subtrees[len(w.subtrees)-1-i]
+}
+
+func (w *deltaWriter) push(s subtree) {
+	if s.changed == 0 {
+		w.unchanged++
+	} else if n := len(w.subtrees); n > 0 && w.subtrees[n-1].changed > 0 {
+		w.subtrees[n-1].changed += s.changed
+		return
+	}
+	w.subtrees = append(w.subtrees, s)
+}
+
+func (w *deltaWriter) pop() subtree {
+	n := len(w.subtrees)
+	if n == 0 {
+		return subtree{}
+	}
+	top := w.subtrees[n-1]
+	if top.changed > 1 {
+		w.subtrees[n-1].changed--
+		return subtree{changed: 1}
+	}
+	w.subtrees = w.subtrees[:n-1]
+	if top.changed == 0 {
+		w.unchanged--
+	}
+	return top
+}
+
+// flush writes the references to the unchanged subtrees, which become written
+// subtrees.
+func (w *deltaWriter) flush() error {
+	if w.unchanged == 0 {
+		return nil
+	}
+
+	subtrees := w.subtrees
+	w.subtrees = nil
+	w.unchanged = 0
+	for _, s := range subtrees {
+		if s.changed == 0 {
+			err := w.Writer.WriteMsg(&types.SnapshotItem{
+				Item: &types.SnapshotItem_IAVLReference{
+					IAVLReference: &types.SnapshotIAVLReference{
+						Key:   s.key,
+						Items: s.items,
+					},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			s = subtree{changed: 1}
+		}
+		w.push(s)
+	}
+
+	return nil
+}
+
+// deltaReader reads the items of a delta snapshot, the references are replaced
+// by the items of the base snapshot they refer to.
+type deltaReader struct {
+	delta protoio.ReadCloser
+	// openBase opens the stream of the base snapshot, it is called again if a
+	// store is not found after the current position of the base stream.
+	openBase func() (protoio.ReadCloser, error)
+	base     protoio.ReadCloser
+
+	// store is the current store of the delta stream.
+	store string
+	// baseStore is the current store of the base stream.
+	baseStore string
+	// next is the base item to return before reading the base stream again.
+	next *types.SnapshotItem
+	// remaining is the number of items to read from the base stream.
+	remaining uint64
+}
+
+var _ protoio.ReadCloser = (*deltaReader)(nil)
+
+func newDeltaReader(delta protoio.ReadCloser, openBase func() (protoio.ReadCloser, error)) *deltaReader {
+	return &deltaReader{
+		delta:    delta,
+		openBase: openBase,
+	}
+}
+
+// ReadMsg implements protoio.Reader.
+func (r *deltaReader) ReadMsg(msg proto.Message) error {
+	item, ok := msg.(*types.SnapshotItem)
+	if !ok {
+		return fmt.Errorf("unexpected snapshot message %T", msg)
+	}
+
+	for r.remaining == 0 {
+		if err := r.delta.ReadMsg(item); err != nil {
+			return err
+		}
+		switch i := item.Item.(type) {
+		case *types.SnapshotItem_Store:
+			r.store = i.Store.Name
+			return nil
+
+		case *types.SnapshotItem_IAVLReference:
+			if i.IAVLReference.Items == 0 {
+				return errors.New("invalid reference to an empty subtree")
+			}
+			if err := r.seekBase(i.IAVLReference.Key); err != nil {
+				return err
+			}
+			r.remaining = i.IAVLReference.Items
+
+		default:
+			return nil
+		}
+	}
+
+	r.remaining--
+	if r.next != nil {
+		*item = *r.next
+		r.next = nil
+		return nil
+	}
+	err := r.base.ReadMsg(item)
+	if errors.Is(err, io.EOF) || (err == nil && item.GetIAVL() == nil) {
+		return fmt.Errorf("reference exceeds the nodes of store %s in the base snapshot", r.store)
+	}
+	return err
+}
+
+// seekBase positions the base stream at the leaf with the given key of the
+// current store.
+func (r *deltaReader) seekBase(key []byte) error {
+	if err := r.seekBaseStore(); err != nil {
+		return err
+	}
+
+	for {
+		item := &types.SnapshotItem{}
+		err := r.base.ReadMsg(item)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		node := item.GetIAVL()
+		if node == nil {
+			// the base stream left the store
+			r.baseStore = ""
+			if s := item.GetStore(); s != nil {
+				r.baseStore = s.Name
+			}
+			break
+		}
+		if node.Height == 0 && bytes.Equal(node.Key, key) {
+			r.next = item
+			return nil
+		}
+	}
+
+	return fmt.Errorf("leaf %X of store %s not found in the base snapshot", key, r.store)
+}
+
+// seekBaseStore positions the base stream at the current store, the base
+// stream is reopened if the store is not found after its current position.
+func (r *deltaReader) seekBaseStore() error {
+	if r.base != nil && r.baseStore == r.store {
+		return nil
+	}
+
+	// the store may be before the current position only if the base stream was
+	// not read from its start
+	fromStart := r.base == nil
+	if fromStart {
+		if err := r.reopenBase(); err != nil {
+			return err
+		}
+	}
+	for {
+		found, err := r.scanBaseStore()
+		if err != nil || found {
+			return err
+		}
+		if fromStart {
+			return fmt.Errorf("store %s not found in the base snapshot", r.store)
+		}
+		if err := r.reopenBase(); err != nil {
+			return err
+		}
+		fromStart = true
+	}
+}
+
+// scanBaseStore reads the base stream up to the start of the current store.
+func (r *deltaReader) scanBaseStore() (bool, error) {
+	for {
+		item := &types.SnapshotItem{}
+		err := r.base.ReadMsg(item)
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+
+		switch i := item.Item.(type) {
+		case *types.SnapshotItem_Store:
+			r.baseStore = i.Store.Name
+			if r.baseStore == r.store {
+				return true, nil
+			}
+		case *types.SnapshotItem_IAVL:
+		default:
+			// the extensions are written after the stores
+			return false, nil
+		}
+	}
+}
+
+func (r *deltaReader) reopenBase() error {
+	if r.base != nil {
+		if err := r.base.Close(); err != nil {
+			return err
+		}
+	}
+	base, err := r.openBase()
+	if err != nil {
+		return err
+	}
+	r.base = base
+	r.baseStore = ""
+	r.next = nil
+	return nil
+}
+
+// Close implements io.Closer.
+func (r *deltaReader) Close() error {
+	err := r.delta.Close()
+	if r.base != nil {
+		err = errors.Join(err, r.base.Close())
+	}
+	return err
+}
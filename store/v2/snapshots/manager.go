@@ -11,6 +11,8 @@ import (
 	"sort"
 	"sync"
 
+	protoio "github.com/cosmos/gogoproto/io"
+
 	corelog "cosmossdk.io/core/log"
 	corestore "cosmossdk.io/core/store"
 	errorsmod "cosmossdk.io/errors/v2"
@@ -166,6 +168,17 @@ func (m *Manager) GetSnapshotBlockRetentionHeights() int64 {
 
 // Create creates a snapshot and returns its metadata.
 func (m *Manager) Create(height uint64) (*types.Snapshot, error) {
+	return m.create(height, false)
+}
+
+// CreateDelta creates a delta snapshot applied to the latest snapshot and returns
+// its metadata. Only the subtrees changed since the latest snapshot are written,
+// restoring it requires the latest snapshot and its own base snapshots.
+func (m *Manager) CreateDelta(height uint64) (*types.Snapshot, error) {
+	return m.create(height, true)
+}
+
+func (m *Manager) create(height uint64, delta bool) (*types.Snapshot, error) {
 	if m == nil {
 		return nil, errorsmod.Wrap(storeerrors.ErrLogic, "Snapshot Manager is nil")
 	}
@@ -185,17 +198,33 @@ func (m *Manager) Create(height uint64) (*types.Snapshot, error) {
 			"a more recent snapshot already exists at height %v", latest.Height)
 	}
 
+	if !IsCompressionSupported(m.opts.Compression) {
+		return nil, errorsmod.Wrapf(storeerrors.ErrLogic, "unknown snapshot compression %q", m.opts.Compression)
+	}
+
+	format := snapshotFormat(m.opts.Compression, delta)
+	metadata := types.Metadata{
+		Compression: metadataCompression(m.opts.Compression),
+	}
+	if delta {
+		if latest == nil {
+			return nil, errorsmod.Wrap(storeerrors.ErrLogic, "no snapshot to create a delta snapshot from")
+		}
+		metadata.BaseHeight = latest.Height
+	}
+
 	// Spawn goroutine to generate snapshot chunks and pass their io.ReadClosers through a channel
 	ch := make(chan io.ReadCloser)
-	go m.createSnapshot(height, ch)
+	go m.createSnapshot(height, metadata.BaseHeight, ch)
 
-	return m.store.Save(height, types.CurrentFormat, ch)
+	return m.store.SaveWithMetadata(height, format, metadata, ch)
 }
 
 // createSnapshot do the heavy work of snapshotting after the validations of request are done
-// the produced chunks are written to the channel.
-func (m *Manager) createSnapshot(height uint64, ch chan<- io.ReadCloser) {
-	streamWriter := NewStreamWriter(ch)
+// the produced chunks are written to the channel. If baseHeight is not 0, a delta snapshot
+// applied to the snapshot at baseHeight is written.
+func (m *Manager) createSnapshot(height, baseHeight uint64, ch chan<- io.ReadCloser) {
+	streamWriter := NewCompressedStreamWriter(ch, m.opts.Compression)
 	if streamWriter == nil {
 		return
 	}
@@ -205,9 +234,21 @@ func (m *Manager) createSnapshot(height uint64, ch chan<- io.ReadCloser) {
 		}
 	}()
 
-	if err := m.commitSnapshotter.Snapshot(height, streamWriter); err != nil {
-		streamWriter.CloseWithError(err)
-		return
+	if baseHeight == 0 {
+		if err := m.commitSnapshotter.Snapshot(height, streamWriter); err != nil {
+			streamWriter.CloseWithError(err)
+			return
+		}
+	} else {
+		deltaWriter := newDeltaWriter(streamWriter, baseHeight)
+		if err := m.commitSnapshotter.Snapshot(height, deltaWriter); err != nil {
+			streamWriter.CloseWithError(err)
+			return
+		}
+		if err := deltaWriter.Flush(); err != nil {
+			streamWriter.CloseWithError(err)
+			return
+		}
 	}
 	for _, name := range m.sortedExtensionNames() {
 		extension := m.extensions[name]
@@ -310,7 +351,7 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 	defer m.mtx.Unlock()
 
 	// check multistore supported format preemptive
-	if snapshot.Format != types.CurrentFormat {
+	if !isSnapshotFormat(snapshot.Format) {
 		return errorsmod.Wrapf(types.ErrUnknownFormat, "snapshot format %v", snapshot.Format)
 	}
 	if snapshot.Height == 0 {
@@ -321,6 +362,18 @@ func (m *Manager) Restore(snapshot types.Snapshot) error {
 			"snapshot height %v cannot exceed %v", snapshot.Height, int64(math.MaxInt64))
 	}
 
+	if !IsCompressionSupported(snapshot.Metadata.Compression) {
+		return errorsmod.Wrapf(types.ErrInvalidMetadata, "unknown snapshot compression %q", snapshot.Metadata.Compression)
+	}
+	if err := checkFormatCompression(snapshot.Format, snapshot.Metadata.Compression); err != nil {
+		return errorsmod.Wrap(types.ErrInvalidMetadata, err.Error())
+	}
+
+	// a delta snapshot can only be restored on top of its local base snapshots
+	if err := m.checkBases(&snapshot); err != nil {
+		return err
+	}
+
 	err := m.beginLocked(opRestore)
 	if err != nil {
 		return err
@@ -379,10 +432,19 @@ func (m *Manager) doRestoreSnapshot(snapshot types.Snapshot, chChunks <-chan io.
 	}
 
 	var nextItem types.SnapshotItem
-	streamReader, err := NewStreamReader(chChunks)
+	var streamReader protoio.ReadCloser
+	streamReader, err := NewCompressedStreamReader(chChunks, snapshot.Metadata.Compression)
 	if err != nil {
 		return err
 	}
+	if snapshot.Format == types.DeltaFormat {
+		// the references are read from the base snapshot, so that the
+		// snapshotters restore the items of a full snapshot
+		streamReader, err = m.expandDelta(&snapshot, streamReader)
+		if err != nil {
+			return err
+		}
+	}
 	defer streamReader.Close()
 
 	// payloadReader reads an extension payload for extension snapshotter, it returns `io.EOF` at extension boundaries.
@@ -410,7 +472,9 @@ func (m *Manager) doRestoreSnapshot(snapshot types.Snapshot, chChunks <-chan io.
 		}
 	}()
 
-	nextItem, err = m.commitSnapshotter.Restore(snapshot.Height, snapshot.Format, streamReader, chStorage)
+	// the snapshot items are encoded in the current format, whatever the
+	// compression and whether the snapshot is a delta snapshot
+	nextItem, err = m.commitSnapshotter.Restore(snapshot.Height, types.CurrentFormat, streamReader, chStorage)
 	if err != nil {
 		return errorsmod.Wrap(err, "multistore restore")
 	}
@@ -537,6 +601,72 @@ func (m *Manager) RestoreLocalSnapshot(height uint64, format uint32) error {
 	return m.doRestoreSnapshot(*snapshot, ch)
 }
 
+// getBase returns the base snapshot of a delta snapshot.
+func (m *Manager) getBase(snapshot *types.Snapshot) (*types.Snapshot, error) {
+	baseHeight := snapshot.Metadata.BaseHeight
+	if baseHeight == 0 || baseHeight >= snapshot.Height {
+		return nil, errorsmod.Wrapf(types.ErrInvalidMetadata,
+			"invalid base height %v for a delta snapshot at height %v", baseHeight, snapshot.Height)
+	}
+	for _, format := range snapshotFormats {
+		base, err := m.store.Get(baseHeight, format)
+		if err != nil {
+			return nil, err
+		}
+		if base != nil {
+			return base, nil
+		}
+	}
+	return nil, errorsmod.Wrapf(types.ErrInvalidMetadata, "base snapshot at height %v not found", baseHeight)
+}
+
+// checkBases checks that the base snapshots of a delta snapshot are available,
+// down to a full snapshot.
+func (m *Manager) checkBases(snapshot *types.Snapshot) error {
+	for snapshot.Format == types.DeltaFormat {
+		base, err := m.getBase(snapshot)
+		if err != nil {
+			return err
+		}
+		snapshot = base
+	}
+	return nil
+}
+
+// openSnapshot opens the item stream of a local snapshot, the references of a
+// delta snapshot are replaced by the items of its base snapshots.
+func (m *Manager) openSnapshot(snapshot *types.Snapshot) (protoio.ReadCloser, error) {
+	_, chunks, err := m.store.Load(snapshot.Height, snapshot.Format)
+	if err != nil {
+		return nil, err
+	}
+	if chunks == nil {
+		return nil, fmt.Errorf("snapshot doesn't exist, height: %d, format: %d", snapshot.Height, snapshot.Format)
+	}
+	streamReader, err := NewCompressedStreamReader(chunks, snapshot.Metadata.Compression)
+	if err != nil {
+		DrainChunks(chunks)
+		return nil, err
+	}
+	if snapshot.Format != types.DeltaFormat {
+		return streamReader, nil
+	}
+	return m.expandDelta(snapshot, streamReader)
+}
+
+// expandDelta wraps the item stream of a delta snapshot to replace its references
+// by the items of its base snapshot. The stream is closed if an error is returned.
+func (m *Manager) expandDelta(snapshot *types.Snapshot, stream protoio.ReadCloser) (protoio.ReadCloser, error) {
+	base, err := m.getBase(snapshot)
+	if err != nil {
+		_ = stream.Close()
+		return nil, err
+	}
+	return newDeltaReader(stream, func() (protoio.ReadCloser, error) {
+		return m.openSnapshot(base)
+	}), nil
+}
+
 // sortedExtensionNames sort extension names for deterministic iteration.
 func (m *Manager) sortedExtensionNames() []string {
 	names := make([]string, 0, len(m.extensions))
@@ -577,6 +707,28 @@ func (m *Manager) shouldTakeSnapshot(height int64) bool {
 	return m.opts.Interval > 0 && uint64(height)%m.opts.Interval == 0
 }
 
+// shouldTakeDelta returns true if the next snapshot should be a delta snapshot,
+// which is the case until the latest full snapshot has DeltaSnapshots deltas.
+func (m *Manager) shouldTakeDelta() (bool, error) {
+	if m.opts.DeltaSnapshots == 0 {
+		return false, nil
+	}
+	snapshot, err := m.store.GetLatest()
+	if err != nil || snapshot == nil {
+		return false, err
+	}
+	for deltas := uint32(0); snapshot.Format == types.DeltaFormat; deltas++ {
+		if deltas+1 >= m.opts.DeltaSnapshots {
+			return false, nil
+		}
+		snapshot, err = m.getBase(snapshot)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func (m *Manager) snapshot(height int64) {
 	m.logger.Info("creating state snapshot", "height", height)
 
@@ -585,7 +737,15 @@ func (m *Manager) snapshot(height int64) {
 		return
 	}
 
-	snapshot, err := m.Create(uint64(height))
+	create := m.Create
+	delta, err := m.shouldTakeDelta()
+	if err != nil {
+		m.logger.Error("failed to examine latest snapshot, taking a full snapshot", "height", height, "err", err)
+	} else if delta {
+		create = m.CreateDelta
+	}
+
+	snapshot, err := create(uint64(height))
 	if err != nil {
 		m.logger.Error("failed to create state snapshot", "height", height, "err", err)
 		return
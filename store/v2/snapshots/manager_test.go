@@ -128,6 +128,124 @@ func TestManager_Prune(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestManager_TakeDelta(t *testing.T) {
+	store, err := snapshots.NewStore(t.TempDir())
+	require.NoError(t, err)
+	items := [][]byte{
+		{1, 2, 3},
+		{4, 5, 6},
+	}
+	commitSnapshotter := &mockCommitSnapshotter{
+		items: items,
+	}
+	manager := snapshots.NewManager(store, opts, commitSnapshotter, &mockStorageSnapshotter{}, nil, coretesting.NewNopLogger())
+
+	// creating a delta snapshot without a snapshot to apply it to should error
+	_, err = manager.CreateDelta(5)
+	require.Error(t, err)
+
+	full, err := manager.Create(5)
+	require.NoError(t, err)
+
+	// the delta snapshot is applied to the latest snapshot
+	snapshot, err := manager.CreateDelta(7)
+	require.NoError(t, err)
+	assert.Equal(t, types.DeltaFormat, snapshot.Format)
+	assert.EqualValues(t, 5, snapshot.Metadata.BaseHeight)
+
+	snapshot, err = manager.CreateDelta(9)
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, snapshot.Metadata.BaseHeight)
+
+	// the items which are not tree nodes are written in full
+	assert.Equal(t, full.Hash, snapshot.Hash)
+
+	// restoring the delta snapshot reads its base snapshots
+	target := &mockCommitSnapshotter{}
+	manager = snapshots.NewManager(store, opts, target, &mockStorageSnapshotter{items: map[string][]byte{}}, nil, coretesting.NewNopLogger())
+	err = manager.RestoreLocalSnapshot(9, types.DeltaFormat)
+	require.NoError(t, err)
+	assert.Equal(t, items, target.items)
+
+	// pruning retains the base snapshots of the retained delta snapshots
+	pruned, err := manager.Prune(1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, pruned)
+
+	// a delta snapshot can't be restored without its base snapshots
+	err = store.Delete(5, types.CurrentFormat)
+	require.NoError(t, err)
+	err = manager.Restore(*snapshot)
+	require.ErrorIs(t, err, types.ErrInvalidMetadata)
+}
+
+func TestManager_TakeCompressed(t *testing.T) {
+	items := [][]byte{
+		{1, 2, 3},
+		{4, 5, 6},
+	}
+	// each compression has its own format
+	formats := map[string]uint32{
+		"":                    types.CurrentFormat,
+		types.CompressionZlib: types.CurrentFormat,
+		types.CompressionZstd: types.ZstdFormat,
+		types.CompressionGzip: types.GzipFormat,
+		types.CompressionNone: types.UncompressedFormat,
+	}
+	for compression, format := range formats {
+		t.Run(compression, func(t *testing.T) {
+			store, err := snapshots.NewStore(t.TempDir())
+			require.NoError(t, err)
+			snapshotOpts := opts
+			snapshotOpts.Compression = compression
+			manager := snapshots.NewManager(store, snapshotOpts, &mockCommitSnapshotter{items: items}, &mockStorageSnapshotter{}, nil, coretesting.NewNopLogger())
+
+			snapshot, err := manager.Create(1)
+			require.NoError(t, err)
+			assert.Equal(t, format, snapshot.Format)
+			if compression == types.CompressionZlib {
+				// zlib is recorded as the default compression
+				assert.Empty(t, snapshot.Metadata.Compression)
+			} else {
+				assert.Equal(t, compression, snapshot.Metadata.Compression)
+			}
+
+			target := &mockCommitSnapshotter{}
+			manager = snapshots.NewManager(store, opts, target, &mockStorageSnapshotter{items: map[string][]byte{}}, nil, coretesting.NewNopLogger())
+			err = manager.RestoreLocalSnapshot(1, format)
+			require.NoError(t, err)
+			assert.Equal(t, items, target.items)
+		})
+	}
+
+	// creating a snapshot with an unknown compression should error
+	snapshotOpts := opts
+	snapshotOpts.Compression = "lz4"
+	manager := snapshots.NewManager(setupStore(t), snapshotOpts, &mockCommitSnapshotter{items: items}, &mockStorageSnapshotter{}, nil, coretesting.NewNopLogger())
+	_, err := manager.Create(5)
+	require.Error(t, err)
+
+	// restoring a snapshot with an unknown compression should error
+	err = manager.Restore(types.Snapshot{
+		Height:   3,
+		Format:   types.CurrentFormat,
+		Hash:     []byte{1, 2, 3},
+		Chunks:   1,
+		Metadata: types.Metadata{ChunkHashes: checksums([][]byte{{1}}), Compression: "lz4"},
+	})
+	require.ErrorIs(t, err, types.ErrInvalidMetadata)
+
+	// restoring a snapshot with a compression which doesn't match its format should error
+	err = manager.Restore(types.Snapshot{
+		Height:   3,
+		Format:   types.CurrentFormat,
+		Hash:     []byte{1, 2, 3},
+		Chunks:   1,
+		Metadata: types.Metadata{ChunkHashes: checksums([][]byte{{1}}), Compression: types.CompressionZstd},
+	})
+	require.ErrorIs(t, err, types.ErrInvalidMetadata)
+}
+
 func TestManager_Restore(t *testing.T) {
 	store := setupStore(t)
 	target := &mockCommitSnapshotter{}
@@ -535,3 +653,33 @@ func TestSnapshot_SnapshotIfApplicable(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, uint64(0), pruned)
 }
+
+func TestSnapshot_SnapshotIfApplicable_Delta(t *testing.T) {
+	store, err := snapshots.NewStore(t.TempDir())
+	require.NoError(t, err)
+
+	commitSnapshotter := &mockCommitSnapshotter{
+		items: [][]byte{{1, 2, 3}},
+	}
+
+	snapshotOpts := snapshots.NewSnapshotOptions(1, 0)
+	snapshotOpts.DeltaSnapshots = 2
+
+	manager := snapshots.NewManager(store, snapshotOpts, commitSnapshotter, &mockStorageSnapshotter{}, nil, coretesting.NewNopLogger())
+
+	// a full snapshot is followed by DeltaSnapshots delta snapshots
+	expectFormats := []uint32{types.CurrentFormat, types.DeltaFormat, types.DeltaFormat, types.CurrentFormat}
+	for i, format := range expectFormats {
+		height := uint64(i + 1)
+		manager.SnapshotIfApplicable(int64(height))
+
+		require.Eventually(t, func() bool {
+			latestSnapshot, _ := store.GetLatest()
+			return latestSnapshot != nil && latestSnapshot.Height == height
+		}, time.Second*10, 10*time.Millisecond)
+
+		latestSnapshot, err := store.GetLatest()
+		require.NoError(t, err)
+		require.Equal(t, format, latestSnapshot.Format, "height %d", height)
+	}
+}
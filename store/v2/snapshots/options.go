@@ -8,6 +8,19 @@ type SnapshotOptions struct {
 
 	// KeepRecent defines how many snapshots to keep in heights.
 	KeepRecent uint32
+
+	// DeltaSnapshots defines how many delta snapshots are taken between two full
+	// snapshots, 0 disables delta snapshots. A delta snapshot only contains the
+	// changes since the previous snapshot.
+	DeltaSnapshots uint32
+
+	// Compression defines the compression of the snapshot chunks, one of the
+	// types.Compression constants. It defaults to zlib if empty.
+	Compression string
+
+	// ChunkDedup defines whether the snapshot store shares the identical chunks
+	// of the snapshots, see Store.SetChunkDedup.
+	ChunkDedup bool
 }
 
 func NewSnapshotOptions(interval uint64, keepRecent uint32) SnapshotOptions {
@@ -3,6 +3,7 @@ package snapshots
 import (
 	"crypto/sha256"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"hash"
 	"io"
@@ -33,6 +34,9 @@ type Store struct {
 
 	mtx    sync.Mutex
 	saving map[uint64]bool // heights currently being saved
+
+	// dedup stores the chunks by content, see SetChunkDedup.
+	dedup bool
 }
 
 // NewStore creates a new snapshot store.
@@ -55,6 +59,14 @@ func NewStore(dir string) (*Store, error) {
 	}, nil
 }
 
+// SetChunkDedup sets whether the chunks are stored by content, so that the
+// identical chunks of the saved snapshots share the disk space. The chunks
+// remain available at their snapshot paths, as hard links to the files named
+// after their hashes. It must be called before any snapshot is saved.
+func (s *Store) SetChunkDedup(enabled bool) {
+	s.dedup = enabled
+}
+
 // Delete deletes a snapshot.
 func (s *Store) Delete(height uint64, format uint32) error {
 	s.mtx.Lock()
@@ -64,12 +76,49 @@ func (s *Store) Delete(height uint64, format uint32) error {
 		return errors.Wrapf(storeerrors.ErrConflict,
 			"snapshot for height %v format %v is currently being saved", height, format)
 	}
+	// the chunk hashes are read to remove the unused content addressed chunks
+	var hashes [][]byte
+	if snapshot, err := s.Get(height, format); err == nil && snapshot != nil {
+		hashes = snapshot.Metadata.ChunkHashes
+	}
 	if err := os.RemoveAll(s.pathSnapshot(height, format)); err != nil {
 		return errors.Wrapf(err, "failed to delete snapshot chunks for height %v format %v", height, format)
 	}
 	if err := os.RemoveAll(s.pathMetadata(height, format)); err != nil {
 		return errors.Wrapf(err, "failed to delete snapshot metadata for height %v format %v", height, format)
 	}
+	return s.removeUnusedChunks(hashes)
+}
+
+// removeUnusedChunks removes the content addressed chunks with the given hashes
+// which are not used by the remaining snapshots. A chunk removed while a snapshot
+// using it is being saved remains available at the snapshot path.
+func (s *Store) removeUnusedChunks(hashes [][]byte) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(s.pathChunksDir()); os.IsNotExist(err) {
+		return nil
+	}
+
+	snapshots, err := s.List()
+	if err != nil {
+		return err
+	}
+	used := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		for _, hash := range snapshot.Metadata.ChunkHashes {
+			used[string(hash)] = true
+		}
+	}
+	for _, hash := range hashes {
+		if used[string(hash)] {
+			continue
+		}
+		if err := os.Remove(s.pathContentChunk(hash)); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to delete snapshot chunk %x", hash)
+		}
+	}
 	return nil
 }
 
@@ -203,7 +252,8 @@ func (s *Store) loadChunkFile(height uint64, format, chunk uint32) (io.ReadClose
 	return os.Open(path)
 }
 
-// Prune removes old snapshots. The given number of most recent heights (regardless of format) are retained.
+// Prune removes old snapshots. The given number of most recent heights (regardless of format) are retained,
+// as well as the heights of the base snapshots of the retained delta snapshots.
 func (s *Store) Prune(retain uint32) (uint64, error) {
 	metadata, err := os.ReadDir(s.pathMetadataDir())
 	if err != nil {
@@ -221,6 +271,14 @@ func (s *Store) Prune(retain uint32) (uint64, error) {
 
 		if skip[height] || uint32(len(skip)) < retain {
 			skip[height] = true
+			if format == types.DeltaFormat {
+				// the base snapshot has a lower height, so it is visited later
+				snapshot, err := s.Get(height, format)
+				if err != nil {
+					return 0, err
+				}
+				skip[snapshot.Metadata.BaseHeight] = true
+			}
 			continue
 		}
 		err = s.Delete(height, format)
@@ -247,7 +305,51 @@ func (s *Store) Prune(retain uint32) (uint64, error) {
 func (s *Store) Save(
 	height uint64, format uint32, chunks <-chan io.ReadCloser,
 ) (*types.Snapshot, error) {
+	return s.SaveWithMetadata(height, format, types.Metadata{}, chunks)
+}
+
+// SaveDelta saves a delta snapshot applied to the snapshot at the base height
+// to disk, returning it.
+func (s *Store) SaveDelta(
+	height, baseHeight uint64, chunks <-chan io.ReadCloser,
+) (*types.Snapshot, error) {
+	return s.SaveWithMetadata(height, types.DeltaFormat, types.Metadata{BaseHeight: baseHeight}, chunks)
+}
+
+// SaveWithMetadata saves a snapshot with the given metadata to disk, returning it.
+// The chunk hashes of the metadata are computed from the saved chunks.
+func (s *Store) SaveWithMetadata(
+	height uint64, format uint32, metadata types.Metadata, chunks <-chan io.ReadCloser,
+) (*types.Snapshot, error) {
+	if format == types.DeltaFormat && (metadata.BaseHeight == 0 || metadata.BaseHeight >= height) {
+		DrainChunks(chunks)
+		return nil, errors.Wrapf(storeerrors.ErrLogic,
+			"invalid base height %v for a delta snapshot at height %v", metadata.BaseHeight, height)
+	}
+	if format != types.DeltaFormat && metadata.BaseHeight != 0 {
+		DrainChunks(chunks)
+		return nil, errors.Wrapf(storeerrors.ErrLogic, "base height %v for a snapshot of format %v", metadata.BaseHeight, format)
+	}
+	if !IsCompressionSupported(metadata.Compression) {
+		DrainChunks(chunks)
+		return nil, errors.Wrapf(storeerrors.ErrLogic, "unknown snapshot compression %q", metadata.Compression)
+	}
+	if err := checkFormatCompression(format, metadata.Compression); err != nil {
+		DrainChunks(chunks)
+		return nil, errors.Wrap(storeerrors.ErrLogic, err.Error())
+	}
+
+	metadata.ChunkHashes = nil
+	return s.save(&types.Snapshot{
+		Height:   height,
+		Format:   format,
+		Metadata: metadata,
+	}, chunks)
+}
+
+func (s *Store) save(snapshot *types.Snapshot, chunks <-chan io.ReadCloser) (*types.Snapshot, error) {
 	defer DrainChunks(chunks)
+	height, format := snapshot.Height, snapshot.Format
 	if height == 0 {
 		return nil, errors.Wrap(storeerrors.ErrLogic, "snapshot height cannot be 0")
 	}
@@ -266,11 +368,6 @@ func (s *Store) Save(
 		s.mtx.Unlock()
 	}()
 
-	snapshot := &types.Snapshot{
-		Height: height,
-		Format: format,
-	}
-
 	// create height directory or do nothing
 	if err := os.MkdirAll(s.pathHeight(height), 0o750); err != nil {
 		return nil, errors.Wrapf(err, "failed to create snapshot directory for height %v", height)
@@ -320,7 +417,44 @@ func (s *Store) saveChunk(chunkBody io.ReadCloser, index uint32, snapshot *types
 		return errors.Wrapf(err, "failed to close snapshot chunk body %d", index)
 	}
 
-	snapshot.Metadata.ChunkHashes = append(snapshot.Metadata.ChunkHashes, chunkHasher.Sum(nil))
+	chunkHash := chunkHasher.Sum(nil)
+	if s.dedup {
+		if err := s.dedupChunk(path, chunkHash); err != nil {
+			return err
+		}
+	}
+
+	snapshot.Metadata.ChunkHashes = append(snapshot.Metadata.ChunkHashes, chunkHash)
+	return nil
+}
+
+// dedupChunk links the chunk file to the content addressed file of its hash. If
+// the content addressed file already exists, the chunk file is replaced by a
+// hard link to it.
+func (s *Store) dedupChunk(path string, hash []byte) error {
+	if err := os.MkdirAll(s.pathChunksDir(), 0o750); err != nil {
+		return errors.Wrapf(err, "failed to create snapshot chunks directory")
+	}
+
+	contentPath := s.pathContentChunk(hash)
+	err := os.Link(path, contentPath)
+	if err == nil {
+		return nil
+	}
+	if !os.IsExist(err) {
+		// the file system may not support hard links, the chunk is kept as is
+		return nil
+	}
+
+	// the chunk is replaced through a temporary link, so that it always exists
+	tmpPath := path + ".tmp"
+	if err := os.Link(contentPath, tmpPath); err != nil {
+		// the content addressed chunk may have been removed meanwhile
+		return nil
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Wrapf(err, "failed to replace snapshot chunk file %q", path)
+	}
 	return nil
 }
 
@@ -362,6 +496,15 @@ func (s *Store) pathMetadata(height uint64, format uint32) string {
 	return filepath.Join(s.pathMetadataDir(), fmt.Sprintf("%020d-%08d", height, format))
 }
 
+func (s *Store) pathChunksDir() string {
+	return filepath.Join(s.dir, "chunks")
+}
+
+// pathContentChunk generates the path of a content addressed chunk.
+func (s *Store) pathContentChunk(hash []byte) string {
+	return filepath.Join(s.pathChunksDir(), hex.EncodeToString(hash))
+}
+
 // PathChunk generates a snapshot chunk path.
 func (s *Store) PathChunk(height uint64, format, chunk uint32) string {
 	return filepath.Join(s.pathSnapshot(height, format), strconv.FormatUint(uint64(chunk), 10))
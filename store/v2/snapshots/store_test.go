@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -346,6 +348,74 @@ func TestStore_Save(t *testing.T) {
 	assert.Equal(t, []uint64{7, 7}, gotErrHeights)
 }
 
+func TestStore_SaveWithMetadata(t *testing.T) {
+	store := setupStore(t)
+
+	// the metadata is saved, with the chunk hashes computed from the chunks
+	snapshot, err := store.SaveWithMetadata(4, types.DeltaFormat, types.Metadata{
+		ChunkHashes: [][]byte{{9}},
+		BaseHeight:  3,
+		Compression: types.CompressionZstd,
+	}, makeChunks([][]byte{{1}, {2}}))
+	require.NoError(t, err)
+	assert.Equal(t, types.Metadata{
+		ChunkHashes: checksums([][]byte{{1}, {2}}),
+		BaseHeight:  3,
+		Compression: types.CompressionZstd,
+	}, snapshot.Metadata)
+	loaded, err := store.Get(snapshot.Height, snapshot.Format)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+
+	// saving a snapshot with an unknown compression should error
+	_, err = store.SaveWithMetadata(5, 1, types.Metadata{Compression: "lz4"}, makeChunks([][]byte{{1}}))
+	require.Error(t, err)
+
+	// saving a snapshot which is not a delta snapshot with a base height should error
+	_, err = store.SaveWithMetadata(5, 1, types.Metadata{BaseHeight: 3}, makeChunks([][]byte{{1}}))
+	require.Error(t, err)
+}
+
+func TestStore_ChunkDedup(t *testing.T) {
+	dir := t.TempDir()
+	store, err := snapshots.NewStore(dir)
+	require.NoError(t, err)
+	store.SetChunkDedup(true)
+
+	_, err = store.Save(1, 1, makeChunks([][]byte{{1, 0}, {1, 1}}))
+	require.NoError(t, err)
+	_, err = store.Save(2, 1, makeChunks([][]byte{{1, 0}, {2, 1}}))
+	require.NoError(t, err)
+
+	// the identical chunks share their file
+	info1, err := os.Stat(store.PathChunk(1, 1, 0))
+	require.NoError(t, err)
+	info2, err := os.Stat(store.PathChunk(2, 1, 0))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(info1, info2))
+
+	contents, err := os.ReadDir(filepath.Join(dir, "chunks"))
+	require.NoError(t, err)
+	assert.Len(t, contents, 3)
+
+	// deleting a snapshot keeps the chunks used by the other snapshots
+	err = store.Delete(1, 1)
+	require.NoError(t, err)
+	contents, err = os.ReadDir(filepath.Join(dir, "chunks"))
+	require.NoError(t, err)
+	assert.Len(t, contents, 2)
+
+	_, chunks, err := store.Load(2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{{1, 0}, {2, 1}}, readChunks(chunks))
+
+	err = store.Delete(2, 1)
+	require.NoError(t, err)
+	contents, err = os.ReadDir(filepath.Join(dir, "chunks"))
+	require.NoError(t, err)
+	assert.Empty(t, contents)
+}
+
 type ReadCloserMock struct{}
 
 func (r ReadCloserMock) Read(p []byte) (n int, err error) {
@@ -2,20 +2,20 @@ package snapshots
 
 import (
 	"bufio"
-	"compress/zlib"
 	"io"
 
 	protoio "github.com/cosmos/gogoproto/io"
 	"github.com/cosmos/gogoproto/proto"
 
-	"cosmossdk.io/errors/v2"
+	"cosmossdk.io/store/v2/snapshots/types"
 )
 
 const (
 	// Do not change chunk size without new snapshot format (must be uniform across nodes)
 	snapshotChunkSize  = uint64(10e6)
 	snapshotBufferSize = int(snapshotChunkSize)
-	// Do not change compression level without new snapshot format (must be uniform across nodes)
+	// Do not change compression level without new snapshot format (must be uniform across nodes),
+	// it is used by the zlib and gzip compressions
 	snapshotCompressionLevel = 7
 )
 
@@ -27,21 +27,27 @@ type WriteCloser interface {
 }
 
 // StreamWriter set up a stream pipeline to serialize snapshot nodes:
-// Exported Items -> delimited Protobuf -> zlib -> buffer -> chunkWriter -> chan io.ReadCloser
+// Exported Items -> delimited Protobuf -> compression -> buffer -> chunkWriter -> chan io.ReadCloser
 type StreamWriter struct {
 	chunkWriter *ChunkWriter
 	bufWriter   *bufio.Writer
-	zWriter     *zlib.Writer
+	zWriter     io.WriteCloser
 	protoWriter protoio.WriteCloser
 }
 
 // NewStreamWriter set up a stream pipeline to serialize snapshot DB records.
 func NewStreamWriter(ch chan<- io.ReadCloser) *StreamWriter {
+	return NewCompressedStreamWriter(ch, types.CompressionZlib)
+}
+
+// NewCompressedStreamWriter set up a stream pipeline to serialize snapshot DB records
+// with the given compression.
+func NewCompressedStreamWriter(ch chan<- io.ReadCloser, compression string) *StreamWriter {
 	chunkWriter := NewChunkWriter(ch, snapshotChunkSize)
 	bufWriter := bufio.NewWriterSize(chunkWriter, snapshotBufferSize)
-	zWriter, err := zlib.NewWriterLevel(bufWriter, snapshotCompressionLevel)
+	zWriter, err := newCompressWriter(bufWriter, compression)
 	if err != nil {
-		chunkWriter.CloseWithError(errors.Wrap(err, "zlib failure"))
+		chunkWriter.CloseWithError(err)
 		return nil
 	}
 	protoWriter := protoio.NewDelimitedWriter(zWriter)
@@ -77,7 +83,7 @@ func (sw *StreamWriter) CloseWithError(err error) {
 }
 
 // StreamReader set up a restore stream pipeline
-// chan io.ReadCloser -> chunkReader -> zlib -> delimited Protobuf -> ExportNode
+// chan io.ReadCloser -> chunkReader -> decompression -> delimited Protobuf -> ExportNode
 type StreamReader struct {
 	chunkReader *ChunkReader
 	zReader     io.ReadCloser
@@ -86,10 +92,16 @@ type StreamReader struct {
 
 // NewStreamReader set up a restore stream pipeline.
 func NewStreamReader(chunks <-chan io.ReadCloser) (*StreamReader, error) {
+	return NewCompressedStreamReader(chunks, types.CompressionZlib)
+}
+
+// NewCompressedStreamReader set up a restore stream pipeline for a snapshot stream
+// with the given compression.
+func NewCompressedStreamReader(chunks <-chan io.ReadCloser, compression string) (*StreamReader, error) {
 	chunkReader := NewChunkReader(chunks)
-	zReader, err := zlib.NewReader(chunkReader)
+	zReader, err := newDecompressReader(chunkReader, compression)
 	if err != nil {
-		return nil, errors.Wrap(err, "zlib failure")
+		return nil, err
 	}
 	protoReader := protoio.NewDelimitedReader(zReader, snapshotMaxItemSize)
 	return &StreamReader{
@@ -4,3 +4,31 @@ package types
 // must be identical across all nodes for a given height, so this must be bumped when the binary
 // snapshot output changes.
 const CurrentFormat uint32 = 3
+
+// DeltaFormat is the format of delta snapshots. A delta snapshot is applied to
+// the snapshot at Metadata.BaseHeight, the subtrees which are unchanged since
+// the base snapshot are written as references to the base snapshot items.
+const DeltaFormat uint32 = 4
+
+// The formats of the snapshots compressed with another compression than zlib,
+// which is the compression of CurrentFormat. The compression changes the binary
+// snapshot output, so each compression has its own format and the nodes which
+// don't support it reject the snapshot instead of mis-restoring it. A delta
+// snapshot is never offered to the peers, so DeltaFormat is used whatever its
+// compression.
+const (
+	ZstdFormat         uint32 = 5
+	GzipFormat         uint32 = 6
+	UncompressedFormat uint32 = 7
+)
+
+// The compressions of the snapshot stream, recorded in Metadata.Compression,
+// which must match the compression of the snapshot format. An empty compression
+// is the zlib compression of the snapshots taken before the compression was
+// recorded.
+const (
+	CompressionZlib = "zlib"
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+	CompressionNone = "none"
+)
@@ -104,6 +104,12 @@ func (m *Snapshot) GetMetadata() Metadata {
 // Metadata contains SDK-specific snapshot metadata.
 type Metadata struct {
 	ChunkHashes [][]byte `protobuf:"bytes,1,rep,name=chunk_hashes,json=chunkHashes,proto3" json:"chunk_hashes,omitempty"`
+	// base_height is the height of the snapshot a delta snapshot is applied to,
+	// it is 0 for a full snapshot.
+	BaseHeight uint64 `protobuf:"varint,2,opt,name=base_height,json=baseHeight,proto3" json:"base_height,omitempty"`
+	// compression is the compression of the snapshot stream, it is empty for
+	// the default zlib compression.
+	Compression string `protobuf:"bytes,3,opt,name=compression,proto3" json:"compression,omitempty"`
 }
 
 func (m *Metadata) Reset()         { *m = Metadata{} }
@@ -146,6 +152,20 @@ func (m *Metadata) GetChunkHashes() [][]byte {
 	return nil
 }
 
+func (m *Metadata) GetBaseHeight() uint64 {
+	if m != nil {
+		return m.BaseHeight
+	}
+	return 0
+}
+
+func (m *Metadata) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 // SnapshotItem is an item contained in a rootmulti.Store snapshot.
 type SnapshotItem struct {
 	// item is the specific type of snapshot item.
@@ -156,6 +176,7 @@ type SnapshotItem struct {
 	//	*SnapshotItem_IAVL
 	//	*SnapshotItem_Extension
 	//	*SnapshotItem_ExtensionPayload
+	//	*SnapshotItem_IAVLReference
 	Item isSnapshotItem_Item `protobuf_oneof:"item"`
 }
 
@@ -210,11 +231,15 @@ type SnapshotItem_Extension struct {
 type SnapshotItem_ExtensionPayload struct {
 	ExtensionPayload *SnapshotExtensionPayload `protobuf:"bytes,4,opt,name=extension_payload,json=extensionPayload,proto3,oneof" json:"extension_payload,omitempty"`
 }
+type SnapshotItem_IAVLReference struct {
+	IAVLReference *SnapshotIAVLReference `protobuf:"bytes,5,opt,name=iavl_reference,json=iavlReference,proto3,oneof" json:"iavl_reference,omitempty"`
+}
 
 func (*SnapshotItem_Store) isSnapshotItem_Item()            {}
 func (*SnapshotItem_IAVL) isSnapshotItem_Item()             {}
 func (*SnapshotItem_Extension) isSnapshotItem_Item()        {}
 func (*SnapshotItem_ExtensionPayload) isSnapshotItem_Item() {}
+func (*SnapshotItem_IAVLReference) isSnapshotItem_Item()    {}
 
 func (m *SnapshotItem) GetItem() isSnapshotItem_Item {
 	if m != nil {
@@ -251,6 +276,13 @@ func (m *SnapshotItem) GetExtensionPayload() *SnapshotExtensionPayload {
 	return nil
 }
 
+func (m *SnapshotItem) GetIAVLReference() *SnapshotIAVLReference {
+	if x, ok := m.GetItem().(*SnapshotItem_IAVLReference); ok {
+		return x.IAVLReference
+	}
+	return nil
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*SnapshotItem) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -258,6 +290,7 @@ func (*SnapshotItem) XXX_OneofWrappers() []interface{} {
 		(*SnapshotItem_IAVL)(nil),
 		(*SnapshotItem_Extension)(nil),
 		(*SnapshotItem_ExtensionPayload)(nil),
+		(*SnapshotItem_IAVLReference)(nil),
 	}
 }
 
@@ -377,6 +410,63 @@ func (m *SnapshotIAVLItem) GetHeight() int32 {
 	return 0
 }
 
+// SnapshotIAVLReference refers to a subtree of a delta snapshot which is
+// unchanged since its base snapshot, the exported nodes of the subtree are read
+// from the base snapshot.
+type SnapshotIAVLReference struct {
+	// key is the key of the leftmost leaf of the subtree.
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// items is the number of exported nodes of the subtree.
+	Items uint64 `protobuf:"varint,2,opt,name=items,proto3" json:"items,omitempty"`
+}
+
+func (m *SnapshotIAVLReference) Reset()         { *m = SnapshotIAVLReference{} }
+func (m *SnapshotIAVLReference) String() string { return proto.CompactTextString(m) }
+func (*SnapshotIAVLReference) ProtoMessage()    {}
+func (*SnapshotIAVLReference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6851f1463fcbb80c, []int{5}
+}
+func (m *SnapshotIAVLReference) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SnapshotIAVLReference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SnapshotIAVLReference.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SnapshotIAVLReference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotIAVLReference.Merge(m, src)
+}
+func (m *SnapshotIAVLReference) XXX_Size() int {
+	return m.Size()
+}
+func (m *SnapshotIAVLReference) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotIAVLReference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotIAVLReference proto.InternalMessageInfo
+
+func (m *SnapshotIAVLReference) GetKey() []byte {
+	if m != nil {
+		return m.Key
+	}
+	return nil
+}
+
+func (m *SnapshotIAVLReference) GetItems() uint64 {
+	if m != nil {
+		return m.Items
+	}
+	return 0
+}
+
 // SnapshotExtensionMeta contains metadata about an external snapshotter.
 type SnapshotExtensionMeta struct {
 	Name   string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -387,7 +477,7 @@ func (m *SnapshotExtensionMeta) Reset()         { *m = SnapshotExtensionMeta{} }
 func (m *SnapshotExtensionMeta) String() string { return proto.CompactTextString(m) }
 func (*SnapshotExtensionMeta) ProtoMessage()    {}
 func (*SnapshotExtensionMeta) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6851f1463fcbb80c, []int{5}
+	return fileDescriptor_6851f1463fcbb80c, []int{6}
 }
 func (m *SnapshotExtensionMeta) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -439,7 +529,7 @@ func (m *SnapshotExtensionPayload) Reset()         { *m = SnapshotExtensionPaylo
 func (m *SnapshotExtensionPayload) String() string { return proto.CompactTextString(m) }
 func (*SnapshotExtensionPayload) ProtoMessage()    {}
 func (*SnapshotExtensionPayload) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6851f1463fcbb80c, []int{6}
+	return fileDescriptor_6851f1463fcbb80c, []int{7}
 }
 func (m *SnapshotExtensionPayload) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -481,6 +571,7 @@ func init() {
 	proto.RegisterType((*SnapshotItem)(nil), "cosmos.store.snapshots.v2.SnapshotItem")
 	proto.RegisterType((*SnapshotStoreItem)(nil), "cosmos.store.snapshots.v2.SnapshotStoreItem")
 	proto.RegisterType((*SnapshotIAVLItem)(nil), "cosmos.store.snapshots.v2.SnapshotIAVLItem")
+	proto.RegisterType((*SnapshotIAVLReference)(nil), "cosmos.store.snapshots.v2.SnapshotIAVLReference")
 	proto.RegisterType((*SnapshotExtensionMeta)(nil), "cosmos.store.snapshots.v2.SnapshotExtensionMeta")
 	proto.RegisterType((*SnapshotExtensionPayload)(nil), "cosmos.store.snapshots.v2.SnapshotExtensionPayload")
 }
@@ -490,41 +581,51 @@ func init() {
 }
 
 var fileDescriptor_6851f1463fcbb80c = []byte{
-	// 541 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x41, 0x6b, 0x13, 0x41,
-	0x14, 0xde, 0x69, 0x36, 0x31, 0x7d, 0xbb, 0x62, 0x3a, 0x56, 0x59, 0x7b, 0xd8, 0xae, 0x11, 0x61,
-	0x41, 0xb3, 0x29, 0x5b, 0xf1, 0x20, 0x82, 0x18, 0x2c, 0x24, 0xa8, 0x50, 0xa6, 0x20, 0xe2, 0x25,
-	0x4c, 0x9b, 0x31, 0x1b, 0x92, 0xcd, 0x84, 0xcc, 0x34, 0x98, 0xa3, 0xff, 0xc0, 0x3f, 0xe2, 0xcd,
-	0x1f, 0xd1, 0x63, 0xf1, 0xe4, 0xa9, 0x48, 0xf2, 0x17, 0xfc, 0x01, 0x32, 0x33, 0xbb, 0xb1, 0xd4,
-	0x8d, 0xd4, 0xdb, 0xfb, 0xde, 0xbc, 0xef, 0x9b, 0x79, 0xdf, 0xcc, 0x3c, 0x08, 0x4f, 0xb8, 0x48,
-	0xb9, 0x68, 0x0a, 0xc9, 0xa7, 0xac, 0x29, 0xc6, 0x74, 0x22, 0x12, 0x2e, 0x45, 0x73, 0x16, 0xaf,
-	0x40, 0x34, 0x99, 0x72, 0xc9, 0xf1, 0x3d, 0x53, 0x19, 0xe9, 0xca, 0x68, 0x55, 0x19, 0xcd, 0xe2,
-	0x9d, 0xed, 0x3e, 0xef, 0x73, 0x5d, 0xd5, 0x54, 0x91, 0x21, 0xec, 0x64, 0x84, 0xae, 0x59, 0xc8,
-	0xd8, 0x1a, 0xd4, 0xbf, 0x22, 0xa8, 0x1e, 0x65, 0x0a, 0xf8, 0x2e, 0x54, 0x12, 0x36, 0xe8, 0x27,
-	0xd2, 0x43, 0x01, 0x0a, 0x6d, 0x92, 0x21, 0x95, 0xff, 0xc8, 0xa7, 0x29, 0x95, 0xde, 0x46, 0x80,
-	0xc2, 0x9b, 0x24, 0x43, 0x2a, 0x7f, 0x92, 0x9c, 0x8e, 0x87, 0xc2, 0x2b, 0x99, 0xbc, 0x41, 0x18,
-	0x83, 0x9d, 0x50, 0x91, 0x78, 0x76, 0x80, 0x42, 0x97, 0xe8, 0x18, 0x1f, 0x40, 0x35, 0x65, 0x92,
-	0xf6, 0xa8, 0xa4, 0x5e, 0x39, 0x40, 0xa1, 0x13, 0x3f, 0x88, 0xd6, 0xf6, 0x11, 0xbd, 0xcd, 0x4a,
-	0x5b, 0xf6, 0xd9, 0xc5, 0xae, 0x45, 0x56, 0xd4, 0x7a, 0x03, 0xaa, 0xf9, 0x1a, 0xbe, 0x0f, 0xae,
-	0xde, 0xb0, 0xab, 0x36, 0x60, 0xc2, 0x43, 0x41, 0x29, 0x74, 0x89, 0xa3, 0x73, 0x6d, 0x9d, 0xaa,
-	0xff, 0xda, 0x00, 0x37, 0x6f, 0xaf, 0x23, 0x59, 0x8a, 0x5f, 0x41, 0x59, 0x6f, 0xa7, 0x3b, 0x74,
-	0xe2, 0xc7, 0xff, 0x38, 0x43, 0xce, 0x3b, 0x52, 0x4b, 0x8a, 0xdc, 0xb6, 0x88, 0x21, 0xe3, 0xd7,
-	0x60, 0x0f, 0xe8, 0x6c, 0xa4, 0xed, 0x70, 0xe2, 0x47, 0xd7, 0x10, 0xe9, 0xbc, 0x7c, 0xf7, 0x46,
-	0x69, 0xb4, 0xaa, 0x8b, 0x8b, 0x5d, 0x5b, 0xa1, 0xb6, 0x45, 0xb4, 0x08, 0x3e, 0x84, 0x4d, 0xf6,
-	0x49, 0xb2, 0xb1, 0x18, 0xf0, 0xb1, 0x36, 0xd2, 0x89, 0xf7, 0xae, 0xa1, 0x78, 0x90, 0x73, 0x94,
-	0x1f, 0x6d, 0x8b, 0xfc, 0x11, 0xc1, 0xc7, 0xb0, 0xb5, 0x02, 0xdd, 0x09, 0x9d, 0x8f, 0x38, 0xed,
-	0xe9, 0xcb, 0x70, 0xe2, 0xfd, 0xff, 0x51, 0x3e, 0x34, 0xd4, 0xb6, 0x45, 0x6a, 0xec, 0x4a, 0xee,
-	0xd9, 0xed, 0xef, 0xdf, 0x1a, 0xb7, 0x8c, 0x56, 0x43, 0xf4, 0x86, 0xc1, 0x5e, 0xf4, 0xe4, 0x69,
-	0xab, 0x02, 0xf6, 0x40, 0xb2, 0xb4, 0xfe, 0x1c, 0xb6, 0xfe, 0x72, 0x4f, 0xbd, 0x8a, 0x31, 0x4d,
-	0x8d, 0xf3, 0x9b, 0x44, 0xc7, 0x85, 0x2a, 0xf5, 0xcf, 0x08, 0x6a, 0x57, 0x7d, 0xc3, 0x35, 0x28,
-	0x0d, 0xd9, 0x5c, 0x93, 0x5d, 0xa2, 0x42, 0xbc, 0x0d, 0xe5, 0x19, 0x1d, 0x9d, 0x32, 0x7d, 0x0b,
-	0x2e, 0x31, 0x00, 0x7b, 0x70, 0x63, 0xc6, 0xa6, 0x2b, 0x2f, 0x4b, 0x24, 0x87, 0x97, 0x5e, 0xb7,
-	0xb2, 0xa2, 0x9c, 0xbf, 0xee, 0xe2, 0x33, 0xbc, 0x87, 0x3b, 0x85, 0x46, 0x17, 0x75, 0xb1, 0xee,
-	0x7f, 0x14, 0x2b, 0x77, 0xc0, 0x5b, 0x67, 0xb4, 0x3a, 0x7c, 0x7e, 0x5d, 0xa6, 0xd1, 0x1c, 0x16,
-	0xdb, 0xfd, 0xe2, 0x6c, 0xe1, 0xa3, 0xf3, 0x85, 0x8f, 0x7e, 0x2e, 0x7c, 0xf4, 0x65, 0xe9, 0x5b,
-	0xe7, 0x4b, 0xdf, 0xfa, 0xb1, 0xf4, 0xad, 0x0f, 0x0f, 0x4d, 0xa9, 0xe8, 0x0d, 0xa3, 0x01, 0xcf,
-	0x46, 0xca, 0xa5, 0x41, 0x22, 0x9a, 0x72, 0x3e, 0x61, 0xe2, 0xb8, 0xa2, 0x87, 0xc0, 0xfe, 0xef,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0xb3, 0x23, 0x48, 0xd5, 0x7c, 0x04, 0x00, 0x00,
+	// 699 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x54, 0x5f, 0x6b, 0xd3, 0x5e,
+	0x18, 0x4e, 0xfa, 0x67, 0xbf, 0xee, 0x4d, 0xfb, 0x73, 0x3b, 0x6e, 0x92, 0x0d, 0x6d, 0x63, 0x65,
+	0x50, 0xd0, 0xa5, 0x23, 0x13, 0x2f, 0x82, 0x20, 0x76, 0x0e, 0x33, 0x54, 0x28, 0xa7, 0x50, 0x44,
+	0x0a, 0x25, 0x6b, 0xcf, 0xd6, 0xd2, 0xa6, 0xa7, 0xe4, 0x64, 0xc5, 0x5d, 0xfa, 0x0d, 0xfc, 0x0c,
+	0x5e, 0x0a, 0xde, 0xf9, 0x21, 0x86, 0x57, 0xc3, 0x2b, 0xaf, 0x86, 0x64, 0x77, 0x5e, 0xfb, 0x01,
+	0xe4, 0x9c, 0x93, 0xc4, 0xb9, 0xa5, 0x32, 0xef, 0xce, 0xf3, 0xbe, 0xef, 0xf3, 0xe4, 0xfd, 0x97,
+	0x17, 0x6a, 0x3d, 0xca, 0x3c, 0xca, 0xea, 0x2c, 0xa0, 0x3e, 0xa9, 0xb3, 0x89, 0x3b, 0x65, 0x03,
+	0x1a, 0xb0, 0xfa, 0xcc, 0x4a, 0x80, 0x39, 0xf5, 0x69, 0x40, 0xd1, 0x9a, 0x8c, 0x34, 0x45, 0xa4,
+	0x99, 0x44, 0x9a, 0x33, 0x6b, 0x7d, 0xe5, 0x90, 0x1e, 0x52, 0x11, 0x55, 0xe7, 0x2f, 0x49, 0x58,
+	0x8f, 0x08, 0x5d, 0xe9, 0x88, 0xd8, 0x02, 0x54, 0x3f, 0xa9, 0x50, 0x68, 0x45, 0x0a, 0xe8, 0x16,
+	0x2c, 0x0c, 0xc8, 0xf0, 0x70, 0x10, 0xe8, 0xaa, 0xa1, 0xd6, 0x72, 0x38, 0x42, 0xdc, 0x7e, 0x40,
+	0x7d, 0xcf, 0x0d, 0xf4, 0x8c, 0xa1, 0xd6, 0x4a, 0x38, 0x42, 0xdc, 0xde, 0x1b, 0x1c, 0x4d, 0x46,
+	0x4c, 0xcf, 0x4a, 0xbb, 0x44, 0x08, 0x41, 0x6e, 0xe0, 0xb2, 0x81, 0x9e, 0x33, 0xd4, 0x5a, 0x11,
+	0x8b, 0x37, 0xda, 0x85, 0x82, 0x47, 0x02, 0xb7, 0xef, 0x06, 0xae, 0x9e, 0x37, 0xd4, 0x9a, 0x66,
+	0xdd, 0x33, 0xe7, 0xd6, 0x61, 0xbe, 0x8a, 0x42, 0x1b, 0xb9, 0x93, 0xb3, 0x8a, 0x82, 0x13, 0x6a,
+	0x75, 0x0a, 0x85, 0xd8, 0x87, 0xee, 0x42, 0x51, 0x7c, 0xb0, 0xcb, 0x3f, 0x40, 0x98, 0xae, 0x1a,
+	0xd9, 0x5a, 0x11, 0x6b, 0xc2, 0xe6, 0x08, 0x13, 0xaa, 0x80, 0xb6, 0xef, 0x32, 0xd2, 0x8d, 0xca,
+	0xca, 0x88, 0xb2, 0x80, 0x9b, 0x1c, 0x59, 0x9a, 0x01, 0x5a, 0x8f, 0x7a, 0x53, 0x9f, 0x30, 0x36,
+	0xa4, 0x13, 0x51, 0xc7, 0x22, 0xbe, 0x68, 0xaa, 0x86, 0x59, 0x28, 0xc6, 0x1d, 0xda, 0x0b, 0x88,
+	0x87, 0x9e, 0x41, 0x5e, 0x64, 0x2c, 0x9a, 0xa4, 0x59, 0x0f, 0xfe, 0x52, 0x46, 0xcc, 0x6b, 0x71,
+	0x17, 0x27, 0x3b, 0x0a, 0x96, 0x64, 0xf4, 0x02, 0x72, 0x43, 0x77, 0x36, 0x16, 0x29, 0x69, 0xd6,
+	0xfd, 0x6b, 0x88, 0xec, 0x3d, 0x6d, 0xbf, 0xe4, 0x1a, 0x8d, 0x42, 0x78, 0x56, 0xc9, 0x71, 0xe4,
+	0x28, 0x58, 0x88, 0xa0, 0x26, 0x2c, 0x92, 0xb7, 0x01, 0x99, 0x24, 0x35, 0x68, 0xd6, 0xd6, 0x35,
+	0x14, 0x77, 0x63, 0x0e, 0x6f, 0xa9, 0xa3, 0xe0, 0xdf, 0x22, 0x68, 0x1f, 0x96, 0x13, 0xd0, 0x9d,
+	0xba, 0xc7, 0x63, 0xea, 0xf6, 0xc5, 0x3c, 0x35, 0x6b, 0xfb, 0x5f, 0x94, 0x9b, 0x92, 0xea, 0x28,
+	0x78, 0x89, 0x5c, 0xb2, 0xa1, 0x31, 0xfc, 0xcf, 0xb3, 0xef, 0xfa, 0xe4, 0x80, 0xf8, 0x64, 0xd2,
+	0x23, 0x7a, 0xfe, 0xda, 0xa9, 0xf3, 0xf2, 0x71, 0xcc, 0x6b, 0x2c, 0x87, 0x67, 0x95, 0xd2, 0x1f,
+	0x26, 0x47, 0xc1, 0x25, 0x2e, 0x9e, 0x18, 0xec, 0x9b, 0x5f, 0x3f, 0x6f, 0xde, 0x90, 0xc2, 0x9b,
+	0xac, 0x3f, 0x32, 0xb6, 0xcc, 0x87, 0x8f, 0x1a, 0x0b, 0x90, 0x1b, 0x06, 0xc4, 0xab, 0x3e, 0x86,
+	0xe5, 0x2b, 0xb3, 0xe2, 0x6b, 0x3c, 0x71, 0x3d, 0x39, 0xe7, 0x45, 0x2c, 0xde, 0xa9, 0x2a, 0xd5,
+	0x77, 0x2a, 0x2c, 0x5d, 0x9e, 0x12, 0x5a, 0x82, 0xec, 0x88, 0x1c, 0x0b, 0x72, 0x11, 0xf3, 0x27,
+	0x5a, 0x81, 0xfc, 0xcc, 0x1d, 0x1f, 0x11, 0x31, 0xf3, 0x22, 0x96, 0x00, 0xe9, 0xf0, 0xdf, 0x8c,
+	0xf8, 0xc9, 0xe4, 0xb2, 0x38, 0x86, 0x17, 0x7e, 0x47, 0xde, 0xf8, 0x7c, 0xfc, 0x3b, 0xa6, 0xe7,
+	0xf0, 0x04, 0x56, 0x53, 0x7b, 0x93, 0x9e, 0x07, 0x2f, 0x9a, 0x45, 0xbf, 0x83, 0x04, 0xd5, 0xd7,
+	0xb0, 0x7a, 0x65, 0x7a, 0x7c, 0x2f, 0xd2, 0xda, 0x30, 0xef, 0x22, 0xa4, 0xa7, 0xb6, 0x07, 0xfa,
+	0xbc, 0xbd, 0xe0, 0xd5, 0xc7, 0xdb, 0x25, 0x33, 0x8c, 0x61, 0xfa, 0xbc, 0x7e, 0xaa, 0x27, 0x61,
+	0x59, 0x3d, 0x0d, 0xcb, 0xea, 0xf7, 0xb0, 0xac, 0xbe, 0x3f, 0x2f, 0x2b, 0xa7, 0xe7, 0x65, 0xe5,
+	0xdb, 0x79, 0x59, 0x81, 0x3b, 0x3d, 0xea, 0xcd, 0x5f, 0x9c, 0x46, 0x29, 0x4e, 0xa1, 0xc9, 0xef,
+	0x5e, 0x53, 0x7d, 0xb3, 0x21, 0x63, 0x59, 0x7f, 0x64, 0x0e, 0x69, 0x74, 0x75, 0x2f, 0xdc, 0x5a,
+	0x56, 0x0f, 0x8e, 0xa7, 0x84, 0x7d, 0xc8, 0x64, 0x77, 0x5a, 0xad, 0x8f, 0x99, 0xb5, 0x1d, 0xa9,
+	0x2c, 0xb6, 0x23, 0xd9, 0x42, 0x66, 0xb6, 0xad, 0x2f, 0xb1, 0xaf, 0x23, 0x7c, 0x9d, 0xc4, 0xd7,
+	0x69, 0x5b, 0x61, 0x66, 0x63, 0xae, 0xaf, 0xf3, 0xbc, 0xd9, 0x88, 0x4f, 0xd9, 0x8f, 0xcc, 0x6d,
+	0x19, 0x67, 0xdb, 0x22, 0xd0, 0xb6, 0x93, 0x48, 0xdb, 0x6e, 0x5b, 0xfb, 0x0b, 0xe2, 0x58, 0x6f,
+	0xff, 0x1a, 0x00, 0x0d, 0x9b, 0xc5, 0xd8, 0x24, 0x06, 0x00, 0x00,
 }
 
 func (m *Snapshot) Marshal() (dAtA []byte, err error) {
@@ -602,6 +703,18 @@ func (m *Metadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Compression) > 0 {
+		i -= len(m.Compression)
+		copy(dAtA[i:], m.Compression)
+		i = encodeVarintSnapshot(dAtA, i, uint64(len(m.Compression)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.BaseHeight != 0 {
+		i = encodeVarintSnapshot(dAtA, i, uint64(m.BaseHeight))
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.ChunkHashes) > 0 {
 		for iNdEx := len(m.ChunkHashes) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.ChunkHashes[iNdEx])
@@ -730,6 +843,27 @@ func (m *SnapshotItem_ExtensionPayload) MarshalToSizedBuffer(dAtA []byte) (int,
 	}
 	return len(dAtA) - i, nil
 }
+func (m *SnapshotItem_IAVLReference) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SnapshotItem_IAVLReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	if m.IAVLReference != nil {
+		{
+			size, err := m.IAVLReference.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintSnapshot(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x2a
+	}
+	return len(dAtA) - i, nil
+}
 func (m *SnapshotStoreItem) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -807,6 +941,41 @@ func (m *SnapshotIAVLItem) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *SnapshotIAVLReference) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SnapshotIAVLReference) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SnapshotIAVLReference) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Items != 0 {
+		i = encodeVarintSnapshot(dAtA, i, uint64(m.Items))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Key) > 0 {
+		i -= len(m.Key)
+		copy(dAtA[i:], m.Key)
+		i = encodeVarintSnapshot(dAtA, i, uint64(len(m.Key)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *SnapshotExtensionMeta) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -919,6 +1088,13 @@ func (m *Metadata) Size() (n int) {
 			n += 1 + l + sovSnapshot(uint64(l))
 		}
 	}
+	if m.BaseHeight != 0 {
+		n += 1 + sovSnapshot(uint64(m.BaseHeight))
+	}
+	l = len(m.Compression)
+	if l > 0 {
+		n += 1 + l + sovSnapshot(uint64(l))
+	}
 	return n
 }
 
@@ -982,6 +1158,18 @@ func (m *SnapshotItem_ExtensionPayload) Size() (n int) {
 	}
 	return n
 }
+func (m *SnapshotItem_IAVLReference) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.IAVLReference != nil {
+		l = m.IAVLReference.Size()
+		n += 1 + l + sovSnapshot(uint64(l))
+	}
+	return n
+}
 func (m *SnapshotStoreItem) Size() (n int) {
 	if m == nil {
 		return 0
@@ -1018,6 +1206,22 @@ func (m *SnapshotIAVLItem) Size() (n int) {
 	return n
 }
 
+func (m *SnapshotIAVLReference) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Key)
+	if l > 0 {
+		n += 1 + l + sovSnapshot(uint64(l))
+	}
+	if m.Items != 0 {
+		n += 1 + sovSnapshot(uint64(m.Items))
+	}
+	return n
+}
+
 func (m *SnapshotExtensionMeta) Size() (n int) {
 	if m == nil {
 		return 0
@@ -1288,6 +1492,57 @@ func (m *Metadata) Unmarshal(dAtA []byte) error {
 			m.ChunkHashes = append(m.ChunkHashes, make([]byte, postIndex-iNdEx))
 			copy(m.ChunkHashes[len(m.ChunkHashes)-1], dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BaseHeight", wireType)
+			}
+			m.BaseHeight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshot
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BaseHeight |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshot
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Compression = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSnapshot(dAtA[iNdEx:])
@@ -1478,6 +1733,41 @@ func (m *SnapshotItem) Unmarshal(dAtA []byte) error {
 			}
 			m.Item = &SnapshotItem_ExtensionPayload{v}
 			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IAVLReference", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshot
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			v := &SnapshotIAVLReference{}
+			if err := v.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			m.Item = &SnapshotItem_IAVLReference{v}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipSnapshot(dAtA[iNdEx:])
@@ -1737,6 +2027,109 @@ func (m *SnapshotIAVLItem) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *SnapshotIAVLReference) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowSnapshot
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SnapshotIAVLReference: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SnapshotIAVLReference: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshot
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = append(m.Key[:0], dAtA[iNdEx:postIndex]...)
+			if m.Key == nil {
+				m.Key = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Items", wireType)
+			}
+			m.Items = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowSnapshot
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Items |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipSnapshot(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthSnapshot
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *SnapshotExtensionMeta) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -7,6 +7,14 @@ import (
 	"cosmossdk.io/store/v2"
 )
 
+// Compactor is implemented by the databases which support triggering a manual
+// compaction of their storage.
+type Compactor interface {
+	// Compact compacts the whole database, reclaiming the space of the deleted
+	// and pruned entries.
+	Compact() error
+}
+
 // Database is an interface that wraps the storage database methods. A wrapper
 // is useful for instances where you want to perform logic that is identical for all SS
 // backends, such as restoring snapshots.
@@ -7,11 +7,15 @@ import (
 	"fmt"
 	"math"
 	"slices"
+	"strings"
+	"sync"
 
 	"github.com/cockroachdb/pebble"
+	"github.com/spf13/cast"
 
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
+	dbm "cosmossdk.io/store/v2/db"
 	storeerrors "cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/internal/encoding"
 	"cosmossdk.io/store/v2/storage"
@@ -26,18 +30,36 @@ const (
 	// batchBufferSize defines the maximum size of a batch before it is committed.
 	batchBufferSize = 100_000
 
-	StorePrefixTpl        = "s/k:%s/"         // s/k:<storeKey>
-	removedStoreKeyPrefix = "s/_removed_key"  // NB: removedStoreKeys key must be lexically smaller than StorePrefixTpl
-	latestVersionKey      = "s/_latest"       // NB: latestVersionKey key must be lexically smaller than StorePrefixTpl
-	pruneHeightKey        = "s/_prune_height" // NB: pruneHeightKey key must be lexically smaller than StorePrefixTpl
+	StorePrefixTpl        = "s/k:%s/"            // s/k:<storeKey>
+	removedStoreKeyPrefix = "s/_removed_key"     // NB: removedStoreKeys key must be lexically smaller than StorePrefixTpl
+	latestVersionKey      = "s/_latest"          // NB: latestVersionKey key must be lexically smaller than StorePrefixTpl
+	pruneHeightKey        = "s/_prune_height"    // NB: pruneHeightKey key must be lexically smaller than StorePrefixTpl
+	storePruneHeightTpl   = "s/_prune_height/%s" // s/_prune_height/<storeKey>
 	tombstoneVal          = "TOMBSTONE"
 )
 
 var (
 	_ storage.Database         = (*Database)(nil)
 	_ store.UpgradableDatabase = (*Database)(nil)
+	_ store.StorePruner        = (*Database)(nil)
 )
 
+func init() {
+	storage.RegisterDatabase(dbm.DBTypePebbleDB, func(dataDir string, opts store.DBOptions) (storage.Database, error) {
+		db, err := New(dataDir)
+		if err != nil {
+			return nil, err
+		}
+		if opts != nil {
+			if sync := opts.Get("sync"); sync != nil {
+				db.SetSync(cast.ToBool(sync))
+			}
+		}
+
+		return db, nil
+	})
+}
+
 type Database struct {
 	storage *pebble.DB
 
@@ -45,6 +67,11 @@ type Database struct {
 	// only updated when the database is pruned.
 	earliestVersion uint64
 
+	// storePruneHeights defines the prune heights of the stores pruned to a
+	// different version than the rest of the database, see PruneStores.
+	storePruneHeights map[string]uint64
+	mtx               sync.RWMutex
+
 	// Sync is whether to sync writes through the OS buffer cache and down onto
 	// the actual disk, if applicable. Setting Sync is required for durability of
 	// individual write operations but can result in slower writes.
@@ -72,11 +99,16 @@ func New(dataDir string) (*Database, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prune height: %w", err)
 	}
+	storePruneHeights, err := getStorePruneHeights(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store prune heights: %w", err)
+	}
 
 	return &Database{
-		storage:         db,
-		earliestVersion: pruneHeight + 1,
-		sync:            true,
+		storage:           db,
+		earliestVersion:   pruneHeight + 1,
+		storePruneHeights: storePruneHeights,
+		sync:              true,
 	}, nil
 }
 
@@ -85,11 +117,16 @@ func NewWithDB(storage *pebble.DB, sync bool) *Database {
 	if err != nil {
 		panic(fmt.Errorf("failed to get prune height: %w", err))
 	}
+	storePruneHeights, err := getStorePruneHeights(storage)
+	if err != nil {
+		panic(fmt.Errorf("failed to get store prune heights: %w", err))
+	}
 
 	return &Database{
-		storage:         storage,
-		earliestVersion: pruneHeight + 1,
-		sync:            sync,
+		storage:           storage,
+		earliestVersion:   pruneHeight + 1,
+		storePruneHeights: storePruneHeights,
+		sync:              sync,
 	}
 }
 
@@ -146,6 +183,18 @@ func (db *Database) setPruneHeight(pruneVersion uint64) error {
 	return db.storage.Set([]byte(pruneHeightKey), ts[:], &pebble.WriteOptions{Sync: db.sync})
 }
 
+// storeEarliestVersion returns the earliest version available for the store.
+func (db *Database) storeEarliestVersion(storeKey []byte) uint64 {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+
+	if pruneHeight, ok := db.storePruneHeights[string(storeKey)]; ok {
+		return pruneHeight + 1
+	}
+
+	return db.earliestVersion
+}
+
 func (db *Database) Has(storeKey []byte, version uint64, key []byte) (bool, error) {
 	val, err := db.Get(storeKey, version, key)
 	if err != nil {
@@ -156,8 +205,8 @@ func (db *Database) Has(storeKey []byte, version uint64, key []byte) (bool, erro
 }
 
 func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]byte, error) {
-	if targetVersion < db.earliestVersion {
-		return nil, storeerrors.ErrVersionPruned{EarliestVersion: db.earliestVersion, RequestedVersion: targetVersion}
+	if earliestVersion := db.storeEarliestVersion(storeKey); targetVersion < earliestVersion {
+		return nil, storeerrors.ErrVersionPruned{EarliestVersion: earliestVersion, RequestedVersion: targetVersion}
 	}
 
 	prefixedVal, err := getMVCCSlice(db.storage, storeKey, key, targetVersion)
@@ -195,6 +244,31 @@ func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]by
 	return nil, nil
 }
 
+// Compact implements storage.Compactor.
+func (db *Database) Compact() error {
+	itr, err := db.storage.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	var start, end []byte
+	if itr.First() {
+		start = slices.Clone(itr.Key())
+	}
+	if itr.Last() {
+		// the end of the range is exclusive, the immediate successor of the last
+		// key includes it
+		end = append(slices.Clone(itr.Key()), 0)
+	}
+	if err := itr.Close(); err != nil {
+		return err
+	}
+	if start == nil {
+		return nil
+	}
+
+	return db.storage.Compact(start, end, true)
+}
+
 // Prune removes all versions of all keys that are <= the given version.
 //
 // Note, the implementation of this method is inefficient and can be potentially
@@ -204,6 +278,35 @@ func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]by
 //
 // See: https://github.com/cockroachdb/cockroach/blob/33623e3ee420174a4fd3226d1284b03f0e3caaac/pkg/storage/mvcc.go#L3182
 func (db *Database) Prune(version uint64) error {
+	return db.PruneStores(version, nil)
+}
+
+// PruneStores removes all versions of the keys of the stores in storeVersions
+// that are <= their version, and all versions of the keys of the other stores
+// that are <= the given version. A version of 0 is not pruned.
+//
+// The stores in storeVersions keep their own prune height, so they can be
+// pruned less (or more) aggressively than the rest of the database.
+func (db *Database) PruneStores(version uint64, storeVersions map[string]uint64) error {
+	storePrefixes := make(map[string]uint64, len(storeVersions))
+	for storeKey, v := range storeVersions {
+		storePrefixes[string(storePrefix([]byte(storeKey)))] = v
+	}
+	// pruneVersion returns the version to prune the given key to, along with the
+	// store prefix the key belongs to if it is pruned to its own version
+	pruneVersion := func(prefixedKey []byte) (uint64, []byte) {
+		var (
+			matched []byte
+			pruneTo = version
+		)
+		for prefix, v := range storePrefixes {
+			if len(prefix) > len(matched) && bytes.HasPrefix(prefixedKey, []byte(prefix)) {
+				matched, pruneTo = []byte(prefix), v
+			}
+		}
+		return pruneTo, matched
+	}
+
 	itr, err := db.storage.NewIter(&pebble.IterOptions{LowerBound: []byte("s/k:")})
 	if err != nil {
 		return err
@@ -216,7 +319,7 @@ func (db *Database) Prune(version uint64) error {
 	var (
 		batchCounter                              int
 		prevKey, prevKeyPrefixed, prevPrefixedVal []byte
-		prevKeyVersion                            uint64
+		prevKeyVersion, prevPruneTo               uint64
 	)
 
 	for itr.First(); itr.Valid(); {
@@ -232,8 +335,15 @@ func (db *Database) Prune(version uint64) error {
 			return fmt.Errorf("failed to decode key version: %w", err)
 		}
 
+		pruneTo, matched := pruneVersion(keyBz)
+		// seek to the next store if the store is not pruned
+		if pruneTo == 0 && matched != nil {
+			itr.SeekGE(prefixEnd(matched))
+			continue
+		}
+
 		// seek to next key if we are at a version which is higher than prune height
-		if keyVersion > version {
+		if keyVersion > pruneTo {
 			itr.NextPrefix()
 			continue
 		}
@@ -241,7 +351,7 @@ func (db *Database) Prune(version uint64) error {
 		// Delete a key if another entry for that key exists a larger version than
 		// the original but <= to the prune height. We also delete a key if it has
 		// been tombstoned and its version is <= to the prune height.
-		if prevKeyVersion <= version && (bytes.Equal(prevKey, keyBz) || valTombstoned(prevPrefixedVal)) {
+		if prevKeyVersion <= prevPruneTo && (bytes.Equal(prevKey, keyBz) || valTombstoned(prevPrefixedVal)) {
 			if err := batch.Delete(prevKeyPrefixed, nil); err != nil {
 				return err
 			}
@@ -259,6 +369,7 @@ func (db *Database) Prune(version uint64) error {
 
 		prevKey = keyBz
 		prevKeyVersion = keyVersion
+		prevPruneTo = pruneTo
 		prevKeyPrefixed = prefixedKey
 		value, err := itr.ValueAndErr()
 		if err != nil {
@@ -276,6 +387,13 @@ func (db *Database) Prune(version uint64) error {
 		}
 	}
 
+	if err := db.setStorePruneHeights(version, storeVersions); err != nil {
+		return err
+	}
+	if version == 0 {
+		return nil
+	}
+
 	if err := db.deleteRemovedStoreKeys(version); err != nil {
 		return err
 	}
@@ -283,6 +401,50 @@ func (db *Database) Prune(version uint64) error {
 	return db.setPruneHeight(version)
 }
 
+// setStorePruneHeights records the prune heights of the stores in
+// storeVersions, which differ from the prune height of the database, and drops
+// the records of the other stores once they are pruned to the given version.
+func (db *Database) setStorePruneHeights(version uint64, storeVersions map[string]uint64) error {
+	db.mtx.Lock()
+	defer db.mtx.Unlock()
+
+	batch := db.storage.NewBatch()
+	defer batch.Close()
+
+	pruneHeights := make(map[string]uint64, len(db.storePruneHeights))
+	for storeKey, pruneHeight := range db.storePruneHeights {
+		if _, ok := storeVersions[storeKey]; !ok && version > 0 && pruneHeight <= version {
+			if err := batch.Delete([]byte(fmt.Sprintf(storePruneHeightTpl, storeKey)), nil); err != nil {
+				return err
+			}
+			continue
+		}
+		pruneHeights[storeKey] = pruneHeight
+	}
+
+	for storeKey, v := range storeVersions {
+		pruneHeight, ok := pruneHeights[storeKey]
+		if !ok {
+			pruneHeight = db.earliestVersion - 1
+		}
+		pruneHeight = max(pruneHeight, v)
+		pruneHeights[storeKey] = pruneHeight
+
+		var ts [VersionSize]byte
+		binary.LittleEndian.PutUint64(ts[:], pruneHeight)
+		if err := batch.Set([]byte(fmt.Sprintf(storePruneHeightTpl, storeKey)), ts[:], nil); err != nil {
+			return err
+		}
+	}
+
+	if err := batch.Commit(&pebble.WriteOptions{Sync: db.sync}); err != nil {
+		return err
+	}
+	db.storePruneHeights = pruneHeights
+
+	return nil
+}
+
 func (db *Database) Iterator(storeKey []byte, version uint64, start, end []byte) (corestore.Iterator, error) {
 	if (start != nil && len(start) == 0) || (end != nil && len(end) == 0) {
 		return nil, storeerrors.ErrKeyEmpty
@@ -304,7 +466,7 @@ func (db *Database) Iterator(storeKey []byte, version uint64, start, end []byte)
 		return nil, err
 	}
 
-	return newPebbleDBIterator(itr, storePrefix(storeKey), start, end, version, db.earliestVersion, false), nil
+	return newPebbleDBIterator(itr, storePrefix(storeKey), start, end, version, db.storeEarliestVersion(storeKey), false), nil
 }
 
 func (db *Database) ReverseIterator(storeKey []byte, version uint64, start, end []byte) (corestore.Iterator, error) {
@@ -328,7 +490,7 @@ func (db *Database) ReverseIterator(storeKey []byte, version uint64, start, end
 		return nil, err
 	}
 
-	return newPebbleDBIterator(itr, storePrefix(storeKey), start, end, version, db.earliestVersion, true), nil
+	return newPebbleDBIterator(itr, storePrefix(storeKey), start, end, version, db.storeEarliestVersion(storeKey), true), nil
 }
 
 func (db *Database) PruneStoreKeys(storeKeys []string, version uint64) error {
@@ -370,6 +532,42 @@ func getPruneHeight(storage *pebble.DB) (uint64, error) {
 	return binary.LittleEndian.Uint64(bz), closer.Close()
 }
 
+func getStorePruneHeights(storage *pebble.DB) (map[string]uint64, error) {
+	prefix := []byte(fmt.Sprintf(storePruneHeightTpl, ""))
+	itr, err := storage.NewIter(&pebble.IterOptions{LowerBound: prefix, UpperBound: prefixEnd(prefix)})
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+
+	pruneHeights := make(map[string]uint64)
+	for itr.First(); itr.Valid(); itr.Next() {
+		storeKey := strings.TrimPrefix(string(itr.Key()), string(prefix))
+		value, err := itr.ValueAndErr()
+		if err != nil {
+			return nil, err
+		}
+		if len(value) != VersionSize {
+			return nil, fmt.Errorf("invalid prune height of store %s: %X", storeKey, value)
+		}
+		pruneHeights[storeKey] = binary.LittleEndian.Uint64(value)
+	}
+
+	return pruneHeights, itr.Error()
+}
+
+// prefixEnd returns the smallest key greater than all the keys with the prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := slices.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		end[i]++
+		if end[i] != 0 {
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
 func valTombstoned(value []byte) bool {
 	if value == nil {
 		return false
@@ -3,26 +3,23 @@ package pebbledb
 import (
 	"testing"
 
-	"github.com/stretchr/testify/suite"
-
-	coretesting "cosmossdk.io/core/testing"
-	"cosmossdk.io/store/v2/storage"
+	"github.com/cockroachdb/pebble"
+	"github.com/stretchr/testify/require"
 )
 
-func TestStorageTestSuite(t *testing.T) {
-	s := &storage.StorageTestSuite{
-		NewDB: func(dir string) (*storage.StorageStore, error) {
-			db, err := New(dir)
-			if err == nil && db != nil {
-				// We set sync=false just to speed up CI tests. Operators should take
-				// careful consideration when setting this value in production environments.
-				db.SetSync(false)
-			}
+func TestCompactSingleKey(t *testing.T) {
+	db, err := New(t.TempDir())
+	require.NoError(t, err)
+	defer db.Close()
+
+	// an empty database is not compacted
+	require.NoError(t, db.Compact())
 
-			return storage.NewStorageStore(db, coretesting.NewNopLogger()), err
-		},
-		EmptyBatchSize: 12,
-	}
+	require.NoError(t, db.storage.Set(MVCCEncode([]byte("key"), 1), []byte("value"), pebble.Sync))
+	require.NoError(t, db.storage.Flush())
+	require.Equal(t, int64(1), db.storage.Metrics().Levels[0].NumFiles)
 
-	suite.Run(t, s)
+	// the file holding the single key is compacted out of L0
+	require.NoError(t, db.Compact())
+	require.Equal(t, int64(0), db.storage.Metrics().Levels[0].NumFiles)
 }
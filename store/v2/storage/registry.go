@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"cosmossdk.io/store/v2"
+	"cosmossdk.io/store/v2/db"
+)
+
+// DatabaseConstructor creates a versioned database in dataDir.
+type DatabaseConstructor func(dataDir string, opts store.DBOptions) (Database, error)
+
+var (
+	registryMtx sync.RWMutex
+	registry    = make(map[db.DBType]DatabaseConstructor)
+)
+
+// RegisterDatabase registers the constructor of a versioned database backend.
+// Backends register themselves from an init function, the ones requiring a
+// build tag are only registered when built with it. It panics if the backend is
+// already registered.
+func RegisterDatabase(dbType db.DBType, constructor DatabaseConstructor) {
+	registryMtx.Lock()
+	defer registryMtx.Unlock()
+
+	if _, ok := registry[dbType]; ok {
+		panic(fmt.Sprintf("storage db type %s is already registered", dbType))
+	}
+	registry[dbType] = constructor
+}
+
+// RegisteredDatabaseTypes returns the sorted types of the registered versioned
+// database backends.
+func RegisteredDatabaseTypes() []db.DBType {
+	registryMtx.RLock()
+	defer registryMtx.RUnlock()
+
+	dbTypes := make([]db.DBType, 0, len(registry))
+	for dbType := range registry {
+		dbTypes = append(dbTypes, dbType)
+	}
+	slices.Sort(dbTypes)
+
+	return dbTypes
+}
+
+// NewDatabase creates a versioned database of the given registered type.
+func NewDatabase(dbType db.DBType, dataDir string, opts store.DBOptions) (Database, error) {
+	registryMtx.RLock()
+	constructor, ok := registry[dbType]
+	registryMtx.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported storage db type: %s", dbType)
+	}
+
+	return constructor(dataDir, opts)
+}
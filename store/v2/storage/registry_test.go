@@ -0,0 +1,57 @@
+package storage_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	coretesting "cosmossdk.io/core/testing"
+	"cosmossdk.io/store/v2"
+	dbm "cosmossdk.io/store/v2/db"
+	"cosmossdk.io/store/v2/storage"
+	_ "cosmossdk.io/store/v2/storage/pebbledb"
+	_ "cosmossdk.io/store/v2/storage/rocksdb"
+	_ "cosmossdk.io/store/v2/storage/sqlite"
+)
+
+// skipTests lists the conformance tests skipped per backend.
+var skipTests = map[dbm.DBType][]string{
+	dbm.DBTypeRocksDB: {"TestUpgradable_Prune", "TestDatabase_PruneStores"},
+}
+
+// testOptions speeds up the tests, operators should take careful consideration
+// when disabling sync in production environments.
+type testOptions map[string]any
+
+func (o testOptions) Get(key string) any {
+	return o[key]
+}
+
+func TestStorageTestSuite(t *testing.T) {
+	for _, dbType := range storage.RegisteredDatabaseTypes() {
+		t.Run(string(dbType), func(t *testing.T) {
+			s := &storage.StorageTestSuite{
+				NewDB: func(dir string) (*storage.StorageStore, error) {
+					db, err := storage.NewDatabase(dbType, dir, testOptions{"sync": false})
+					return storage.NewStorageStore(db, coretesting.NewNopLogger()), err
+				},
+				SkipTests: skipTests[dbType],
+			}
+			suite.Run(t, s)
+		})
+	}
+}
+
+func TestNewDatabase(t *testing.T) {
+	require.Contains(t, storage.RegisteredDatabaseTypes(), dbm.DBTypePebbleDB)
+	require.Contains(t, storage.RegisteredDatabaseTypes(), dbm.DBTypeSQLite)
+
+	_, err := storage.NewDatabase(dbm.DBTypeGoLevelDB, t.TempDir(), nil)
+	require.ErrorContains(t, err, "unsupported storage db type")
+	require.Panics(t, func() {
+		storage.RegisterDatabase(dbm.DBTypeSQLite, func(string, store.DBOptions) (storage.Database, error) {
+			return nil, nil
+		})
+	})
+}
@@ -13,6 +13,7 @@ import (
 
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
+	dbm "cosmossdk.io/store/v2/db"
 	"cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/storage"
 	"cosmossdk.io/store/v2/storage/util"
@@ -33,6 +34,12 @@ var (
 	defaultReadOpts  = grocksdb.NewDefaultReadOptions()
 )
 
+func init() {
+	storage.RegisterDatabase(dbm.DBTypeRocksDB, func(dataDir string, _ store.DBOptions) (storage.Database, error) {
+		return New(dataDir)
+	})
+}
+
 type Database struct {
 	storage  *grocksdb.DB
 	cfHandle *grocksdb.ColumnFamilyHandle
@@ -149,6 +156,12 @@ func (db *Database) Get(storeKey []byte, version uint64, key []byte) ([]byte, er
 	return copyAndFreeSlice(slice), nil
 }
 
+// Compact implements storage.Compactor.
+func (db *Database) Compact() error {
+	db.storage.CompactRangeCF(db.cfHandle, grocksdb.Range{})
+	return nil
+}
+
 // Prune prunes all versions up to and including the provided version argument.
 // Internally, this performs a manual compaction, the data with older timestamp
 // will be GCed by compaction.
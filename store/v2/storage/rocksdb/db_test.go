@@ -8,26 +8,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
-	"github.com/stretchr/testify/suite"
-
-	coretesting "cosmossdk.io/core/testing"
-	"cosmossdk.io/store/v2/storage"
 )
 
 var storeKey1 = []byte("store1")
 
-func TestStorageTestSuite(t *testing.T) {
-	s := &storage.StorageTestSuite{
-		NewDB: func(dir string) (*storage.StorageStore, error) {
-			db, err := New(dir)
-			return storage.NewStorageStore(db, coretesting.NewNopLogger()), err
-		},
-		EmptyBatchSize: 12,
-		SkipTests:      []string{"TestUpgradable_Prune"},
-	}
-	suite.Run(t, s)
-}
-
 func TestDatabase_ReverseIterator(t *testing.T) {
 	db, err := New(t.TempDir())
 	require.NoError(t, err)
@@ -0,0 +1,4 @@
+// Package rocksdb implements a versioned database for the state storage on top
+// of RocksDB. It requires to be built with the rocksdb build tag, otherwise the
+// package is empty and the backend is not registered.
+package rocksdb
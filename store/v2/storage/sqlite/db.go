@@ -7,11 +7,13 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	corestore "cosmossdk.io/core/store"
 	"cosmossdk.io/store/v2"
+	dbm "cosmossdk.io/store/v2/db"
 	storeerrors "cosmossdk.io/store/v2/errors"
 	"cosmossdk.io/store/v2/storage"
 )
@@ -22,6 +24,7 @@ const (
 	reservedStoreKey  = "_RESERVED_"
 	keyLatestHeight   = "latest_height"
 	keyPruneHeight    = "prune_height"
+	keyStorePruneTpl  = "prune_height/%s"
 	valueRemovedStore = "removed_store"
 
 	reservedUpsertStmt = `
@@ -47,14 +50,26 @@ const (
 var (
 	_ storage.Database         = (*Database)(nil)
 	_ store.UpgradableDatabase = (*Database)(nil)
+	_ store.StorePruner        = (*Database)(nil)
 )
 
+func init() {
+	storage.RegisterDatabase(dbm.DBTypeSQLite, func(dataDir string, _ store.DBOptions) (storage.Database, error) {
+		return New(dataDir)
+	})
+}
+
 type Database struct {
 	storage *sql.DB
 
 	// earliestVersion defines the earliest version set in the database, which is
 	// only updated when the database is pruned.
 	earliestVersion uint64
+
+	// storePruneHeights defines the prune heights of the stores pruned to a
+	// different version than the rest of the database, see PruneStores.
+	storePruneHeights map[string]uint64
+	mtx               sync.RWMutex
 }
 
 func New(dataDir string) (*Database, error) {
@@ -85,10 +100,15 @@ func New(dataDir string) (*Database, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get prune height: %w", err)
 	}
+	storePruneHeights, err := getStorePruneHeights(storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get store prune heights: %w", err)
+	}
 
 	return &Database{
-		storage:         storage,
-		earliestVersion: pruneHeight,
+		storage:           storage,
+		earliestVersion:   pruneHeight,
+		storePruneHeights: storePruneHeights,
 	}, nil
 }
 
@@ -132,6 +152,18 @@ func (db *Database) SetLatestVersion(version uint64) error {
 	return nil
 }
 
+// storeEarliestVersion returns the earliest version available for the store.
+func (db *Database) storeEarliestVersion(storeKey []byte) uint64 {
+	db.mtx.RLock()
+	defer db.mtx.RUnlock()
+
+	if pruneHeight, ok := db.storePruneHeights[string(storeKey)]; ok {
+		return pruneHeight + 1
+	}
+
+	return db.earliestVersion
+}
+
 func (db *Database) Has(storeKey []byte, version uint64, key []byte) (bool, error) {
 	val, err := db.Get(storeKey, version, key)
 	if err != nil {
@@ -142,8 +174,8 @@ func (db *Database) Has(storeKey []byte, version uint64, key []byte) (bool, erro
 }
 
 func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]byte, error) {
-	if targetVersion < db.earliestVersion {
-		return nil, storeerrors.ErrVersionPruned{EarliestVersion: db.earliestVersion, RequestedVersion: targetVersion}
+	if earliestVersion := db.storeEarliestVersion(storeKey); targetVersion < earliestVersion {
+		return nil, storeerrors.ErrVersionPruned{EarliestVersion: earliestVersion, RequestedVersion: targetVersion}
 	}
 
 	stmt, err := db.storage.Prepare(`
@@ -179,6 +211,16 @@ func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]by
 	return nil, nil
 }
 
+// Compact implements storage.Compactor. It rebuilds the database file to
+// reclaim the space of the pruned entries.
+func (db *Database) Compact() error {
+	if _, err := db.storage.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum database: %w", err)
+	}
+
+	return nil
+}
+
 // Prune removes all versions of all keys that are <= the given version. It keeps
 // the latest (non-tombstoned) version of each key/value tuple to handle queries
 // above the prune version. This is analogous to RocksDB full_history_ts_low.
@@ -186,13 +228,24 @@ func (db *Database) Get(storeKey []byte, targetVersion uint64, key []byte) ([]by
 // We perform the prune by deleting all versions of a key, excluding reserved keys,
 // that are <= the given version, except for the latest version of the key.
 func (db *Database) Prune(version uint64) error {
+	return db.PruneStores(version, nil)
+}
+
+// PruneStores removes all versions of the keys of the stores in storeVersions
+// that are <= their version, and all versions of the keys of the other stores
+// that are <= the given version, except for the latest version of each key.
+// A version of 0 is not pruned.
+//
+// The stores in storeVersions keep their own prune height, so they can be
+// pruned less (or more) aggressively than the rest of the database.
+func (db *Database) PruneStores(version uint64, storeVersions map[string]uint64) (err error) {
 	tx, err := db.storage.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to create SQL transaction: %w", err)
 	}
 	defer func() {
 		if err != nil {
-			err = tx.Rollback()
+			err = errors.Join(err, tx.Rollback())
 		}
 	}()
 
@@ -203,14 +256,38 @@ func (db *Database) Prune(version uint64) error {
 		t2.store_key = state_storage.store_key AND
 		t2.key = state_storage.key AND
 		t2.version <= ?
-	) AND store_key != ?;
+	) AND %s;
 	`
-	if _, err := tx.Exec(pruneStmt, version, reservedStoreKey); err != nil {
-		return fmt.Errorf("failed to exec SQL statement: %w", err)
+	if version > 0 {
+		clause := "store_key != ?"
+		args := []any{version, reservedStoreKey}
+		if len(storeVersions) > 0 {
+			clause += " AND store_key NOT IN (" + strings.Repeat("?, ", len(storeVersions)-1) + "?)"
+			for storeKey := range storeVersions {
+				args = append(args, []byte(storeKey))
+			}
+		}
+		if _, err := tx.Exec(fmt.Sprintf(pruneStmt, clause), args...); err != nil {
+			return fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
+	}
+	for storeKey, v := range storeVersions {
+		if v == 0 {
+			continue
+		}
+		if _, err := tx.Exec(fmt.Sprintf(pruneStmt, "store_key = ?"), v, []byte(storeKey)); err != nil {
+			return fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
 	}
 
-	// prune removed stores
-	pruneRemovedStoreKeysStmt := `DELETE FROM state_storage AS s
+	storePruneHeights, err := db.setStorePruneHeights(tx, version, storeVersions)
+	if err != nil {
+		return err
+	}
+
+	if version > 0 {
+		// prune removed stores
+		pruneRemovedStoreKeysStmt := `DELETE FROM state_storage AS s
 	WHERE EXISTS ( 
 		SELECT 1 FROM
 			(
@@ -222,28 +299,79 @@ func (db *Database) Prune(version uint64) error {
 		WHERE s.store_key = t.key AND s.version <= t.max_version LIMIT 1
 	);
 	`
-	if _, err := tx.Exec(pruneRemovedStoreKeysStmt, reservedStoreKey, valueRemovedStore, version, version); err != nil {
-		return fmt.Errorf("failed to exec SQL statement: %w", err)
-	}
+		if _, err := tx.Exec(pruneRemovedStoreKeysStmt, reservedStoreKey, valueRemovedStore, version, version); err != nil {
+			return fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
 
-	// delete the removedKeys
-	if _, err := tx.Exec("DELETE FROM state_storage WHERE store_key = ? AND value = ? AND version <= ?", reservedStoreKey, valueRemovedStore, version); err != nil {
-		return fmt.Errorf("failed to exec SQL statement: %w", err)
-	}
+		// delete the removedKeys
+		if _, err := tx.Exec("DELETE FROM state_storage WHERE store_key = ? AND value = ? AND version <= ?", reservedStoreKey, valueRemovedStore, version); err != nil {
+			return fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
 
-	// set the prune height so we can return <nil> for queries below this height
-	if _, err := tx.Exec(reservedUpsertStmt, reservedStoreKey, keyPruneHeight, version, 0, version); err != nil {
-		return fmt.Errorf("failed to exec SQL statement: %w", err)
+		// set the prune height so we can return <nil> for queries below this height
+		if _, err := tx.Exec(reservedUpsertStmt, reservedStoreKey, keyPruneHeight, version, 0, version); err != nil {
+			return fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("failed to write SQL transaction: %w", err)
 	}
 
-	db.earliestVersion = version + 1
+	db.mtx.Lock()
+	db.storePruneHeights = storePruneHeights
+	db.mtx.Unlock()
+	if version > 0 {
+		db.earliestVersion = version + 1
+	}
+
 	return nil
 }
 
+// setStorePruneHeights records the prune heights of the stores in
+// storeVersions, which differ from the prune height of the database, and drops
+// the records of the other stores once they are pruned to the given version.
+// It returns the resulting prune heights of the stores.
+func (db *Database) setStorePruneHeights(tx *sql.Tx, version uint64, storeVersions map[string]uint64) (map[string]uint64, error) {
+	db.mtx.RLock()
+	pruneHeights := make(map[string]uint64, len(db.storePruneHeights))
+	for storeKey, pruneHeight := range db.storePruneHeights {
+		pruneHeights[storeKey] = pruneHeight
+	}
+	db.mtx.RUnlock()
+
+	for storeKey, pruneHeight := range pruneHeights {
+		if _, ok := storeVersions[storeKey]; !ok && version > 0 && pruneHeight <= version {
+			if _, err := tx.Exec("DELETE FROM state_storage WHERE store_key = ? AND key = ?", reservedStoreKey, fmt.Sprintf(keyStorePruneTpl, storeKey)); err != nil {
+				return nil, fmt.Errorf("failed to exec SQL statement: %w", err)
+			}
+			delete(pruneHeights, storeKey)
+		}
+	}
+
+	// the stores without a prune height of their own are pruned to the prune
+	// height of the database
+	var dbPruneHeight uint64
+	if err := tx.QueryRow("SELECT value FROM state_storage WHERE store_key = ? AND key = ?", reservedStoreKey, keyPruneHeight).Scan(&dbPruneHeight); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to query row: %w", err)
+	}
+
+	for storeKey, v := range storeVersions {
+		pruneHeight, ok := pruneHeights[storeKey]
+		if !ok {
+			pruneHeight = dbPruneHeight
+		}
+		pruneHeight = max(pruneHeight, v)
+		pruneHeights[storeKey] = pruneHeight
+
+		if _, err := tx.Exec(reservedUpsertStmt, reservedStoreKey, fmt.Sprintf(keyStorePruneTpl, storeKey), pruneHeight, 0, pruneHeight); err != nil {
+			return nil, fmt.Errorf("failed to exec SQL statement: %w", err)
+		}
+	}
+
+	return pruneHeights, nil
+}
+
 func (db *Database) Iterator(storeKey []byte, version uint64, start, end []byte) (corestore.Iterator, error) {
 	if (start != nil && len(start) == 0) || (end != nil && len(end) == 0) {
 		return nil, storeerrors.ErrKeyEmpty
@@ -326,6 +454,33 @@ func (db *Database) PrintRowsDebug() {
 	fmt.Println(strings.TrimSpace(sb.String()))
 }
 
+func getStorePruneHeights(storage *sql.DB) (map[string]uint64, error) {
+	prefix := fmt.Sprintf(keyStorePruneTpl, "")
+	// the reserved keys of the store prune heights are in the [prefix, prefix end) range
+	rows, err := storage.Query(
+		"SELECT key, value FROM state_storage WHERE store_key = ? AND key >= ? AND key < ?",
+		reservedStoreKey, prefix, prefix[:len(prefix)-1]+"0",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute SQL query: %w", err)
+	}
+	defer rows.Close()
+
+	pruneHeights := make(map[string]uint64)
+	for rows.Next() {
+		var (
+			key   string
+			value uint64
+		)
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		pruneHeights[strings.TrimPrefix(key, prefix)] = value
+	}
+
+	return pruneHeights, rows.Err()
+}
+
 func getPruneHeight(storage *sql.DB) (uint64, error) {
 	stmt, err := storage.Prepare(`SELECT value FROM state_storage WHERE store_key = ? AND key = ?`)
 	if err != nil {
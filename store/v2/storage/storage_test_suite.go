@@ -586,6 +586,37 @@ func (s *StorageTestSuite) TestDatabase_Prune() {
 	}
 }
 
+func (s *StorageTestSuite) TestDatabase_Compact() {
+	db, err := s.NewDB(s.T().TempDir())
+	s.Require().NoError(err)
+	defer db.Close()
+
+	// compacting an empty database is a no-op
+	s.Require().NoError(db.Compact())
+
+	for v := uint64(1); v <= 10; v++ {
+		cs := corestore.NewChangesetWithPairs(map[string]corestore.KVPairs{storeKey1: {}})
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key%03d", i)
+			val := fmt.Sprintf("val%03d-%03d", i, v)
+
+			cs.AddKVPair(storeKey1Bytes, corestore.KVPair{Key: []byte(key), Value: []byte(val)})
+		}
+		s.Require().NoError(db.ApplyChangeset(v, cs))
+	}
+	s.Require().NoError(db.Prune(5))
+	s.Require().NoError(db.Compact())
+
+	// the state above the pruned version is left untouched
+	for v := uint64(6); v <= 10; v++ {
+		for i := 0; i < 10; i++ {
+			bz, err := db.Get(storeKey1Bytes, v, []byte(fmt.Sprintf("key%03d", i)))
+			s.Require().NoError(err)
+			s.Require().Equal([]byte(fmt.Sprintf("val%03d-%03d", i, v)), bz)
+		}
+	}
+}
+
 func (s *StorageTestSuite) TestDatabase_Prune_KeepRecent() {
 	if slices.Contains(s.SkipTests, s.T().Name()) {
 		s.T().SkipNow()
@@ -640,6 +671,76 @@ func (s *StorageTestSuite) TestDatabase_Prune_KeepRecent() {
 	s.Require().Equal([]byte("val200"), bz)
 }
 
+func (s *StorageTestSuite) TestDatabase_PruneStores() {
+	if slices.Contains(s.SkipTests, "TestDatabase_PruneStores") {
+		s.T().SkipNow()
+	}
+
+	dir := s.T().TempDir()
+	db, err := s.NewDB(dir)
+	s.Require().NoError(err)
+
+	storeKey2 := "store2"
+	storeKey2Bytes := []byte(storeKey2)
+
+	// for versions 1-20, set 10 keys in both stores
+	for v := uint64(1); v <= 20; v++ {
+		cs := corestore.NewChangesetWithPairs(map[string]corestore.KVPairs{storeKey1: {}, storeKey2: {}})
+		for i := 0; i < 10; i++ {
+			key := fmt.Sprintf("key%03d", i)
+			val := fmt.Sprintf("val%03d-%03d", i, v)
+
+			cs.AddKVPair(storeKey1Bytes, corestore.KVPair{Key: []byte(key), Value: []byte(val)})
+			cs.AddKVPair(storeKey2Bytes, corestore.KVPair{Key: []byte(key), Value: []byte(val)})
+		}
+
+		s.Require().NoError(db.ApplyChangeset(v, cs))
+	}
+
+	// prune store1 up to version 10 and store2 up to version 5
+	s.Require().NoError(db.PruneStores(10, map[string]uint64{storeKey2: 5}))
+
+	checkPruned := func(db *StorageStore, storeKey []byte, pruneHeight uint64) {
+		for v := uint64(1); v <= 20; v++ {
+			bz, err := db.Get(storeKey, v, []byte("key000"))
+			if v <= pruneHeight {
+				s.Require().Error(err, "store %s version %d", storeKey, v)
+				s.Require().Nil(bz)
+			} else {
+				s.Require().NoError(err, "store %s version %d", storeKey, v)
+				s.Require().Equal([]byte(fmt.Sprintf("val000-%03d", v)), bz)
+			}
+		}
+	}
+	checkPruned(db, storeKey1Bytes, 10)
+	checkPruned(db, storeKey2Bytes, 5)
+
+	itr, err := db.Iterator(storeKey2Bytes, 6, nil, nil)
+	s.Require().NoError(err)
+	s.Require().True(itr.Valid())
+	s.Require().NoError(itr.Close())
+
+	// a version of 0 leaves the store untouched
+	s.Require().NoError(db.PruneStores(15, map[string]uint64{storeKey2: 0}))
+	checkPruned(db, storeKey1Bytes, 15)
+	checkPruned(db, storeKey2Bytes, 5)
+
+	// the prune heights of the stores are persisted
+	s.Require().NoError(db.Close())
+	db, err = s.NewDB(dir)
+	s.Require().NoError(err)
+	defer db.Close()
+	checkPruned(db, storeKey2Bytes, 5)
+	bz, err := db.Get(storeKey1Bytes, 14, []byte("key000"))
+	s.Require().Error(err)
+	s.Require().Nil(bz)
+
+	// pruning all the stores to the same version drops the store prune height
+	s.Require().NoError(db.Prune(18))
+	checkPruned(db, storeKey1Bytes, 18)
+	checkPruned(db, storeKey2Bytes, 18)
+}
+
 func (s *StorageTestSuite) TestDatabase_Restore() {
 	db, err := s.NewDB(s.T().TempDir())
 	s.Require().NoError(err)
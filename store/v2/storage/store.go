@@ -19,6 +19,7 @@ var (
 	_ store.VersionedDatabase      = (*StorageStore)(nil)
 	_ snapshots.StorageSnapshotter = (*StorageStore)(nil)
 	_ store.Pruner                 = (*StorageStore)(nil)
+	_ store.StorePruner            = (*StorageStore)(nil)
 	_ store.UpgradableDatabase     = (*StorageStore)(nil)
 )
 
@@ -99,6 +100,29 @@ func (ss *StorageStore) Prune(version uint64) error {
 	return ss.db.Prune(version)
 }
 
+// PruneStores prunes the stores in storeVersions up to their version and the
+// other stores up to the given version, an error is returned if the database
+// does not support store pruning.
+func (ss *StorageStore) PruneStores(version uint64, storeVersions map[string]uint64) error {
+	pruner, ok := ss.db.(store.StorePruner)
+	if !ok {
+		return errors.New("the storage database does not support store pruning")
+	}
+
+	return pruner.PruneStores(version, storeVersions)
+}
+
+// Compact compacts the underlying database, an error is returned if the database
+// does not support compaction.
+func (ss *StorageStore) Compact() error {
+	compactor, ok := ss.db.(Compactor)
+	if !ok {
+		return errors.New("the storage database does not support compaction")
+	}
+
+	return compactor.Compact()
+}
+
 // Restore restores the store from the given channel.
 func (ss *StorageStore) Restore(version uint64, chStorage <-chan *corestore.StateChanges) error {
 	latestVersion, err := ss.db.GetLatestVersion()
@@ -89,6 +89,16 @@ type Pruner interface {
 	Prune(version uint64) error
 }
 
+// StorePruner extends the Pruner interface to prune the stores to different
+// versions.
+type StorePruner interface {
+	Pruner
+
+	// PruneStores prunes the stores in storeVersions to their version and the
+	// other stores to the provided version. A version of 0 is not pruned.
+	PruneStores(version uint64, storeVersions map[string]uint64) error
+}
+
 // PausablePruner extends the Pruner interface to include the API for pausing
 // the pruning process.
 type PausablePruner interface {
@@ -106,3 +116,26 @@ type QueryResult struct {
 	Version  uint64
 	ProofOps []proof.CommitmentOp
 }
+
+// BatchQuerier defines an API for querying several keys or a key range of a
+// store at once, which is implemented by the RootStores supporting it.
+type BatchQuerier interface {
+	// QueryBatch queries the given keys of a store, the keys which do not exist
+	// are omitted from the result. If prove is true, the result contains the batch
+	// proof of existence of the pairs and of non-existence of the omitted keys.
+	QueryBatch(storeKey []byte, version uint64, keys [][]byte, prove bool) (BatchQueryResult, error)
+
+	// QueryRange queries the key/value pairs of the range [start, end) of a store.
+	// If prove is true, the result contains the proof that they are all the pairs
+	// of the range. ErrTooManyPairs is returned if the range contains more than
+	// limit pairs, 0 means no limit.
+	QueryRange(storeKey []byte, version uint64, start, end []byte, limit int, prove bool) (BatchQueryResult, error)
+}
+
+// BatchQueryResult defines the response type to performing a batch or a range
+// query on a RootStore.
+type BatchQueryResult struct {
+	Pairs    []proof.KVPair
+	Version  uint64
+	ProofOps []proof.CommitmentOp
+}
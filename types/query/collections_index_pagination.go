@@ -0,0 +1,102 @@
+package query
+
+import (
+	"context"
+
+	"cosmossdk.io/collections"
+	collcodec "cosmossdk.io/collections/codec"
+	"cosmossdk.io/collections/indexes"
+)
+
+// IndexedCollection defines the minimum required API of the collection
+// indexed by an index, used to resolve the primary keys of the index to values.
+// It is implemented by collections.IndexedMap and collections.IndexedLookupMap.
+type IndexedCollection[PK, V any] interface {
+	Get(ctx context.Context, key PK) (V, error)
+}
+
+// CollectionMultiIndexPaginate follows the same logic as CollectionPaginate but
+// for an indexes.Multi: it paginates over the index entries in reference key order,
+// resolves the primary key of each entry through the indexed collection and passes
+// the primary key and value to transformFunc. The NextKey of the response is the raw
+// index key of the next entry. The pagination can be bounded to the entries of a
+// single reference key with WithCollectionPaginationPairPrefix.
+func CollectionMultiIndexPaginate[RK, PK, V any, T any](
+	ctx context.Context,
+	index *indexes.Multi[RK, PK, V],
+	indexedColl IndexedCollection[PK, V],
+	pageReq *PageRequest,
+	transformFunc func(pk PK, value V) (T, error),
+	opts ...func(opt *CollectionsPaginateOptions[collections.Pair[RK, PK]]),
+) ([]T, *PageResponse, error) {
+	return CollectionPaginate(
+		ctx,
+		multiIndexCollection[RK, PK, V]{index},
+		pageReq,
+		func(key collections.Pair[RK, PK], _ collections.NoValue) (T, error) {
+			return resolveIndexedValue(ctx, indexedColl, key.K2(), transformFunc)
+		},
+		opts...,
+	)
+}
+
+// CollectionUniqueIndexPaginate follows the same logic as CollectionPaginate but
+// for an indexes.Unique: it paginates over the index entries in reference key order,
+// resolves the primary key of each entry through the indexed collection and passes
+// the primary key and value to transformFunc. The NextKey of the response is the raw
+// index key of the next entry.
+func CollectionUniqueIndexPaginate[RK, PK, V any, T any](
+	ctx context.Context,
+	index *indexes.Unique[RK, PK, V],
+	indexedColl IndexedCollection[PK, V],
+	pageReq *PageRequest,
+	transformFunc func(pk PK, value V) (T, error),
+	opts ...func(opt *CollectionsPaginateOptions[RK]),
+) ([]T, *PageResponse, error) {
+	return CollectionPaginate(
+		ctx,
+		uniqueIndexCollection[RK, PK, V]{index},
+		pageReq,
+		func(_ RK, pk PK) (T, error) {
+			return resolveIndexedValue(ctx, indexedColl, pk, transformFunc)
+		},
+		opts...,
+	)
+}
+
+func resolveIndexedValue[PK, V, T any](ctx context.Context, indexedColl IndexedCollection[PK, V], pk PK, transformFunc func(PK, V) (T, error)) (T, error) {
+	value, err := indexedColl.Get(ctx, pk)
+	if err != nil {
+		var t T
+		return t, err
+	}
+	return transformFunc(pk, value)
+}
+
+// multiIndexCollection adapts an indexes.Multi to the Collection interface.
+type multiIndexCollection[RK, PK, V any] struct {
+	index *indexes.Multi[RK, PK, V]
+}
+
+func (m multiIndexCollection[RK, PK, V]) IterateRaw(ctx context.Context, start, end []byte, order collections.Order) (collections.Iterator[collections.Pair[RK, PK], collections.NoValue], error) {
+	iter, err := m.index.IterateRaw(ctx, start, end, order)
+	return (collections.Iterator[collections.Pair[RK, PK], collections.NoValue])(iter), err
+}
+
+func (m multiIndexCollection[RK, PK, V]) KeyCodec() collcodec.KeyCodec[collections.Pair[RK, PK]] {
+	return m.index.KeyCodec()
+}
+
+// uniqueIndexCollection adapts an indexes.Unique to the Collection interface.
+type uniqueIndexCollection[RK, PK, V any] struct {
+	index *indexes.Unique[RK, PK, V]
+}
+
+func (u uniqueIndexCollection[RK, PK, V]) IterateRaw(ctx context.Context, start, end []byte, order collections.Order) (collections.Iterator[RK, PK], error) {
+	iter, err := u.index.IterateRaw(ctx, start, end, order)
+	return (collections.Iterator[RK, PK])(iter), err
+}
+
+func (u uniqueIndexCollection[RK, PK, V]) KeyCodec() collcodec.KeyCodec[RK] {
+	return u.index.KeyCodec()
+}
@@ -0,0 +1,109 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/collections"
+	"cosmossdk.io/collections/indexes"
+)
+
+type paginationTestIndexes struct {
+	Group *indexes.Multi[uint64, uint64, uint64]
+	Code  *indexes.Unique[uint64, uint64, uint64]
+}
+
+func (i paginationTestIndexes) IndexesList() []collections.Index[uint64, uint64] {
+	return []collections.Index[uint64, uint64]{i.Group, i.Code}
+}
+
+func TestCollectionIndexPagination(t *testing.T) {
+	sk, ctx := deps()
+	sb := collections.NewSchemaBuilder(sk)
+	// values are indexed by group, value % 3, and by a unique code, 1000 - value
+	m := collections.NewIndexedMap(sb, collections.NewPrefix(0), "m", collections.Uint64Key, collections.Uint64Value, paginationTestIndexes{
+		Group: indexes.NewMulti(sb, collections.NewPrefix(1), "group", collections.Uint64Key, collections.Uint64Key, func(_, value uint64) (uint64, error) {
+			return value % 3, nil
+		}),
+		Code: indexes.NewUnique(sb, collections.NewPrefix(2), "code", collections.Uint64Key, collections.Uint64Key, func(_, value uint64) (uint64, error) {
+			return 1000 - value, nil
+		}),
+	})
+	_, err := sb.Build()
+	require.NoError(t, err)
+
+	for i := uint64(0); i < 30; i++ {
+		require.NoError(t, m.Set(ctx, i, i*10))
+	}
+
+	transform := func(pk, value uint64) (collections.KeyValue[uint64, uint64], error) {
+		return collections.KeyValue[uint64, uint64]{Key: pk, Value: value}, nil
+	}
+	kvs := func(pks ...uint64) []collections.KeyValue[uint64, uint64] {
+		res := make([]collections.KeyValue[uint64, uint64], len(pks))
+		for i, pk := range pks {
+			res[i] = collections.KeyValue[uint64, uint64]{Key: pk, Value: pk * 10}
+		}
+		return res
+	}
+
+	t.Run("multi", func(t *testing.T) {
+		// all the entries of the index, ordered by group and then by primary key
+		results, resp, err := CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Limit: 4, CountTotal: true}, transform)
+		require.NoError(t, err)
+		require.Equal(t, kvs(0, 3, 6, 9), results)
+		require.Equal(t, uint64(30), resp.Total)
+
+		results, resp, err = CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Key: resp.NextKey, Limit: 4}, transform)
+		require.NoError(t, err)
+		require.Equal(t, kvs(12, 15, 18, 21), results)
+		require.NotNil(t, resp.NextKey)
+	})
+
+	t.Run("multi with prefix", func(t *testing.T) {
+		withGroup := WithCollectionPaginationPairPrefix[uint64, uint64](1)
+
+		var all []collections.KeyValue[uint64, uint64]
+		var key []byte
+		for {
+			results, resp, err := CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Key: key, Limit: 3}, transform, withGroup)
+			require.NoError(t, err)
+			all = append(all, results...)
+			if resp.NextKey == nil {
+				break
+			}
+			key = resp.NextKey
+		}
+		require.Equal(t, kvs(1, 4, 7, 10, 13, 16, 19, 22, 25, 28), all)
+
+		results, resp, err := CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Limit: 3, Reverse: true, CountTotal: true}, transform, withGroup)
+		require.NoError(t, err)
+		require.Equal(t, kvs(28, 25, 22), results)
+		require.Equal(t, uint64(10), resp.Total)
+
+		results, _, err = CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Key: resp.NextKey, Limit: 3, Reverse: true}, transform, withGroup)
+		require.NoError(t, err)
+		require.Equal(t, kvs(19, 16, 13), results)
+
+		results, _, err = CollectionMultiIndexPaginate(ctx, m.Indexes.Group, m, &PageRequest{Offset: 8, Limit: 3}, transform, withGroup)
+		require.NoError(t, err)
+		require.Equal(t, kvs(25, 28), results)
+	})
+
+	t.Run("unique", func(t *testing.T) {
+		// codes are in reverse order of the primary keys
+		results, resp, err := CollectionUniqueIndexPaginate(ctx, m.Indexes.Code, m, &PageRequest{Limit: 3, CountTotal: true}, transform)
+		require.NoError(t, err)
+		require.Equal(t, kvs(29, 28, 27), results)
+		require.Equal(t, uint64(30), resp.Total)
+
+		results, _, err = CollectionUniqueIndexPaginate(ctx, m.Indexes.Code, m, &PageRequest{Key: resp.NextKey, Limit: 3}, transform)
+		require.NoError(t, err)
+		require.Equal(t, kvs(26, 25, 24), results)
+
+		results, _, err = CollectionUniqueIndexPaginate(ctx, m.Indexes.Code, m, &PageRequest{Limit: 3, Reverse: true}, transform)
+		require.NoError(t, err)
+		require.Equal(t, kvs(0, 1, 2), results)
+	})
+}